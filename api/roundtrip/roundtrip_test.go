@@ -0,0 +1,39 @@
+package roundtrip
+
+import (
+	"encoding/json"
+	"testing"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func TestRoundTripTypes(t *testing.T) {
+	Test(t, nil)
+}
+
+// FuzzDashboardAppJSON checks that any DashboardApp the API would accept as
+// JSON encodes back to an equivalent object.
+func FuzzDashboardAppJSON(f *testing.F) {
+	f.Add([]byte(`{"apiVersion":"dashboard.homelab.io/v1alpha1","kind":"DashboardApp","metadata":{"name":"plex"},"spec":{"name":"Plex","url":"https://plex","category":"media","icon":"<svg/>","groups":["family"],"ttl":"1h"}}`))
+	f.Add([]byte(`{"spec":{"visibilitySchedule":[{"groups":["kids"],"schedule":"0 20 * * 0-4","duration":"10h"}],"dependsOn":[{"name":"db"}]},"status":{"health":{"state":"down"}}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var app dashboardv1alpha1.DashboardApp
+		if err := json.Unmarshal(data, &app); err != nil {
+			return
+		}
+		encoded, err := json.Marshal(&app)
+		if err != nil {
+			t.Fatalf("marshal decoded app: %v", err)
+		}
+		var again dashboardv1alpha1.DashboardApp
+		if err := json.Unmarshal(encoded, &again); err != nil {
+			t.Fatalf("unmarshal re-encoded app: %v\n%s", err, encoded)
+		}
+		if !apiequality.Semantic.DeepEqual(app, again) {
+			t.Fatalf("round trip changed the object:\nbefore: %#v\nafter:  %#v", app, again)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DashboardFinalizer keeps a deleted Dashboard around until the operator
+// instance writing it has cleaned up its target, as set by
+// spec.deletionPolicy
+const DashboardFinalizer = "dashboard.homelab.io/target-cleanup"
+
+// Deletion policies of a Dashboard target
+const (
+	// DeletionPolicyDelete deletes the target with the Dashboard
+	DeletionPolicyDelete = "Delete"
+	// DeletionPolicyOrphan leaves the target in place, no longer owned by
+	// the Dashboard
+	DeletionPolicyOrphan = "Orphan"
+)
+
+// DashboardTarget is the ConfigMap, or Secret, a Dashboard is written to
+type DashboardTarget struct {
+	// Namespace of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// ConfigMap is the name of the ConfigMap, created if missing and owned by
+	// the Dashboard
+	// +kubebuilder:validation:MinLength=1
+	ConfigMap string `json:"configMap"`
+
+	// Kind of the object written: configmap, or secret, in which case
+	// ConfigMap names a Secret; the operator's --output-kind when unset
+	// +kubebuilder:validation:Enum=configmap;secret
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// DashboardSpec selects the apps of an additional dashboard. The apps are
+// those of the operator instance that also match both selectors; they are
+// assembled exactly like the main output, into their own ConfigMap.
+type DashboardSpec struct {
+	// Target is the ConfigMap the dashboard documents are written to
+	Target DashboardTarget `json:"target"`
+
+	// Selector restricts the dashboard to DashboardApps with matching labels
+	// (all apps when empty)
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// NamespaceSelector restricts the dashboard to DashboardApps in
+	// namespaces with matching labels (all namespaces when empty)
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Formats lists the dashboard formats written alongside duro's
+	// documents (e.g. homer, homepage); the operator's --output-formats
+	// when unset
+	// +optional
+	Formats []string `json:"formats,omitempty"`
+
+	// Instance is the operator instance (--instance-name) writing the
+	// dashboard; empty for the default instance
+	// +optional
+	Instance string `json:"instance,omitempty"`
+
+	// MinWriteInterval is the minimum time between two writes to the
+	// target, e.g. for a dashboard polling its ConfigMap less often than
+	// duro; the operator's --min-write-interval when unset, 0s disables it
+	// +optional
+	MinWriteInterval *metav1.Duration `json:"minWriteInterval,omitempty"`
+
+	// DeletionPolicy is what becomes of the target when the Dashboard is
+	// deleted: Delete removes it, Orphan leaves it in place, no longer
+	// owned by the Dashboard
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+}
+
+// DashboardStatus is the state of the last write of a Dashboard
+type DashboardStatus struct {
+	// ObservedGeneration is the generation last written
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Apps is the number of DashboardApps selected
+	// +optional
+	Apps int `json:"apps,omitempty"`
+
+	// Entries is the number of entries written
+	// +optional
+	Entries int `json:"entries,omitempty"`
+
+	// ConfigHash is the hash of the documents last written
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// LastWriteTime is when the target ConfigMap was last written
+	// +optional
+	LastWriteTime *metav1.Time `json:"lastWriteTime,omitempty"`
+
+	// Conditions include Ready, false when the dashboard could not be
+	// written
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=dash
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.spec.target.namespace`
+// +kubebuilder:printcolumn:name="ConfigMap",type=string,JSONPath=`.spec.target.configMap`
+// +kubebuilder:printcolumn:name="Apps",type=integer,JSONPath=`.status.apps`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Dashboard is the Schema for the dashboards API
+type Dashboard struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DashboardSpec   `json:"spec,omitempty"`
+	Status DashboardStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DashboardList contains a list of Dashboard
+type DashboardList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Dashboard `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Dashboard{}, &DashboardList{})
+}
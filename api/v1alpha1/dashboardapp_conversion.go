@@ -0,0 +1,5 @@
+package v1alpha1
+
+// Hub marks v1alpha1 as the version other DashboardApp versions convert
+// through; it is also the version DashboardApps are stored in.
+func (*DashboardApp) Hub() {}
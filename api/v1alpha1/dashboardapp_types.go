@@ -1,10 +1,36 @@
 package v1alpha1
 
 import (
+	"slices"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// HeartbeatAnnotation holds the RFC3339 time an external agent last confirmed
+// the app is still around; spec.ttl counts from it when present
+const HeartbeatAnnotation = "dashboard.homelab.io/last-heartbeat"
+
+// ResyncAnnotation requests a resync whenever its value (typically a
+// timestamp) changes: on a DashboardApp it re-validates the app and
+// re-assembles the output, on the OperatorOverview it rebuilds and rewrites
+// every output document and app status
+const ResyncAnnotation = "dashboard.homelab.io/resync"
+
+// RemovalFinalizer keeps a deleted DashboardApp around until its entry is
+// gone from the output, listing it as removed for the operator's removal
+// grace period first. The operator releases it from apps leaving its
+// --app-selector; apps it no longer sees at all (uninstalled operator,
+// namespaces taken out by --watch-namespaces or --exclude-namespaces) are
+// released with duroctl release
+const RemovalFinalizer = "dashboard.homelab.io/removal-grace"
+
+// SourceLabel records who manages a DashboardApp when it is not written by
+// hand (e.g. external registration, Helm discovery)
+const SourceLabel = "dashboard.homelab.io/source"
+
 // DashboardAppSpec defines the desired state of DashboardApp
+// +kubebuilder:validation:XValidation:rule="(has(self.icon) && size(self.icon) > 0) || (has(self.iconURL) && size(self.iconURL) > 0)",message="one of icon or iconURL is required"
 type DashboardAppSpec struct {
 	// Name is the display name of the application
 	// +kubebuilder:validation:Required
@@ -14,49 +40,340 @@ type DashboardAppSpec struct {
 	// +kubebuilder:validation:Required
 	URL string `json:"url"`
 
+	// InternalURL is an endpoint reachable from inside the cluster or the
+	// LAN (e.g. "http://plex.{{ .namespace }}.svc.{{ .clusterDomain }}:32400"),
+	// offered alongside URL to users on the local network
+	// +optional
+	InternalURL string `json:"internalURL,omitempty"`
+
 	// Category groups the app in the dashboard (free-form string, e.g. media, ai, automation, storage)
-	// +kubebuilder:validation:Required
+	// When left empty the app is only listed if the operator infers its
+	// category from a label on its namespace (--category-label).
 	// +kubebuilder:validation:MinLength=1
-	Category string `json:"category"`
+	// +optional
+	Category string `json:"category,omitempty"`
 
-	// Icon is the raw SVG string for the app icon
-	// +kubebuilder:validation:Required
+	// Icon is the raw SVG string for the app icon, or a shorthand for an
+	// icon of a well-known set (e.g. "sh:plex", "si:jellyfin",
+	// "mdi:server"); required unless IconURL is set
+	// +optional
 	Icon string `json:"icon"`
 
-	// Groups defines which LDAP/OIDC groups can see this app (OR logic)
-	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:MinItems=1
-	Groups []string `json:"groups"`
+	// IconURL points at an SVG icon the operator fetches, caches and inlines
+	// in place of Icon, for icons too large to embed comfortably
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	IconURL string `json:"iconURL,omitempty"`
+
+	// Description is a short blurb shown under the app's tile; like URL it
+	// may reference template variables, e.g. {{ .clusterDomain }}
+	// +kubebuilder:validation:MaxLength=200
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Tags are free-form labels the dashboard can filter apps by
+	// +kubebuilder:validation:MaxItems=20
+	// +kubebuilder:validation:items:MinLength=1
+	// +kubebuilder:validation:items:MaxLength=32
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// Extra holds fields passed through verbatim to the app's entry, for
+	// dashboard frontends with attributes the operator doesn't know about
+	// +kubebuilder:validation:MaxProperties=32
+	// +kubebuilder:validation:XValidation:rule="self.all(k, size(k) <= 63 && size(self[k]) <= 1024)",message="extra keys are limited to 63 characters and values to 1024"
+	// +optional
+	Extra map[string]string `json:"extra,omitempty"`
+
+	// Groups defines which LDAP/OIDC groups can see this app (OR logic).
+	// Entries may end with a wildcard: "media/*" matches any subgroup of
+	// media, "media*" any group starting with media, and "*" every group.
+	// When left empty the app is only listed if the operator derives its
+	// groups from RoleBindings in its namespace (--rbac-groups).
+	// +kubebuilder:validation:items:Pattern=`^[^*]+\*?$|^\*$`
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// Access refines who sees the app beyond the OR logic of Groups, which
+	// is shorthand for access.anyOf
+	// +optional
+	Access *AppAccess `json:"access,omitempty"`
+
+	// Actions are quick actions duro renders as buttons on the app's tile,
+	// e.g. restarting its container or opening its logs
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Actions []AppAction `json:"actions,omitempty"`
 
 	// Priority controls sort order within a category (lower = first)
 	// +kubebuilder:default=100
 	// +optional
 	Priority int `json:"priority,omitempty"`
+
+	// Enabled set to false hides the app from the dashboard without deleting
+	// it
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// DependsOn lists DashboardApps this app needs; if any of them is down
+	// the app is reported as degraded
+	// +optional
+	DependsOn []AppReference `json:"dependsOn,omitempty"`
+
+	// VisibilitySchedule hides the app from some groups during recurring
+	// time windows (e.g. game servers on school nights)
+	// +optional
+	VisibilitySchedule []VisibilityWindow `json:"visibilitySchedule,omitempty"`
+
+	// HealthCheck has the operator probe the app over HTTP and record the
+	// outcome in status.health, when the operator runs with --health-probes
+	// +optional
+	HealthCheck *HealthCheck `json:"healthCheck,omitempty"`
+
+	// HeartbeatTimeout marks the app stale (health unknown) when its
+	// last-heartbeat annotation is older than this, for apps the operator
+	// cannot probe directly and whose agent refreshes the annotation instead
+	// +optional
+	HeartbeatTimeout *metav1.Duration `json:"heartbeatTimeout,omitempty"`
+
+	// Condition is a CEL expression over cluster facts (crds, namespaces,
+	// flags); the app is only listed while it evaluates to true, e.g.
+	// `"ingressroutes.traefik.io" in crds && flags["media"] == "true"`
+	// +optional
+	Condition string `json:"condition,omitempty"`
+
+	// TTL removes the DashboardApp once this long has passed since its
+	// creation or last heartbeat (see the last-heartbeat annotation), so
+	// externally registered services age out when they stop reporting
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// AppAccess combines group lists, with the same syntax as spec.groups. A
+// user sees the app when they belong to a group of AnyOf (if any), to a group
+// matching each entry of AllOf, and to no group of NoneOf. An app needs AnyOf
+// or AllOf to be listed at all.
+// +kubebuilder:validation:XValidation:rule="has(self.anyOf) || has(self.allOf) || has(self.noneOf)",message="access needs at least one of anyOf, allOf and noneOf"
+type AppAccess struct {
+	// AnyOf lists groups any of which grants access, in addition to
+	// spec.groups
+	// +kubebuilder:validation:items:Pattern=`^[^*]+\*?$|^\*$`
+	// +optional
+	AnyOf []string `json:"anyOf,omitempty"`
+
+	// AllOf lists groups the user must all belong to, e.g. ["media",
+	// "adults"]
+	// +kubebuilder:validation:items:Pattern=`^[^*]+\*?$|^\*$`
+	// +optional
+	AllOf []string `json:"allOf,omitempty"`
+
+	// NoneOf lists groups whose members never see the app, whatever else
+	// they belong to
+	// +kubebuilder:validation:items:Pattern=`^[^*]+\*?$|^\*$`
+	// +optional
+	NoneOf []string `json:"noneOf,omitempty"`
+}
+
+// ActionMethods are the HTTP methods an action may use
+var ActionMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// AppAction is a quick action on an app: a link duro opens, or a request it
+// sends, when the action's button is pressed
+type AppAction struct {
+	// Name labels the action's button, e.g. "Open logs"; unique among the
+	// app's actions
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=32
+	Name string `json:"name"`
+
+	// Icon is an icon library shorthand (e.g. "mdi:restart"), emitted as
+	// the icon's URL, or any other icon reference duro understands
+	// +optional
+	Icon string `json:"icon,omitempty"`
+
+	// URL the action opens or requests; templated like spec.url
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Method is the HTTP method of the request; GET actions are opened as
+	// links
+	// +kubebuilder:validation:Enum=GET;POST;PUT;PATCH;DELETE
+	// +kubebuilder:default=GET
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Groups restricts the action to members of these groups (same syntax
+	// as spec.groups); everyone seeing the app gets it when empty
+	// +kubebuilder:validation:items:Pattern=`^[^*]+\*?$|^\*$`
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// AppReference points at another DashboardApp
+type AppReference struct {
+	// Name of the DashboardApp
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the DashboardApp (defaults to the referencing app's namespace)
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// HealthCheck is an HTTP probe of an app; the app is up while the probe
+// answers with an expected status code and down otherwise
+type HealthCheck struct {
+	// Path probed on the app's internal URL, or its URL when it has none,
+	// e.g. "/healthz" (default: the URL's own path)
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Interval between probes (default 1m, at least 10s)
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Timeout of each probe (default 5s, at most 30s)
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// ExpectedStatuses are the HTTP status codes meaning the app is up
+	// (default: any 2xx or 3xx)
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:Minimum=100
+	// +kubebuilder:validation:items:Maximum=599
+	// +optional
+	ExpectedStatuses []int `json:"expectedStatuses,omitempty"`
+}
+
+// HealthState is the observed health of an app
+// +kubebuilder:validation:Enum=up;down;degraded;unknown
+type HealthState string
+
+const (
+	HealthUp       HealthState = "up"
+	HealthDown     HealthState = "down"
+	HealthDegraded HealthState = "degraded"
+	HealthUnknown  HealthState = "unknown"
+)
+
+// AppHealth is the last observed health of an app
+type AppHealth struct {
+	// State is the current health state
+	State HealthState `json:"state"`
+
+	// Reason is a short human-readable explanation of the state
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// LastTransitionTime is when State last changed
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// HealthSample is a health state observed for an app and when it began
+type HealthSample struct {
+	// State is the observed health state
+	State HealthState `json:"state"`
+
+	// Time is when the app entered State
+	Time metav1.Time `json:"time"`
+
+	// Count is how many consecutive health check results reported State,
+	// counted up to the health damping threshold; 0 for states the operator
+	// did not probe
+	// +optional
+	Count int32 `json:"count,omitempty"`
+}
+
+// AppUsage is the app's popularity according to imported usage counts
+type AppUsage struct {
+	// Count is how often the app was opened
+	Count int64 `json:"count"`
+
+	// Rank is the app's position when all apps are ordered by count (1 = most
+	// used); 0 if the app was never opened
+	// +optional
+	Rank int `json:"rank,omitempty"`
+}
+
+// VisibilityWindow hides an app from the listed groups whenever Schedule
+// fires, for Duration
+type VisibilityWindow struct {
+	// Groups the app is hidden from while the window is open (same syntax as spec.groups)
+	// +kubebuilder:validation:MinItems=1
+	Groups []string `json:"groups"`
+
+	// Schedule is a five-field cron expression (minute hour day-of-month month
+	// day-of-week) marking the start of each window, e.g. "0 20 * * 0-4"
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open after each start (max 168h)
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone is the IANA time zone the schedule is evaluated in (default UTC)
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
 // DashboardAppStatus defines the observed state of DashboardApp
 type DashboardAppStatus struct {
-	// Ready indicates if the app has been synced to the ConfigMap
+	// Ready indicates if the app has been synced to the ConfigMap.
+	// Deprecated: use the Ready condition, which also gives the reason
 	Ready bool `json:"ready,omitempty"`
 
 	// LastSyncedAt is the timestamp of the last successful sync
 	LastSyncedAt *metav1.Time `json:"lastSyncedAt,omitempty"`
 
+	// LastSyncTraceID is the trace ID of the reconcile that last synced this
+	// app; it appears as trace_id in operator logs
+	// +optional
+	LastSyncTraceID string `json:"lastSyncTraceID,omitempty"`
+
 	// ObservedGeneration is the generation of the DashboardApp spec that was
 	// last reconciled. If it matches metadata.generation, the spec has been
 	// fully processed and the controller can skip redundant work.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// ObservedResync is the value of the resync annotation last acted upon
+	// +optional
+	ObservedResync string `json:"observedResync,omitempty"`
+
+	// Health is the last observed health of the app
+	// +optional
+	Health *AppHealth `json:"health,omitempty"`
+
+	// HealthHistory holds the most recent health states observed, oldest
+	// first; the output damps states reported by fewer consecutive health
+	// check results than the health damping threshold
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	HealthHistory []HealthSample `json:"healthHistory,omitempty"`
+
+	// Usage is the app's popularity, when usage counts are imported from duro
+	// +optional
+	Usage *AppUsage `json:"usage,omitempty"`
+
 	// Conditions represent the current state of the DashboardApp
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// InferredCategory is the category taken from the app's namespace label
+	// when spec.category is empty
+	// +optional
+	InferredCategory string `json:"inferredCategory,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=dapp
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
 // +kubebuilder:printcolumn:name="Category",type=string,JSONPath=`.spec.category`
-// +kubebuilder:printcolumn:name="Ready",type=boolean,JSONPath=`.status.ready`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // DashboardApp is the Schema for the dashboardapps API
@@ -68,6 +385,45 @@ type DashboardApp struct {
 	Status DashboardAppStatus `json:"status,omitempty"`
 }
 
+// Disabled reports whether spec.enabled hides the app.
+func (in *DashboardApp) Disabled() bool {
+	return in.Spec.Enabled != nil && !*in.Spec.Enabled
+}
+
+// AnyOfGroups returns the groups any of which grants access to the app:
+// spec.groups followed by the groups of spec.access.anyOf not already
+// listed.
+func (in *DashboardApp) AnyOfGroups() []string {
+	if in.Spec.Access == nil || len(in.Spec.Access.AnyOf) == 0 {
+		return in.Spec.Groups
+	}
+	anyOf := slices.Clone(in.Spec.Groups)
+	for _, g := range in.Spec.Access.AnyOf {
+		if !slices.Contains(anyOf, g) {
+			anyOf = append(anyOf, g)
+		}
+	}
+	return anyOf
+}
+
+// HasAccessGroups reports whether some group grants access to the app,
+// through spec.groups, spec.access.anyOf or spec.access.allOf.
+func (in *DashboardApp) HasAccessGroups() bool {
+	return len(in.Spec.Groups) > 0 || (in.Spec.Access != nil && (len(in.Spec.Access.AnyOf) > 0 || len(in.Spec.Access.AllOf) > 0))
+}
+
+// LastSeen returns when the app was last known to exist: its heartbeat
+// annotation if set and later than its creation, otherwise its creation time.
+func (in *DashboardApp) LastSeen() time.Time {
+	seen := in.CreationTimestamp.Time
+	if v, ok := in.Annotations[HeartbeatAnnotation]; ok {
+		if t, err := time.Parse(time.RFC3339, v); err == nil && t.After(seen) {
+			seen = t
+		}
+	}
+	return seen
+}
+
 // +kubebuilder:object:root=true
 
 // DashboardAppList contains a list of DashboardApp
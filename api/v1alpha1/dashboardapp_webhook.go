@@ -0,0 +1,78 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultPriority is the priority of apps that don't set one
+const DefaultPriority = 100
+
+// DashboardAppDefaulter canonicalizes DashboardApp specs on admission, so
+// the specs stored in the cluster read the way the operator renders them
+type DashboardAppDefaulter struct{}
+
+// SetupWebhookWithManager registers the DashboardApp defaulting webhook.
+func (d *DashboardAppDefaulter) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&DashboardApp{}).
+		WithDefaulter(d).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-dashboard-homelab-io-v1alpha1-dashboardapp,mutating=true,failurePolicy=ignore,sideEffects=None,groups=dashboard.homelab.io,resources=dashboardapps,verbs=create;update,versions=v1alpha1,name=mdashboardapp.dashboard.homelab.io,admissionReviewVersions=v1
+
+var _ admission.CustomDefaulter = &DashboardAppDefaulter{}
+
+// Default implements admission.CustomDefaulter.
+func (d *DashboardAppDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	app, ok := obj.(*DashboardApp)
+	if !ok {
+		return fmt.Errorf("expected a DashboardApp, got %T", obj)
+	}
+	app.Default()
+	return nil
+}
+
+// Default canonicalizes the spec: the display name defaults to the object
+// name, the priority to DefaultPriority and the category is lowercased.
+// URLs without a scheme get https:// and lose their trailing slash. Action
+// methods are uppercased and default to GET.
+func (app *DashboardApp) Default() {
+	spec := &app.Spec
+	if strings.TrimSpace(spec.Name) == "" {
+		spec.Name = app.Name
+	}
+	if spec.Priority == 0 {
+		spec.Priority = DefaultPriority
+	}
+	spec.Category = strings.ToLower(strings.TrimSpace(spec.Category))
+	spec.URL = normalizeURL(spec.URL)
+	spec.InternalURL = normalizeURL(spec.InternalURL)
+	for i := range spec.Actions {
+		action := &spec.Actions[i]
+		action.Method = strings.ToUpper(strings.TrimSpace(action.Method))
+		if action.Method == "" {
+			action.Method = "GET"
+		}
+	}
+}
+
+// normalizeURL adds the https scheme to a URL without one and strips its
+// trailing slash. Templates are left alone where they could render the
+// scheme themselves.
+func normalizeURL(u string) string {
+	u = strings.TrimSpace(u)
+	if u == "" {
+		return u
+	}
+	if !strings.Contains(u, "://") && !strings.HasPrefix(u, "{{") {
+		u = "https://" + u
+	}
+	return strings.TrimSuffix(u, "/")
+}
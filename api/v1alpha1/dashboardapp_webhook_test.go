@@ -0,0 +1,58 @@
+package v1alpha1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDashboardAppDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		spec DashboardAppSpec
+		want DashboardAppSpec
+	}{
+		{
+			name: "fills name and priority",
+			spec: DashboardAppSpec{URL: "https://plex.example.test", Category: "media"},
+			want: DashboardAppSpec{Name: "plex", URL: "https://plex.example.test", Category: "media", Priority: DefaultPriority},
+		},
+		{
+			name: "keeps set values",
+			spec: DashboardAppSpec{Name: "Plex", URL: "http://plex.lan:32400", Category: "media", Priority: 5},
+			want: DashboardAppSpec{Name: "Plex", URL: "http://plex.lan:32400", Category: "media", Priority: 5},
+		},
+		{
+			name: "canonicalizes URLs and category",
+			spec: DashboardAppSpec{Name: "Plex", URL: " plex.example.test/ ", InternalURL: "http://plex.media.svc:32400/", Category: " Media "},
+			want: DashboardAppSpec{Name: "Plex", URL: "https://plex.example.test", InternalURL: "http://plex.media.svc:32400", Category: "media", Priority: DefaultPriority},
+		},
+		{
+			name: "canonicalizes action methods",
+			spec: DashboardAppSpec{Name: "Plex", URL: "https://plex.lan", Category: "media",
+				Actions: []AppAction{{Name: "Logs", URL: "https://logs.lan"}, {Name: "Restart", URL: "https://plex.lan/restart", Method: "post"}}},
+			want: DashboardAppSpec{Name: "Plex", URL: "https://plex.lan", Category: "media", Priority: DefaultPriority,
+				Actions: []AppAction{{Name: "Logs", URL: "https://logs.lan", Method: "GET"}, {Name: "Restart", URL: "https://plex.lan/restart", Method: "POST"}}},
+		},
+		{
+			name: "leaves templates rendering the scheme alone",
+			spec: DashboardAppSpec{Name: "Plex", URL: "{{ .scheme }}://plex.{{ .externalSuffix }}/", Category: "media"},
+			want: DashboardAppSpec{Name: "Plex", URL: "{{ .scheme }}://plex.{{ .externalSuffix }}", Category: "media", Priority: DefaultPriority},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &DashboardApp{ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"}, Spec: tt.spec}
+			if err := (&DashboardAppDefaulter{}).Default(context.Background(), app); err != nil {
+				t.Fatal(err)
+			}
+			if app.Spec.Name != tt.want.Name || app.Spec.URL != tt.want.URL || app.Spec.InternalURL != tt.want.InternalURL ||
+				app.Spec.Category != tt.want.Category || app.Spec.Priority != tt.want.Priority || !reflect.DeepEqual(app.Spec.Actions, tt.want.Actions) {
+				t.Errorf("spec = %+v, want %+v", app.Spec, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DashboardBookmarkSpec defines a plain link listed in the bookmarks section
+// of the dashboard, for what doesn't deserve an app tile: no icon, health,
+// actions or status.
+type DashboardBookmarkSpec struct {
+	// Name is the link text shown in the dashboard (defaults to the object name)
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// URL is the link target
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Category groups the bookmark in the bookmarks section (free-form,
+	// like DashboardApp spec.category); uncategorized bookmarks come last
+	// +optional
+	Category string `json:"category,omitempty"`
+
+	// Groups restricts the bookmark to members of these groups (same syntax
+	// as DashboardApp spec.groups); everyone sees it when empty
+	// +kubebuilder:validation:items:Pattern=`^[^*]+\*?$|^\*$`
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=dbm
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`
+// +kubebuilder:printcolumn:name="Category",type=string,JSONPath=`.spec.category`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// DashboardBookmark is the Schema for the dashboardbookmarks API
+type DashboardBookmark struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DashboardBookmarkSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DashboardBookmarkList contains a list of DashboardBookmark
+type DashboardBookmarkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DashboardBookmark `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DashboardBookmark{}, &DashboardBookmarkList{})
+}
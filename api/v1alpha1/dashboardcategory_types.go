@@ -0,0 +1,59 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DashboardCategorySpec defines how a category is presented in the dashboard.
+// The category ID is the object name and is matched against DashboardApp
+// spec.category.
+type DashboardCategorySpec struct {
+	// DisplayName is the category title shown in the dashboard (defaults to the ID)
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Icon is the raw SVG string for the category header
+	// +optional
+	Icon string `json:"icon,omitempty"`
+
+	// Order controls category position in the dashboard (lower = first)
+	// +kubebuilder:default=100
+	// +optional
+	Order int `json:"order,omitempty"`
+
+	// Description is a short blurb shown under the category header
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Collapsed renders the category folded by default in the dashboard
+	// +optional
+	Collapsed bool `json:"collapsed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=dcat
+// +kubebuilder:printcolumn:name="Display Name",type=string,JSONPath=`.spec.displayName`
+// +kubebuilder:printcolumn:name="Order",type=integer,JSONPath=`.spec.order`
+// +kubebuilder:printcolumn:name="Collapsed",type=boolean,JSONPath=`.spec.collapsed`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// DashboardCategory is the Schema for the dashboardcategories API
+type DashboardCategory struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DashboardCategorySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DashboardCategoryList contains a list of DashboardCategory
+type DashboardCategoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DashboardCategory `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DashboardCategory{}, &DashboardCategoryList{})
+}
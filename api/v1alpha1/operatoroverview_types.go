@@ -0,0 +1,275 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorOverviewName is the name of the OperatorOverview the operator
+// maintains; instances started with an instance name suffix it with
+// "-<instance>"
+const OperatorOverviewName = "duro-operator"
+
+// ValidateAnnotation on the OperatorOverview requests a validation sweep of
+// every DashboardApp whenever its value changes
+const ValidateAnnotation = "dashboard.homelab.io/validate"
+
+// ReconcileOutcome is the result of a reconcile
+// +kubebuilder:validation:Enum=Succeeded;Failed
+type ReconcileOutcome string
+
+const (
+	ReconcileSucceeded ReconcileOutcome = "Succeeded"
+	ReconcileFailed    ReconcileOutcome = "Failed"
+)
+
+// ReconcileError is a failed reconcile kept in the overview
+type ReconcileError struct {
+	// Time is when the reconcile failed
+	Time metav1.Time `json:"time"`
+
+	// TraceID is the trace ID of the failed reconcile, as found in logs
+	// +optional
+	TraceID string `json:"traceID,omitempty"`
+
+	// Message is the error message
+	Message string `json:"message"`
+}
+
+// OutputTargetStatus is the state of one document the operator writes
+type OutputTargetStatus struct {
+	// Kind of the sink (e.g. ConfigMap)
+	Kind string `json:"kind"`
+
+	// Name is the namespace/name of the sink
+	Name string `json:"name"`
+
+	// Key is the document within the sink (e.g. apps.json)
+	Key string `json:"key"`
+
+	// Hash is the hash of the document last written
+	// +optional
+	Hash string `json:"hash,omitempty"`
+
+	// LastWriteTime is when the document last changed
+	// +optional
+	LastWriteTime *metav1.Time `json:"lastWriteTime,omitempty"`
+
+	// LastError is the error of the last failed write, cleared by the next
+	// successful one
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// ConformanceStatus compares the apps document written with the one duro
+// serves
+type ConformanceStatus struct {
+	// URL is the duro endpoint checked
+	URL string `json:"url"`
+
+	// InSync is true when duro serves the last written document
+	InSync bool `json:"inSync"`
+
+	// WrittenHash is the fingerprint of the last written document
+	// +optional
+	WrittenHash string `json:"writtenHash,omitempty"`
+
+	// ServedHash is the fingerprint of the document duro served at the last
+	// successful check
+	// +optional
+	ServedHash string `json:"servedHash,omitempty"`
+
+	// LastCheckTime is when duro was last checked
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// Lag is how long the last written document took to be served, or has
+	// not been served for so far
+	// +optional
+	Lag *metav1.Duration `json:"lag,omitempty"`
+
+	// LastError is why the last check could not fetch the served document
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// ValidationSweepStatus summarizes the last check of every DashboardApp
+// against the current rules
+type ValidationSweepStatus struct {
+	// LastSweepTime is when the sweep ran
+	// +optional
+	LastSweepTime *metav1.Time `json:"lastSweepTime,omitempty"`
+
+	// Trigger is the value of the validate annotation when the sweep ran; a
+	// new value requests another sweep
+	// +optional
+	Trigger string `json:"trigger,omitempty"`
+
+	// Checked is the number of DashboardApps checked
+	// +optional
+	Checked int `json:"checked,omitempty"`
+
+	// NonConformingCount is the number of DashboardApps breaking a rule
+	// +optional
+	NonConformingCount int `json:"nonConformingCount,omitempty"`
+
+	// NonConforming lists (namespace/name) DashboardApps breaking a rule;
+	// their Conforming condition says which
+	// +optional
+	// +kubebuilder:validation:MaxItems=50
+	NonConforming []string `json:"nonConforming,omitempty"`
+}
+
+// DeadLink is a dashboard link the dead-link sweep could not reach
+type DeadLink struct {
+	// App is the DashboardApp (namespace/name) listing the link
+	App string `json:"app"`
+
+	// URL is the link
+	URL string `json:"url"`
+
+	// Reason is the error or HTTP status the probe got
+	Reason string `json:"reason"`
+}
+
+// DeadLinkSweepStatus summarizes the last probe of every dashboard link
+type DeadLinkSweepStatus struct {
+	// LastSweepTime is when the sweep ran
+	// +optional
+	LastSweepTime *metav1.Time `json:"lastSweepTime,omitempty"`
+
+	// Checked is the number of links probed
+	// +optional
+	Checked int `json:"checked,omitempty"`
+
+	// DeadCount is the number of links found dead
+	// +optional
+	DeadCount int `json:"deadCount,omitempty"`
+
+	// Dead lists the dead links
+	// +optional
+	// +kubebuilder:validation:MaxItems=50
+	Dead []DeadLink `json:"dead,omitempty"`
+}
+
+// PublishedCatalog is the apps document published in the overview status,
+// for clients reading the catalog through the API instead of a mounted
+// ConfigMap
+type PublishedCatalog struct {
+	// Data is the apps document, gzip-compressed; empty when it was omitted
+	// +optional
+	Data []byte `json:"data,omitempty"`
+
+	// Hash is the fingerprint of the uncompressed document
+	Hash string `json:"hash"`
+
+	// Size is the uncompressed size of the document in bytes
+	Size int `json:"size"`
+
+	// Omitted explains why Data is empty
+	// +optional
+	Omitted string `json:"omitted,omitempty"`
+}
+
+// OperatorOverviewStatus summarizes what the operator has been doing
+type OperatorOverviewStatus struct {
+	// LastReconcileTime is when the last reconcile finished
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// LastResult is the outcome of the last reconcile
+	// +optional
+	LastResult ReconcileOutcome `json:"lastResult,omitempty"`
+
+	// LastDuration is how long the last reconcile took
+	// +optional
+	LastDuration *metav1.Duration `json:"lastDuration,omitempty"`
+
+	// LastTraceID is the trace ID of the last reconcile
+	// +optional
+	LastTraceID string `json:"lastTraceID,omitempty"`
+
+	// ReconcileCount counts the reconciles recorded in this overview
+	// +optional
+	ReconcileCount int64 `json:"reconcileCount,omitempty"`
+
+	// Apps is the number of DashboardApps seen by the last reconcile
+	// +optional
+	Apps int `json:"apps,omitempty"`
+
+	// Entries is the number of entries in the last written catalog (apps
+	// hidden from all their groups are not counted)
+	// +optional
+	Entries int `json:"entries,omitempty"`
+
+	// Categories is the number of categories in the last written catalog
+	// +optional
+	Categories int `json:"categories,omitempty"`
+
+	// ConfigHash is the hash of the last written output
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// Targets lists every output document with its hash and last write,
+	// so a failing sink can be told apart from healthy ones
+	// +optional
+	Targets []OutputTargetStatus `json:"targets,omitempty"`
+
+	// Catalog is the last written apps document; only set when catalog
+	// publishing is enabled
+	// +optional
+	Catalog *PublishedCatalog `json:"catalog,omitempty"`
+
+	// Conformance reports whether duro serves what was written; only set
+	// when conformance checks are enabled
+	// +optional
+	Conformance *ConformanceStatus `json:"conformance,omitempty"`
+
+	// Validation is the result of the last validation sweep
+	// +optional
+	Validation *ValidationSweepStatus `json:"validation,omitempty"`
+
+	// DeadLinks is the result of the last dead-link sweep; only set when
+	// the sweep is enabled
+	// +optional
+	DeadLinks *DeadLinkSweepStatus `json:"deadLinks,omitempty"`
+
+	// ObservedResync is the value of the resync annotation last acted upon
+	// by a full rebuild
+	// +optional
+	ObservedResync string `json:"observedResync,omitempty"`
+
+	// LastErrors holds the most recent failed reconciles, newest first
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	LastErrors []ReconcileError `json:"lastErrors,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=doo
+// +kubebuilder:printcolumn:name="Result",type=string,JSONPath=`.status.lastResult`
+// +kubebuilder:printcolumn:name="Apps",type=integer,JSONPath=`.status.apps`
+// +kubebuilder:printcolumn:name="Duration",type=string,JSONPath=`.status.lastDuration`
+// +kubebuilder:printcolumn:name="Last Reconcile",type=date,JSONPath=`.status.lastReconcileTime`
+
+// OperatorOverview is a singleton reporting the operator's recent activity,
+// so its health can be watched on one object instead of through logs
+type OperatorOverview struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status OperatorOverviewStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OperatorOverviewList contains a list of OperatorOverview
+type OperatorOverviewList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorOverview `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OperatorOverview{}, &OperatorOverviewList{})
+}
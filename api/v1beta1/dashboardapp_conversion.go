@@ -0,0 +1,70 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &DashboardApp{}
+
+// ConvertTo converts the app to the v1alpha1 hub, where the groups of
+// access.anyOf are listed in spec.groups.
+func (src *DashboardApp) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1alpha1.DashboardApp)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha1 DashboardApp, got %T", dstRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("converting spec: %w", err)
+	}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return fmt.Errorf("converting status: %w", err)
+	}
+	dst.Spec.Groups = nil
+	if access := dst.Spec.Access; access != nil {
+		dst.Spec.Groups, access.AnyOf = access.AnyOf, nil
+		if len(access.AllOf) == 0 && len(access.NoneOf) == 0 {
+			dst.Spec.Access = nil
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the app from the v1alpha1 hub, folding spec.groups
+// into access.anyOf.
+func (dst *DashboardApp) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1alpha1.DashboardApp)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha1 DashboardApp, got %T", srcRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+	if err := convertJSON(&src.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("converting spec: %w", err)
+	}
+	if err := convertJSON(&src.Status, &dst.Status); err != nil {
+		return fmt.Errorf("converting status: %w", err)
+	}
+	if anyOf := src.AnyOfGroups(); len(anyOf) > 0 {
+		if dst.Spec.Access == nil {
+			dst.Spec.Access = &AppAccess{}
+		}
+		dst.Spec.Access.AnyOf = anyOf
+	}
+	return nil
+}
+
+// convertJSON copies in to out through their JSON form. Both versions
+// share their layout apart from group access, so everything else converts
+// field for field.
+func convertJSON(in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
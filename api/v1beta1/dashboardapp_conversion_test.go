@@ -0,0 +1,85 @@
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func TestDashboardAppConversion(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "plex", Namespace: "media", Labels: map[string]string{"tier": "home"}}
+	tests := []struct {
+		name  string
+		alpha v1alpha1.DashboardAppSpec
+		beta  DashboardAppSpec
+	}{
+		{
+			name:  "groups become access.anyOf",
+			alpha: v1alpha1.DashboardAppSpec{Name: "Plex", URL: "https://plex.lan", Category: "media", Groups: []string{"family", "media/*"}},
+			beta:  DashboardAppSpec{Name: "Plex", URL: "https://plex.lan", Category: "media", Access: &AppAccess{AnyOf: []string{"family", "media/*"}}},
+		},
+		{
+			name: "other access rules and fields carry over",
+			alpha: v1alpha1.DashboardAppSpec{Name: "Plex", URL: "https://plex.lan", Description: "Movies", Tags: []string{"video"},
+				Groups: []string{"family"}, Access: &v1alpha1.AppAccess{AllOf: []string{"adults"}, NoneOf: []string{"guests"}},
+				Actions: []v1alpha1.AppAction{{Name: "Logs", URL: "https://logs.lan", Method: "GET", Groups: []string{"admins"}}}},
+			beta: DashboardAppSpec{Name: "Plex", URL: "https://plex.lan", Description: "Movies", Tags: []string{"video"},
+				Access:  &AppAccess{AnyOf: []string{"family"}, AllOf: []string{"adults"}, NoneOf: []string{"guests"}},
+				Actions: []AppAction{{Name: "Logs", URL: "https://logs.lan", Method: "GET", Groups: []string{"admins"}}}},
+		},
+		{
+			name:  "no access at all",
+			alpha: v1alpha1.DashboardAppSpec{Name: "Plex", URL: "https://plex.lan"},
+			beta:  DashboardAppSpec{Name: "Plex", URL: "https://plex.lan"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &v1alpha1.DashboardApp{ObjectMeta: meta, Spec: tt.alpha,
+				Status: v1alpha1.DashboardAppStatus{ObservedGeneration: 3, Health: &v1alpha1.AppHealth{State: v1alpha1.HealthUp}}}
+
+			beta := &DashboardApp{}
+			if err := beta.ConvertFrom(hub); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+			if !reflect.DeepEqual(beta.Spec, tt.beta) {
+				t.Errorf("ConvertFrom() spec = %+v, want %+v", beta.Spec, tt.beta)
+			}
+			if beta.Name != "plex" || beta.Status.ObservedGeneration != 3 || beta.Status.Health.State != HealthUp {
+				t.Errorf("ConvertFrom() lost metadata or status: %+v", beta)
+			}
+
+			back := &v1alpha1.DashboardApp{}
+			if err := beta.ConvertTo(back); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+			if !reflect.DeepEqual(back, hub) {
+				t.Errorf("round trip = %+v, want %+v", back, hub)
+			}
+		})
+	}
+}
+
+func TestDashboardAppConversion_AnyOfMergesGroups(t *testing.T) {
+	hub := &v1alpha1.DashboardApp{Spec: v1alpha1.DashboardAppSpec{Groups: []string{"family"},
+		Access: &v1alpha1.AppAccess{AnyOf: []string{"media", "family"}}}}
+
+	beta := &DashboardApp{}
+	if err := beta.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if want := []string{"family", "media"}; !reflect.DeepEqual(beta.Spec.Access.AnyOf, want) {
+		t.Errorf("access.anyOf = %v, want %v", beta.Spec.Access.AnyOf, want)
+	}
+
+	back := &v1alpha1.DashboardApp{}
+	if err := beta.ConvertTo(back); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if !reflect.DeepEqual(back.Spec.Groups, []string{"family", "media"}) || back.Spec.Access != nil {
+		t.Errorf("ConvertTo() groups = %v, access = %+v", back.Spec.Groups, back.Spec.Access)
+	}
+}
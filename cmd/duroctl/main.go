@@ -0,0 +1,123 @@
+// Command duroctl is the administration companion of duro-operator.
+//
+// Usage:
+//
+//	duroctl rbac [flags]        print the RBAC manifests for a set of operator flags
+//	duroctl render [flags]      print the apps.json assembled from manifests or the cluster
+//	duroctl release [flags]     release the removal finalizer of DashboardApps, e.g. before uninstalling
+//	duroctl reconciles [flags]  print the recent reconcile outcomes of a running operator
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/fredericrous/duro-operator/pkg/config"
+	"github.com/fredericrous/duro-operator/pkg/rbac"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "rbac":
+		err = runRBAC(os.Args[2:])
+	case "render":
+		err = runRender(os.Args[2:])
+	case "release":
+		err = runRelease(os.Args[2:])
+	case "reconciles":
+		err = runReconciles(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "duroctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: duroctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  rbac        Print minimal Role/RoleBinding manifests for the operator's enabled features")
+	fmt.Fprintln(os.Stderr, "  render      Print the apps.json the operator would write for DashboardApp manifests or the cluster")
+	fmt.Fprintln(os.Stderr, "  release     Release the operator's finalizer from DashboardApps it no longer handles, e.g. before uninstalling it")
+	fmt.Fprintln(os.Stderr, "  reconciles  Print the recent reconcile outcomes served by the operator at /debug/reconciles")
+}
+
+// runRBAC prints the RBAC manifests of an operator deployment. Feature flags
+// carry the names of the operator's own flags, so the operator's arguments
+// can be passed along as is.
+func runRBAC(args []string) error {
+	defaults := config.NewDefaultConfig()
+	fs := flag.NewFlagSet("rbac", flag.ContinueOnError)
+	var (
+		serviceAccount = fs.String("service-account", "duro-operator", "Service account the operator runs as, also naming the generated objects")
+		namespace      = fs.String("namespace", "duro-system", "Namespace the operator is deployed in")
+
+		instanceName     = fs.String("instance-name", "", "Operator --instance-name, suffixing the generated object names")
+		duroNamespace    = fs.String("duro-namespace", defaults.DuroNamespace, "Operator --duro-namespace")
+		outputKind       = fs.String("output-kind", defaults.OutputKind, "Operator --output-kind")
+		leaderElect      = fs.Bool("leader-elect", false, "Operator --leader-elect")
+		leaderElectionNS = fs.String("leader-election-namespace", "", "Operator --leader-election-namespace (defaults to --namespace)")
+		registrationNS   = fs.String("registration-namespace", "", "Operator --registration-namespace")
+		apiTokenFile     = fs.String("api-token-file", "", "Operator --api-token-file; any value enables the registration endpoints")
+		dispatchSecret   = fs.String("dispatch-secret-file", "", "Operator --dispatch-secret-file; any value enables the dispatch receiver")
+		iconConfigMap    = fs.String("icon-configmap", "", "Operator --icon-configmap")
+		rbacGroups       = fs.Bool("rbac-groups", false, "Operator --rbac-groups")
+		helmDiscovery    = fs.Bool("helm-discovery", false, "Operator --helm-discovery")
+		workloadDisc     = fs.Bool("workload-discovery", false, "Operator --workload-discovery")
+		ingressDisc      = fs.Bool("ingress-discovery", false, "Operator --ingress-discovery")
+		alertRules       = fs.Bool("alert-rules", false, "Operator --alert-rules")
+		dashboards       = fs.Bool("dashboards", false, "Operator --dashboards")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.InstanceName = *instanceName
+	cfg.DuroNamespace = *duroNamespace
+	cfg.OutputKind = *outputKind
+	cfg.EnableLeaderElection = *leaderElect
+	cfg.LeaderElectionNamespace = *leaderElectionNS
+	cfg.RegistrationNamespace = *registrationNS
+	cfg.IconConfigMap = *iconConfigMap
+	cfg.RBACGroups = *rbacGroups
+	cfg.HelmDiscovery = *helmDiscovery
+	cfg.WorkloadDiscovery = *workloadDisc
+	cfg.IngressDiscovery = *ingressDisc
+	cfg.AlertRules = *alertRules
+	cfg.Dashboards = *dashboards
+	if *apiTokenFile != "" {
+		// Only whether a token is set matters here
+		cfg.APIToken = "set"
+	}
+	if *dispatchSecret != "" {
+		cfg.DispatchSecret = "set"
+	}
+	// The API server needs no rights of its own
+	cfg.ApiAddr = "0"
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	out, err := rbac.YAML(rbac.Manifests(cfg, rbac.Subject{Name: *serviceAccount, Namespace: *namespace}))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
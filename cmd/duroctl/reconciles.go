@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fredericrous/duro-operator/pkg/history"
+)
+
+// runReconciles prints the recent reconcile outcomes the operator serves at
+// /debug/reconciles, newest first. The operator's API server is usually
+// reached through a port-forward, e.g.
+//
+//	kubectl -n duro-system port-forward deploy/duro-operator 9090
+func runReconciles(args []string) error {
+	flags := flag.NewFlagSet("reconciles", flag.ContinueOnError)
+	var (
+		apiURL  = flags.String("url", "http://localhost:9090", "Base URL of the operator's API server (--api-bind-address)")
+		limit   = flags.Int("limit", 0, "Only print the last n reconciles (0 prints all the operator keeps)")
+		asJSON  = flags.Bool("json", false, "Print the reconciles as served, in JSON")
+		timeout = flags.Duration("timeout", 10*time.Second, "Timeout of the request")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(*apiURL, "/") + "/debug/reconciles")
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if *limit > 0 {
+		u.RawQuery = url.Values{"limit": {strconv.Itoa(*limit)}}.Encode()
+	}
+	resp, err := (&http.Client{Timeout: *timeout}).Get(u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return fmt.Errorf("%s not found, is the operator running with --reconcile-history above 0?", u)
+	default:
+		return fmt.Errorf("%s: %s: %s", u, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if *asJSON {
+		_, err := fmt.Fprintln(os.Stdout, strings.TrimSpace(string(body)))
+		return err
+	}
+	var records []history.Reconcile
+	if err := json.Unmarshal(body, &records); err != nil {
+		return fmt.Errorf("failed to decode the reconciles: %w", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTRIGGER\tDURATION\tAPPS\tENTRIES\tDELTA\tTRACE ID\tERROR")
+	for _, rec := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n", rec.Time.Local().Format(time.DateTime), rec.Trigger,
+			rec.Duration.Round(time.Millisecond), rec.Apps, rec.Entries, formatDelta(rec.Delta), rec.TraceID, rec.Error)
+	}
+	return w.Flush()
+}
+
+// formatDelta summarizes the entries added, removed and changed by a
+// reconcile, "-" if nothing was assembled.
+func formatDelta(d *history.Delta) string {
+	if d == nil {
+		return "-"
+	}
+	return fmt.Sprintf("+%d -%d ~%d", len(d.Added), len(d.Removed), len(d.Changed))
+}
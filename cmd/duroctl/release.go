@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+// runRelease removes the operator's removal finalizer from the DashboardApps
+// of the cluster of the current kubeconfig, so they can be deleted while no
+// operator handles them: before uninstalling the operator (otherwise apps,
+// their namespaces and the CRD hang on deletion), or for apps that
+// --watch-namespaces or --exclude-namespaces took out of its scope.
+func runRelease(args []string) error {
+	fs := flag.NewFlagSet("release", flag.ContinueOnError)
+	var (
+		namespace = fs.String("namespace", "", "Only release the apps of this namespace (empty releases every namespace)")
+		selector  = fs.String("selector", "", "Only release the apps matching this label selector")
+		dryRun    = fs.Bool("dry-run", false, "List the apps that would be released without changing them")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sel, err := labels.Parse(*selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector: %w", err)
+	}
+
+	ctx := context.Background()
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	apps := &dashboardv1alpha1.DashboardAppList{}
+	if err := c.List(ctx, apps, client.InNamespace(*namespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return fmt.Errorf("failed to list DashboardApps: %w", err)
+	}
+	for i := range apps.Items {
+		app := &apps.Items[i]
+		if !controllerutil.ContainsFinalizer(app, dashboardv1alpha1.RemovalFinalizer) {
+			continue
+		}
+		if !*dryRun {
+			orig := app.DeepCopy()
+			controllerutil.RemoveFinalizer(app, dashboardv1alpha1.RemovalFinalizer)
+			if err := c.Patch(ctx, app, client.MergeFrom(orig)); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to release %s/%s: %w", app.Namespace, app.Name, err)
+			}
+		}
+		fmt.Fprintf(os.Stdout, "released %s/%s\n", app.Namespace, app.Name)
+	}
+	return nil
+}
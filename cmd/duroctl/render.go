@@ -0,0 +1,197 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/config"
+	"github.com/fredericrous/duro-operator/pkg/iconlib"
+	"github.com/fredericrous/duro-operator/pkg/render"
+)
+
+// pathList is a flag that can be repeated
+type pathList []string
+
+func (p *pathList) String() string { return strings.Join(*p, ",") }
+
+func (p *pathList) Set(v string) error {
+	*p = append(*p, v)
+	return nil
+}
+
+// runRender prints the apps.json the operator would write for the
+// DashboardApps, DashboardCategories and DashboardBookmarks of manifest
+// files, of the cluster, or of the cluster with the files applied on top.
+// Findings are reported on stderr; the command fails when apps are left out
+// in strict mode.
+func runRender(args []string) error {
+	defaults := config.NewDefaultConfig()
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	var (
+		files     pathList
+		cluster   = flags.Bool("cluster", false, "Read the DashboardApps, DashboardCategories and DashboardBookmarks of the cluster of the current kubeconfig; -f manifests replace the objects they name")
+		namespace = flags.String("namespace", "default", "Namespace of the apps and bookmarks whose manifest doesn't set one")
+		bookmarks = flags.Bool("bookmarks", false, "Print bookmarks.json instead of apps.json")
+
+		clusterDomain    = flags.String("cluster-domain", defaults.ClusterDomain, "Operator --cluster-domain")
+		externalSuffix   = flags.String("external-suffix", defaults.ExternalSuffix, "Operator --external-suffix")
+		fallbackCategory = flags.String("fallback-category", defaults.FallbackCategory, "Operator --fallback-category")
+		duplicateNames   = flags.String("duplicate-name-policy", defaults.DuplicateNamePolicy, "Operator --duplicate-name-policy")
+		idTemplate       = flags.String("id-template", defaults.IDTemplate, "Operator --id-template")
+		sortOrder        = flags.String("sort", defaults.Sort, "Operator --sort")
+		iconBaseURL      = flags.String("icon-base-url", defaults.IconBaseURL, "Operator --icon-base-url")
+		strict           = flags.Bool("strict", defaults.Strict, "Operator --strict")
+	)
+	flags.Var(&files, "f", "Manifest file or directory of manifests to read (.yaml, .yml or .json), - for stdin; may be repeated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(files) == 0 && !*cluster {
+		return errors.New("nothing to render: pass manifests with -f or read the cluster with --cluster")
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.ClusterDomain = *clusterDomain
+	cfg.ExternalSuffix = *externalSuffix
+	cfg.FallbackCategory = *fallbackCategory
+	cfg.DuplicateNamePolicy = *duplicateNames
+	cfg.IDTemplate = *idTemplate
+	cfg.Sort = *sortOrder
+	cfg.IconBaseURL = *iconBaseURL
+	cfg.Strict = *strict
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	ctx := context.Background()
+	catalog := &render.Catalog{}
+	if *cluster {
+		if err := loadCluster(ctx, catalog); err != nil {
+			return err
+		}
+	}
+	for _, path := range files {
+		if err := loadPath(catalog, path, *namespace); err != nil {
+			return err
+		}
+	}
+
+	// Icon URLs are published as is rather than fetched
+	asm := assembler.NewAssembler(logr.Discard())
+	asm.FallbackCategory = cfg.FallbackCategory
+	asm.DuplicateNamePolicy = cfg.DuplicateNamePolicy
+	asm.Strict = cfg.Strict
+	asm.Sort = cfg.Sort
+	asm.IDTemplate = cfg.IDTemplate
+	asm.IconBaseURL = cfg.IconBaseURL
+	asm.IconLibraries = iconlib.Defaults
+	result, err := asm.WithVariables(cfg.TemplateVariables()).WithCategories(catalog.Categories).WithBookmarks(catalog.Bookmarks).Assemble(ctx, catalog.Apps)
+	if err != nil {
+		return err
+	}
+
+	for _, finding := range render.Findings(result) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", finding)
+	}
+	doc := result.AppsJSON
+	if *bookmarks {
+		doc = cmp.Or(result.BookmarksJSON, "[]")
+	}
+	if _, err := fmt.Fprintln(os.Stdout, doc); err != nil {
+		return err
+	}
+	if len(result.StrictFailures) > 0 {
+		return fmt.Errorf("%d namespace(s) left out of the output in strict mode", len(result.StrictFailures))
+	}
+	return nil
+}
+
+// loadCluster adds the DashboardApps, DashboardCategories and
+// DashboardBookmarks of the cluster to the catalog.
+func loadCluster(ctx context.Context, catalog *render.Catalog) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	apps := &dashboardv1alpha1.DashboardAppList{}
+	if err := c.List(ctx, apps); err != nil {
+		return fmt.Errorf("failed to list DashboardApps: %w", err)
+	}
+	for _, app := range apps.Items {
+		catalog.AddApp(app, app.Namespace)
+	}
+	categories := &dashboardv1alpha1.DashboardCategoryList{}
+	if err := c.List(ctx, categories); err != nil {
+		return fmt.Errorf("failed to list DashboardCategories: %w", err)
+	}
+	for _, category := range categories.Items {
+		catalog.AddCategory(category)
+	}
+	bookmarks := &dashboardv1alpha1.DashboardBookmarkList{}
+	if err := c.List(ctx, bookmarks); err != nil {
+		return fmt.Errorf("failed to list DashboardBookmarks: %w", err)
+	}
+	for _, bookmark := range bookmarks.Items {
+		catalog.AddBookmark(bookmark, bookmark.Namespace)
+	}
+	return nil
+}
+
+// newClient returns a client of the cluster of the current kubeconfig,
+// knowing the dashboard API.
+func newClient() (client.Client, error) {
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	s := runtime.NewScheme()
+	if err := dashboardv1alpha1.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	return client.New(restConfig, client.Options{Scheme: s})
+}
+
+// loadPath adds the objects of a manifest file, of the manifests under a
+// directory, or of stdin for "-", to the catalog.
+func loadPath(catalog *render.Catalog, path, namespace string) error {
+	if path == "-" {
+		return catalog.Decode(os.Stdin, "stdin", namespace)
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		// Files named explicitly are read whatever their extension
+		switch filepath.Ext(p) {
+		case ".yaml", ".yml", ".json":
+		default:
+			if p != path {
+				return nil
+			}
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return catalog.Decode(f, p, namespace)
+	})
+}
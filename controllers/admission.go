@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/facts"
+)
+
+// +kubebuilder:webhook:path=/validate-dashboard-homelab-io-v1alpha1-dashboardapp,mutating=false,failurePolicy=ignore,sideEffects=None,groups=dashboard.homelab.io,resources=dashboardapps,verbs=create;update,versions=v1alpha1,name=vdashboardapp.dashboard.homelab.io,admissionReviewVersions=v1
+
+// catalogSimulator rejects DashboardApps that would break the catalog they
+// join, by assembling it with the incoming app at admission time. It catches
+// what only shows up next to the other apps: an entry ID already taken, an
+// output outgrowing its ConfigMap, a namespace left out in strict mode.
+type catalogSimulator struct {
+	r *DashboardAppReconciler
+}
+
+var _ admission.CustomValidator = &catalogSimulator{}
+
+// SetupSimulationWebhook registers the DashboardApp validating webhook
+// simulating the assembly of the incoming app (see
+// Config.SimulateAdmission). It must be called after SetupWithManager.
+func (r *DashboardAppReconciler) SetupSimulationWebhook(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&dashboardv1alpha1.DashboardApp{}).
+		WithValidator(&catalogSimulator{r: r}).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator.
+func (s *catalogSimulator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	app, ok := obj.(*dashboardv1alpha1.DashboardApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a DashboardApp, got %T", obj)
+	}
+	return s.validate(ctx, nil, app)
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (s *catalogSimulator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	old, ok := oldObj.(*dashboardv1alpha1.DashboardApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a DashboardApp, got %T", oldObj)
+	}
+	app, ok := newObj.(*dashboardv1alpha1.DashboardApp)
+	if !ok {
+		return nil, fmt.Errorf("expected a DashboardApp, got %T", newObj)
+	}
+	return s.validate(ctx, old, app)
+}
+
+// ValidateDelete implements admission.CustomValidator; leaving the catalog
+// never breaks it.
+func (s *catalogSimulator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate assembles the catalog with app in place of old and rejects app
+// when it breaks the catalog. Problems the simulation itself runs into
+// (e.g. the cache not answering) admit the app with a warning: the
+// reconcile reports them anyway.
+func (s *catalogSimulator) validate(ctx context.Context, old, app *dashboardv1alpha1.DashboardApp) (admission.Warnings, error) {
+	if !s.r.Config.WatchesNamespace(app.Namespace) || !s.r.selector.Matches(labels.Set(app.Labels)) || !app.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	source := app.Namespace + "/" + app.Name
+	log := s.r.Log.WithName("admission").WithValues("app", source)
+
+	result, data, err := s.simulate(ctx, app)
+	if err != nil {
+		var opErr *operrors.OperatorError
+		if goerrors.As(err, &opErr) && !operrors.ShouldRetry(err) && opErr.Context["app"] == source {
+			return nil, s.deny(app, field.Forbidden(field.NewPath("spec"), err.Error()))
+		}
+		log.Error(err, "Failed to simulate the catalog assembly")
+		return admission.Warnings{fmt.Sprintf("catalog not checked: %v", err)}, nil
+	}
+
+	var errs field.ErrorList
+	for _, c := range result.IDCollisions {
+		for i, other := range c.Sources {
+			if other == source {
+				owner := c.Sources[0]
+				if i == 0 {
+					owner = c.Sources[1]
+				}
+				errs = append(errs, field.Forbidden(field.NewPath("metadata", "name"),
+					fmt.Sprintf("entry ID %q is already used by %s", c.ID, owner)))
+			}
+		}
+	}
+	if reason, ok := result.LeftOut[source]; ok {
+		errs = append(errs, field.Forbidden(field.NewPath("spec"), reason))
+	}
+	if failure, ok := assembler.StrictFailureFor(result.StrictFailures, app.Namespace); ok {
+		if findings, offends := failure.Findings[source]; offends {
+			errs = append(errs, field.Forbidden(field.NewPath("spec"), fmt.Sprintf("%s, leaving namespace %s out of the output in strict mode", strings.Join(findings, "; "), app.Namespace)))
+		}
+	}
+	if size := outputSize(data); size > maxConfigMapBytes {
+		// A catalog already over the limit still accepts changes that don't
+		// grow it, so it can be brought back under
+		_, before, err := s.simulate(ctx, old)
+		if err != nil || outputSize(before) < size {
+			errs = append(errs, field.Forbidden(field.NewPath("spec"),
+				fmt.Sprintf("the output would be %d bytes, over the %d bytes a ConfigMap can hold", size, maxConfigMapBytes)))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, s.deny(app, errs...)
+	}
+	return nil, nil
+}
+
+// simulate assembles the catalog of the instance with app in place of its
+// stored version, or without it when app is nil, and returns the output
+// documents. Icons are not fetched and hooks don't run, so the simulation
+// stays fast and free of side effects; inlined icons are not counted in the
+// output size.
+func (s *catalogSimulator) simulate(ctx context.Context, app *dashboardv1alpha1.DashboardApp) (*assembler.AssemblyResult, map[string]string, error) {
+	r := s.r
+	ctx = logr.NewContext(ctx, logr.Discard())
+
+	appList := &dashboardv1alpha1.DashboardAppList{}
+	if err := r.List(ctx, appList, client.MatchingLabelsSelector{Selector: r.selector}); err != nil {
+		return nil, nil, operrors.NewTransientError("failed to list DashboardApps", err)
+	}
+	apps := appList.Items[:0]
+	for i := range appList.Items {
+		if app == nil || appList.Items[i].Namespace != app.Namespace || appList.Items[i].Name != app.Name {
+			apps = append(apps, appList.Items[i])
+		}
+	}
+	if app != nil {
+		apps = append(apps, *app)
+	}
+
+	vars, err := r.loadSubstitutions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	categoryList := &dashboardv1alpha1.DashboardCategoryList{}
+	if err := r.List(ctx, categoryList); err != nil {
+		return nil, nil, operrors.NewTransientError("failed to list DashboardCategories", err)
+	}
+	bookmarkList := &dashboardv1alpha1.DashboardBookmarkList{}
+	if err := r.List(ctx, bookmarkList); err != nil {
+		return nil, nil, operrors.NewTransientError("failed to list DashboardBookmarks", err)
+	}
+	var clusterFacts *facts.Facts
+	if assembler.HasConditions(apps) {
+		key := client.ObjectKey{Name: r.Config.FactsConfigMap, Namespace: r.Config.DuroNamespace}
+		if clusterFacts, err = facts.Gather(ctx, r.Client, key); err != nil {
+			return nil, nil, operrors.NewTransientError("failed to gather cluster facts", err)
+		}
+	}
+
+	asm := *r.Assembler.WithVariables(vars).WithCategories(categoryList.Items).WithBookmarks(bookmarkList.Items).WithFacts(clusterFacts)
+	asm.IconResolver = nil
+	asm.Hooks = nil
+	result, err := asm.Assemble(ctx, apps)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := outputData(result, r.Config.OutputFormats, r.Config.FormatVersions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, data, nil
+}
+
+// deny builds the admission error rejecting app.
+func (s *catalogSimulator) deny(app *dashboardv1alpha1.DashboardApp, errs ...*field.Error) error {
+	return errors.NewInvalid(dashboardv1alpha1.GroupVersion.WithKind("DashboardApp").GroupKind(), app.Name, errs)
+}
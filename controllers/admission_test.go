@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func newAdmissionApp(name, namespace, description string) *dashboardv1alpha1.DashboardApp {
+	return &dashboardv1alpha1.DashboardApp{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: dashboardv1alpha1.DashboardAppSpec{Name: name, URL: "https://" + name + ".lan", Category: "media",
+			Groups: []string{"family"}, Description: description},
+	}
+}
+
+func TestCatalogSimulator_EntryIDs(t *testing.T) {
+	s := &catalogSimulator{r: newFakeReconciler(t, nil, newAdmissionApp("plex", "media", ""))}
+
+	_, err := s.ValidateCreate(context.Background(), newAdmissionApp("plex", "streaming", ""))
+	if err == nil || !strings.Contains(err.Error(), `entry ID "plex" is already used by media/plex`) {
+		t.Errorf("ValidateCreate() error = %v, want the entry ID taken", err)
+	}
+	if _, err := s.ValidateCreate(context.Background(), newAdmissionApp("sonarr", "media", "")); err != nil {
+		t.Errorf("ValidateCreate() error = %v, want the app admitted", err)
+	}
+}
+
+func TestCatalogSimulator_ConfigMapSize(t *testing.T) {
+	big := newAdmissionApp("big", "media", strings.Repeat("x", maxConfigMapBytes))
+	s := &catalogSimulator{r: newFakeReconciler(t, nil, newAdmissionApp("plex", "media", ""))}
+	_, err := s.ValidateCreate(context.Background(), big)
+	if err == nil || !strings.Contains(err.Error(), "over the 1048576 bytes a ConfigMap can hold") {
+		t.Errorf("ValidateCreate() error = %v, want the output refused as too big", err)
+	}
+
+	// Changes shrinking an output already over the limit are accepted
+	s = &catalogSimulator{r: newFakeReconciler(t, nil, big)}
+	smaller := big.DeepCopy()
+	smaller.Spec.Description = strings.Repeat("x", maxConfigMapBytes-10)
+	if _, err := s.ValidateUpdate(context.Background(), big, smaller); err != nil {
+		t.Errorf("ValidateUpdate() error = %v, want the shrinking change admitted", err)
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// The reconciler handles two kinds of requests: those of single apps, and
+// catalogRequest. A change to an app only concerns that app until the
+// catalog is assembled again, which the app's reconcile asks the aggregator
+// for. The aggregator waits for changes to settle and then queues a single
+// catalogRequest, which assembles every app, writes the output and updates
+// the status of the apps whose outcome changed (see statusMemo). Changes to
+// the catalog's other dependencies queue catalogRequest directly.
+
+// catalogRequest is the request the catalog is assembled under. Its empty
+// namespace sets it apart from the requests of apps, which are namespaced.
+var catalogRequest = reconcile.Request{NamespacedName: types.NamespacedName{Name: "catalog"}}
+
+// aggregateMaxDelayFactor bounds how long the aggregator holds off the
+// catalog while changes keep coming, in debounce delays
+const aggregateMaxDelayFactor = 5
+
+// aggregator debounces app changes into catalog reconciles: the catalog is
+// queued once no change came in for the debounce delay, or once the first
+// change waited aggregateMaxDelayFactor delays. A burst of only background
+// changes queues it in the background lane (see laneFuncs). It only runs on
+// the leader, like the controller reading its channels.
+type aggregator struct {
+	delay time.Duration
+
+	// user and background receive an event per catalog reconcile to
+	// queue, in the user and the background lane
+	user, background chan event.GenericEvent
+
+	// wake is signalled on every trigger
+	wake chan struct{}
+
+	mu sync.Mutex
+	// pending is set while triggers wait to be flushed, since first and
+	// last; pendingBackground while they were all background
+	pending           bool
+	pendingBackground bool
+	first, last       time.Time
+}
+
+func newAggregator(delay time.Duration) *aggregator {
+	return &aggregator{
+		delay:      delay,
+		user:       make(chan event.GenericEvent, 1),
+		background: make(chan event.GenericEvent, 1),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Trigger asks for the catalog to be assembled again. It never blocks.
+func (a *aggregator) Trigger(background bool) {
+	a.mu.Lock()
+	now := time.Now()
+	if !a.pending {
+		a.pending, a.pendingBackground, a.first = true, true, now
+	}
+	a.pendingBackground = a.pendingBackground && background
+	a.last = now
+	a.mu.Unlock()
+
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next reports whether the pending triggers are due at now, and their lane.
+// If they are not, wait is how long until they are, 0 if none is pending.
+func (a *aggregator) next(now time.Time) (due, background bool, wait time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.pending {
+		return false, false, 0
+	}
+	deadline := a.last.Add(a.delay)
+	if limit := a.first.Add(aggregateMaxDelayFactor * a.delay); limit.Before(deadline) {
+		deadline = limit
+	}
+	if now.Before(deadline) {
+		return false, false, deadline.Sub(now)
+	}
+	a.pending = false
+	return true, a.pendingBackground, 0
+}
+
+// Start flushes triggers into the user and background channels until ctx is
+// done.
+func (a *aggregator) Start(ctx context.Context) error {
+	marker := event.GenericEvent{Object: &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: catalogRequest.Name}}}
+	for {
+		due, background, wait := a.next(time.Now())
+		if due {
+			out := a.user
+			if background {
+				out = a.background
+			}
+			select {
+			case out <- marker:
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-a.wake:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregator", func() {
+	It("queues the catalog once per burst of changes, in the lane of its most urgent change", func() {
+		a := newAggregator(time.Minute)
+		due, _, wait := a.next(time.Now())
+		Expect(due).To(BeFalse())
+		Expect(wait).To(BeZero())
+
+		a.Trigger(true)
+		a.Trigger(true)
+		due, _, wait = a.next(time.Now())
+		Expect(due).To(BeFalse())
+		Expect(wait).To(BeNumerically("~", time.Minute, time.Second))
+		due, background, _ := a.next(time.Now().Add(time.Minute))
+		Expect(due).To(BeTrue())
+		Expect(background).To(BeTrue())
+		due, _, _ = a.next(time.Now().Add(time.Minute))
+		Expect(due).To(BeFalse())
+
+		By("moving the burst to the user lane on a user's change")
+		a.Trigger(true)
+		a.Trigger(false)
+		due, background, _ = a.next(time.Now().Add(time.Minute))
+		Expect(due).To(BeTrue())
+		Expect(background).To(BeFalse())
+
+		By("flushing a burst that keeps going")
+		a.Trigger(true)
+		a.mu.Lock()
+		a.first = a.first.Add(-aggregateMaxDelayFactor * time.Minute)
+		a.mu.Unlock()
+		due, _, _ = a.next(time.Now())
+		Expect(due).To(BeTrue())
+	})
+
+	It("sends catalog reconciles to the channel of their lane", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		a := newAggregator(0)
+		go func() { _ = a.Start(ctx) }()
+
+		a.Trigger(false)
+		Eventually(a.user).Should(Receive())
+		a.Trigger(true)
+		Eventually(a.background).Should(Receive())
+		Consistently(a.user, 100*time.Millisecond).ShouldNot(Receive())
+	})
+})
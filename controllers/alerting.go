@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/alerting"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/metrics"
+)
+
+// healthStates are the states exported by duro_app_health_status
+var healthStates = []dashboardv1alpha1.HealthState{
+	dashboardv1alpha1.HealthUp,
+	dashboardv1alpha1.HealthDown,
+	dashboardv1alpha1.HealthDegraded,
+	dashboardv1alpha1.HealthUnknown,
+}
+
+// recordAppHealth exports the published health of every entry, dropping
+// series of apps no longer listed.
+func recordAppHealth(entries []assembler.AppEntry) {
+	metrics.AppHealthStatus.Reset()
+	for _, e := range entries {
+		if e.Health == "" {
+			continue
+		}
+		namespace, name, _ := strings.Cut(e.Source, "/")
+		for _, state := range healthStates {
+			value := 0.0
+			if string(state) == e.Health {
+				value = 1
+			}
+			metrics.AppHealthStatus.WithLabelValues(namespace, name, string(state)).Set(value)
+		}
+	}
+}
+
+// AlertRuleReconciler maintains one PrometheusRule per DashboardApp,
+// alerting when the app has been down for longer than For. The rule is
+// controlled by the app, so it is garbage collected along with it, and
+// deleted when the app opts out through alerting.OptOutLabel. It must only
+// be set up when the PrometheusRule CRD exists.
+type AlertRuleReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder record.EventRecorder
+
+	// For is how long an app must be down before the alert fires
+	For time.Duration
+
+	// Selector restricts the apps getting a rule to the instance's apps
+	Selector labels.Selector
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *AlertRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Selector == nil {
+		r.Selector = labels.Everything()
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("alertrules").
+		For(&dashboardv1alpha1.DashboardApp{},
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{},
+				predicate.LabelChangedPredicate{})),
+		).
+		Owns(alerting.NewRule()).
+		Complete(r)
+}
+
+// Reconcile handles the reconciliation loop
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;delete
+
+func (r *AlertRuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("dashboardapp", req.NamespacedName)
+
+	app := &dashboardv1alpha1.DashboardApp{}
+	if err := r.Get(ctx, req.NamespacedName, app); err != nil {
+		// The rule of a deleted app is garbage collected
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	wanted := app.DeletionTimestamp.IsZero() && alerting.Enabled(app) &&
+		r.Selector.Matches(labels.Set(app.Labels))
+
+	existing := alerting.NewRule()
+	err := r.Get(ctx, client.ObjectKey{Namespace: app.Namespace, Name: alerting.RuleName(app)}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, operrors.NewTransientError("failed to get PrometheusRule", err)
+	}
+	found := err == nil
+	managed := found && existing.GetLabels()[alerting.SourceLabel] == "duro-operator" &&
+		metav1.IsControlledBy(existing, app)
+
+	spec := alerting.Spec(app, r.For)
+	switch {
+	case !wanted:
+		if managed {
+			log.Info("App opted out of alerting, deleting PrometheusRule")
+			if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, operrors.NewTransientError("failed to delete PrometheusRule", err)
+			}
+		}
+	case !found:
+		rule := alerting.NewRule()
+		rule.SetNamespace(app.Namespace)
+		rule.SetName(alerting.RuleName(app))
+		rule.SetLabels(map[string]string{alerting.SourceLabel: "duro-operator"})
+		rule.Object["spec"] = spec
+		if err := controllerutil.SetControllerReference(app, rule, r.Scheme); err != nil {
+			return ctrl.Result{}, operrors.NewPermanentError("failed to set owner reference", err)
+		}
+		log.Info("Creating PrometheusRule for app")
+		if err := r.Create(ctx, rule); err != nil {
+			return ctrl.Result{}, operrors.NewTransientError("failed to create PrometheusRule", err)
+		}
+	case !managed:
+		log.Info("PrometheusRule exists and is not managed by this app, leaving it alone")
+		r.Recorder.Event(app, corev1.EventTypeWarning, "AlertRuleConflict",
+			"PrometheusRule "+alerting.RuleName(app)+" exists and is not managed by this app")
+	case !equality.Semantic.DeepEqual(existing.Object["spec"], spec):
+		existing.Object["spec"] = spec
+		log.Info("Updating PrometheusRule for app")
+		if err := r.Update(ctx, existing); err != nil {
+			return ctrl.Result{}, operrors.NewTransientError("failed to update PrometheusRule", err)
+		}
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/redact"
+)
+
+const (
+	// ConditionPriorityCollision is True when the app shares its priority with
+	// another app of the same category
+	ConditionPriorityCollision = "PriorityCollision"
+
+	// ConditionSynced is True when the last reconcile wrote the output, and
+	// False with the failing reconcile's trace ID when it could not
+	ConditionSynced = "Synced"
+
+	// ConditionDanglingReference is True when the app references a category
+	// that no longer exists
+	ConditionDanglingReference = "DanglingReference"
+
+	// ConditionDuplicateName is True when another app has the same display
+	// name
+	ConditionDuplicateName = "DuplicateName"
+
+	// ConditionDisabled is True when spec.enabled hides the app from the
+	// dashboard
+	ConditionDisabled = "Disabled"
+
+	// ConditionReady is True when the app is listed in the written output;
+	// it supersedes status.ready
+	ConditionReady = "Ready"
+
+	// ConditionValidationFailed is True when the app breaks a rule of the
+	// current configuration, as found by the last reconcile
+	ConditionValidationFailed = "ValidationFailed"
+
+	// ConditionIconResolveFailed is True when the app's icon shorthand or
+	// iconURL could not be fetched, the app being listed without an icon
+	ConditionIconResolveFailed = "IconResolveFailed"
+)
+
+// setPriorityCondition records the app's priority analysis result. A nil
+// report (analysis disabled) removes the condition. Returns true if the
+// status changed.
+func setPriorityCondition(app *dashboardv1alpha1.DashboardApp, report *assembler.PriorityReport) bool {
+	if report == nil {
+		return meta.RemoveStatusCondition(&app.Status.Conditions, ConditionPriorityCollision)
+	}
+
+	source := app.Namespace + "/" + app.Name
+	cond := metav1.Condition{
+		Type:               ConditionPriorityCollision,
+		Status:             metav1.ConditionFalse,
+		Reason:             "UniquePriority",
+		Message:            "Priority is unique within the category",
+		ObservedGeneration: app.Generation,
+	}
+
+	if collision, ok := report.CollisionFor(source); ok {
+		others := make([]string, 0, len(collision.Sources)-1)
+		for _, s := range collision.Sources {
+			if s != source {
+				others = append(others, s)
+			}
+		}
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "SharedPriority"
+		cond.Message = fmt.Sprintf("Priority %d is shared with %s in category %q; suggested priority: %d",
+			collision.Priority, strings.Join(others, ", "), collision.Category, report.Suggestions[source])
+	}
+
+	return meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
+
+// setDisabledCondition records whether spec.enabled hides the app. Returns
+// true if the status changed.
+func setDisabledCondition(app *dashboardv1alpha1.DashboardApp) bool {
+	cond := metav1.Condition{
+		Type:               ConditionDisabled,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Enabled",
+		Message:            "App is listed on the dashboard",
+		ObservedGeneration: app.Generation,
+	}
+	if app.Disabled() {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "Disabled"
+		cond.Message = "spec.enabled is false, app is hidden from the dashboard"
+	}
+	return meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
+
+// setSyncedCondition records whether the app's output was written. A failure
+// keeps the message (and trace ID) of the first failing reconcile until the
+// reason changes or the app syncs again, so a persistent failure does not
+// rewrite every app on each retry. Returns true if the status changed.
+func setSyncedCondition(app *dashboardv1alpha1.DashboardApp, failReason, failMessage string) bool {
+	cond := metav1.Condition{
+		Type:               ConditionSynced,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Synced",
+		Message:            "Output written",
+		ObservedGeneration: app.Generation,
+	}
+	if failReason != "" {
+		if existing := meta.FindStatusCondition(app.Status.Conditions, ConditionSynced); existing != nil &&
+			existing.Status == metav1.ConditionFalse && existing.Reason == failReason {
+			return false
+		}
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = failReason
+		cond.Message = failMessage
+	}
+	return meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
+
+// setDanglingCondition records whether the app's category exists; missing
+// is the dangling category, empty if it resolves. Returns true if the status
+// changed.
+func setDanglingCondition(app *dashboardv1alpha1.DashboardApp, missing, fallback string) bool {
+	cond := metav1.Condition{
+		Type:               ConditionDanglingReference,
+		Status:             metav1.ConditionFalse,
+		Reason:             "ReferencesResolved",
+		Message:            "Category exists",
+		ObservedGeneration: app.Generation,
+	}
+	if missing != "" {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "CategoryNotFound"
+		cond.Message = fmt.Sprintf("Category %q is neither a DashboardCategory nor built in", missing)
+		if fallback != "" {
+			cond.Message += fmt.Sprintf("; listed under %q", fallback)
+		}
+	}
+	return meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
+
+// setDuplicateNameCondition records whether another app shares the app's
+// display name. The condition is removed when the policy is off. Returns
+// true if the status changed.
+func setDuplicateNameCondition(app *dashboardv1alpha1.DashboardApp, duplicates []assembler.NameDuplicate, policy string) bool {
+	if policy == "" || policy == assembler.DuplicateNamesOff {
+		return meta.RemoveStatusCondition(&app.Status.Conditions, ConditionDuplicateName)
+	}
+
+	source := app.Namespace + "/" + app.Name
+	cond := metav1.Condition{
+		Type:               ConditionDuplicateName,
+		Status:             metav1.ConditionFalse,
+		Reason:             "UniqueName",
+		Message:            "Display name is unique",
+		ObservedGeneration: app.Generation,
+	}
+	if d, ok := assembler.DuplicateFor(duplicates, source); ok {
+		others := make([]string, 0, len(d.Sources)-1)
+		for _, s := range d.Sources {
+			if s != source {
+				others = append(others, s)
+			}
+		}
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "SharedName"
+		cond.Message = fmt.Sprintf("Display name %q is also used by %s", d.Name, strings.Join(others, ", "))
+		if policy == assembler.DuplicateNamesSuffix {
+			cond.Message += "; suffixed to tell them apart"
+		}
+	}
+	return meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
+
+// setReadyCondition records whether the app is listed in the output;
+// notReadyReason and message explain why it is not. Returns true if the
+// status changed.
+func setReadyCondition(app *dashboardv1alpha1.DashboardApp, notReadyReason, message string) bool {
+	cond := metav1.Condition{
+		Type:               ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Listed",
+		Message:            "App is listed in the output",
+		ObservedGeneration: app.Generation,
+	}
+	if notReadyReason != "" {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = notReadyReason
+		cond.Message = message
+	}
+	return meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
+
+// setValidationCondition records the rules the app breaks, if any, with the
+// credentials of rendered URLs masked. Returns true if the status changed.
+func setValidationCondition(app *dashboardv1alpha1.DashboardApp, violations []string) bool {
+	cond := metav1.Condition{
+		Type:               ConditionValidationFailed,
+		Status:             metav1.ConditionFalse,
+		Reason:             "Valid",
+		Message:            "App follows the current rules",
+		ObservedGeneration: app.Generation,
+	}
+	if len(violations) > 0 {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "RulesViolated"
+		redacted := make([]string, len(violations))
+		for i, v := range violations {
+			redacted[i] = redact.String(v)
+		}
+		cond.Message = strings.Join(redacted, "; ")
+	}
+	return meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
+
+// setIconCondition records whether the app's icon resolved; failure is the
+// resolve error, empty if it did, whose icon URL credentials are masked.
+// Returns true if the status changed.
+func setIconCondition(app *dashboardv1alpha1.DashboardApp, failure string) bool {
+	cond := metav1.Condition{
+		Type:               ConditionIconResolveFailed,
+		Status:             metav1.ConditionFalse,
+		Reason:             "IconResolved",
+		Message:            "Icon resolved",
+		ObservedGeneration: app.Generation,
+	}
+	if failure != "" {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "FetchFailed"
+		cond.Message = redact.String(failure)
+	}
+	return meta.SetStatusCondition(&app.Status.Conditions, cond)
+}
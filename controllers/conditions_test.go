@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func TestConditionMessagesRedacted(t *testing.T) {
+	tests := []struct {
+		name      string
+		set       func(*dashboardv1alpha1.DashboardApp)
+		condition string
+	}{
+		{
+			name: "icon fetch failure",
+			set: func(app *dashboardv1alpha1.DashboardApp) {
+				setIconCondition(app, `Get "https://icons.lan/plex.svg?token=s3cr3t": server returned 500`)
+			},
+			condition: ConditionIconResolveFailed,
+		},
+		{
+			name: "rule violations",
+			set: func(app *dashboardv1alpha1.DashboardApp) {
+				setValidationCondition(app, []string{"url https://plex.lan/?token=s3cr3t is not https", "name is too long"})
+			},
+			condition: ConditionValidationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &dashboardv1alpha1.DashboardApp{}
+			tt.set(app)
+			cond := meta.FindStatusCondition(app.Status.Conditions, tt.condition)
+			if cond == nil {
+				t.Fatalf("condition %s not set", tt.condition)
+			}
+			if strings.Contains(cond.Message, "s3cr3t") || !strings.Contains(cond.Message, "token=REDACTED") {
+				t.Errorf("message = %q, want the token masked", cond.Message)
+			}
+		})
+	}
+}
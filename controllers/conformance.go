@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/conformance"
+	"github.com/fredericrous/duro-operator/pkg/metrics"
+	"github.com/fredericrous/duro-operator/pkg/redact"
+)
+
+// expectServed tells the conformance verifier, if any, which apps document
+// was just written.
+func (r *DashboardAppReconciler) expectServed(ctx context.Context, result *assembler.AssemblyResult) {
+	if r.Conformance == nil {
+		return
+	}
+	sum, err := conformance.Fingerprint([]byte(result.AppsJSON))
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).V(1).Info("Failed to fingerprint apps document for conformance checks", "error", err.Error())
+		return
+	}
+	r.Conformance.Expect(sum, time.Now())
+}
+
+// recordConformance publishes a conformance check to metrics and the
+// OperatorOverview. The served hash of a failed check is kept from the last
+// successful one.
+func (r *DashboardAppReconciler) recordConformance(ctx context.Context, res conformance.Result) {
+	log := r.Log.WithName("conformance")
+
+	if res.Err != nil {
+		metrics.ConformanceCheckErrors.Inc()
+		log.V(1).Info("Conformance check failed", "url", r.Config.ConformanceURL, "error", res.Err.Error())
+	}
+	if res.InSync() {
+		metrics.ConformanceDrift.Set(0)
+	} else {
+		metrics.ConformanceDrift.Set(1)
+	}
+	metrics.ConformanceLag.Set(res.Lag().Seconds())
+
+	checked := metav1.NewTime(res.CheckedAt)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		overview := &dashboardv1alpha1.OperatorOverview{}
+		err := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview)
+		if err != nil {
+			// Created by the first reconcile, which precedes any write
+			return err
+		}
+		status := overview.Status.Conformance
+		if status == nil {
+			status = &dashboardv1alpha1.ConformanceStatus{}
+		}
+		status.URL = r.Config.ConformanceURL
+		status.InSync = res.InSync()
+		status.WrittenHash = res.Written
+		status.LastCheckTime = &checked
+		status.Lag = &metav1.Duration{Duration: res.Lag().Round(time.Second)}
+		status.LastError = ""
+		if res.Err != nil {
+			status.LastError = redact.String(res.Err.Error())
+		} else {
+			status.ServedHash = res.Served
+		}
+		overview.Status.Conformance = status
+		return r.Status().Update(ctx, overview)
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		log.V(1).Info("Failed to record conformance in OperatorOverview", "error", err.Error())
+	}
+}
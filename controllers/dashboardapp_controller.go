@@ -2,28 +2,73 @@ package controllers
 
 import (
 	"context"
-	"crypto/sha256"
+	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
+	goerrors "errors"
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
 
 	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
 	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
 	"github.com/fredericrous/duro-operator/pkg/config"
+	"github.com/fredericrous/duro-operator/pkg/conformance"
 	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/facts"
+	"github.com/fredericrous/duro-operator/pkg/hashing"
+	"github.com/fredericrous/duro-operator/pkg/history"
+	"github.com/fredericrous/duro-operator/pkg/hooks"
+	"github.com/fredericrous/duro-operator/pkg/iconfetch"
+	"github.com/fredericrous/duro-operator/pkg/iconlib"
+	"github.com/fredericrous/duro-operator/pkg/iconpolicy"
+	"github.com/fredericrous/duro-operator/pkg/metrics"
+	"github.com/fredericrous/duro-operator/pkg/redact"
+	"github.com/fredericrous/duro-operator/pkg/usage"
+)
+
+const (
+	// traceIDAnnotation records the trace ID of the reconcile that last wrote the output
+	traceIDAnnotation = "dashboard.homelab.io/trace-id"
+	// lastWriteAnnotation records when the output was last written (RFC3339)
+	lastWriteAnnotation = "dashboard.homelab.io/last-write"
+	// documentHashesAnnotation records the hash of each output document as a
+	// JSON object keyed by ConfigMap key
+	documentHashesAnnotation = "dashboard.homelab.io/document-hashes"
+	// instanceLabel names the operator instance writing an output, so two
+	// instances never overwrite each other's ConfigMap
+	instanceLabel = "dashboard.homelab.io/instance"
+	// maxConfigMapBytes is the most data the API server accepts in a ConfigMap
+	maxConfigMapBytes = 1 << 20
 )
 
 // DashboardAppReconciler reconciles DashboardApp objects
@@ -34,6 +79,55 @@ type DashboardAppReconciler struct {
 	Recorder  record.EventRecorder
 	Config    *config.OperatorConfig
 	Assembler *assembler.Assembler
+
+	// Usage imports per-app usage counts; built from Config when nil
+	Usage usage.Source
+
+	// Catalog, if set, receives every successfully written assembly so it can
+	// be served by the API
+	Catalog *catalog.Store
+
+	// History, if set, keeps the outcome of recent reconciles for the
+	// /debug/reconciles endpoint
+	History *history.Ring
+
+	// Conformance checks that duro serves what was written; built from
+	// Config when nil and a conformance URL is set
+	Conformance *conformance.Verifier
+
+	// Version is the operator version, recorded on the outputs it writes so
+	// the next version can tell what it upgrades from
+	Version string
+
+	// Migrations are run once per instance after upgrades (see Migration);
+	// defaultMigrations when nil
+	Migrations []Migration
+
+	// swept is set once the validation sweep ran since startup
+	swept atomic.Bool
+
+	// selector restricts the apps rendered by this instance
+	selector labels.Selector
+
+	// statuses remembers what each app's status was derived from, so only
+	// the apps affected by a change get their status updated
+	statuses statusMemo
+
+	// aggregator debounces app changes into catalog reconciles
+	aggregator *aggregator
+
+	// offboarded holds the namespaces being deleted whose offboarding was
+	// reported. Only catalog reconciles, which never run concurrently,
+	// touch it.
+	offboarded map[string]bool
+
+	// restartedFor is the output hash the duro Deployment was last rolled
+	// out for; only touched by catalog reconciles
+	restartedFor string
+
+	// migrated is set once the migrations ran since startup; only touched
+	// by catalog reconciles
+	migrated bool
 }
 
 // SetupWithManager sets up the controller with the Manager
@@ -43,161 +137,1080 @@ func (r *DashboardAppReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	r.Assembler = assembler.NewAssembler(r.Log.WithName("assembler"))
+	r.Assembler.OutputGroups = r.Config.GroupOutputs
+	r.Assembler.ShardByCategory = r.Config.ShardByCategory
+	r.Assembler.Checksums = r.Config.Checksums
+	r.Assembler.FallbackCategory = r.Config.FallbackCategory
+	r.Assembler.DuplicateNamePolicy = r.Config.DuplicateNamePolicy
+	r.Assembler.Strict = r.Config.Strict
+	r.Assembler.HealthDamping = r.Config.HealthDamping
+	r.Assembler.Variables = r.Config.TemplateVariables()
+	r.Assembler.Sort = r.Config.Sort
+	r.Assembler.NewWindow = r.Config.NewBadgeWindow
+	r.Assembler.IDTemplate = r.Config.IDTemplate
+	r.Assembler.IconBaseURL = r.Config.IconBaseURL
+	r.Assembler.IconConfigMap = r.Config.IconConfigMap
+	r.Assembler.IconsKey = r.Config.IconsKey
+	r.Assembler.IconLibraries = iconlib.Defaults.With(r.Config.IconLibraries)
+	if !r.Config.IconURLPassthrough {
+		fetcher := iconfetch.NewFetcher(int64(r.Config.IconURLMaxBytes), r.Config.IconURLRefresh)
+		// Validated with the rest of the configuration
+		fetcher.AllowedNetworks, _ = r.Config.IconURLNetworks()
+		r.Assembler.IconResolver = fetcher
+		r.Assembler.IconFetchBudget = r.Config.IconURLFetchBudget
+	}
+	r.Assembler.IconPolicy = iconpolicy.Policy{Mode: iconpolicy.Mode(r.Config.IconPolicy), MaxDataURIBytes: r.Config.IconMaxDataURIBytes}
+	r.Assembler.HookFailurePolicy = r.Config.HookFailurePolicy
+	for _, path := range r.Config.EntryHooks {
+		hook, err := hooks.NewExec(path, r.Config.HookTimeout)
+		if err != nil {
+			return err
+		}
+		r.Assembler.Hooks = append(r.Assembler.Hooks, hook)
+	}
+	if r.Usage == nil {
+		r.Usage = r.usageSource()
+	}
+	if r.Conformance == nil && r.Config.ConformanceURL != "" {
+		r.Conformance = conformance.NewVerifier(r.Config.ConformanceURL, r.Config.ConformanceInterval, r.recordConformance)
+		if err := mgr.Add(r.Conformance); err != nil {
+			return err
+		}
+	}
+
+	r.selector = r.Config.Selector()
+	r.aggregator = newAggregator(r.Config.AggregateDebounce)
+	if err := mgr.Add(r.aggregator); err != nil {
+		return err
+	}
+
+	if err := r.setupValidationSweep(mgr); err != nil {
+		return err
+	}
 
 	opts := controller.Options{
 		MaxConcurrentReconciles: r.Config.MaxConcurrentReconciles,
+		UsePriorityQueue:        &r.Config.PriorityLanes,
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&dashboardv1alpha1.DashboardApp{},
+	// Apps are watched through laned rather than For, so that background
+	// changes to them queue behind the edits of users (see laneFuncs)
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named("dashboardapp").
+		Watches(&dashboardv1alpha1.DashboardApp{}, laned(&handler.EnqueueRequestForObject{}, appLanes),
 			// Ignore status-only changes: Reconcile writes Status.LastSyncedAt=now
 			// on every DashboardApp per reconcile, which would otherwise cascade
 			// into N² re-reconciles through the default watch predicate.
-			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+			// Health changes are the exception since dependents roll them up,
+			// as are heartbeats bringing a stale app back.
+			// Apps outside the instance's selector are ignored, except when a
+			// label change moves them in or out of it.
+			builder.WithPredicates(selectedPredicate(r.selector), predicate.Or(predicate.GenerationChangedPredicate{},
+				healthChangedPredicate(), heartbeatRecoveredPredicate(), resyncRequestedPredicate(), selectionChangedPredicate(r.selector))),
 		).
 		WithOptions(opts).
-		Complete(r)
+		WatchesRawSource(source.Channel(r.aggregator.user, handler.EnqueueRequestsFromMapFunc(mapToCatalog))).
+		WatchesRawSource(source.Channel(r.aggregator.background, laned(handler.EnqueueRequestsFromMapFunc(mapToCatalog), allBackground)))
+
+	// Category metadata is part of the output, so any change re-renders it
+	b = b.Watches(&dashboardv1alpha1.DashboardCategory{},
+		handler.EnqueueRequestsFromMapFunc(mapToCatalog),
+		builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+	)
+
+	// Bookmarks are rendered next to the apps; label changes may move them
+	// in or out of the instance's selector
+	b = b.Watches(&dashboardv1alpha1.DashboardBookmark{},
+		handler.EnqueueRequestsFromMapFunc(mapToCatalog),
+		builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{})),
+	)
+
+	// A resync annotation on the overview requests a full rebuild
+	b = b.Watches(&dashboardv1alpha1.OperatorOverview{},
+		handler.EnqueueRequestsFromMapFunc(mapToCatalog),
+		builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetName() == r.Config.Identity()
+		}), resyncRequestedPredicate()),
+	)
+
+	// Changing a shared substitution re-renders the whole catalog
+	if r.Config.SubstitutionsConfigMap != "" {
+		b = b.Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(mapToCatalog),
+			builder.WithPredicates(predicate.NewPredicateFuncs(r.isSubstitutionsConfigMap)),
+		)
+	}
+
+	// Dashboards are written with the main output; their target ConfigMaps
+	// and Secrets are owned by them, so edits to those are repaired right
+	// away too
+	if r.Config.Dashboards {
+		b = b.Watches(&dashboardv1alpha1.Dashboard{},
+			handler.EnqueueRequestsFromMapFunc(mapToCatalog),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		)
+		for _, obj := range []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}} {
+			b = b.Watches(obj,
+				handler.EnqueueRequestsFromMapFunc(mapToCatalog),
+				builder.WithPredicates(predicate.NewPredicateFuncs(isDashboardOutput)),
+			)
+		}
+	}
+
+	// Edits to the output, or its deletion, are repaired right away
+	b = b.Watches(newOutputObject(r.Config.OutputKind),
+		handler.EnqueueRequestsFromMapFunc(mapToCatalog),
+		builder.WithPredicates(predicate.NewPredicateFuncs(r.isOutputConfigMap)),
+	)
+
+	// Feature flags feed spec.condition
+	if r.Config.FactsConfigMap != "" {
+		b = b.Watches(&corev1.ConfigMap{},
+			laned(handler.EnqueueRequestsFromMapFunc(mapToCatalog), allBackground),
+			builder.WithPredicates(predicate.NewPredicateFuncs(r.isFactsConfigMap)),
+		)
+	}
+
+	// Groups derived from RoleBindings follow changes to them
+	if r.Config.RBACGroups {
+		b = b.Watches(&rbacv1.RoleBinding{},
+			laned(handler.EnqueueRequestsFromMapFunc(mapToCatalog), allBackground),
+		)
+	}
+
+	// Apps of namespaces being deleted leave the catalog right away
+	b = b.Watches(&corev1.Namespace{},
+		handler.EnqueueRequestsFromMapFunc(mapToCatalog),
+		builder.WithPredicates(namespaceTerminatingPredicate()),
+	)
+
+	// Categories inferred from namespace labels follow changes to them
+	if r.Config.CategoryLabel != "" {
+		b = b.Watches(&corev1.Namespace{},
+			laned(handler.EnqueueRequestsFromMapFunc(mapToCatalog), allBackground),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		)
+	}
+
+	return b.Complete(r)
+}
+
+// selectedPredicate passes apps matching the instance's selector; updates
+// pass if either version matches, so an app leaving the selector is
+// dropped from the output, or if the app still holds the removal
+// finalizer, so it is released from apps that left the selector earlier.
+func selectedPredicate(sel labels.Selector) predicate.Predicate {
+	matches := func(obj client.Object) bool { return sel.Matches(labels.Set(obj.GetLabels())) }
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return matches(e.Object) },
+		DeleteFunc: func(e event.DeleteEvent) bool { return matches(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return matches(e.ObjectOld) || matches(e.ObjectNew) ||
+				controllerutil.ContainsFinalizer(e.ObjectNew, dashboardv1alpha1.RemovalFinalizer)
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return matches(e.Object) },
+	}
+}
+
+// selectionChangedPredicate passes updates moving an app in or out of the
+// instance's selector, which do not change its generation.
+func selectionChangedPredicate(sel labels.Selector) predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return sel.Matches(labels.Set(e.ObjectOld.GetLabels())) != sel.Matches(labels.Set(e.ObjectNew.GetLabels()))
+		},
+	}
+}
+
+// healthChangedPredicate passes updates that change status.health.state or
+// count another health check result towards the health damping threshold.
+func healthChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldApp, ok1 := e.ObjectOld.(*dashboardv1alpha1.DashboardApp)
+			newApp, ok2 := e.ObjectNew.(*dashboardv1alpha1.DashboardApp)
+			if !ok1 || !ok2 {
+				return false
+			}
+			return healthState(oldApp) != healthState(newApp) || healthResults(oldApp) != healthResults(newApp)
+		},
+	}
+}
+
+// healthResults is how many consecutive health check results reported the
+// app's last recorded health state.
+func healthResults(app *dashboardv1alpha1.DashboardApp) int32 {
+	if n := len(app.Status.HealthHistory); n > 0 {
+		return app.Status.HealthHistory[n-1].Count
+	}
+	return 0
+}
+
+func healthState(app *dashboardv1alpha1.DashboardApp) dashboardv1alpha1.HealthState {
+	if app.Status.Health == nil {
+		return ""
+	}
+	return app.Status.Health.State
+}
+
+func (r *DashboardAppReconciler) isSubstitutionsConfigMap(obj client.Object) bool {
+	return obj.GetNamespace() == r.Config.DuroNamespace && obj.GetName() == r.Config.SubstitutionsConfigMap
+}
+
+func (r *DashboardAppReconciler) isOutputConfigMap(obj client.Object) bool {
+	if obj.GetNamespace() != r.Config.DuroNamespace {
+		return false
+	}
+	if r.Config.OutputNameHash {
+		return obj.GetLabels()[outputNameLabel] == r.Config.DuroConfigMapName
+	}
+	return obj.GetName() == r.Config.DuroConfigMapName
+}
+
+func (r *DashboardAppReconciler) isFactsConfigMap(obj client.Object) bool {
+	return obj.GetNamespace() == r.Config.DuroNamespace && obj.GetName() == r.Config.FactsConfigMap
+}
+
+// mapToCatalog enqueues the catalog for a dependency of it (category,
+// substitutions ConfigMap), so changes to several dependencies queue a
+// single reconcile.
+func mapToCatalog(context.Context, client.Object) []reconcile.Request {
+	return []reconcile.Request{catalogRequest}
 }
 
-// Reconcile handles the reconciliation loop
+// Reconcile handles the requests of single apps with reconcileApp, and
+// assembles the catalog for catalogRequest
 // +kubebuilder:rbac:groups=dashboard.homelab.io,resources=dashboardapps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=dashboard.homelab.io,resources=dashboardapps/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=dashboard.homelab.io,resources=dashboardapps/finalizers,verbs=update
+// +kubebuilder:rbac:groups=dashboard.homelab.io,resources=dashboardbookmarks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dashboard.homelab.io,resources=dashboardcategories,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dashboard.homelab.io,resources=dashboards,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=dashboard.homelab.io,resources=dashboards/finalizers,verbs=update
+// +kubebuilder:rbac:groups=dashboard.homelab.io,resources=dashboards/status,verbs=get;update
+// +kubebuilder:rbac:groups=dashboard.homelab.io,resources=operatoroverviews,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=dashboard.homelab.io,resources=operatoroverviews/status,verbs=get;update
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;create;update
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=patch
 
 func (r *DashboardAppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := r.Log.WithValues("dashboardapp", req.NamespacedName, "trace_id", generateTraceID())
+	if req != catalogRequest {
+		return r.reconcileApp(ctx, req)
+	}
+
+	traceID := generateTraceID()
+	log := r.Log.WithValues("dashboardapp", req.NamespacedName, "trace_id", traceID)
+	start := time.Now()
 
-	ctx, cancel := context.WithTimeout(ctx, r.Config.ReconcileTimeout)
+	reconcileCtx, cancel := context.WithTimeout(ctx, r.Config.ReconcileTimeout)
 	defer cancel()
 
-	ctx = logr.NewContext(ctx, log)
+	summary := &reconcileSummary{}
+	result, err := r.reconcile(logr.NewContext(reconcileCtx, log), traceID, summary)
+
+	// Recorded outside the reconcile timeout so timeouts show up too
+	r.recordDeadline(logr.NewContext(ctx, log), traceID, time.Since(start))
+	r.recordOverview(logr.NewContext(ctx, log), traceID, time.Since(start), summary, err)
+	r.recordHistory(req, traceID, start, summary, err)
+
+	return result, err
+}
+
+// reconcileApp handles a change to a single app. It acknowledges a resync
+// requested on the app right away and asks for the catalog to be assembled
+// again; the rest of the app's status follows from the assembly.
+func (r *DashboardAppReconciler) reconcileApp(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	app := &dashboardv1alpha1.DashboardApp{}
+	if err := r.Get(ctx, req.NamespacedName, app); err != nil {
+		if errors.IsNotFound(err) {
+			r.aggregator.Trigger(false)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, operrors.NewTransientError("failed to get DashboardApp", err)
+	}
+	// An app leaving the selector is dropped from the catalog, and no
+	// longer held by the removal finalizer
+	if !r.selector.Matches(labels.Set(app.Labels)) {
+		if err := r.releaseFinalizer(ctx, app); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.aggregator.Trigger(false)
+		return ctrl.Result{}, nil
+	}
+
+	// Spec changes and resyncs of apps users write are theirs, the rest
+	// (health, heartbeats, discovery) background work; see appLanes
+	background := discovered(app) || (app.Generation == app.Status.ObservedGeneration &&
+		app.Annotations[dashboardv1alpha1.ResyncAnnotation] == app.Status.ObservedResync)
+	if r.resyncApp(app, false) {
+		if err := r.Status().Update(ctx, app); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	r.aggregator.Trigger(background)
+	return ctrl.Result{}, nil
+}
+
+// reconcile assembles every DashboardApp into the duro ConfigMap, filling in
+// summary as it goes. Failures that are retried through RequeueAfter rather
+// than returned are recorded in summary.err.
+func (r *DashboardAppReconciler) reconcile(ctx context.Context, traceID string, summary *reconcileSummary) (ctrl.Result, error) {
+	log := logr.FromContextOrDiscard(ctx)
 
 	log.V(1).Info("Starting reconciliation")
 
-	// Fetch all DashboardApps cluster-wide
+	// Fetch the DashboardApps of this instance cluster-wide
 	appList := &dashboardv1alpha1.DashboardAppList{}
-	if err := r.List(ctx, appList); err != nil {
+	if err := r.List(ctx, appList, client.MatchingLabelsSelector{Selector: r.selector}); err != nil {
 		return ctrl.Result{}, operrors.NewTransientError("failed to list DashboardApps", err)
 	}
 
-	if len(appList.Items) == 0 {
-		log.Info("No DashboardApp resources found, skipping reconciliation")
+	bookmarkList := &dashboardv1alpha1.DashboardBookmarkList{}
+	if err := r.List(ctx, bookmarkList, client.MatchingLabelsSelector{Selector: r.selector}); err != nil {
+		return ctrl.Result{}, operrors.NewTransientError("failed to list DashboardBookmarks", err)
+	}
+
+	summary.apps = len(appList.Items)
+	if len(appList.Items) == 0 && len(bookmarkList.Items) == 0 {
+		log.Info("No DashboardApp or DashboardBookmark resources found, skipping reconciliation")
 		return ctrl.Result{}, nil
 	}
+	// Events about the whole catalog go to its first app, if any
+	var subject *dashboardv1alpha1.DashboardApp
+	if len(appList.Items) > 0 {
+		subject = &appList.Items[0]
+	}
+
+	// Drop externally registered apps whose TTL ran out, and deleted apps
+	// once their removal grace period is over
+	apps, nextExpiry := r.pruneExpired(ctx, appList.Items, time.Now())
+	apps, offboarded, err := r.offboardNamespaces(ctx, apps)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	apps, removed, nextRemoval := r.applyRemovalGrace(ctx, apps, time.Now())
+	nextExpiry = earliest(nextExpiry, nextRemoval)
+
+	if err := r.applyRBACGroups(ctx, apps); err != nil {
+		return ctrl.Result{}, err
+	}
+	inferred, err := r.applyNamespaceCategories(ctx, apps)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Migrations may request a rebuild, done right away
+	if err := r.runMigrations(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// A resync requested on the overview rewrites everything
+	rebuild, err := r.pendingRebuild(ctx)
+	if err != nil {
+		return ctrl.Result{}, operrors.NewTransientError("failed to get OperatorOverview", err)
+	}
+	if rebuild != "" {
+		log.Info("Full rebuild requested", "resync", rebuild)
+		r.forgetIcons(apps...)
+	}
+
+	vars, err := r.loadSubstitutions(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	categoryList := &dashboardv1alpha1.DashboardCategoryList{}
+	if err := r.List(ctx, categoryList); err != nil {
+		return ctrl.Result{}, operrors.NewTransientError("failed to list DashboardCategories", err)
+	}
+	counts := r.loadUsage(ctx)
+
+	// Cluster facts are only gathered when some app is conditional
+	var clusterFacts *facts.Facts
+	if assembler.HasConditions(apps) {
+		clusterFacts, err = facts.Gather(ctx, r.Client, types.NamespacedName{Name: r.Config.FactsConfigMap, Namespace: r.Config.DuroNamespace})
+		if err != nil {
+			return ctrl.Result{}, operrors.NewTransientError("failed to gather cluster facts", err)
+		}
+	}
+
+	// Apps of equal priority keep the order of the last write
+	previous, err := r.previousOrder(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// Assemble the apps JSON
-	result, err := r.Assembler.Assemble(ctx, appList.Items)
+	asm := r.Assembler.WithVariables(vars).WithCategories(categoryList.Items).WithBookmarks(bookmarkList.Items).WithUsage(counts).WithFacts(clusterFacts).WithPreviousOrder(previous)
+	assemblyStart := time.Now()
+	result, err := asm.Assemble(ctx, apps)
+	metrics.AssemblyDuration.Observe(time.Since(assemblyStart).Seconds())
 	if err != nil {
-		r.Recorder.Event(&appList.Items[0], corev1.EventTypeWarning, "AssemblyFailed", err.Error())
+		r.reportSyncFailure(ctx, apps, failingApp(apps, err, subject), "AssemblyFailed", err.Error(), traceID)
 		if operrors.ShouldRetry(err) {
-			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+			summary.err = err
+			return ctrl.Result{RequeueAfter: defaultRetryDelay}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
+	// Icons kept next to their apps are written first, so the catalog never
+	// references an icon that isn't there yet
+	if err := r.syncIconConfigMaps(ctx, apps, result); err != nil {
+		r.reportSyncFailure(ctx, apps, subject, "IconConfigMapFailed", fmt.Sprintf("Failed to update icon ConfigMaps: %v", err), traceID)
+		summary.err = fmt.Errorf("failed to update icon ConfigMaps: %w", err)
+		return ctrl.Result{RequeueAfter: retryDelay(err)}, nil
+	}
+
 	// Update the duro apps ConfigMap
-	if err := r.updateAppsConfig(ctx, result); err != nil {
-		r.Recorder.Eventf(&appList.Items[0], corev1.EventTypeWarning, "ConfigUpdateFailed", "Failed to update duro apps config: %v", err)
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	// A write held back by the minimum write interval is retried once it
+	// is over; everything else still runs against the output in place
+	configHash, err := r.updateAppsConfig(ctx, result, traceID, rebuild != "")
+	var deferred *writeDeferredError
+	var writeRetry time.Duration
+	if goerrors.As(err, &deferred) {
+		log.V(1).Info("Output write deferred by minimum write interval", "target", deferred.target, "after", deferred.wait)
+		writeRetry, err = deferred.wait, nil
+	}
+	summary.targets = r.outputTargets(result, err)
+	if err != nil {
+		r.reportSyncFailure(ctx, apps, subject, "ConfigUpdateFailed", fmt.Sprintf("Failed to update duro apps config: %v", err), traceID)
+		summary.err = fmt.Errorf("failed to update duro apps config: %w", err)
+		return ctrl.Result{RequeueAfter: retryDelay(err)}, nil
+	}
+	written := writeRetry == 0
+	if written {
+		r.releaseRemoved(ctx, removed)
+		r.releaseOffboarded(ctx, offboarded, apps)
+	}
+	// duro only reads the output at startup
+	var restartRetry time.Duration
+	if err := r.restartDuro(ctx, configHash); err != nil {
+		log.Error(err, "Failed to roll out the duro Deployment", "deployment", r.Config.RestartDeployment)
+		summary.err = err
+		restartRetry = retryDelay(err)
+	}
+	dashboardRetry := r.syncDashboards(ctx, asm, apps, traceID, rebuild != "")
+	summary.entries = len(result.Entries)
+	summary.categories = len(result.Categories)
+	summary.configHash = configHash
+	summary.resync = rebuild
+	if r.Config.CatalogStatus {
+		summary.catalog = r.publishedCatalog(result)
+	}
+
+	// The catalog holds what was written
+	if r.Catalog != nil && written {
+		if previous, _ := r.Catalog.Get(); previous != nil {
+			summary.delta = history.Diff(previous.Entries, result.Entries)
+		}
+		r.Catalog.Set(result)
 	}
+	if written {
+		r.expectServed(ctx, result)
+	}
+	recordAppHealth(result.Entries)
+	recordAppsTotal(result.Entries)
 
 	// Update status for all DashboardApps. Skip the write if nothing changed
 	// — ObservedGeneration acts as the "spec was processed" marker, and we
 	// only refresh LastSyncedAt if we actually had work to do or the app
 	// wasn't Ready before. This keeps the controller quiet at steady state.
+	var priorityReport *assembler.PriorityReport
+	if r.Config.PriorityAnalysis {
+		priorityReport = r.analyzePriorities(ctx, result)
+	}
+
+	r.reportIDCollisions(apps, result.IDCollisions)
+
+	// Usage counts are keyed by entry ID, which may differ from the app name
+	entries := make(map[string]*assembler.AppEntry, len(result.Entries))
+	for i := range result.Entries {
+		entries[result.Entries[i].Source] = &result.Entries[i]
+	}
+	ranks := counts.Ranks()
 	now := metav1.Now()
 	var statusUpdateErrors []error
-	for i := range appList.Items {
-		app := &appList.Items[i]
-		if app.Status.Ready && app.Status.ObservedGeneration == app.Generation {
+	sources := make(map[string]bool, len(apps))
+	for i := range apps {
+		app := &apps[i]
+		source := app.Namespace + "/" + app.Name
+		sources[source] = true
+		// An app is left alone when neither it nor what the assembly made
+		// of it changed since its status was last brought up to date.
+		// Heartbeat health goes stale with time alone, and a rebuild
+		// refreshes every app.
+		inputs := statusFingerprint(app, entries[source], result, priorityReport, counts, ranks, inferred[source])
+		if rebuild == "" && app.Spec.HeartbeatTimeout == nil && r.statuses.current(source, app.ResourceVersion, inputs) {
+			metrics.StatusUpdatesSkipped.Inc()
+			continue
+		}
+		wasStale := healthState(app) == dashboardv1alpha1.HealthUnknown
+		statusChanged := setPriorityCondition(app, priorityReport)
+		var failReason, failMessage string
+		if failure, ok := assembler.StrictFailureFor(result.StrictFailures, app.Namespace); ok {
+			failReason, failMessage = "StrictValidationFailed", failure.Message()
+		} else if reason, ok := result.LeftOut[source]; ok {
+			failReason, failMessage = "RenderFailed", fmt.Sprintf("%s (trace_id=%s)", redact.String(reason), traceID)
+		}
+		if setSyncedCondition(app, failReason, failMessage) {
+			statusChanged = true
+		}
+		if setDanglingCondition(app, result.DanglingCategories[source], r.Config.FallbackCategory) {
+			statusChanged = true
+		}
+		id := app.Name
+		if e, ok := entries[source]; ok {
+			id = e.ID
+		}
+		if setDuplicateNameCondition(app, result.DuplicateNames, r.Config.DuplicateNamePolicy) {
+			statusChanged = true
+		}
+		// Resyncs requested on the app itself are acknowledged by
+		// reconcileApp
+		if rebuild != "" && r.resyncApp(app, true) {
+			statusChanged = true
+		}
+		if setUsage(app, id, counts, ranks) {
+			statusChanged = true
+		}
+		if setHeartbeatHealth(app, now.Time) {
+			statusChanged = true
+			if stale := healthState(app) == dashboardv1alpha1.HealthUnknown; stale != wasStale {
+				if stale {
+					r.Recorder.Event(app, corev1.EventTypeWarning, "HeartbeatStale", app.Status.Health.Reason)
+				} else {
+					r.Recorder.Event(app, corev1.EventTypeNormal, "HeartbeatRecovered", "Heartbeat received")
+				}
+			}
+		}
+		if assembler.RecordHealth(app, now.Time) {
+			statusChanged = true
+		}
+		if setDisabledCondition(app) {
+			statusChanged = true
+		}
+		if setValidationCondition(app, result.Violations[source]) {
+			statusChanged = true
+		}
+		if setIconCondition(app, result.IconFailures[source]) {
+			statusChanged = true
+		}
+		if app.Status.InferredCategory != inferred[source] {
+			app.Status.InferredCategory = inferred[source]
+			statusChanged = true
+		}
+		// Disabled apps and apps left out in strict mode or for failing to
+		// render are not on the dashboard, so they are not ready
+		notReadyReason, notReadyMessage := failReason, failMessage
+		if app.Disabled() {
+			notReadyReason, notReadyMessage = "Disabled", "spec.enabled is false"
+		}
+		if setReadyCondition(app, notReadyReason, notReadyMessage) {
+			statusChanged = true
+		}
+		ready := notReadyReason == ""
+		if !statusChanged && app.Status.Ready == ready && app.Status.ObservedGeneration == app.Generation {
+			r.statuses.record(source, app.ResourceVersion, inputs)
 			continue
 		}
-		app.Status.Ready = true
+		app.Status.Ready = ready
 		app.Status.ObservedGeneration = app.Generation
 		app.Status.LastSyncedAt = &now
+		app.Status.LastSyncTraceID = traceID
 		if err := r.Status().Update(ctx, app); err != nil {
 			log.Error(err, "Failed to update DashboardApp status", "app", app.Name)
 			statusUpdateErrors = append(statusUpdateErrors, err)
+			continue
 		}
+		r.statuses.record(source, app.ResourceVersion, inputs)
 	}
+	r.statuses.retain(sources)
 
 	if len(statusUpdateErrors) > 0 {
 		log.Info("Some status updates failed, requeueing", "failedCount", len(statusUpdateErrors))
+		summary.err = fmt.Errorf("%d status updates failed, first: %w", len(statusUpdateErrors), statusUpdateErrors[0])
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 	}
 
-	log.Info("Reconciliation completed successfully", "appCount", len(appList.Items))
+	log.Info("Reconciliation completed successfully", "appCount", len(apps))
+
+	if subject != nil {
+		r.Recorder.Event(subject, corev1.EventTypeNormal, "Synced",
+			fmt.Sprintf("Successfully assembled %d dashboard apps", len(apps)))
+	}
 
-	r.Recorder.Event(&appList.Items[0], corev1.EventTypeNormal, "Synced",
-		fmt.Sprintf("Successfully assembled %d dashboard apps", len(appList.Items)))
+	// Re-render when a time-based rule (visibility window, TTL) flips, and
+	// periodically to pick up new usage counts and cluster facts
+	next := earliest(result.NextTransition, nextExpiry)
+	if r.Usage != nil {
+		next = earliest(next, time.Now().Add(r.Config.UsageRefreshInterval))
+	}
+	if clusterFacts != nil {
+		next = earliest(next, time.Now().Add(r.Config.FactsRefreshInterval))
+	}
+	if dashboardRetry > 0 {
+		next = earliest(next, time.Now().Add(dashboardRetry))
+	}
+	if restartRetry > 0 {
+		next = earliest(next, time.Now().Add(restartRetry))
+	}
+	if writeRetry > 0 {
+		next = earliest(next, time.Now().Add(writeRetry))
+	}
+	if !next.IsZero() {
+		requeueAfter := max(time.Until(next), time.Second)
+		log.V(1).Info("Scheduling re-render for next transition", "at", next, "after", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 
 	return ctrl.Result{}, nil
 }
 
-// updateAppsConfig updates the duro apps ConfigMap
-func (r *DashboardAppReconciler) updateAppsConfig(ctx context.Context, result *assembler.AssemblyResult) error {
+// reportSyncFailure emits a warning Event on subject, if any, and marks
+// every app not Synced. Both carry the trace ID so the failure can be found in the logs.
+func (r *DashboardAppReconciler) reportSyncFailure(ctx context.Context, apps []dashboardv1alpha1.DashboardApp, subject *dashboardv1alpha1.DashboardApp, reason, message, traceID string) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	metrics.SyncErrors.WithLabelValues(reason).Inc()
+	message = fmt.Sprintf("%s (trace_id=%s)", redact.String(message), traceID)
+	if subject != nil {
+		r.Recorder.Event(subject, corev1.EventTypeWarning, reason, message)
+	}
+	for i := range apps {
+		app := &apps[i]
+		if !setSyncedCondition(app, reason, message) {
+			continue
+		}
+		if err := r.Status().Update(ctx, app); err != nil {
+			log.Error(err, "Failed to update DashboardApp status", "app", app.Name)
+		}
+	}
+}
+
+// reportIDCollisions warns on every app dropped from the output because an
+// app sorting before it has the same ID.
+func (r *DashboardAppReconciler) reportIDCollisions(apps []dashboardv1alpha1.DashboardApp, collisions []assembler.IDCollision) {
+	for _, c := range collisions {
+		for _, source := range c.Sources[1:] {
+			for i := range apps {
+				if apps[i].Namespace+"/"+apps[i].Name == source {
+					r.Recorder.Eventf(&apps[i], corev1.EventTypeWarning, "IDCollision",
+						"Entry ID %q is already used by %s; this app is left out of the output", c.ID, c.Sources[0])
+				}
+			}
+		}
+	}
+}
+
+// failingApp returns the app an assembly error is about, when the error
+// names one, or fallback.
+func failingApp(apps []dashboardv1alpha1.DashboardApp, err error, fallback *dashboardv1alpha1.DashboardApp) *dashboardv1alpha1.DashboardApp {
+	var opErr *operrors.OperatorError
+	if !goerrors.As(err, &opErr) {
+		return fallback
+	}
+	source, _ := opErr.Context["app"].(string)
+	for i := range apps {
+		if apps[i].Namespace+"/"+apps[i].Name == source {
+			return &apps[i]
+		}
+	}
+	return fallback
+}
+
+// analyzePriorities reports priority collisions through logs and metrics and
+// returns the report so it can be surfaced on each app's status.
+func (r *DashboardAppReconciler) analyzePriorities(ctx context.Context, result *assembler.AssemblyResult) *assembler.PriorityReport {
 	log := logr.FromContextOrDiscard(ctx)
 
-	configHash := computeHash(result.AppsJSON)
+	report := assembler.AnalyzePriorities(result.Entries)
+
+	metrics.PriorityCollisions.Reset()
+	for _, c := range report.Collisions {
+		metrics.PriorityCollisions.WithLabelValues(c.Category).Add(float64(len(c.Sources)))
+		log.Info("Priority collision", "category", c.Category, "priority", c.Priority, "apps", c.Sources)
+	}
+	return report
+}
 
-	existing := &corev1.ConfigMap{}
-	err := r.Get(ctx, types.NamespacedName{Name: r.Config.DuroConfigMapName, Namespace: r.Config.DuroNamespace}, existing)
+// loadSubstitutions reads the shared substitutions ConfigMap, if configured.
+// A missing ConfigMap is not an error: the apps whose templates reference
+// its keys are left out of the output, each with a ValidationFailed
+// condition, while the others are still published.
+func (r *DashboardAppReconciler) loadSubstitutions(ctx context.Context) (map[string]string, error) {
+	if r.Config.SubstitutionsConfigMap == "" {
+		return nil, nil
+	}
+
+	log := logr.FromContextOrDiscard(ctx)
+
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: r.Config.SubstitutionsConfigMap, Namespace: r.Config.DuroNamespace}, cm)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			cm := &corev1.ConfigMap{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      r.Config.DuroConfigMapName,
-					Namespace: r.Config.DuroNamespace,
-					Labels: map[string]string{
-						"app.kubernetes.io/managed-by": "duro-operator",
-					},
-					Annotations: map[string]string{
-						"dashboard.homelab.io/config-hash": configHash,
-					},
-				},
-				Data: map[string]string{
-					"apps.json": result.AppsJSON,
-				},
-			}
-			log.Info("Creating duro apps ConfigMap", "name", r.Config.DuroConfigMapName)
-			return r.Create(ctx, cm)
+			log.Info("Substitutions ConfigMap not found, using operator variables only", "name", r.Config.SubstitutionsConfigMap)
+			return nil, nil
+		}
+		return nil, operrors.NewTransientError("failed to get substitutions ConfigMap", err)
+	}
+	return cm.Data, nil
+}
+
+// updateAppsConfig updates the duro apps ConfigMap, or Secret (see
+// Config.OutputKind). Returns the hash of the output.
+func (r *DashboardAppReconciler) updateAppsConfig(ctx context.Context, result *assembler.AssemblyResult, traceID string, force bool) (string, error) {
+	if !r.Config.OutputNameHash {
+		key := types.NamespacedName{Name: r.Config.DuroConfigMapName, Namespace: r.Config.DuroNamespace}
+		return r.writeOutput(ctx, key, r.Config.OutputKind, nil, result, r.Config.OutputFormats, traceID, force)
+	}
+
+	// Every version of a hashed output is written to its own object
+	data, err := outputData(result, r.Config.OutputFormats, r.Config.FormatVersions)
+	if err != nil {
+		return "", operrors.NewPermanentError("failed to render output", err)
+	}
+	key := r.outputKey(data)
+	configHash, err := r.writeOutput(ctx, key, r.Config.OutputKind, nil, result, r.Config.OutputFormats, traceID, force)
+	if err != nil {
+		return configHash, err
+	}
+	return configHash, r.publishOutputName(ctx, key.Name)
+}
+
+// writeOutput writes the documents of result, and those of the extra
+// formats, to the ConfigMap or Secret key, depending on kind, created with
+// owner as its controller when owner is set. The trace ID and time of
+// the write are recorded as annotations so the served catalog can be tied
+// back to the reconcile that produced it. Returns the hash of the output.
+//
+// Every document is validated before anything is written and all of them
+// go out in a single server-side apply, so a bad document leaves every key
+// as it was rather than publishing a mix of old and new documents.
+func (r *DashboardAppReconciler) writeOutput(ctx context.Context, key types.NamespacedName, kind string, owner client.Object, result *assembler.AssemblyResult,
+	formats []string, traceID string, force bool) (string, error) {
+	log := logr.FromContextOrDiscard(ctx).WithValues("kind", outputKindName(kind))
+
+	data, err := outputData(result, formats, r.Config.FormatVersions)
+	if err != nil {
+		return "", operrors.NewPermanentError("failed to render output", err)
+	}
+	if err := validateOutput(data); err != nil {
+		return "", err
+	}
+	configHash, err := r.outputHash(result, data)
+	if err != nil {
+		return "", err
+	}
+	docHashes := hashing.SumEach(r.Config.HashAlgorithm, data)
+
+	if kind == config.OutputKindSecret {
+		if err := r.deleteStaleConfigMap(ctx, key); err != nil {
+			return "", err
 		}
-		return err
 	}
 
-	existingHash := ""
-	if existing.Annotations != nil {
-		existingHash = existing.Annotations["dashboard.homelab.io/config-hash"]
+	existing := newOutputObject(kind)
+	err = r.Get(ctx, key, existing)
+	found := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
 	}
+	existing.SetName(key.Name)
+	existing.SetNamespace(key.Namespace)
 
-	if existingHash == configHash {
-		log.V(1).Info("Duro apps ConfigMap unchanged (hash match), skipping update")
-		return nil
+	if owner := existing.GetLabels()[instanceLabel]; owner != "" && owner != r.Config.Identity() {
+		return "", operrors.NewPermanentError(fmt.Sprintf("%s %s/%s is written by operator instance %s",
+			outputKindName(kind), existing.GetNamespace(), existing.GetName(), owner), nil)
 	}
 
-	existing.Data = map[string]string{
-		"apps.json": result.AppsJSON,
+	// Configured labels and annotations are kept in place even when the
+	// documents are unchanged
+	metadataChanged := r.applyOutputMetadata(existing)
+
+	// Documents edited or removed behind our back are repaired right away,
+	// hash match or not
+	existingData := outputDocuments(existing)
+	previous := hashing.DecodeSums(existing.GetAnnotations()[documentHashesAnnotation])
+	stale := slices.Sorted(maps.Keys(data))
+	if found {
+		drifted := driftedDocuments(existingData, previous)
+		if len(drifted) > 0 {
+			log.Info("Duro apps output drifted from the last write, repairing", "documents", drifted)
+			metrics.OutputDriftRepairs.Inc()
+		}
+		// Whether a document needs writing is decided by what the target
+		// holds, not by the hashes recorded at the last write
+		stale = staleDocuments(existingData, docHashes, previous)
+		if !force && !metadataChanged && len(stale) == 0 && hashing.Equal(existing.GetAnnotations()["dashboard.homelab.io/config-hash"], configHash) {
+			log.V(1).Info("Duro apps output unchanged (hash match), skipping update")
+			return configHash, nil
+		}
+
+		// The hash of what the target still holds comes along, so the steps
+		// following the write act on the served output
+		if wait := writeDelay(existing, r.minWriteInterval(owner), time.Now()); wait > 0 && !force && len(drifted) == 0 {
+			return existing.GetAnnotations()["dashboard.homelab.io/config-hash"], &writeDeferredError{target: client.ObjectKeyFromObject(existing).String(), wait: wait}
+		}
+
+		if err := r.adoptOutputFields(ctx, existing); err != nil {
+			return "", err
+		}
+	}
+
+	// The output is server-side applied: the operator owns its documents,
+	// labels and annotations, keys written by someone else are left alone,
+	// and keys we applied before but no longer produce are removed
+	merged := maps.Clone(existingData)
+	if merged == nil {
+		merged = make(map[string]string, len(data))
+	}
+	for key := range previous {
+		delete(merged, key)
+	}
+	maps.Copy(merged, data)
+	if err := checkOutputSize(merged, result.IconBytes); err != nil {
+		return "", err
+	}
+	r.warnOutputSize(ctx, key, kind, outputSize(merged), result)
+
+	objLabels := map[string]string{
+		"app.kubernetes.io/managed-by": "duro-operator",
+		instanceLabel:                  r.Config.Identity(),
+	}
+	maps.Copy(objLabels, r.Config.OutputLabels)
+	// Versions of the hashed output never change once written
+	immutable := owner == nil && r.Config.OutputNameHash
+	if immutable {
+		objLabels[outputNameLabel] = r.Config.DuroConfigMapName
+	}
+	annotations := map[string]string{
+		"dashboard.homelab.io/config-hash": configHash,
+		documentHashesAnnotation:           hashing.EncodeSums(docHashes),
+		traceIDAnnotation:                  traceID,
+		lastWriteAnnotation:                time.Now().UTC().Format(time.RFC3339),
+	}
+	if r.Version != "" {
+		annotations[operatorVersionAnnotation] = r.Version
+	}
+	maps.Copy(annotations, r.Config.OutputAnnotations)
+	if stamped, ok := existing.GetAnnotations()[stampedMetadataAnnotation]; ok {
+		annotations[stampedMetadataAnnotation] = stamped
+	}
+	r.stampEntryOrder(annotations, result)
+
+	var ownerRef *metav1ac.OwnerReferenceApplyConfiguration
+	if owner != nil {
+		gvk, err := apiutil.GVKForObject(owner, r.Scheme)
+		if err != nil {
+			return "", err
+		}
+		ownerRef = metav1ac.OwnerReference().
+			WithAPIVersion(gvk.GroupVersion().String()).
+			WithKind(gvk.Kind).
+			WithName(owner.GetName()).
+			WithUID(owner.GetUID()).
+			WithController(true).
+			WithBlockOwnerDeletion(true)
+	}
+
+	if found {
+		log.Info("Updating duro apps output", "name", key.Name, "namespace", key.Namespace, "hash", configHash,
+			"changedDocuments", stale, "metadataChanged", metadataChanged)
+	} else {
+		log.Info("Creating duro apps output", "name", key.Name, "namespace", key.Namespace)
+	}
+	if err := r.Apply(ctx, outputApplyConfiguration(kind, key, objLabels, annotations, data, immutable, ownerRef),
+		client.FieldOwner(r.Config.Identity()), client.ForceOwnership); err != nil {
+		return configHash, err
+	}
+	metrics.ConfigMapUpdates.Inc()
+	return configHash, nil
+}
+
+// recordAppsTotal publishes the number of entries of each category.
+func recordAppsTotal(entries []assembler.AppEntry) {
+	metrics.AppsTotal.Reset()
+	for _, e := range entries {
+		metrics.AppsTotal.WithLabelValues(e.Category).Inc()
+	}
+}
+
+// outputData builds the output documents: apps.json, categories.json,
+// groups.json, tags.json, one filtered apps key per configured output group, when
+// sharding by category one apps key per category, when enabled,
+// checksums.json and icons.json, and the documents of the extra formats.
+// duro's documents are written in each of the format versions
+// (FormatVersionLegacy if none).
+// Each document is hashed and written independently, so new documents only
+// need to be added here.
+func outputData(result *assembler.AssemblyResult, formats []string, versions []int) (map[string]string, error) {
+	docs := map[string]string{
+		"apps.json":       result.AppsJSON,
+		"categories.json": result.CategoriesJSON,
+		"groups.json":     result.GroupCatalogJSON,
+		"tags.json":       result.TagsJSON,
+	}
+	if result.ChecksumsJSON != "" {
+		docs["checksums.json"] = result.ChecksumsJSON
+	}
+	if result.BookmarksJSON != "" {
+		docs["bookmarks.json"] = result.BookmarksJSON
+	}
+	for group, groupJSON := range result.GroupsJSON {
+		docs[groupOutputKey(group)] = groupJSON
+	}
+	if result.IconsJSON != "" {
+		docs["icons.json"] = result.IconsJSON
+	}
+	for category, shardJSON := range result.CategoryShards {
+		docs[categoryOutputKey(category)] = shardJSON
+	}
+	if len(versions) == 0 {
+		versions = []int{assembler.FormatVersionLegacy}
+	}
+	data, err := assembler.Versioned(docs, versions)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := assembler.Render(result, formats)
+	if err != nil {
+		return nil, err
+	}
+	for key, doc := range rendered {
+		if _, ok := data[key]; ok {
+			return nil, fmt.Errorf("output format document %s clashes with a duro document", key)
+		}
+		data[key] = doc
+	}
+	return data, nil
+}
+
+// driftedDocuments lists the documents of the last write, by their hashes
+// in written, that are missing from existing or whose content no longer
+// matches, in key order. Documents the operator is about to change are not
+// drift.
+func driftedDocuments(existing, written map[string]string) []string {
+	var drifted []string
+	for _, key := range slices.Sorted(maps.Keys(written)) {
+		alg, _ := hashing.Parse(written[key])
+		if content, ok := existing[key]; !ok || !hashing.Equal(written[key], hashing.Sum(alg, map[string]string{key: content})) {
+			drifted = append(drifted, key)
+		}
+	}
+	return drifted
+}
+
+// staleDocuments lists, in key order, the documents whose content in
+// existing doesn't match their hash in docHashes, missing ones included, and
+// the documents of the last write, by their hashes in written, that are no
+// longer produced but still there.
+func staleDocuments(existing, docHashes, written map[string]string) []string {
+	var stale []string
+	for _, key := range slices.Sorted(maps.Keys(docHashes)) {
+		alg, _ := hashing.Parse(docHashes[key])
+		if content, ok := existing[key]; !ok || !hashing.Equal(docHashes[key], hashing.Sum(alg, map[string]string{key: content})) {
+			stale = append(stale, key)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(written)) {
+		if _, produced := docHashes[key]; !produced {
+			if _, ok := existing[key]; ok {
+				stale = append(stale, key)
+			}
+		}
+	}
+	return stale
+}
+
+// validateOutput checks every output document before any is written: keys
+// must be valid ConfigMap keys and documents valid JSON, or valid YAML for
+// .yml and .yaml keys.
+func validateOutput(data map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(data)) {
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return operrors.NewPermanentError(fmt.Sprintf("invalid output key %q: %s", key, strings.Join(errs, "; ")), nil)
+		}
+		if strings.HasSuffix(key, ".yml") || strings.HasSuffix(key, ".yaml") {
+			if _, err := yaml.YAMLToJSON([]byte(data[key])); err != nil {
+				return operrors.NewPermanentError(fmt.Sprintf("output document %s is not valid YAML: %v", key, err), nil)
+			}
+			continue
+		}
+		if !json.Valid([]byte(data[key])) {
+			return operrors.NewPermanentError(fmt.Sprintf("output document %s is not valid JSON", key), nil)
+		}
 	}
-	if existing.Labels == nil {
-		existing.Labels = make(map[string]string)
+	return nil
+}
+
+// checkOutputSize fails when the ConfigMap data, including keys written by
+// others, would exceed what the API server accepts, naming the largest of
+// the icons sized by iconBytes.
+func checkOutputSize(data map[string]string, iconBytes map[string]int) error {
+	if size := outputSize(data); size > maxConfigMapBytes {
+		msg := fmt.Sprintf("output is %d bytes, over the %d bytes a ConfigMap can hold", size, maxConfigMapBytes)
+		if icons := largestIcons(iconBytes, largestIconsListed); icons != "" {
+			msg += "; largest icons: " + icons
+		}
+		return operrors.NewPermanentError(msg, nil)
 	}
-	existing.Labels["app.kubernetes.io/managed-by"] = "duro-operator"
-	if existing.Annotations == nil {
-		existing.Annotations = make(map[string]string)
+	return nil
+}
+
+// outputSize is the number of bytes data takes in a ConfigMap.
+func outputSize(data map[string]string) int {
+	size := 0
+	for key, value := range data {
+		size += len(key) + len(value)
 	}
-	existing.Annotations["dashboard.homelab.io/config-hash"] = configHash
+	return size
+}
 
-	log.Info("Updating duro apps ConfigMap", "name", r.Config.DuroConfigMapName, "hash", configHash)
-	return r.Update(ctx, existing)
+// invalidKeyChars matches characters not allowed in ConfigMap keys
+var invalidKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// categoryOutputKey maps a category ID onto a valid ConfigMap key, e.g.
+// "media" becomes "category-media.json".
+func categoryOutputKey(category string) string {
+	return "category-" + invalidKeyChars.ReplaceAllString(category, "_") + ".json"
 }
 
-func computeHash(s string) string {
-	h := sha256.Sum256([]byte(s))
-	return hex.EncodeToString(h[:])
+// groupOutputKey maps a group name onto a valid ConfigMap key like
+// categoryOutputKey; hierarchy separators are not allowed in keys either so
+// "media/kids" becomes "apps-media_kids.json".
+func groupOutputKey(group string) string {
+	return "apps-" + invalidKeyChars.ReplaceAllString(group, "_") + ".json"
 }
 
+// outputHash computes the change-detection hash of the output according to
+// the configured algorithm and scope.
+func (r *DashboardAppReconciler) outputHash(result *assembler.AssemblyResult, data map[string]string) (string, error) {
+	if r.Config.HashScope == hashing.ScopeEntries {
+		entries, err := json.Marshal(result.Entries)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode entries for hashing: %w", err)
+		}
+		data = map[string]string{"entries": string(entries)}
+	}
+	return hashing.Sum(r.Config.HashAlgorithm, data), nil
+}
+
+// generateTraceID returns a random 128-bit hex ID in the W3C trace-id format
+// so it can be correlated with tracing backends as well as logs.
 func generateTraceID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
 }
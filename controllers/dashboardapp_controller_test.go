@@ -1,14 +1,20 @@
 package controllers
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/hashing"
 )
 
 var _ = Describe("DashboardApp controller", func() {
@@ -34,6 +40,22 @@ var _ = Describe("DashboardApp controller", func() {
 		}
 	}
 
+	Context("status.conditions", func() {
+		It("reports listed, valid apps as ready", func() {
+			app := newApp("conditions-app")
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var got dashboardv1alpha1.DashboardApp
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: app.Namespace}, &got)).To(Succeed())
+				g.Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ConditionReady)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionFalse(got.Status.Conditions, ConditionValidationFailed)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionFalse(got.Status.Conditions, ConditionIconResolveFailed)).To(BeTrue())
+				g.Expect(meta.FindStatusCondition(got.Status.Conditions, ConditionReady).ObservedGeneration).To(Equal(got.Generation))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
 	Context("status.observedGeneration", func() {
 		It("is set to metadata.generation after the first reconcile", func() {
 			app := newApp("obs-gen-initial")
@@ -47,6 +69,7 @@ var _ = Describe("DashboardApp controller", func() {
 				g.Expect(got.Status.Ready).To(BeTrue())
 				g.Expect(got.Status.ObservedGeneration).To(Equal(got.Generation))
 				g.Expect(got.Status.LastSyncedAt).NotTo(BeNil())
+				g.Expect(got.Status.LastSyncTraceID).To(HaveLen(32))
 			}, timeout, interval).Should(Succeed())
 		})
 
@@ -111,4 +134,285 @@ var _ = Describe("DashboardApp controller", func() {
 				got.Status.LastSyncedAt, first)
 		})
 	})
+
+	Context("spec.ttl", func() {
+		It("deletes the app once the TTL has elapsed", func() {
+			app := newApp("ttl-expiry")
+			app.Spec.TTL = &metav1.Duration{Duration: 2 * time.Second}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			key := types.NamespacedName{Name: app.Name, Namespace: app.Namespace}
+
+			Eventually(func() bool {
+				var got dashboardv1alpha1.DashboardApp
+				return errors.IsNotFound(k8sClient.Get(ctx, key, &got))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("OperatorOverview", func() {
+		It("records the last reconcile on the singleton overview", func() {
+			app := newApp("overview")
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			key := types.NamespacedName{Name: dashboardv1alpha1.OperatorOverviewName}
+
+			Eventually(func(g Gomega) {
+				var got dashboardv1alpha1.OperatorOverview
+				g.Expect(k8sClient.Get(ctx, key, &got)).To(Succeed())
+				g.Expect(got.Status.LastResult).To(Equal(dashboardv1alpha1.ReconcileSucceeded))
+				g.Expect(got.Status.ReconcileCount).To(BeNumerically(">", 0))
+				g.Expect(got.Status.Apps).To(BeNumerically(">", 0))
+				g.Expect(got.Status.ConfigHash).NotTo(BeEmpty())
+				g.Expect(got.Status.LastTraceID).To(HaveLen(32))
+				g.Expect(got.Status.Targets).To(ContainElement(SatisfyAll(
+					HaveField("Kind", "ConfigMap"),
+					HaveField("Name", "duro/duro-apps"),
+					HaveField("Key", "apps.json"),
+					HaveField("Hash", Not(BeEmpty())),
+					HaveField("LastWriteTime", Not(BeNil())),
+				)))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("output documents", func() {
+		It("records a hash per document on the ConfigMap", func() {
+			app := newApp("document-hashes")
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			key := types.NamespacedName{Name: "duro-apps", Namespace: "duro"}
+
+			Eventually(func(g Gomega) {
+				var cm corev1.ConfigMap
+				g.Expect(k8sClient.Get(ctx, key, &cm)).To(Succeed())
+				g.Expect(cm.Data).To(HaveKey("apps.json"))
+				g.Expect(cm.Data["apps.json"]).To(ContainSubstring("document-hashes"))
+
+				var sums map[string]string
+				g.Expect(json.Unmarshal([]byte(cm.Annotations[documentHashesAnnotation]), &sums)).To(Succeed())
+				g.Expect(sums).To(HaveKey("apps.json"))
+				g.Expect(sums).To(HaveKey("categories.json"))
+				g.Expect(sums).To(HaveKey("groups.json"))
+				g.Expect(sums).To(HaveKey("tags.json"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("repairs documents edited behind its back", func() {
+			app := newApp("drift-repair")
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			key := types.NamespacedName{Name: "duro-apps", Namespace: "duro"}
+			var cm corev1.ConfigMap
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, key, &cm)).To(Succeed())
+				g.Expect(cm.Data["apps.json"]).To(ContainSubstring("drift-repair"))
+			}, timeout, interval).Should(Succeed())
+
+			cm.Data["apps.json"] = "[]"
+			Expect(k8sClient.Update(ctx, &cm)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, key, &cm)).To(Succeed())
+				g.Expect(cm.Data["apps.json"]).To(ContainSubstring("drift-repair"))
+			}, timeout, interval).Should(Succeed())
+			written := hashing.SumEach(hashing.SHA256, map[string]string{"apps.json": "[]", "groups.json": "[]", "tags.json": "[]"})
+			Expect(driftedDocuments(map[string]string{"apps.json": "[]", "tags.json": `["media"]`}, written)).
+				To(Equal([]string{"groups.json", "tags.json"}))
+		})
+
+		It("rejects the whole output when one document is invalid", func() {
+			Expect(validateOutput(map[string]string{"apps.json": "[]", "categories.json": "[]"})).To(Succeed())
+			Expect(validateOutput(map[string]string{"apps.json": "[]", "categories.json": "[{"})).
+				To(MatchError(ContainSubstring("categories.json")))
+			Expect(validateOutput(map[string]string{"apps.json": "[]", "config.yml": "services: []"})).To(Succeed())
+			Expect(validateOutput(map[string]string{"apps.json": "[]", "config.yml": "services: [\n"})).
+				To(MatchError(ContainSubstring("not valid YAML")))
+			Expect(validateOutput(map[string]string{"apps/media.json": "[]"})).
+				To(MatchError(ContainSubstring("invalid output key")))
+			Expect(checkOutputSize(map[string]string{"apps.json": strings.Repeat("x", maxConfigMapBytes)}, nil)).
+				To(MatchError(ContainSubstring("over the")))
+			Expect(checkOutputSize(map[string]string{"apps.json": strings.Repeat("x", maxConfigMapBytes)},
+				map[string]int{"media/plex": 900_000, "media/sonarr": 1_000, "media/radarr": 50_000, "tools/grafana": 2_000})).
+				To(MatchError(ContainSubstring("largest icons: media/plex (900000 bytes), media/radarr (50000 bytes), tools/grafana (2000 bytes)")))
+		})
+	})
+
+	Context("deletion", func() {
+		It("removes the entry from the output before the app is gone", func() {
+			app := newApp("deleted-app")
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			key := types.NamespacedName{Name: app.Name, Namespace: app.Namespace}
+
+			Eventually(func(g Gomega) {
+				var got dashboardv1alpha1.DashboardApp
+				g.Expect(k8sClient.Get(ctx, key, &got)).To(Succeed())
+				g.Expect(got.Finalizers).To(ContainElement(dashboardv1alpha1.RemovalFinalizer))
+
+				var cm corev1.ConfigMap
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "duro-apps", Namespace: "duro"}, &cm)).To(Succeed())
+				g.Expect(cm.Data["apps.json"]).To(ContainSubstring("deleted-app"))
+			}, timeout, interval).Should(Succeed())
+
+			Expect(k8sClient.Delete(ctx, app)).To(Succeed())
+			Eventually(func() bool {
+				var got dashboardv1alpha1.DashboardApp
+				return errors.IsNotFound(k8sClient.Get(ctx, key, &got))
+			}, timeout, interval).Should(BeTrue())
+
+			var cm corev1.ConfigMap
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "duro-apps", Namespace: "duro"}, &cm)).To(Succeed())
+			Expect(cm.Data["apps.json"]).NotTo(ContainSubstring("deleted-app"))
+		})
+	})
+
+	Context("spec.enabled", func() {
+		It("hides disabled apps and reports them as not ready", func() {
+			app := newApp("disabled-app")
+			disabled := false
+			app.Spec.Enabled = &disabled
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				var got dashboardv1alpha1.DashboardApp
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: app.Namespace}, &got)).To(Succeed())
+				g.Expect(got.Status.ObservedGeneration).To(Equal(got.Generation))
+				g.Expect(got.Status.Ready).To(BeFalse())
+				g.Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ConditionDisabled)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionFalse(got.Status.Conditions, ConditionReady)).To(BeTrue())
+
+				var cm corev1.ConfigMap
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "duro-apps", Namespace: "duro"}, &cm)).To(Succeed())
+				g.Expect(cm.Data["apps.json"]).NotTo(ContainSubstring("disabled-app"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("spec.condition", func() {
+		It("only lists apps whose condition holds", func() {
+			present := newApp("condition-present")
+			present.Spec.Condition = `"duro" in namespaces`
+			Expect(k8sClient.Create(ctx, present)).To(Succeed())
+			absent := newApp("condition-absent")
+			absent.Spec.Condition = `"clusters.postgresql.cnpg.io" in crds`
+			Expect(k8sClient.Create(ctx, absent)).To(Succeed())
+
+			key := types.NamespacedName{Name: "duro-apps", Namespace: "duro"}
+
+			Eventually(func(g Gomega) {
+				var cm corev1.ConfigMap
+				g.Expect(k8sClient.Get(ctx, key, &cm)).To(Succeed())
+				g.Expect(cm.Data["apps.json"]).To(ContainSubstring("condition-present"))
+				g.Expect(cm.Data["apps.json"]).NotTo(ContainSubstring("condition-absent"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("failed reconciles", func() {
+		It("reports the trace ID in the Synced condition of the failing app", func() {
+			app := newApp("broken-template")
+			app.Spec.URL = "https://{{ .missing"
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+			DeferCleanup(func() { Expect(k8sClient.Delete(ctx, app)).To(Succeed()) })
+
+			key := types.NamespacedName{Name: app.Name, Namespace: app.Namespace}
+
+			Eventually(func(g Gomega) {
+				var got dashboardv1alpha1.DashboardApp
+				g.Expect(k8sClient.Get(ctx, key, &got)).To(Succeed())
+				cond := meta.FindStatusCondition(got.Status.Conditions, ConditionSynced)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(cond.Reason).To(Equal("RenderFailed"))
+				g.Expect(cond.Message).To(MatchRegexp(`trace_id=[0-9a-f]{32}\)$`))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("validation sweep", func() {
+		It("flags apps breaking the current rules when requested", func() {
+			app := newApp("sweep-bad-group")
+			app.Spec.Groups = []string{"family//kids"}
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			overviewKey := types.NamespacedName{Name: dashboardv1alpha1.OperatorOverviewName}
+			Eventually(func() error {
+				var overview dashboardv1alpha1.OperatorOverview
+				if err := k8sClient.Get(ctx, overviewKey, &overview); err != nil {
+					return err
+				}
+				if overview.Annotations == nil {
+					overview.Annotations = map[string]string{}
+				}
+				overview.Annotations[dashboardv1alpha1.ValidateAnnotation] = "sweep-1"
+				return k8sClient.Update(ctx, &overview)
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var overview dashboardv1alpha1.OperatorOverview
+				g.Expect(k8sClient.Get(ctx, overviewKey, &overview)).To(Succeed())
+				g.Expect(overview.Status.Validation).NotTo(BeNil())
+				g.Expect(overview.Status.Validation.Trigger).To(Equal("sweep-1"))
+				g.Expect(overview.Status.Validation.NonConforming).To(ContainElement("default/sweep-bad-group"))
+
+				var got dashboardv1alpha1.DashboardApp
+				g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: app.Namespace}, &got)).To(Succeed())
+				cond := meta.FindStatusCondition(got.Status.Conditions, ConditionConforming)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(cond.Message).To(ContainSubstring("empty hierarchy level"))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("resync annotation", func() {
+		It("re-validates an app when its resync annotation changes", func() {
+			app := newApp("resync-app")
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			key := types.NamespacedName{Name: app.Name, Namespace: app.Namespace}
+			Eventually(func() error {
+				var got dashboardv1alpha1.DashboardApp
+				if err := k8sClient.Get(ctx, key, &got); err != nil {
+					return err
+				}
+				got.Annotations = map[string]string{dashboardv1alpha1.ResyncAnnotation: "2026-01-01T00:00:00Z"}
+				return k8sClient.Update(ctx, &got)
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var got dashboardv1alpha1.DashboardApp
+				g.Expect(k8sClient.Get(ctx, key, &got)).To(Succeed())
+				g.Expect(got.Status.ObservedResync).To(Equal("2026-01-01T00:00:00Z"))
+				cond := meta.FindStatusCondition(got.Status.Conditions, ConditionConforming)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("rebuilds the output when the overview's resync annotation changes", func() {
+			app := newApp("resync-rebuild")
+			Expect(k8sClient.Create(ctx, app)).To(Succeed())
+
+			overviewKey := types.NamespacedName{Name: dashboardv1alpha1.OperatorOverviewName}
+			Eventually(func() error {
+				var overview dashboardv1alpha1.OperatorOverview
+				if err := k8sClient.Get(ctx, overviewKey, &overview); err != nil {
+					return err
+				}
+				if overview.Annotations == nil {
+					overview.Annotations = map[string]string{}
+				}
+				overview.Annotations[dashboardv1alpha1.ResyncAnnotation] = "rebuild-1"
+				return k8sClient.Update(ctx, &overview)
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				var overview dashboardv1alpha1.OperatorOverview
+				g.Expect(k8sClient.Get(ctx, overviewKey, &overview)).To(Succeed())
+				g.Expect(overview.Status.ObservedResync).To(Equal("rebuild-1"))
+				g.Expect(overview.Status.LastResult).To(Equal(dashboardv1alpha1.ReconcileSucceeded))
+			}, timeout, interval).Should(Succeed())
+		})
+	})
 })
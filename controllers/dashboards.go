@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	goerrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/redact"
+)
+
+// syncDashboards writes the apps selected by each Dashboard of the instance
+// to the Dashboard's target ConfigMap or Secret (see Config.Dashboards), assembled by
+// asm like the main output. apps are those of the main output, expired and
+// removed apps already dropped. A Dashboard that cannot be written is
+// reported on its status without holding back the others; the returned
+// delay retries it, zero when nothing needs retrying.
+func (r *DashboardAppReconciler) syncDashboards(ctx context.Context, asm *assembler.Assembler, apps []dashboardv1alpha1.DashboardApp, traceID string, force bool) time.Duration {
+	if !r.Config.Dashboards {
+		return 0
+	}
+	log := logr.FromContextOrDiscard(ctx)
+
+	dashboards := &dashboardv1alpha1.DashboardList{}
+	if err := r.List(ctx, dashboards); err != nil {
+		log.Error(err, "Failed to list Dashboards")
+		return defaultRetryDelay
+	}
+
+	// Targets already written, the main output first, so two writers never
+	// take turns on a target; the oldest Dashboard keeps a disputed target
+	slices.SortFunc(dashboards.Items, func(a, b dashboardv1alpha1.Dashboard) int {
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	written := map[types.NamespacedName]string{
+		{Name: r.Config.DuroConfigMapName, Namespace: r.Config.DuroNamespace}: "the main output",
+	}
+	namespaceLabels := make(map[string]labels.Set)
+	var retry time.Duration
+	for i := range dashboards.Items {
+		dash := &dashboards.Items[i]
+		if dash.Spec.Instance != r.Config.InstanceName {
+			continue
+		}
+		before := dash.Status.DeepCopy()
+		var wait time.Duration
+		var err error
+		key := types.NamespacedName{Name: dash.Spec.Target.ConfigMap, Namespace: dash.Spec.Target.Namespace}
+		writer, taken := written[key]
+		switch {
+		case !dash.DeletionTimestamp.IsZero():
+			// A deleted Dashboard is gone once its target is cleaned up;
+			// failures are reported on its status until then
+			if err = r.finalizeDashboard(ctx, dash, key); err == nil {
+				continue
+			}
+			wait = retryDelay(err)
+		case taken:
+			err = operrors.NewPermanentError(fmt.Sprintf("target %s is already written by %s", key, writer), nil)
+		default:
+			written[key] = "Dashboard " + dash.Name
+			if !controllerutil.ContainsFinalizer(dash, dashboardv1alpha1.DashboardFinalizer) {
+				orig := dash.DeepCopy()
+				controllerutil.AddFinalizer(dash, dashboardv1alpha1.DashboardFinalizer)
+				if err := r.Patch(ctx, dash, client.MergeFrom(orig)); err != nil {
+					log.Error(err, "Failed to add target cleanup finalizer", "dashboard", dash.Name)
+				}
+			}
+			wait, err = r.syncDashboard(ctx, asm, dash, key, apps, namespaceLabels, traceID, force)
+		}
+		if wait > 0 && (retry == 0 || wait < retry) {
+			retry = wait
+		}
+		setDashboardReadyCondition(dash, err, traceID)
+		if err != nil {
+			log.Error(err, "Failed to write Dashboard", "dashboard", dash.Name)
+			r.Recorder.Event(dash, corev1.EventTypeWarning, "WriteFailed", redact.String(err.Error()))
+		}
+		if equality.Semantic.DeepEqual(before, &dash.Status) {
+			continue
+		}
+		if err := r.Status().Update(ctx, dash); err != nil {
+			log.Error(err, "Failed to update Dashboard status", "dashboard", dash.Name)
+			retry = defaultRetryDelay
+		}
+	}
+	return retry
+}
+
+// syncDashboard assembles and writes the apps selected by dash to key,
+// recording the outcome on its in-memory status. It returns how long to wait
+// before retrying, if the write failed or was deferred.
+func (r *DashboardAppReconciler) syncDashboard(ctx context.Context, asm *assembler.Assembler, dash *dashboardv1alpha1.Dashboard, key types.NamespacedName,
+	apps []dashboardv1alpha1.DashboardApp, namespaceLabels map[string]labels.Set, traceID string, force bool) (time.Duration, error) {
+	selected, err := r.dashboardApps(ctx, dash, apps, namespaceLabels)
+	if err != nil {
+		return retryAfter(err), err
+	}
+	result, err := asm.Assemble(ctx, selected)
+	if err != nil {
+		return retryAfter(err), err
+	}
+
+	formats := r.Config.OutputFormats
+	if dash.Spec.Formats != nil {
+		formats = dash.Spec.Formats
+	}
+	kind := r.Config.OutputKind
+	if dash.Spec.Target.Kind != "" {
+		kind = dash.Spec.Target.Kind
+	}
+	configHash, err := r.writeOutput(ctx, key, kind, dash, result, formats, traceID, force)
+	var deferred *writeDeferredError
+	if goerrors.As(err, &deferred) {
+		return deferred.wait, nil
+	}
+	if err != nil {
+		return retryDelay(err), err
+	}
+
+	if dash.Status.ConfigHash != configHash || dash.Status.ObservedGeneration != dash.Generation {
+		now := metav1.Now()
+		dash.Status.LastWriteTime = &now
+	}
+	dash.Status.ObservedGeneration = dash.Generation
+	dash.Status.Apps = len(selected)
+	dash.Status.Entries = len(result.Entries)
+	dash.Status.ConfigHash = configHash
+	return 0, nil
+}
+
+// finalizeDashboard cleans up the target of a deleted Dashboard as set by
+// its deletion policy, then releases its finalizer. A target the Dashboard
+// doesn't control, e.g. one written by an older Dashboard, is left alone.
+func (r *DashboardAppReconciler) finalizeDashboard(ctx context.Context, dash *dashboardv1alpha1.Dashboard, key types.NamespacedName) error {
+	if !controllerutil.ContainsFinalizer(dash, dashboardv1alpha1.DashboardFinalizer) {
+		return nil
+	}
+	log := logr.FromContextOrDiscard(ctx).WithValues("dashboard", dash.Name, "target", key)
+
+	target := newOutputObject(cmp.Or(dash.Spec.Target.Kind, r.Config.OutputKind))
+	err := r.Get(ctx, key, target)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return operrors.NewTransientError("failed to get target", err)
+	case !metav1.IsControlledBy(target, dash):
+		log.Info("Leaving target of deleted Dashboard alone, it is not controlled by the Dashboard")
+	case dash.Spec.DeletionPolicy == dashboardv1alpha1.DeletionPolicyOrphan:
+		orig := target.DeepCopyObject().(client.Object)
+		target.SetOwnerReferences(slices.DeleteFunc(target.GetOwnerReferences(), func(ref metav1.OwnerReference) bool {
+			return ref.UID == dash.UID
+		}))
+		if err := r.Patch(ctx, target, client.MergeFrom(orig)); client.IgnoreNotFound(err) != nil {
+			return operrors.NewTransientError("failed to orphan target", err)
+		}
+		log.Info("Orphaned target of deleted Dashboard")
+	default:
+		if err := r.Delete(ctx, target); client.IgnoreNotFound(err) != nil {
+			return operrors.NewTransientError("failed to delete target", err)
+		}
+		log.Info("Deleted target of deleted Dashboard")
+	}
+
+	orig := dash.DeepCopy()
+	controllerutil.RemoveFinalizer(dash, dashboardv1alpha1.DashboardFinalizer)
+	if err := r.Patch(ctx, dash, client.MergeFrom(orig)); client.IgnoreNotFound(err) != nil {
+		return operrors.NewTransientError("failed to release target cleanup finalizer", err)
+	}
+	return nil
+}
+
+// dashboardApps returns the apps matching the selectors of dash. Namespace
+// labels are looked up once per reconcile, through namespaceLabels.
+func (r *DashboardAppReconciler) dashboardApps(ctx context.Context, dash *dashboardv1alpha1.Dashboard,
+	apps []dashboardv1alpha1.DashboardApp, namespaceLabels map[string]labels.Set) ([]dashboardv1alpha1.DashboardApp, error) {
+	appSel, err := dashboardSelector(dash.Spec.Selector)
+	if err != nil {
+		return nil, operrors.NewPermanentError(fmt.Sprintf("invalid spec.selector: %v", err), nil)
+	}
+	nsSel, err := dashboardSelector(dash.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, operrors.NewPermanentError(fmt.Sprintf("invalid spec.namespaceSelector: %v", err), nil)
+	}
+
+	var selected []dashboardv1alpha1.DashboardApp
+	for _, app := range apps {
+		if !appSel.Matches(labels.Set(app.Labels)) {
+			continue
+		}
+		if !nsSel.Empty() {
+			set, ok := namespaceLabels[app.Namespace]
+			if !ok {
+				ns := &corev1.Namespace{}
+				if err := r.Get(ctx, client.ObjectKey{Name: app.Namespace}, ns); client.IgnoreNotFound(err) != nil {
+					return nil, operrors.NewTransientError("failed to get namespace", err)
+				}
+				set = labels.Set(ns.Labels)
+				namespaceLabels[app.Namespace] = set
+			}
+			if !nsSel.Matches(set) {
+				continue
+			}
+		}
+		selected = append(selected, app)
+	}
+	return selected, nil
+}
+
+// dashboardSelector converts a Dashboard selector; nil selects everything.
+func dashboardSelector(sel *metav1.LabelSelector) (labels.Selector, error) {
+	if sel == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(sel)
+}
+
+// retryAfter returns the retry delay of a failed assembly: none for
+// permanent errors, which only a spec change fixes.
+func retryAfter(err error) time.Duration {
+	if !operrors.ShouldRetry(err) {
+		return 0
+	}
+	return defaultRetryDelay
+}
+
+// setDashboardReadyCondition records whether the last write of dash
+// succeeded. The failure message carries the trace ID so it can be found
+// in the logs.
+func setDashboardReadyCondition(dash *dashboardv1alpha1.Dashboard, err error, traceID string) {
+	cond := metav1.Condition{
+		Type:               ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Written",
+		Message:            "Target is up to date",
+		ObservedGeneration: dash.Generation,
+	}
+	if err != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "WriteFailed"
+		cond.Message = fmt.Sprintf("%s (trace_id=%s)", redact.String(err.Error()), traceID)
+	}
+	meta.SetStatusCondition(&dash.Status.Conditions, cond)
+}
+
+// isDashboardOutput reports whether obj is the target ConfigMap or Secret of
+// a Dashboard.
+func isDashboardOutput(obj client.Object) bool {
+	owner := metav1.GetControllerOf(obj)
+	return owner != nil && owner.Kind == "Dashboard" && owner.APIVersion == dashboardv1alpha1.GroupVersion.String()
+}
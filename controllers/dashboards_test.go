@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func TestDashboardApps(t *testing.T) {
+	family := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "media", Labels: map[string]string{"homelab.io/audience": "family"}}}
+	monitoring := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}}
+	r := newFakeReconciler(t, nil, family, monitoring)
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "sonarr", Namespace: "media", Labels: map[string]string{"tier": "admin"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}},
+	}
+	tests := []struct {
+		name    string
+		spec    dashboardv1alpha1.DashboardSpec
+		want    []string
+		wantErr string
+	}{
+		{name: "every app", want: []string{"plex", "sonarr", "grafana"}},
+		{
+			name: "by app and namespace labels",
+			spec: dashboardv1alpha1.DashboardSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"homelab.io/audience": "family"}},
+				Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"admin"}},
+				}},
+			},
+			want: []string{"plex"},
+		},
+		{
+			name: "invalid selector",
+			spec: dashboardv1alpha1.DashboardSpec{
+				Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Near"}}},
+			},
+			wantErr: "invalid spec.selector",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := r.dashboardApps(context.Background(), &dashboardv1alpha1.Dashboard{Spec: tt.spec}, apps, map[string]labels.Set{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("dashboardApps() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dashboardApps() error = %v", err)
+			}
+			var names []string
+			for _, app := range selected {
+				names = append(names, app.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("selected %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinalizeDashboard(t *testing.T) {
+	key := types.NamespacedName{Name: "family-apps", Namespace: "family"}
+	tests := []struct {
+		name        string
+		policy      string
+		controlled  bool
+		wantDeleted bool
+		wantOwned   bool
+	}{
+		{name: "deleted with the Dashboard", policy: dashboardv1alpha1.DeletionPolicyDelete, controlled: true, wantDeleted: true},
+		{name: "orphaned", policy: dashboardv1alpha1.DeletionPolicyOrphan, controlled: true},
+		{name: "controlled by another Dashboard", policy: dashboardv1alpha1.DeletionPolicyDelete, wantOwned: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := metav1.Now()
+			dash := &dashboardv1alpha1.Dashboard{
+				ObjectMeta: metav1.ObjectMeta{Name: "family", UID: "family-uid", DeletionTimestamp: &now, Finalizers: []string{dashboardv1alpha1.DashboardFinalizer}},
+				Spec:       dashboardv1alpha1.DashboardSpec{Target: dashboardv1alpha1.DashboardTarget{Namespace: key.Namespace, ConfigMap: key.Name}, DeletionPolicy: tt.policy},
+			}
+			owner := metav1.OwnerReference{APIVersion: dashboardv1alpha1.GroupVersion.String(), Kind: "Dashboard", Name: "older", UID: "older-uid", Controller: ptr.To(true)}
+			if tt.controlled {
+				owner.Name, owner.UID = dash.Name, dash.UID
+			}
+			target := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, OwnerReferences: []metav1.OwnerReference{owner}}}
+			r := newFakeReconciler(t, nil, dash, target)
+			ctx := context.Background()
+
+			if err := r.finalizeDashboard(ctx, dash, key); err != nil {
+				t.Fatalf("finalizeDashboard() error = %v", err)
+			}
+			if err := r.Get(ctx, client.ObjectKeyFromObject(dash), &dashboardv1alpha1.Dashboard{}); !apierrors.IsNotFound(err) {
+				t.Errorf("Dashboard still there (%v), want its finalizer released", err)
+			}
+			cm := &corev1.ConfigMap{}
+			err := r.Get(ctx, key, cm)
+			if tt.wantDeleted {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("target still there (%v), want it deleted", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("target deleted: %v", err)
+			}
+			if owned := len(cm.OwnerReferences) > 0; owned != tt.wantOwned {
+				t.Errorf("target owner references = %v, want owned %v", cm.OwnerReferences, tt.wantOwned)
+			}
+		})
+	}
+}
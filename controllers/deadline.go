@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/metrics"
+)
+
+// recordDeadline exports how much of the reconcile timeout a reconcile
+// used and, past the slow reconcile threshold, raises a warning event on
+// the instance's OperatorOverview.
+func (r *DashboardAppReconciler) recordDeadline(ctx context.Context, traceID string, duration time.Duration) {
+	metrics.ReconcileDeadlineRatio.Observe(duration.Seconds() / r.Config.ReconcileTimeout.Seconds())
+
+	threshold := r.Config.SlowReconcileThreshold
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+	metrics.SlowReconciles.Inc()
+	log := logr.FromContextOrDiscard(ctx)
+	log.Info("Slow reconcile", "duration", duration, "threshold", threshold, "timeout", r.Config.ReconcileTimeout)
+
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	if err := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview); err != nil {
+		return
+	}
+	r.Recorder.Eventf(overview, corev1.EventTypeWarning, "SlowReconcile",
+		"Reconcile %s took %s, over the %s threshold (timeout %s)",
+		traceID, duration.Round(time.Millisecond), threshold, r.Config.ReconcileTimeout)
+}
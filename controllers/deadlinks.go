@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+	"github.com/fredericrous/duro-operator/pkg/linkcheck"
+	"github.com/fredericrous/duro-operator/pkg/metrics"
+	"github.com/fredericrous/duro-operator/pkg/redact"
+)
+
+const (
+	// deadLinkStartDelay leaves the first reconcile time to fill the
+	// catalog before a sweep that is due at startup
+	deadLinkStartDelay = time.Minute
+
+	// maxDeadLinksListed bounds the dead links kept in the overview and
+	// named in the summary event
+	maxDeadLinksListed = 50
+	maxDeadLinksEvent  = 10
+)
+
+// DeadLinkSweeper periodically probes every link of the written catalog,
+// whatever health checks the apps configure, and reports the dead ones in
+// the OperatorOverview and the duro_operator_dead_links metric. Sweeps are
+// scheduled from the last one recorded in the overview, so restarts don't
+// reset a weekly schedule. It only runs on the leader.
+type DeadLinkSweeper struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+
+	// Catalog holds the last written assembly, whose links are probed
+	Catalog *catalog.Store
+
+	// OverviewName is the OperatorOverview of this instance
+	OverviewName string
+
+	// Interval is how often the sweep runs
+	Interval time.Duration
+
+	// Prober probes the links
+	Prober *linkcheck.Prober
+
+	// Events emits one Event on the overview summarizing each sweep that
+	// found dead links
+	Events bool
+}
+
+// Start runs sweeps until ctx is done.
+func (s *DeadLinkSweeper) Start(ctx context.Context) error {
+	timer := time.NewTimer(s.firstSweep(ctx, time.Now()))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+		if err := s.sweep(ctx, time.Now()); err != nil {
+			s.Log.V(1).Info("Dead-link sweep failed", "error", err.Error())
+		}
+		timer.Reset(s.Interval)
+	}
+}
+
+// firstSweep returns how long to wait for the first sweep: until a full
+// interval after the last recorded one, at least deadLinkStartDelay.
+func (s *DeadLinkSweeper) firstSweep(ctx context.Context, now time.Time) time.Duration {
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	if err := s.Get(ctx, client.ObjectKey{Name: s.OverviewName}, overview); err != nil {
+		return deadLinkStartDelay
+	}
+	report := overview.Status.DeadLinks
+	if report == nil || report.LastSweepTime == nil {
+		return deadLinkStartDelay
+	}
+	return max(report.LastSweepTime.Add(s.Interval).Sub(now), deadLinkStartDelay)
+}
+
+// sweep probes the links of the catalog and records the dead ones. It does
+// nothing until a catalog was written.
+func (s *DeadLinkSweeper) sweep(ctx context.Context, now time.Time) error {
+	result, _ := s.Catalog.Get()
+	if result == nil {
+		return nil
+	}
+	var links []linkcheck.Link
+	for _, e := range result.Entries {
+		// Apps kept listed after their deletion are expected to go away
+		if e.Removed || e.URL == "" {
+			continue
+		}
+		links = append(links, linkcheck.Link{App: e.Source, URL: e.URL})
+	}
+
+	dead := s.Prober.Probe(ctx, links)
+	if ctx.Err() != nil {
+		// Probes cut short by shutdown say nothing about the links
+		return nil
+	}
+	metrics.DeadLinks.Set(float64(len(dead)))
+	s.Log.Info("Dead-link sweep finished", "checked", len(links), "dead", len(dead))
+
+	report := &dashboardv1alpha1.DeadLinkSweepStatus{
+		LastSweepTime: &metav1.Time{Time: now},
+		Checked:       len(links),
+		DeadCount:     len(dead),
+	}
+	for _, d := range dead[:min(len(dead), maxDeadLinksListed)] {
+		report.Dead = append(report.Dead, dashboardv1alpha1.DeadLink{App: d.App, URL: redact.String(d.URL), Reason: redact.String(d.Reason)})
+	}
+
+	var overview *dashboardv1alpha1.OperatorOverview
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		overview = &dashboardv1alpha1.OperatorOverview{}
+		if err := s.Get(ctx, client.ObjectKey{Name: s.OverviewName}, overview); err != nil {
+			return err
+		}
+		overview.Status.DeadLinks = report
+		return s.Status().Update(ctx, overview)
+	})
+	if errors.IsNotFound(err) {
+		// Created by the first reconcile
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.Events && len(dead) > 0 {
+		s.Recorder.Event(overview, corev1.EventTypeWarning, "DeadLinks", deadLinkSummary(dead))
+	}
+	return nil
+}
+
+// deadLinkSummary describes dead links in one event message, e.g.
+// "2 dashboard links are broken: media/plex (https://plex.lan: HTTP 502),
+// ...".
+func deadLinkSummary(dead []linkcheck.Dead) string {
+	noun := "links are"
+	if len(dead) == 1 {
+		noun = "link is"
+	}
+	parts := make([]string, 0, maxDeadLinksEvent)
+	for _, d := range dead[:min(len(dead), maxDeadLinksEvent)] {
+		parts = append(parts, fmt.Sprintf("%s (%s: %s)", d.App, redact.String(d.URL), redact.String(d.Reason)))
+	}
+	msg := fmt.Sprintf("%d dashboard %s broken: %s", len(dead), noun, strings.Join(parts, ", "))
+	if len(dead) > maxDeadLinksEvent {
+		msg += fmt.Sprintf(" and %d more", len(dead)-maxDeadLinksEvent)
+	}
+	return msg
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+	"github.com/fredericrous/duro-operator/pkg/linkcheck"
+)
+
+func TestDeadLinkSweeper(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	overview := &dashboardv1alpha1.OperatorOverview{ObjectMeta: metav1.ObjectMeta{Name: "duro-operator"}}
+	c := newFakeClient(t, overview)
+	store := catalog.NewStore()
+	store.Set(&assembler.AssemblyResult{Entries: []assembler.AppEntry{
+		{ID: "plex", URL: srv.URL + "/", Source: "media/plex"},
+		{ID: "grafana", URL: srv.URL + "/down?token=s3cr3t", Source: "monitoring/grafana"},
+		{ID: "old", URL: srv.URL + "/down", Source: "media/old", Removed: true},
+	}})
+	recorder := record.NewFakeRecorder(5)
+	sweeper := &DeadLinkSweeper{
+		Client:       c,
+		Log:          logr.Discard(),
+		Recorder:     recorder,
+		Catalog:      store,
+		OverviewName: "duro-operator",
+		Interval:     168 * time.Hour,
+		Prober:       linkcheck.NewProber(5 * time.Second),
+		Events:       true,
+	}
+
+	now := time.Now()
+	if err := sweeper.sweep(context.Background(), now); err != nil {
+		t.Fatalf("sweep() error = %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(overview), overview); err != nil {
+		t.Fatal(err)
+	}
+	// Apps kept listed after their deletion are not checked, credentials
+	// of dead links are masked
+	report := overview.Status.DeadLinks
+	if report == nil {
+		t.Fatal("no dead links recorded in the overview")
+	}
+	want := []dashboardv1alpha1.DeadLink{{App: "monitoring/grafana", URL: srv.URL + "/down?token=REDACTED", Reason: "HTTP 502"}}
+	if report.Checked != 2 || report.DeadCount != 1 || !slices.Equal(report.Dead, want) {
+		t.Errorf("report = %+v, want 2 checked and %+v dead", report, want)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "1 dashboard link is broken: monitoring/grafana") || strings.Contains(event, "s3cr3t") {
+			t.Errorf("event = %q, want the broken link summarized without its token", event)
+		}
+	default:
+		t.Error("no event recorded")
+	}
+
+	// The next sweep follows the recorded one, even after a restart
+	next := sweeper.firstSweep(context.Background(), now.Add(time.Hour))
+	if diff := next - 167*time.Hour; diff < -time.Second || diff > time.Second {
+		t.Errorf("firstSweep() = %v, want 167h", next)
+	}
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// discoverer holds what the discovery controllers share to keep the
+// DashboardApp synthesized from a source object in line with it
+type discoverer struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// kind names the source objects in events, e.g. Workload
+	kind string
+
+	// source is the source label value of the synthesized DashboardApps
+	source string
+}
+
+// namespacePredicate passes the objects in the namespaces accept accepts,
+// or every object when accept is nil.
+func namespacePredicate(accept func(namespace string) bool) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return accept == nil || accept(obj.GetNamespace())
+	})
+}
+
+// syncApp creates, updates or deletes the DashboardApp named after owner
+// and controlled by it, so that it has spec, or is gone when spec is nil.
+// A DashboardApp of that name not controlled by owner is left alone.
+func (d *discoverer) syncApp(ctx context.Context, log logr.Logger, owner client.Object, spec *dashboardv1alpha1.DashboardAppSpec) error {
+	key := client.ObjectKeyFromObject(owner)
+	existing := &dashboardv1alpha1.DashboardApp{}
+	err := d.Get(ctx, key, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return operrors.NewTransientError("failed to get DashboardApp", err)
+	}
+	found := err == nil
+	managed := found && existing.Labels[dashboardv1alpha1.SourceLabel] == d.source &&
+		metav1.IsControlledBy(existing, owner)
+
+	switch {
+	case spec == nil:
+		if managed {
+			log.Info(d.kind + " no longer declares a dashboard entry, deleting DashboardApp")
+			if err := d.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+				return operrors.NewTransientError("failed to delete DashboardApp", err)
+			}
+		}
+	case !found:
+		app := &dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    map[string]string{dashboardv1alpha1.SourceLabel: d.source},
+			},
+			Spec: *spec,
+		}
+		if err := controllerutil.SetControllerReference(owner, app, d.Scheme); err != nil {
+			return operrors.NewPermanentError("failed to set owner reference", err)
+		}
+		log.Info("Creating DashboardApp for " + d.source)
+		if err := d.Create(ctx, app); err != nil {
+			return operrors.NewTransientError("failed to create DashboardApp", err)
+		}
+	case !managed:
+		log.Info("DashboardApp exists and is not managed by this " + d.source + ", leaving it alone")
+		d.Recorder.Event(existing, corev1.EventTypeWarning, d.kind+"DiscoveryConflict",
+			d.kind+" "+key.Name+" declares a dashboard entry but this DashboardApp is not managed by it")
+	case !equality.Semantic.DeepEqual(existing.Spec, *spec):
+		existing.Spec = *spec
+		log.Info("Updating DashboardApp for " + d.source)
+		if err := d.Update(ctx, existing); err != nil {
+			return operrors.NewTransientError("failed to update DashboardApp", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+// Tests of this file and its neighbours run against a fake client, without
+// the envtest API server the Ginkgo suite needs.
+
+// newFakeScheme returns a scheme knowing the built-in kinds and the
+// dashboard API.
+func newFakeScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := dashboardv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// newFakeClient returns a fake client holding objs, with the status
+// subresources of the dashboard API.
+func newFakeClient(t *testing.T, objs ...client.Object) client.WithWatch {
+	t.Helper()
+	return fakeclient.NewClientBuilder().WithScheme(newFakeScheme(t)).WithObjects(objs...).
+		WithStatusSubresource(&dashboardv1alpha1.DashboardApp{}, &dashboardv1alpha1.Dashboard{}, &dashboardv1alpha1.OperatorOverview{}).
+		Build()
+}
+
+// newFakeReconciler returns a reconciler of cfg, the default configuration
+// if nil, on a fake client holding objs. Its events are kept in a
+// record.FakeRecorder.
+func newFakeReconciler(t *testing.T, cfg *config.OperatorConfig, objs ...client.Object) *DashboardAppReconciler {
+	t.Helper()
+	if cfg == nil {
+		cfg = config.NewDefaultConfig()
+	}
+	return &DashboardAppReconciler{
+		Client:    newFakeClient(t, objs...),
+		Log:       logr.Discard(),
+		Recorder:  record.NewFakeRecorder(20),
+		Config:    cfg,
+		Assembler: assembler.NewAssembler(logr.Discard()),
+		selector:  cfg.Selector(),
+	}
+}
+
+// recordedEvents drains the events recorded by the reconciler.
+func recordedEvents(r *DashboardAppReconciler) []string {
+	var events []string
+	recorder := r.Recorder.(*record.FakeRecorder)
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	return events
+}
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+	"github.com/fredericrous/duro-operator/pkg/healthcheck"
+	"github.com/fredericrous/duro-operator/pkg/redact"
+)
+
+// healthProbeTick is how often the HealthProber looks for apps due a probe
+const healthProbeTick = 5 * time.Second
+
+// HealthProber probes the apps of the written catalog that set
+// spec.healthCheck, each at its own interval, and records the outcome in
+// their status.health, from which the output takes it. Apps whose health
+// comes from heartbeats are left alone. It only runs on the leader.
+type HealthProber struct {
+	client.Client
+	Log logr.Logger
+
+	// Catalog holds the last written assembly, whose rendered URLs are
+	// probed
+	Catalog *catalog.Store
+
+	// Prober runs the probes
+	Prober *healthcheck.Prober
+
+	// Concurrency bounds the probes in flight
+	// (healthcheck.DefaultConcurrency if 0)
+	Concurrency int
+
+	// Damping is the health damping threshold, up to which consecutive
+	// results of the same state are counted in status.healthHistory
+	Damping int
+
+	// next holds when each app, by namespace/name, is due its next probe
+	next map[string]time.Time
+}
+
+// healthProbe is an app due a probe
+type healthProbe struct {
+	key   types.NamespacedName
+	check healthcheck.Check
+	// err is why the app's health check cannot run, reported as its
+	// health instead
+	err error
+}
+
+// Start probes apps as they fall due until ctx is done.
+func (p *HealthProber) Start(ctx context.Context) error {
+	ticker := time.NewTicker(healthProbeTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		p.probeDue(ctx, time.Now())
+	}
+}
+
+// probeDue probes the apps due a probe at now, in parallel, and records
+// their health. It does nothing until a catalog was written.
+func (p *HealthProber) probeDue(ctx context.Context, now time.Time) {
+	due, err := p.dueProbes(ctx, now)
+	if err != nil {
+		p.Log.V(1).Info("Failed to list apps to probe", "error", err.Error())
+		return
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = healthcheck.DefaultConcurrency
+	}
+	results := make([]healthcheck.Result, len(due))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, probe := range due {
+		if probe.err != nil {
+			results[i] = healthcheck.Result{State: dashboardv1alpha1.HealthDown, Reason: "invalid health check: " + probe.err.Error()}
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = p.Prober.Probe(ctx, probe.check)
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		// Probes cut short by shutdown say nothing about the apps
+		return
+	}
+
+	for i, probe := range due {
+		if err := p.record(ctx, probe.key, results[i], time.Now()); err != nil {
+			p.Log.V(1).Info("Failed to record app health", "app", probe.key.String(), "error", err.Error())
+		}
+	}
+}
+
+// dueProbes returns the apps due a probe at now and schedules their next
+// one. Only apps listed in the catalog are probed, on their internal URL
+// when they have one.
+func (p *HealthProber) dueProbes(ctx context.Context, now time.Time) ([]healthProbe, error) {
+	result, _ := p.Catalog.Get()
+	if result == nil {
+		return nil, nil
+	}
+	bases := make(map[string]string, len(result.Entries))
+	for _, e := range result.Entries {
+		// Apps kept listed after their deletion are expected to go away
+		if e.Removed {
+			continue
+		}
+		bases[e.Source] = e.URL
+		if e.InternalURL != "" {
+			bases[e.Source] = e.InternalURL
+		}
+	}
+
+	apps := &dashboardv1alpha1.DashboardAppList{}
+	if err := p.List(ctx, apps); err != nil {
+		return nil, err
+	}
+	if p.next == nil {
+		p.next = make(map[string]time.Time)
+	}
+	var due []healthProbe
+	probed := make(map[string]bool)
+	for i := range apps.Items {
+		app := &apps.Items[i]
+		source := app.Namespace + "/" + app.Name
+		base, listed := bases[source]
+		hc := app.Spec.HealthCheck
+		if hc == nil || !listed || app.Spec.HeartbeatTimeout != nil {
+			continue
+		}
+		probed[source] = true
+		if next, ok := p.next[source]; ok && now.Before(next) {
+			continue
+		}
+		p.next[source] = now.Add(healthcheck.Interval(hc))
+		check, err := healthcheck.CheckFor(hc, base)
+		due = append(due, healthProbe{key: client.ObjectKeyFromObject(app), check: check, err: err})
+	}
+	// Forget apps that went away or stopped asking for probes
+	for source := range p.next {
+		if !probed[source] {
+			delete(p.next, source)
+		}
+	}
+	return due, nil
+}
+
+// record writes the outcome of a probe to the app's status.health and
+// counts it in status.healthHistory, unless neither changes or the app no
+// longer asks for probes.
+func (p *HealthProber) record(ctx context.Context, key types.NamespacedName, result healthcheck.Result, now time.Time) error {
+	health := dashboardv1alpha1.AppHealth{State: result.State, Reason: redact.String(result.Reason)}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		app := &dashboardv1alpha1.DashboardApp{}
+		if err := p.Get(ctx, key, app); err != nil {
+			return err
+		}
+		if app.Spec.HealthCheck == nil || app.Spec.HeartbeatTimeout != nil {
+			return nil
+		}
+		changed := setHealth(app, health, now)
+		counted := assembler.CountHealthResult(app, result.State, now, p.Damping)
+		if !changed && !counted {
+			return nil
+		}
+		if changed && result.State != dashboardv1alpha1.HealthUp {
+			p.Log.Info("App health check failed", "app", key.String(), "reason", health.Reason)
+		}
+		return p.Status().Update(ctx, app)
+	})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
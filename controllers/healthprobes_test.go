@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+	"github.com/fredericrous/duro-operator/pkg/healthcheck"
+)
+
+func TestHealthProber(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	newApp := func(name, path string) *dashboardv1alpha1.DashboardApp {
+		return &dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "media"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name:        name,
+				URL:         "https://" + name + ".example.com",
+				HealthCheck: &dashboardv1alpha1.HealthCheck{Path: path},
+			},
+		}
+	}
+	plex, sonarr := newApp("plex", "/healthz"), newApp("sonarr", "/down")
+	unchecked := newApp("radarr", "")
+	unchecked.Spec.HealthCheck = nil
+	c := newFakeClient(t, plex, sonarr, unchecked)
+
+	store := catalog.NewStore()
+	store.Set(&assembler.AssemblyResult{Entries: []assembler.AppEntry{
+		{ID: "plex", URL: plex.Spec.URL, InternalURL: srv.URL, Source: "media/plex"},
+		{ID: "sonarr", URL: srv.URL, Source: "media/sonarr"},
+		{ID: "radarr", URL: srv.URL, Source: "media/radarr"},
+	}})
+	prober := &HealthProber{Client: c, Log: logr.Discard(), Catalog: store, Prober: healthcheck.NewProber(), Damping: 2}
+	get := func(app *dashboardv1alpha1.DashboardApp) *dashboardv1alpha1.DashboardApp {
+		t.Helper()
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(app), app); err != nil {
+			t.Fatal(err)
+		}
+		return app
+	}
+
+	// Apps are probed on their internal URL when they have one
+	now := time.Now()
+	prober.probeDue(context.Background(), now)
+	if h := get(plex).Status.Health; h == nil || h.State != dashboardv1alpha1.HealthUp {
+		t.Errorf("plex health = %+v, want up", h)
+	}
+	if h := get(sonarr).Status.Health; h == nil || h.State != dashboardv1alpha1.HealthDown || h.Reason != "HTTP 503" {
+		t.Errorf("sonarr health = %+v, want down with HTTP 503", h)
+	}
+	if h := get(unchecked).Status.Health; h != nil {
+		t.Errorf("radarr health = %+v, want it left alone", h)
+	}
+
+	// Nothing is due again before the interval is over
+	for _, tt := range []struct {
+		at   time.Time
+		want int
+	}{
+		{now.Add(30 * time.Second), 0},
+		{now.Add(healthcheck.DefaultInterval), 2},
+	} {
+		due, err := prober.dueProbes(context.Background(), tt.at)
+		if err != nil {
+			t.Fatalf("dueProbes() error = %v", err)
+		}
+		if len(due) != tt.want {
+			t.Errorf("%d probes due after %s, want %d", len(due), tt.at.Sub(now), tt.want)
+		}
+	}
+
+	// Results are counted towards the damping threshold, and no further
+	prober.probeDue(context.Background(), now.Add(2*healthcheck.DefaultInterval))
+	prober.probeDue(context.Background(), now.Add(3*healthcheck.DefaultInterval))
+	if history := get(plex).Status.HealthHistory; len(history) != 1 || history[0].Count != 2 {
+		t.Errorf("plex health history = %+v, want one sample counted twice", history)
+	}
+}
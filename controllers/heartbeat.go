@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+// setHeartbeatHealth records the heartbeat-derived health of an app with
+// spec.heartbeatTimeout in its status. Returns true if the status changed.
+func setHeartbeatHealth(app *dashboardv1alpha1.DashboardApp, now time.Time) bool {
+	health, ok := assembler.HeartbeatHealth(app, now)
+	if !ok {
+		return false
+	}
+	return setHealth(app, health, now)
+}
+
+// setHealth records health in the app's status, keeping the transition time
+// while the state holds. Returns true if the status changed.
+func setHealth(app *dashboardv1alpha1.DashboardApp, health dashboardv1alpha1.AppHealth, now time.Time) bool {
+	current := app.Status.Health
+	if current != nil && current.State == health.State && current.Reason == health.Reason {
+		return false
+	}
+	health.LastTransitionTime = &metav1.Time{Time: now}
+	if current != nil && current.State == health.State {
+		health.LastTransitionTime = current.LastTransitionTime
+	}
+	app.Status.Health = &health
+	return true
+}
+
+// heartbeatRecoveredPredicate passes updates refreshing the heartbeat of an
+// app currently marked stale. Fresh heartbeats are otherwise not watched: the
+// reconciler requeues itself for the moment an app would go stale.
+func heartbeatRecoveredPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldApp, ok1 := e.ObjectOld.(*dashboardv1alpha1.DashboardApp)
+			newApp, ok2 := e.ObjectNew.(*dashboardv1alpha1.DashboardApp)
+			if !ok1 || !ok2 || newApp.Spec.HeartbeatTimeout == nil {
+				return false
+			}
+			return healthState(newApp) == dashboardv1alpha1.HealthUnknown &&
+				oldApp.Annotations[dashboardv1alpha1.HeartbeatAnnotation] != newApp.Annotations[dashboardv1alpha1.HeartbeatAnnotation]
+		},
+	}
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/helm"
+)
+
+// HelmReleaseReconciler synthesizes DashboardApps for Helm releases whose
+// chart opts in through dashboard.homelab.io/* annotations or values. Requests
+// are keyed by release namespace and name; the DashboardApp is named after
+// the release and removed once no deployed revision declares it.
+type HelmReleaseReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+
+	// Namespaces, if set, restricts discovery to the namespaces it accepts
+	// (see Config.WatchesNamespace)
+	Namespaces func(namespace string) bool
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *HelmReleaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("helmrelease").
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(mapToRelease),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isReleaseSecret), namespacePredicate(r.Namespaces)),
+		).
+		Complete(r)
+}
+
+func isReleaseSecret(obj client.Object) bool {
+	secret, ok := obj.(*corev1.Secret)
+	return ok && secret.Type == helm.SecretType && secret.Labels[helm.OwnerLabel] == "helm"
+}
+
+// mapToRelease enqueues the release a revision Secret belongs to.
+func mapToRelease(_ context.Context, obj client.Object) []reconcile.Request {
+	name := obj.GetLabels()[helm.NameLabel]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: obj.GetNamespace(), Name: name}}}
+}
+
+// Reconcile handles the reconciliation loop
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+func (r *HelmReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("release", req.NamespacedName)
+
+	secret, rel, err := r.deployedRelease(ctx, req.Namespace, req.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var spec *dashboardv1alpha1.DashboardAppSpec
+	if rel != nil {
+		var optIn bool
+		spec, optIn, err = rel.AppSpec()
+		if err != nil {
+			log.Info("Chart declares an invalid dashboard entry", "error", err.Error())
+			r.Recorder.Eventf(secret, corev1.EventTypeWarning, "InvalidDashboardAnnotations", "Chart %s: %v", rel.Chart.Metadata.Name, err)
+			return ctrl.Result{}, nil
+		}
+		if !optIn {
+			spec = nil
+		}
+	}
+
+	existing := &dashboardv1alpha1.DashboardApp{}
+	err = r.Get(ctx, req.NamespacedName, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, operrors.NewTransientError("failed to get DashboardApp", err)
+	}
+	found := err == nil
+	managed := found && existing.Labels[dashboardv1alpha1.SourceLabel] == helm.SourceHelm &&
+		existing.Labels[helm.ReleaseLabel] == req.Name
+
+	switch {
+	case spec == nil:
+		if managed {
+			log.Info("Helm release no longer declares a dashboard entry, deleting DashboardApp")
+			if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, operrors.NewTransientError("failed to delete DashboardApp", err)
+			}
+		}
+	case !found:
+		app := &dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      req.Name,
+				Namespace: req.Namespace,
+				Labels: map[string]string{
+					dashboardv1alpha1.SourceLabel: helm.SourceHelm,
+					helm.ReleaseLabel:             req.Name,
+				},
+			},
+			Spec: *spec,
+		}
+		log.Info("Creating DashboardApp for Helm release", "chart", rel.Chart.Metadata.Name)
+		if err := r.Create(ctx, app); err != nil {
+			return ctrl.Result{}, operrors.NewTransientError("failed to create DashboardApp", err)
+		}
+	case !managed:
+		log.Info("DashboardApp exists and is not managed by Helm discovery, leaving it alone")
+		r.Recorder.Event(existing, corev1.EventTypeWarning, "HelmDiscoveryConflict",
+			"Helm release "+req.Name+" declares a dashboard entry but this DashboardApp is not managed by Helm discovery")
+	case !equality.Semantic.DeepEqual(existing.Spec, *spec):
+		existing.Spec = *spec
+		log.Info("Updating DashboardApp for Helm release", "chart", rel.Chart.Metadata.Name, "revision", rel.Version)
+		if err := r.Update(ctx, existing); err != nil {
+			return ctrl.Result{}, operrors.NewTransientError("failed to update DashboardApp", err)
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// deployedRelease returns the latest deployed revision of a release and the
+// Secret holding it, or nils if the release is not (or no longer) deployed.
+func (r *HelmReleaseReconciler) deployedRelease(ctx context.Context, namespace, name string) (*corev1.Secret, *helm.Release, error) {
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(namespace), client.MatchingLabels{
+		helm.OwnerLabel:  "helm",
+		helm.NameLabel:   name,
+		helm.StatusLabel: helm.StatusDeployed,
+	}); err != nil {
+		return nil, nil, operrors.NewTransientError("failed to list Helm release Secrets", err)
+	}
+
+	var latest *helm.Release
+	var latestSecret *corev1.Secret
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.Type != helm.SecretType {
+			continue
+		}
+		rel, err := helm.Decode(secret.Data["release"])
+		if err != nil {
+			r.Log.Info("Skipping undecodable Helm release Secret", "secret", client.ObjectKeyFromObject(secret), "error", err.Error())
+			continue
+		}
+		if latest == nil || rel.Version > latest.Version {
+			latest, latestSecret = rel, secret
+		}
+	}
+	return latestSecret, latest, nil
+}
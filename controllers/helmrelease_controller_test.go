@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/helm"
+)
+
+var _ = Describe("HelmRelease controller", func() {
+	const (
+		timeout  = 10 * time.Second
+		interval = 250 * time.Millisecond
+	)
+
+	newReleaseSecret := func(name string, annotations map[string]string) *corev1.Secret {
+		data, err := helm.Encode(&helm.Release{
+			Name: name, Namespace: "default", Version: 1,
+			Info:  helm.ReleaseInfo{Status: helm.StatusDeployed},
+			Chart: helm.Chart{Metadata: helm.ChartMetadata{Name: name, Version: "1.0.0", Annotations: annotations}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sh.helm.release.v1." + name + ".v1",
+				Namespace: "default",
+				Labels: map[string]string{
+					helm.OwnerLabel:  "helm",
+					helm.NameLabel:   name,
+					helm.StatusLabel: helm.StatusDeployed,
+				},
+			},
+			Type: helm.SecretType,
+			Data: map[string][]byte{"release": data},
+		}
+	}
+
+	It("synthesizes a DashboardApp for an opted-in chart and removes it on uninstall", func() {
+		secret := newReleaseSecret("helm-jellyfin", map[string]string{
+			"dashboard.homelab.io/url":      "https://jellyfin.example.test",
+			"dashboard.homelab.io/category": "media",
+			"dashboard.homelab.io/icon":     "<svg/>",
+			"dashboard.homelab.io/groups":   "family",
+		})
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+		key := types.NamespacedName{Name: "helm-jellyfin", Namespace: "default"}
+		Eventually(func(g Gomega) {
+			var app dashboardv1alpha1.DashboardApp
+			g.Expect(k8sClient.Get(ctx, key, &app)).To(Succeed())
+			g.Expect(app.Labels).To(HaveKeyWithValue(dashboardv1alpha1.SourceLabel, helm.SourceHelm))
+			g.Expect(app.Spec.URL).To(Equal("https://jellyfin.example.test"))
+			g.Expect(app.Spec.Groups).To(Equal([]string{"family"}))
+		}, timeout, interval).Should(Succeed())
+
+		Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		Eventually(func() bool {
+			var app dashboardv1alpha1.DashboardApp
+			return errors.IsNotFound(k8sClient.Get(ctx, key, &app))
+		}, timeout, interval).Should(BeTrue())
+	})
+
+	It("ignores charts that do not opt in", func() {
+		Expect(k8sClient.Create(ctx, newReleaseSecret("helm-plain", nil))).To(Succeed())
+
+		key := types.NamespacedName{Name: "helm-plain", Namespace: "default"}
+		Consistently(func() bool {
+			var app dashboardv1alpha1.DashboardApp
+			return errors.IsNotFound(k8sClient.Get(ctx, key, &app))
+		}, 2*time.Second, interval).Should(BeTrue())
+	})
+})
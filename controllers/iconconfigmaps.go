@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// iconsLabel marks the namespace icon ConfigMaps, so those left without
+// icons can be found and deleted
+const iconsLabel = "dashboard.homelab.io/icons"
+
+// syncIconConfigMaps writes the namespace icon ConfigMaps of the assembly
+// (see Config.IconConfigMap) and deletes those of namespaces left without
+// icons. Each ConfigMap is owned by the apps whose icons it holds, so it is
+// garbage collected along with the last of them.
+func (r *DashboardAppReconciler) syncIconConfigMaps(ctx context.Context, apps []dashboardv1alpha1.DashboardApp, result *assembler.AssemblyResult) error {
+	if r.Config.IconConfigMap == "" {
+		return nil
+	}
+
+	owners := make(map[string][]*dashboardv1alpha1.DashboardApp)
+	bySource := make(map[string]*dashboardv1alpha1.DashboardApp, len(apps))
+	for i := range apps {
+		bySource[apps[i].Namespace+"/"+apps[i].Name] = &apps[i]
+	}
+	for _, e := range result.Entries {
+		if app := bySource[e.Source]; e.IconRef != nil && app != nil {
+			owners[e.IconRef.Namespace] = append(owners[e.IconRef.Namespace], app)
+		}
+	}
+
+	for _, namespace := range slices.Sorted(maps.Keys(result.NamespaceIcons)) {
+		if err := r.writeIconConfigMap(ctx, namespace, result.NamespaceIcons[namespace], owners[namespace]); err != nil {
+			return err
+		}
+	}
+
+	var existing corev1.ConfigMapList
+	if err := r.List(ctx, &existing, client.MatchingLabels{iconsLabel: "true", instanceLabel: r.Config.Identity()}); err != nil {
+		return operrors.NewTransientError("failed to list icon ConfigMaps", err)
+	}
+	for i := range existing.Items {
+		cm := &existing.Items[i]
+		if _, ok := result.NamespaceIcons[cm.Namespace]; ok && cm.Name == r.Config.IconConfigMap {
+			continue
+		}
+		logr.FromContextOrDiscard(ctx).Info("Deleting icon ConfigMap no longer needed", "configmap", client.ObjectKeyFromObject(cm))
+		if err := r.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			return operrors.NewTransientError("failed to delete icon ConfigMap", err)
+		}
+	}
+	return nil
+}
+
+// writeIconConfigMap creates or updates the icon ConfigMap of namespace,
+// skipping the write when its icons and owners are unchanged.
+func (r *DashboardAppReconciler) writeIconConfigMap(ctx context.Context, namespace string, icons map[string]string, owners []*dashboardv1alpha1.DashboardApp) error {
+	if err := checkOutputSize(icons, nil); err != nil {
+		return err
+	}
+
+	key := types.NamespacedName{Name: r.Config.IconConfigMap, Namespace: namespace}
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, key, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return operrors.NewTransientError("failed to get icon ConfigMap", err)
+	}
+	found := err == nil
+	if found {
+		if owner := cm.Labels[instanceLabel]; owner != r.Config.Identity() {
+			return operrors.NewPermanentError(fmt.Sprintf("ConfigMap %s is not an icon ConfigMap of operator instance %s",
+				key, r.Config.Identity()), nil)
+		}
+	} else {
+		cm.ObjectMeta = metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}
+	}
+	orig := cm.DeepCopy()
+
+	if cm.Labels == nil {
+		cm.Labels = make(map[string]string, 3)
+	}
+	cm.Labels["app.kubernetes.io/managed-by"] = "duro-operator"
+	cm.Labels[instanceLabel] = r.Config.Identity()
+	cm.Labels[iconsLabel] = "true"
+	cm.OwnerReferences = nil
+	for _, app := range owners {
+		if err := controllerutil.SetOwnerReference(app, cm, r.Scheme); err != nil {
+			return operrors.NewPermanentError("failed to set icon ConfigMap owner", err)
+		}
+	}
+	cm.Data = icons
+
+	if !found {
+		logr.FromContextOrDiscard(ctx).Info("Creating icon ConfigMap", "configmap", key, "icons", len(icons))
+		return r.Create(ctx, cm)
+	}
+	if equality.Semantic.DeepEqual(orig.Labels, cm.Labels) && equality.Semantic.DeepEqual(orig.OwnerReferences, cm.OwnerReferences) &&
+		maps.Equal(orig.Data, cm.Data) {
+		return nil
+	}
+	logr.FromContextOrDiscard(ctx).Info("Updating icon ConfigMap", "configmap", key, "icons", len(icons))
+	return r.Update(ctx, cm)
+}
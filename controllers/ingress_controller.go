@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/ingress"
+)
+
+// IngressReconciler synthesizes DashboardApps for Ingresses opting in
+// through dashboard.homelab.io/* annotations, taking the URL from their
+// rules. The DashboardApp is named after the Ingress and controlled by it,
+// so it is garbage collected along with the Ingress, and deleted once the
+// Ingress opts out.
+type IngressReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder record.EventRecorder
+
+	// Namespaces, if set, restricts discovery to the namespaces it accepts
+	// (see Config.WatchesNamespace)
+	Namespaces func(namespace string) bool
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("ingress").
+		For(&networkingv1.Ingress{}, builder.WithPredicates(namespacePredicate(r.Namespaces))).
+		Owns(&dashboardv1alpha1.DashboardApp{}).
+		Complete(r)
+}
+
+// Reconcile handles the reconciliation loop
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+
+func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("ingress", req.NamespacedName)
+
+	ing := &networkingv1.Ingress{}
+	if err := r.Get(ctx, req.NamespacedName, ing); err != nil {
+		// The DashboardApp of a deleted Ingress is garbage collected
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var spec *dashboardv1alpha1.DashboardAppSpec
+	if ing.DeletionTimestamp.IsZero() {
+		var enabled bool
+		var err error
+		spec, enabled, err = ingress.AppSpec(ing)
+		if err != nil {
+			log.Info("Ingress declares an invalid dashboard entry", "error", err.Error())
+			r.Recorder.Eventf(ing, corev1.EventTypeWarning, "InvalidDashboardAnnotations", "%v", err)
+			return ctrl.Result{}, nil
+		}
+		if !enabled {
+			spec = nil
+		}
+	}
+
+	d := &discoverer{Client: r.Client, Scheme: r.Scheme, Recorder: r.Recorder, kind: "Ingress", source: ingress.SourceIngress}
+	return ctrl.Result{}, d.syncApp(ctx, log, ing, spec)
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/ingress"
+)
+
+var _ = Describe("Ingress controller", func() {
+	const (
+		timeout  = 10 * time.Second
+		interval = 250 * time.Millisecond
+	)
+
+	It("synthesizes a DashboardApp for an annotated Ingress and removes it when disabled", func() {
+		pathType := networkingv1.PathTypePrefix
+		ing := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-grafana",
+				Namespace: "default",
+				Annotations: map[string]string{
+					ingress.EnabledAnnotation:       "true",
+					"dashboard.homelab.io/groups":   "admins",
+					"dashboard.homelab.io/category": "monitoring",
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"grafana.example.test"}}},
+				Rules: []networkingv1.IngressRule{{
+					Host: "grafana.example.test",
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: "grafana", Port: networkingv1.ServiceBackendPort{Number: 80},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, ing)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, ing) })
+
+		key := types.NamespacedName{Name: "ingress-grafana", Namespace: "default"}
+		Eventually(func(g Gomega) {
+			var app dashboardv1alpha1.DashboardApp
+			g.Expect(k8sClient.Get(ctx, key, &app)).To(Succeed())
+			g.Expect(app.Labels).To(HaveKeyWithValue(dashboardv1alpha1.SourceLabel, ingress.SourceIngress))
+			g.Expect(metav1.IsControlledBy(&app, ing)).To(BeTrue())
+			g.Expect(app.Spec.URL).To(Equal("https://grafana.example.test"))
+			g.Expect(app.Spec.Category).To(Equal("monitoring"))
+			g.Expect(app.Spec.Groups).To(Equal([]string{"admins"}))
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, key, ing); err != nil {
+				return err
+			}
+			delete(ing.Annotations, ingress.EnabledAnnotation)
+			return k8sClient.Update(ctx, ing)
+		}, timeout, interval).Should(Succeed())
+		Eventually(func() bool {
+			var app dashboardv1alpha1.DashboardApp
+			return errors.IsNotFound(k8sClient.Get(ctx, key, &app))
+		}, timeout, interval).Should(BeTrue())
+	})
+})
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+const (
+	// appSelectorAnnotation publishes an instance's app selector on its
+	// OperatorOverview
+	appSelectorAnnotation = "dashboard.homelab.io/app-selector"
+
+	// outputAnnotation publishes the namespace/name of an instance's output
+	// ConfigMap on its OperatorOverview
+	outputAnnotation = "dashboard.homelab.io/output"
+)
+
+// checkPartitions publishes this instance's selector and output on its
+// OperatorOverview and warns about other instances writing the same output
+// or selecting the same apps. Instances are told apart by their
+// OperatorOverview, one per instance name.
+func (r *DashboardAppReconciler) checkPartitions(ctx context.Context, overview *dashboardv1alpha1.OperatorOverview) error {
+	log := r.Log.WithName("instance")
+	output := r.Config.DuroNamespace + "/" + r.Config.DuroConfigMapName
+
+	if overview.Annotations[appSelectorAnnotation] != r.Config.AppSelector || overview.Annotations[outputAnnotation] != output {
+		if overview.Annotations == nil {
+			overview.Annotations = map[string]string{}
+		}
+		overview.Annotations[appSelectorAnnotation] = r.Config.AppSelector
+		overview.Annotations[outputAnnotation] = output
+		if err := r.Update(ctx, overview); err != nil {
+			return err
+		}
+	}
+
+	overviews := &dashboardv1alpha1.OperatorOverviewList{}
+	if err := r.List(ctx, overviews); err != nil {
+		return err
+	}
+	for i := range overviews.Items {
+		other := &overviews.Items[i]
+		if other.Name == overview.Name {
+			continue
+		}
+		if other.Annotations[outputAnnotation] == output {
+			log.Info("Another operator instance writes the same output", "instance", other.Name, "output", output)
+			r.Recorder.Eventf(overview, corev1.EventTypeWarning, "OutputConflict",
+				"Operator instance %s also writes %s; give each instance its own output ConfigMap", other.Name, output)
+		}
+
+		otherSelector, ok := other.Annotations[appSelectorAnnotation]
+		if !ok {
+			continue
+		}
+		overlap, err := r.countOverlap(ctx, otherSelector)
+		if err != nil {
+			return err
+		}
+		if overlap > 0 {
+			log.Info("Another operator instance selects the same apps", "instance", other.Name, "apps", overlap)
+			r.Recorder.Eventf(overview, corev1.EventTypeWarning, "OverlappingSelectors",
+				"%d DashboardApps are selected by both this instance (%q) and instance %s (%q)",
+				overlap, r.Config.AppSelector, other.Name, otherSelector)
+		}
+	}
+	return nil
+}
+
+// countOverlap counts the apps selected by both this instance and another
+// instance's selector.
+func (r *DashboardAppReconciler) countOverlap(ctx context.Context, otherSelector string) (int, error) {
+	other, err := labels.Parse(otherSelector)
+	if err != nil {
+		return 0, fmt.Errorf("invalid app selector published by another instance: %w", err)
+	}
+	apps := &dashboardv1alpha1.DashboardAppList{}
+	if err := r.List(ctx, apps, client.MatchingLabelsSelector{Selector: r.selector}); err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range apps.Items {
+		if other.Matches(labels.Set(apps.Items[i].Labels)) {
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/helm"
+	"github.com/fredericrous/duro-operator/pkg/ingress"
+	"github.com/fredericrous/duro-operator/pkg/workload"
+)
+
+// With priority lanes (see Config.PriorityLanes) reconciles are queued in
+// two lanes: changes users make to dashboard resources, and background work
+// such as health probes, heartbeats, discovered apps and the cluster state
+// the output follows. Every reconcile assembles the whole catalog, so under
+// load background events only hold up each other, never an edit someone is
+// waiting to see. A requeue stays in the lane of the reconcile asking for it.
+
+// backgroundPriority is the queue priority of the background lane
+const backgroundPriority = handler.LowPriority
+
+// discoverySources are the SourceLabel values of apps written by the
+// discovery controllers rather than by users
+var discoverySources = []string{helm.SourceHelm, workload.SourceWorkload, ingress.SourceIngress}
+
+// laneFuncs tell which events go to the background lane
+type laneFuncs struct {
+	// Background reports whether create, delete and generic events of obj
+	// are background work
+	Background func(obj client.Object) bool
+	// BackgroundUpdate reports whether an update is background work
+	BackgroundUpdate func(oldObj, newObj client.Object) bool
+}
+
+// allBackground sends every event to the background lane
+var allBackground = laneFuncs{
+	Background:       func(client.Object) bool { return true },
+	BackgroundUpdate: func(client.Object, client.Object) bool { return true },
+}
+
+// appLanes sends events of discovered apps, and updates leaving an app's
+// spec, labels and resync annotation alone (health, heartbeats), to the
+// background lane.
+var appLanes = laneFuncs{
+	Background: discovered,
+	BackgroundUpdate: func(oldObj, newObj client.Object) bool {
+		if discovered(newObj) {
+			return true
+		}
+		return oldObj.GetGeneration() == newObj.GetGeneration() &&
+			oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp()) &&
+			maps.Equal(oldObj.GetLabels(), newObj.GetLabels()) &&
+			oldObj.GetAnnotations()[dashboardv1alpha1.ResyncAnnotation] == newObj.GetAnnotations()[dashboardv1alpha1.ResyncAnnotation]
+	},
+}
+
+// discovered reports whether obj is an app written by a discovery
+// controller.
+func discovered(obj client.Object) bool {
+	return slices.Contains(discoverySources, obj.GetLabels()[dashboardv1alpha1.SourceLabel])
+}
+
+// laned wraps h so the requests it enqueues for background events go to the
+// background lane. Without a priority queue it changes nothing.
+func laned(h handler.EventHandler, lanes laneFuncs) handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			h.Create(ctx, e, inLane(q, lanes.Background(e.Object)))
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			h.Update(ctx, e, inLane(q, lanes.BackgroundUpdate(e.ObjectOld, e.ObjectNew)))
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			h.Delete(ctx, e, inLane(q, lanes.Background(e.Object)))
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			h.Generic(ctx, e, inLane(q, lanes.Background(e.Object)))
+		},
+	}
+}
+
+// inLane returns q adding to the background lane if background is set and
+// q is a priority queue, q itself otherwise.
+func inLane(q workqueue.TypedRateLimitingInterface[reconcile.Request], background bool) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	pq, ok := q.(priorityqueue.PriorityQueue[reconcile.Request])
+	if !background || !ok {
+		return q
+	}
+	return backgroundQueue{pq}
+}
+
+// backgroundQueue adds to the background lane of a priority queue, whether
+// through the plain workqueue methods or AddWithOpts, which event handlers
+// use with a priority queue. A request already waiting in the user lane
+// keeps its priority.
+type backgroundQueue struct {
+	priorityqueue.PriorityQueue[reconcile.Request]
+}
+
+func (q backgroundQueue) AddWithOpts(opts priorityqueue.AddOpts, items ...reconcile.Request) {
+	if opts.Priority == nil || *opts.Priority > backgroundPriority {
+		priority := backgroundPriority
+		opts.Priority = &priority
+	}
+	q.PriorityQueue.AddWithOpts(opts, items...)
+}
+
+func (q backgroundQueue) Add(item reconcile.Request) {
+	q.AddWithOpts(priorityqueue.AddOpts{}, item)
+}
+
+func (q backgroundQueue) AddAfter(item reconcile.Request, after time.Duration) {
+	q.AddWithOpts(priorityqueue.AddOpts{After: after}, item)
+}
+
+func (q backgroundQueue) AddRateLimited(item reconcile.Request) {
+	q.AddWithOpts(priorityqueue.AddOpts{RateLimited: true}, item)
+}
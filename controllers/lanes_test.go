@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/helm"
+)
+
+var _ = Describe("Priority lanes", func() {
+	It("queues background changes to apps behind the edits of users", func() {
+		q := priorityqueue.New[reconcile.Request]("lanes-test")
+		defer q.ShutDown()
+		h := laned(&handler.EnqueueRequestForObject{}, appLanes)
+
+		newApp := func(name string, generation int64) *dashboardv1alpha1.DashboardApp {
+			return &dashboardv1alpha1.DashboardApp{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "media", Generation: generation,
+				ResourceVersion: strconv.FormatInt(generation, 10)}}
+		}
+		probed := newApp("plex", 1)
+		probed.ResourceVersion = "2"
+		probed.Status.Health = &dashboardv1alpha1.AppHealth{State: dashboardv1alpha1.HealthDown}
+		h.Update(context.Background(), event.UpdateEvent{ObjectOld: newApp("plex", 1), ObjectNew: probed}, q)
+		discovered := newApp("jellyfin", 1)
+		discovered.Labels = map[string]string{dashboardv1alpha1.SourceLabel: helm.SourceHelm}
+		h.Create(context.Background(), event.CreateEvent{Object: discovered}, q)
+		h.Update(context.Background(), event.UpdateEvent{ObjectOld: newApp("sonarr", 1), ObjectNew: newApp("sonarr", 2)}, q)
+
+		next := func() (string, int) {
+			item, priority, _ := q.GetWithPriority()
+			q.Done(item)
+			return item.Name, priority
+		}
+		name, priority := next()
+		Expect(name).To(Equal("sonarr"))
+		Expect(priority).To(BeZero())
+		for range 2 {
+			name, priority = next()
+			Expect(name).To(BeElementOf("plex", "jellyfin"))
+			Expect(priority).To(Equal(backgroundPriority))
+		}
+	})
+})
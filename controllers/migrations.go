@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+const (
+	// operatorVersionAnnotation records on an output the version of the
+	// operator that last wrote it
+	operatorVersionAnnotation = "dashboard.homelab.io/operator-version"
+
+	// appliedMigrationsAnnotation lists the migrations run for an instance,
+	// comma-separated, on its OperatorOverview
+	appliedMigrationsAnnotation = "dashboard.homelab.io/applied-migrations"
+)
+
+// Migration brings what an earlier operator version left behind up to date.
+// Each migration runs once per instance, on the first catalog reconcile
+// after the operator starts, and only when there is an output to migrate.
+type Migration struct {
+	// Name identifies the migration in the applied migrations; a migration
+	// is never renamed
+	Name string
+
+	// Run, if set, performs the migration. It runs again if the operator
+	// stops before the migration is recorded, so it must be idempotent
+	Run func(ctx context.Context, r *DashboardAppReconciler) error
+
+	// Rebuild requests a full rebuild once the migration ran, rewriting
+	// every output document and app status in the current format: hashes,
+	// shards and entry IDs included
+	Rebuild bool
+}
+
+// defaultMigrations are the migrations run when Migrations is nil, in
+// order. New ones are appended.
+var defaultMigrations = []Migration{
+	// Outputs written by versions that did not record their version predate
+	// the per-document hashes, the algorithm-prefixed config hash and the
+	// category shards
+	{Name: "rewrite-output", Rebuild: true},
+}
+
+// runMigrations runs the migrations the instance has not applied yet,
+// recording each on the OperatorOverview as soon as it ran. A fresh
+// install has nothing to migrate: its migrations are recorded as applied
+// without running. It does nothing once it succeeded.
+func (r *DashboardAppReconciler) runMigrations(ctx context.Context) error {
+	if r.migrated {
+		return nil
+	}
+	log := logr.FromContextOrDiscard(ctx).WithName("migrations")
+	migrations := r.Migrations
+	if migrations == nil {
+		migrations = defaultMigrations
+	}
+
+	key, found, err := r.currentOutputKey(ctx)
+	if err != nil {
+		return err
+	}
+	output := newOutputObject(r.Config.OutputKind)
+	if found {
+		if err := r.Get(ctx, key, output); err != nil {
+			if !errors.IsNotFound(err) {
+				return operrors.NewTransientError("failed to get duro apps output", err)
+			}
+			found = false
+		}
+	}
+	previous := cmp.Or(output.GetAnnotations()[operatorVersionAnnotation], "an unknown version")
+	if found && previous != r.Version {
+		log.Info("Operator upgraded since the output was last written", "from", previous, "to", r.Version)
+	}
+
+	overview, err := r.instanceOverview(ctx)
+	if err != nil {
+		return err
+	}
+	applied := appliedMigrations(overview)
+	for _, m := range migrations {
+		if slices.Contains(applied, m.Name) {
+			continue
+		}
+		if found && m.Run != nil {
+			if err := m.Run(ctx, r); err != nil {
+				return operrors.NewTransientError(fmt.Sprintf("migration %s failed", m.Name), err)
+			}
+		}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := r.Get(ctx, client.ObjectKeyFromObject(overview), overview); err != nil {
+				return err
+			}
+			if overview.Annotations == nil {
+				overview.Annotations = map[string]string{}
+			}
+			applied = append(appliedMigrations(overview), m.Name)
+			overview.Annotations[appliedMigrationsAnnotation] = strings.Join(applied, ",")
+			if found && m.Rebuild {
+				overview.Annotations[dashboardv1alpha1.ResyncAnnotation] = "migration-" + m.Name
+			}
+			return r.Update(ctx, overview)
+		})
+		if err != nil {
+			return operrors.NewTransientError(fmt.Sprintf("failed to record migration %s", m.Name), err)
+		}
+		if found {
+			log.Info("Migration applied", "migration", m.Name, "from", previous)
+			r.Recorder.Eventf(overview, corev1.EventTypeNormal, "Migrated", "Applied migration %s to the output written by %s", m.Name, previous)
+		}
+	}
+	r.migrated = true
+	return nil
+}
+
+// instanceOverview returns the OperatorOverview of this instance, creating
+// it if needed.
+func (r *DashboardAppReconciler) instanceOverview(ctx context.Context) (*dashboardv1alpha1.OperatorOverview, error) {
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	err := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview)
+	if errors.IsNotFound(err) {
+		overview.Name = r.Config.Identity()
+		err = r.Create(ctx, overview)
+	}
+	if err != nil {
+		return nil, operrors.NewTransientError("failed to get OperatorOverview", err)
+	}
+	return overview, nil
+}
+
+// appliedMigrations returns the migrations recorded as applied on overview.
+func appliedMigrations(overview *dashboardv1alpha1.OperatorOverview) []string {
+	value := overview.Annotations[appliedMigrationsAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
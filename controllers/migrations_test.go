@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+// newMigratingReconciler returns a reconciler of version v2 with two
+// migrations, the first counting its runs in runs.
+func newMigratingReconciler(t *testing.T, runs *int, objs ...client.Object) *DashboardAppReconciler {
+	r := newFakeReconciler(t, nil, objs...)
+	r.Version = "v2"
+	r.Migrations = []Migration{
+		{Name: "count", Run: func(context.Context, *DashboardAppReconciler) error { *runs++; return nil }},
+		{Name: "rewrite", Rebuild: true},
+	}
+	return r
+}
+
+func overviewAnnotations(t *testing.T, r *DashboardAppReconciler) map[string]string {
+	t.Helper()
+	o := &dashboardv1alpha1.OperatorOverview{}
+	if err := r.Get(context.Background(), client.ObjectKey{Name: r.Config.Identity()}, o); err != nil {
+		t.Fatal(err)
+	}
+	return o.Annotations
+}
+
+func TestRunMigrations(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	output := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: cfg.DuroConfigMapName, Namespace: cfg.DuroNamespace,
+		Annotations: map[string]string{operatorVersionAnnotation: "v1"}}}
+	var runs int
+	r := newMigratingReconciler(t, &runs, output)
+
+	// The output of an earlier version is migrated once
+	if err := r.runMigrations(context.Background()); err != nil {
+		t.Fatalf("runMigrations() error = %v", err)
+	}
+	annotations := overviewAnnotations(t, r)
+	if runs != 1 || annotations[appliedMigrationsAnnotation] != "count,rewrite" || annotations[dashboardv1alpha1.ResyncAnnotation] != "migration-rewrite" {
+		t.Errorf("ran %d times, overview annotations %v, want one run, both applied and a rebuild requested", runs, annotations)
+	}
+
+	// and not again after a restart
+	r.migrated = false
+	if err := r.runMigrations(context.Background()); err != nil {
+		t.Fatalf("runMigrations() error = %v", err)
+	}
+	if runs != 1 {
+		t.Errorf("ran %d times after a restart, want 1", runs)
+	}
+}
+
+func TestRunMigrations_FreshInstall(t *testing.T) {
+	var runs int
+	r := newMigratingReconciler(t, &runs)
+
+	// The migrations of a fresh install are recorded without running them
+	if err := r.runMigrations(context.Background()); err != nil {
+		t.Fatalf("runMigrations() error = %v", err)
+	}
+	annotations := overviewAnnotations(t, r)
+	if _, rebuild := annotations[dashboardv1alpha1.ResyncAnnotation]; runs != 0 || annotations[appliedMigrationsAnnotation] != "count,rewrite" || rebuild {
+		t.Errorf("ran %d times, overview annotations %v, want no run, both applied and no rebuild", runs, annotations)
+	}
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// applyNamespaceCategories fills in spec.category of apps that leave it
+// empty with the category label of their namespace (see
+// Config.CategoryLabel), so charts can stay generic while namespaces carry
+// the categorization. Only the in-memory copies are changed. It returns the
+// inferred categories by app namespace/name, for the app status.
+func (r *DashboardAppReconciler) applyNamespaceCategories(ctx context.Context, apps []dashboardv1alpha1.DashboardApp) (map[string]string, error) {
+	if r.Config.CategoryLabel == "" {
+		return nil, nil
+	}
+	log := logr.FromContextOrDiscard(ctx)
+	inferred := make(map[string]string)
+	byNamespace := make(map[string]string)
+	for i := range apps {
+		app := &apps[i]
+		if app.Spec.Category != "" {
+			continue
+		}
+		category, ok := byNamespace[app.Namespace]
+		if !ok {
+			ns := &corev1.Namespace{}
+			if err := r.Get(ctx, client.ObjectKey{Name: app.Namespace}, ns); client.IgnoreNotFound(err) != nil {
+				return nil, operrors.NewTransientError("failed to get namespace", err)
+			}
+			category = ns.Labels[r.Config.CategoryLabel]
+			byNamespace[app.Namespace] = category
+		}
+		if category == "" {
+			log.V(1).Info("App has no category and its namespace has no category label", "app", app.Name, "namespace", app.Namespace,
+				"label", r.Config.CategoryLabel)
+			continue
+		}
+		app.Spec.Category = category
+		inferred[app.Namespace+"/"+app.Name] = category
+	}
+	return inferred, nil
+}
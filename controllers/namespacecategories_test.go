@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+func TestApplyNamespaceCategories(t *testing.T) {
+	media := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "media", Labels: map[string]string{"homelab.io/category": "media"}}}
+	monitoring := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}}
+	cfg := config.NewDefaultConfig()
+	cfg.CategoryLabel = "homelab.io/category"
+	r := newFakeReconciler(t, cfg, media, monitoring)
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "tautulli", Namespace: "media"}, Spec: dashboardv1alpha1.DashboardAppSpec{Category: "monitoring"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}},
+	}
+	inferred, err := r.applyNamespaceCategories(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("applyNamespaceCategories() error = %v", err)
+	}
+	// Apps setting a category keep it
+	for i, want := range []string{"media", "monitoring", ""} {
+		if got := apps[i].Spec.Category; got != want {
+			t.Errorf("%s category = %q, want %q", apps[i].Name, got, want)
+		}
+	}
+	if want := map[string]string{"media/plex": "media"}; !maps.Equal(inferred, want) {
+		t.Errorf("inferred = %v, want %v", inferred, want)
+	}
+}
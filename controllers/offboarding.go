@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// offboardNamespaces splits off the apps of namespaces being deleted. They
+// leave the catalog right away, removal grace period or not, rather than one
+// by one as the namespace's teardown gets to them: the namespace cannot go
+// away while the removal finalizer holds its apps. It returns the apps to
+// assemble and the offboarded apps.
+func (r *DashboardAppReconciler) offboardNamespaces(ctx context.Context, apps []dashboardv1alpha1.DashboardApp) ([]dashboardv1alpha1.DashboardApp, []dashboardv1alpha1.DashboardApp, error) {
+	terminating := make(map[string]bool)
+	for i := range apps {
+		namespace := apps[i].Namespace
+		if _, ok := terminating[namespace]; ok {
+			continue
+		}
+		ns := &corev1.Namespace{}
+		if err := r.Get(ctx, client.ObjectKey{Name: namespace}, ns); client.IgnoreNotFound(err) != nil {
+			return nil, nil, operrors.NewTransientError("failed to get namespace", err)
+		}
+		terminating[namespace] = !ns.DeletionTimestamp.IsZero()
+	}
+
+	kept := make([]dashboardv1alpha1.DashboardApp, 0, len(apps))
+	var offboarded []dashboardv1alpha1.DashboardApp
+	for i := range apps {
+		if terminating[apps[i].Namespace] {
+			offboarded = append(offboarded, apps[i])
+			continue
+		}
+		kept = append(kept, apps[i])
+	}
+	return kept, offboarded, nil
+}
+
+// releaseOffboarded completes the offboarding of namespaces once the catalog
+// without their apps is written: the removal finalizers of their apps are
+// released, the icons only they used are dropped from the icon cache, and a
+// summary event is recorded on the overview for each namespace. apps are the
+// apps remaining in the catalog.
+func (r *DashboardAppReconciler) releaseOffboarded(ctx context.Context, offboarded, apps []dashboardv1alpha1.DashboardApp) {
+	if len(offboarded) == 0 {
+		r.offboarded = nil
+		return
+	}
+	log := logr.FromContextOrDiscard(ctx)
+
+	byNamespace := make(map[string][]string)
+	var namespaces []string
+	for i := range offboarded {
+		app := &offboarded[i]
+		if _, ok := byNamespace[app.Namespace]; !ok {
+			namespaces = append(namespaces, app.Namespace)
+		}
+		byNamespace[app.Namespace] = append(byNamespace[app.Namespace], app.Name)
+	}
+	r.releaseRemoved(ctx, offboarded)
+
+	if forgetter, ok := r.Assembler.IconResolver.(assembler.IconForgetter); ok {
+		inUse := make(map[string]bool, len(apps))
+		for i := range apps {
+			inUse[r.Assembler.IconURL(&apps[i])] = true
+		}
+		var unused []string
+		for i := range offboarded {
+			if u := r.Assembler.IconURL(&offboarded[i]); u != "" && !inUse[u] && !slices.Contains(unused, u) {
+				unused = append(unused, u)
+			}
+		}
+		forgetter.Forget(unused...)
+	}
+
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	hasOverview := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview) == nil
+	for _, namespace := range namespaces {
+		names := byNamespace[namespace]
+		log.Info("Offboarded namespace being deleted", "namespace", namespace, "apps", names)
+		if hasOverview && !r.offboarded[namespace] {
+			r.Recorder.Eventf(overview, corev1.EventTypeNormal, "NamespaceOffboarded",
+				"Namespace %s is being deleted, removed its %d apps from the dashboard", namespace, len(names))
+		}
+	}
+	// Apps linger until the namespace teardown deletes them; the summary is
+	// recorded once
+	r.offboarded = make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		r.offboarded[namespace] = true
+	}
+}
+
+// namespaceTerminatingPredicate passes namespaces entering termination and
+// deleted namespaces.
+func namespaceTerminatingPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetDeletionTimestamp().IsZero() && !e.ObjectNew.GetDeletionTimestamp().IsZero()
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+// forgettingResolver records the icon URLs it is asked to forget
+type forgettingResolver struct {
+	forgotten []string
+}
+
+func (f *forgettingResolver) Resolve(context.Context, string) (string, error) { return "", nil }
+
+func (f *forgettingResolver) Forget(urls ...string) { f.forgotten = append(f.forgotten, urls...) }
+
+func TestOffboardNamespaces(t *testing.T) {
+	now := metav1.Now()
+	media := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "media", DeletionTimestamp: &now, Finalizers: []string{"kubernetes"}}}
+	monitoring := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}}
+	newApp := func(name, namespace, iconURL string) *dashboardv1alpha1.DashboardApp {
+		return &dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Finalizers: []string{dashboardv1alpha1.RemovalFinalizer}},
+			Spec:       dashboardv1alpha1.DashboardAppSpec{Name: name, IconURL: iconURL},
+		}
+	}
+	plex := newApp("plex", "media", "https://icons.lan/plex.svg")
+	sonarr := newApp("sonarr", "media", "https://icons.lan/arr.svg")
+	grafana := newApp("grafana", "monitoring", "https://icons.lan/arr.svg")
+	r := newFakeReconciler(t, nil, media, monitoring, plex, sonarr, grafana,
+		&dashboardv1alpha1.OperatorOverview{ObjectMeta: metav1.ObjectMeta{Name: config.DefaultIdentity}})
+	resolver := &forgettingResolver{}
+	r.Assembler.IconResolver = resolver
+
+	apps := []dashboardv1alpha1.DashboardApp{*plex, *sonarr, *grafana}
+	kept, offboarded, err := r.offboardNamespaces(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("offboardNamespaces() error = %v", err)
+	}
+	if len(kept) != 1 || kept[0].Name != "grafana" || len(offboarded) != 2 {
+		t.Fatalf("kept %d apps and offboarded %d, want grafana kept and 2 offboarded", len(kept), len(offboarded))
+	}
+
+	// Icons still used by a kept app are not forgotten
+	ctx := logr.NewContext(context.Background(), logr.Discard())
+	r.releaseOffboarded(ctx, offboarded, kept)
+	released := &dashboardv1alpha1.DashboardApp{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(plex), released); err != nil {
+		t.Fatal(err)
+	}
+	if len(released.Finalizers) > 0 {
+		t.Errorf("finalizers = %v, want the removal finalizer released", released.Finalizers)
+	}
+	if !slices.Equal(resolver.forgotten, []string{"https://icons.lan/plex.svg"}) {
+		t.Errorf("forgotten icons = %v, want only plex's", resolver.forgotten)
+	}
+	const reported = "Normal NamespaceOffboarded Namespace media is being deleted, removed its 2 apps from the dashboard"
+	if events := recordedEvents(r); !slices.Contains(events, reported) {
+		t.Errorf("events = %q, want %q", events, reported)
+	}
+
+	// Each namespace is reported once
+	r.releaseOffboarded(ctx, offboarded, kept)
+	for _, event := range recordedEvents(r) {
+		if strings.Contains(event, "NamespaceOffboarded") {
+			t.Errorf("namespace reported again: %q", event)
+		}
+	}
+}
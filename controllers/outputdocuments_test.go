@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+func TestUpdateAppsConfig_RepairsDocuments(t *testing.T) {
+	result := &assembler.AssemblyResult{AppsJSON: `[{"name":"plex"}]`, CategoriesJSON: "[]", GroupCatalogJSON: "{}", TagsJSON: "[]"}
+	tests := []struct {
+		name   string
+		tamper func(cm *corev1.ConfigMap)
+	}{
+		{name: "edited", tamper: func(cm *corev1.ConfigMap) { cm.Data["apps.json"] = "[]" }},
+		{name: "deleted", tamper: func(cm *corev1.ConfigMap) { delete(cm.Data, "apps.json") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeReconciler(t, nil)
+			ctx := context.Background()
+			key := types.NamespacedName{Name: r.Config.DuroConfigMapName, Namespace: r.Config.DuroNamespace}
+			if _, err := r.updateAppsConfig(ctx, result, "trace", false); err != nil {
+				t.Fatalf("updateAppsConfig() error = %v", err)
+			}
+
+			// The document is changed behind the operator's back, the hashes
+			// recorded at the last write left as they were
+			cm := &corev1.ConfigMap{}
+			if err := r.Get(ctx, key, cm); err != nil {
+				t.Fatal(err)
+			}
+			tt.tamper(cm)
+			if err := r.Update(ctx, cm); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := r.updateAppsConfig(ctx, result, "trace", false); err != nil {
+				t.Fatalf("updateAppsConfig() error = %v", err)
+			}
+			repaired := &corev1.ConfigMap{}
+			if err := r.Get(ctx, key, repaired); err != nil {
+				t.Fatal(err)
+			}
+			if got := repaired.Data["apps.json"]; got != result.AppsJSON {
+				t.Errorf("apps.json = %q, want it rewritten to %q", got, result.AppsJSON)
+			}
+
+			// and left alone once repaired
+			if _, err := r.updateAppsConfig(ctx, result, "trace", false); err != nil {
+				t.Fatalf("updateAppsConfig() error = %v", err)
+			}
+			unchanged := &corev1.ConfigMap{}
+			if err := r.Get(ctx, key, unchanged); err != nil {
+				t.Fatal(err)
+			}
+			if unchanged.ResourceVersion != repaired.ResourceVersion {
+				t.Errorf("output rewritten: resource version %s, want %s", unchanged.ResourceVersion, repaired.ResourceVersion)
+			}
+		})
+	}
+}
+
+func TestStaleDocuments(t *testing.T) {
+	docHashes := map[string]string{"apps.json": "sha256:x", "tags.json": "sha256:y"}
+	existing := map[string]string{"apps.json": "[]", "config.yml": "services: []", "notes.json": "{}"}
+	written := map[string]string{"config.yml": "sha256:z"}
+	// apps.json doesn't match, tags.json is missing, config.yml is no longer
+	// produced and notes.json was never written by the operator
+	want := []string{"apps.json", "tags.json", "config.yml"}
+	if got := staleDocuments(existing, docHashes, written); !slices.Equal(got, want) {
+		t.Errorf("staleDocuments() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	"k8s.io/client-go/util/csaupgrade"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fredericrous/duro-operator/pkg/config"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// newOutputObject returns an empty object of an output kind (see
+// Config.OutputKind), to read an output into.
+func newOutputObject(kind string) client.Object {
+	if kind == config.OutputKindSecret {
+		return &corev1.Secret{}
+	}
+	return &corev1.ConfigMap{}
+}
+
+// outputKindName is the Kubernetes kind of an output kind, for logs and
+// status.
+func outputKindName(kind string) string {
+	if kind == config.OutputKindSecret {
+		return "Secret"
+	}
+	return "ConfigMap"
+}
+
+// outputDocuments returns the documents of an output object. Secret data is
+// copied.
+func outputDocuments(obj client.Object) map[string]string {
+	switch o := obj.(type) {
+	case *corev1.Secret:
+		if o.Data == nil {
+			return nil
+		}
+		data := make(map[string]string, len(o.Data))
+		for key, value := range o.Data {
+			data[key] = string(value)
+		}
+		return data
+	case *corev1.ConfigMap:
+		return o.Data
+	}
+	return nil
+}
+
+// outputApplyConfiguration is the server-side apply configuration of an
+// output object of kind at key, holding data, immutable if asked to, with
+// owner as its controller when set.
+func outputApplyConfiguration(kind string, key types.NamespacedName, labels, annotations, data map[string]string, immutable bool,
+	owner *metav1ac.OwnerReferenceApplyConfiguration) runtime.ApplyConfiguration {
+	if kind == config.OutputKindSecret {
+		secret := corev1ac.Secret(key.Name, key.Namespace).
+			WithLabels(labels).
+			WithAnnotations(annotations).
+			WithType(corev1.SecretTypeOpaque)
+		secretData := make(map[string][]byte, len(data))
+		for k, v := range data {
+			secretData[k] = []byte(v)
+		}
+		secret.WithData(secretData)
+		if immutable {
+			secret.WithImmutable(true)
+		}
+		if owner != nil {
+			secret.WithOwnerReferences(owner)
+		}
+		return secret
+	}
+	cm := corev1ac.ConfigMap(key.Name, key.Namespace).
+		WithLabels(labels).
+		WithAnnotations(annotations).
+		WithData(data)
+	if immutable {
+		cm.WithImmutable(true)
+	}
+	if owner != nil {
+		cm.WithOwnerReferences(owner)
+	}
+	return cm
+}
+
+// adoptOutputFields moves the fields of obj the operator wrote with updates,
+// before outputs were server-side applied, over to its apply field manager,
+// so keys it no longer produces are removed by the next apply. It does
+// nothing once they are moved.
+func (r *DashboardAppReconciler) adoptOutputFields(ctx context.Context, obj client.Object) error {
+	manager := r.Config.Identity()
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(obj, sets.New(manager), manager)
+	if err != nil || patch == nil {
+		return err
+	}
+	logr.FromContextOrDiscard(ctx).Info("Moving the output's fields to server-side apply", "name", obj.GetName(), "namespace", obj.GetNamespace())
+	if err := r.Patch(ctx, obj, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+		return operrors.NewTransientError("failed to move output fields to server-side apply", err)
+	}
+	return nil
+}
+
+// deleteStaleConfigMap deletes the ConfigMap this instance wrote at key
+// before the output moved to a Secret, so the catalog doesn't stay readable
+// there. ConfigMaps of that name written by anyone else are left alone.
+func (r *DashboardAppReconciler) deleteStaleConfigMap(ctx context.Context, key types.NamespacedName) error {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if cm.Labels[instanceLabel] != r.Config.Identity() {
+		return nil
+	}
+	logr.FromContextOrDiscard(ctx).Info("Deleting the ConfigMap the output was written to before moving to a Secret", "name", key.Name, "namespace", key.Namespace)
+	if err := r.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+		return operrors.NewTransientError("failed to delete stale output ConfigMap", err)
+	}
+	return nil
+}
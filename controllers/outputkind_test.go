@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/config"
+	"github.com/fredericrous/duro-operator/pkg/hashing"
+)
+
+func TestUpdateAppsConfig_Secret(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.OutputKind = config.OutputKindSecret
+	key := types.NamespacedName{Name: cfg.DuroConfigMapName, Namespace: cfg.DuroNamespace}
+	stale := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: map[string]string{instanceLabel: cfg.Identity()}},
+		Data:       map[string]string{"apps.json": "[]"},
+	}
+	r := newFakeReconciler(t, cfg, stale)
+	result := &assembler.AssemblyResult{AppsJSON: `[{"name":"plex"}]`, CategoriesJSON: "[]", GroupCatalogJSON: "{}", TagsJSON: "[]"}
+	ctx := context.Background()
+
+	hash, err := r.updateAppsConfig(ctx, result, "trace", false)
+	if err != nil {
+		t.Fatalf("updateAppsConfig() error = %v", err)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, key, secret); err != nil {
+		t.Fatalf("output Secret not written: %v", err)
+	}
+	if got := string(secret.Data["apps.json"]); got != `[{"name":"plex"}]` {
+		t.Errorf("apps.json = %s, want the assembled apps", got)
+	}
+	if got := secret.Annotations["dashboard.homelab.io/config-hash"]; got != hash {
+		t.Errorf("config hash annotation = %q, want %q", got, hash)
+	}
+	// The ConfigMap written before the switch is deleted
+	if err := r.Get(ctx, key, &corev1.ConfigMap{}); !errors.IsNotFound(err) {
+		t.Errorf("stale ConfigMap lookup error = %v, want it deleted", err)
+	}
+
+	// Unchanged outputs are not written again
+	again, err := r.updateAppsConfig(ctx, result, "trace", false)
+	if err != nil || again != hash {
+		t.Fatalf("second updateAppsConfig() = %q, %v, want %q", again, err, hash)
+	}
+	unchanged := &corev1.Secret{}
+	if err := r.Get(ctx, key, unchanged); err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.ResourceVersion != secret.ResourceVersion {
+		t.Errorf("Secret rewritten: resource version %s, want %s", unchanged.ResourceVersion, secret.ResourceVersion)
+	}
+}
+
+func TestUpdateAppsConfig_ServerSideApply(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	key := types.NamespacedName{Name: cfg.DuroConfigMapName, Namespace: cfg.DuroNamespace}
+	c := fakeclient.NewClientBuilder().WithScheme(newFakeScheme(t)).WithReturnManagedFields().Build()
+	r := &DashboardAppReconciler{Client: c, Config: cfg}
+	ctx := context.Background()
+
+	// Written with updates, as before outputs were applied
+	legacy := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace,
+			Annotations: map[string]string{documentHashesAnnotation: hashing.EncodeSums(map[string]string{"config.yml": "x"})}},
+		Data: map[string]string{"config.yml": "services: []"},
+	}
+	if err := c.Create(ctx, legacy, client.FieldOwner(cfg.Identity())); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Labels = map[string]string{"team": "media"}
+	legacy.Data["notes.json"] = "{}"
+	if err := c.Update(ctx, legacy, client.FieldOwner("kubectl-edit")); err != nil {
+		t.Fatal(err)
+	}
+
+	result := &assembler.AssemblyResult{AppsJSON: `[{"name":"plex"}]`, CategoriesJSON: "[]", GroupCatalogJSON: "{}", TagsJSON: "[]"}
+	if _, err := r.updateAppsConfig(ctx, result, "trace", false); err != nil {
+		t.Fatalf("updateAppsConfig() error = %v", err)
+	}
+
+	// Keys written by others are left alone, documents no longer produced
+	// are removed
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, key, cm); err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["apps.json"] != `[{"name":"plex"}]` {
+		t.Errorf("apps.json = %s, want the assembled apps", cm.Data["apps.json"])
+	}
+	if _, ok := cm.Data["notes.json"]; !ok {
+		t.Error("notes.json written by someone else was removed")
+	}
+	if _, ok := cm.Data["config.yml"]; ok {
+		t.Error("config.yml no longer produced was kept")
+	}
+	if cm.Labels["team"] != "media" || cm.Labels[instanceLabel] != cfg.Identity() {
+		t.Errorf("labels = %v, want both the team and the instance label", cm.Labels)
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// stampedMetadataAnnotation records the configured labels and annotations
+// last stamped on an output object, so keys dropped from the configuration
+// can be removed without touching metadata added by others
+const stampedMetadataAnnotation = "dashboard.homelab.io/stamped-metadata"
+
+// stampedMetadata is the content of stampedMetadataAnnotation
+type stampedMetadata struct {
+	Labels      []string `json:"labels,omitempty"`
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// applyOutputMetadata stamps the configured output labels and annotations on
+// obj and removes those stamped before but no longer configured. It returns
+// whether obj changed.
+func (r *DashboardAppReconciler) applyOutputMetadata(obj metav1.Object) bool {
+	var previous stampedMetadata
+	_ = json.Unmarshal([]byte(obj.GetAnnotations()[stampedMetadataAnnotation]), &previous)
+
+	labels := stampKeys(obj.GetLabels(), r.Config.OutputLabels, previous.Labels)
+	annotations := stampKeys(obj.GetAnnotations(), r.Config.OutputAnnotations, previous.Annotations)
+
+	current := stampedMetadata{
+		Labels:      slices.Sorted(maps.Keys(r.Config.OutputLabels)),
+		Annotations: slices.Sorted(maps.Keys(r.Config.OutputAnnotations)),
+	}
+	if len(current.Labels) == 0 && len(current.Annotations) == 0 {
+		delete(annotations, stampedMetadataAnnotation)
+	} else {
+		data, _ := json.Marshal(current)
+		annotations[stampedMetadataAnnotation] = string(data)
+	}
+
+	changed := !maps.Equal(labels, obj.GetLabels()) || !maps.Equal(annotations, obj.GetAnnotations())
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	return changed
+}
+
+// stampKeys returns a copy of existing with wanted applied and the keys of
+// stamped that are no longer wanted removed.
+func stampKeys(existing, wanted map[string]string, stamped []string) map[string]string {
+	out := maps.Clone(existing)
+	if out == nil {
+		out = make(map[string]string, len(wanted))
+	}
+	for _, key := range stamped {
+		if _, ok := wanted[key]; !ok {
+			delete(out, key)
+		}
+	}
+	maps.Copy(out, wanted)
+	return out
+}
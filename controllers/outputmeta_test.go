@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+var _ = Describe("Output metadata", func() {
+	It("stamps configured metadata and removes keys no longer configured", func() {
+		cfg := config.NewDefaultConfig()
+		cfg.OutputLabels = map[string]string{"team": "platform"}
+		cfg.OutputAnnotations = map[string]string{"argocd.argoproj.io/compare-options": "IgnoreExtraneous"}
+		r := &DashboardAppReconciler{Config: cfg}
+
+		cm := &corev1.ConfigMap{}
+		cm.Labels = map[string]string{"added-by": "someone-else"}
+		Expect(r.applyOutputMetadata(cm)).To(BeTrue())
+		Expect(cm.Labels).To(HaveKeyWithValue("team", "platform"))
+		Expect(cm.Labels).To(HaveKeyWithValue("added-by", "someone-else"))
+		Expect(cm.Annotations).To(HaveKeyWithValue("argocd.argoproj.io/compare-options", "IgnoreExtraneous"))
+		Expect(r.applyOutputMetadata(cm)).To(BeFalse())
+
+		// Edits behind our back are reverted
+		cm.Labels["team"] = "media"
+		Expect(r.applyOutputMetadata(cm)).To(BeTrue())
+		Expect(cm.Labels).To(HaveKeyWithValue("team", "platform"))
+
+		cfg.OutputLabels = nil
+		cfg.OutputAnnotations = nil
+		Expect(r.applyOutputMetadata(cm)).To(BeTrue())
+		Expect(cm.Labels).NotTo(HaveKey("team"))
+		Expect(cm.Labels).To(HaveKey("added-by"))
+		Expect(cm.Annotations).NotTo(HaveKey("argocd.argoproj.io/compare-options"))
+		Expect(cm.Annotations).NotTo(HaveKey(stampedMetadataAnnotation))
+	})
+})
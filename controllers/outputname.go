@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/config"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/hashing"
+)
+
+const (
+	// outputNameLabel carries the configured output name on the versions of
+	// a hashed output (see Config.OutputNameHash), so they can be listed
+	outputNameLabel = "dashboard.homelab.io/output-name"
+
+	// currentOutputAnnotation publishes the name of the current version of
+	// a hashed output on the instance's OperatorOverview; it is the stable
+	// reference to the output, whose own name changes with every write
+	currentOutputAnnotation = "dashboard.homelab.io/current-output"
+)
+
+// hashedOutputName is the name of the version of the output named name
+// holding data, suffixed with its content hash like kustomize does. The
+// whole content is hashed whatever Config.HashScope says: the object is
+// immutable, so any change to it needs a new name.
+func hashedOutputName(name, alg string, data map[string]string) string {
+	return name + "-" + hashing.NameSuffix(hashing.Sum(alg, data))
+}
+
+// outputKey returns the key the output holding data is written to: the
+// configured name, or the hashed name when Config.OutputNameHash is set.
+func (r *DashboardAppReconciler) outputKey(data map[string]string) types.NamespacedName {
+	key := types.NamespacedName{Name: r.Config.DuroConfigMapName, Namespace: r.Config.DuroNamespace}
+	if r.Config.OutputNameHash {
+		key.Name = hashedOutputName(key.Name, r.Config.HashAlgorithm, data)
+	}
+	return key
+}
+
+// currentOutputKey returns the key of the output as last written, false if
+// a hashed output was not written yet.
+func (r *DashboardAppReconciler) currentOutputKey(ctx context.Context) (types.NamespacedName, bool, error) {
+	key := types.NamespacedName{Name: r.Config.DuroConfigMapName, Namespace: r.Config.DuroNamespace}
+	if !r.Config.OutputNameHash {
+		return key, true, nil
+	}
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	if err := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview); err != nil {
+		if errors.IsNotFound(err) {
+			return key, false, nil
+		}
+		return key, false, operrors.NewTransientError("failed to get OperatorOverview", err)
+	}
+	key.Name = overview.Annotations[currentOutputAnnotation]
+	return key, key.Name != "", nil
+}
+
+// publishOutputName records name as the current version of the hashed
+// output on the OperatorOverview, creating it if needed, and deletes the
+// versions older than the one it replaces: pods still reading the previous
+// version keep it while duro rolls out.
+func (r *DashboardAppReconciler) publishOutputName(ctx context.Context, name string) error {
+	log := logr.FromContextOrDiscard(ctx)
+
+	var previous string
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		overview := &dashboardv1alpha1.OperatorOverview{}
+		err := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview)
+		if errors.IsNotFound(err) {
+			overview.Name = r.Config.Identity()
+			err = r.Create(ctx, overview)
+		}
+		if err != nil {
+			return err
+		}
+		previous = overview.Annotations[currentOutputAnnotation]
+		if previous == name {
+			return nil
+		}
+		if overview.Annotations == nil {
+			overview.Annotations = map[string]string{}
+		}
+		overview.Annotations[currentOutputAnnotation] = name
+		return r.Update(ctx, overview)
+	})
+	if err != nil {
+		return operrors.NewTransientError("failed to publish the output name on the OperatorOverview", err)
+	}
+	if previous == name {
+		return nil
+	}
+
+	versions, err := r.outputVersions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, obj := range versions {
+		if obj.GetName() == name || obj.GetName() == previous {
+			continue
+		}
+		log.Info("Deleting an old version of the duro apps output", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return operrors.NewTransientError("failed to delete an old version of the output", err)
+		}
+	}
+	return nil
+}
+
+// outputVersions lists the versions of the hashed output written by this
+// instance.
+func (r *DashboardAppReconciler) outputVersions(ctx context.Context) ([]client.Object, error) {
+	opts := []client.ListOption{
+		client.InNamespace(r.Config.DuroNamespace),
+		client.MatchingLabels{outputNameLabel: r.Config.DuroConfigMapName, instanceLabel: r.Config.Identity()},
+	}
+	var versions []client.Object
+	if r.Config.OutputKind == config.OutputKindSecret {
+		list := &corev1.SecretList{}
+		if err := r.List(ctx, list, opts...); err != nil {
+			return nil, operrors.NewTransientError("failed to list output versions", err)
+		}
+		for i := range list.Items {
+			versions = append(versions, &list.Items[i])
+		}
+		return versions, nil
+	}
+	list := &corev1.ConfigMapList{}
+	if err := r.List(ctx, list, opts...); err != nil {
+		return nil, operrors.NewTransientError("failed to list output versions", err)
+	}
+	for i := range list.Items {
+		versions = append(versions, &list.Items[i])
+	}
+	return versions, nil
+}
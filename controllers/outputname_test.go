@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+func TestUpdateAppsConfig_HashedNames(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.OutputNameHash = true
+	r := newFakeReconciler(t, cfg)
+	ctx := context.Background()
+	write := func(apps string) string {
+		t.Helper()
+		result := &assembler.AssemblyResult{AppsJSON: apps, CategoriesJSON: "[]", GroupCatalogJSON: "{}", TagsJSON: "[]"}
+		if _, err := r.updateAppsConfig(ctx, result, "trace", false); err != nil {
+			t.Fatalf("updateAppsConfig() error = %v", err)
+		}
+		key, found, err := r.currentOutputKey(ctx)
+		if err != nil || !found {
+			t.Fatalf("currentOutputKey() = %v, %v, %v, want the output published", key, found, err)
+		}
+		return key.Name
+	}
+	versions := func() []string {
+		t.Helper()
+		list := &corev1.ConfigMapList{}
+		if err := r.List(ctx, list, client.MatchingLabels{outputNameLabel: cfg.DuroConfigMapName}); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, cm := range list.Items {
+			names = append(names, cm.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+	sorted := func(names ...string) []string {
+		slices.Sort(names)
+		return names
+	}
+
+	// Every version is written to its own immutable ConfigMap
+	first := write(`[{"name":"plex"}]`)
+	if !regexp.MustCompile(`^duro-apps-[0-9a-z]{10}$`).MatchString(first) {
+		t.Errorf("output name = %q, want duro-apps and a hash suffix", first)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Name: first, Namespace: cfg.DuroNamespace}, cm); err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["apps.json"] != `[{"name":"plex"}]` || cm.Immutable == nil || !*cm.Immutable {
+		t.Errorf("output = %v immutable %v, want the assembled apps in an immutable ConfigMap", cm.Data, cm.Immutable)
+	}
+
+	// The name holds while the content is unchanged
+	if got := write(`[{"name":"plex"}]`); got != first {
+		t.Errorf("unchanged output moved to %q, want %q", got, first)
+	}
+
+	// New content moves to a new name, keeping the previous version
+	second := write(`[{"name":"sonarr"}]`)
+	if second == first {
+		t.Error("new content kept the output name")
+	}
+	if got := versions(); !slices.Equal(got, sorted(first, second)) {
+		t.Errorf("versions = %v, want %v", got, sorted(first, second))
+	}
+
+	// Versions before the previous one are deleted
+	third := write(`[{"name":"radarr"}]`)
+	if got := versions(); !slices.Equal(got, sorted(second, third)) {
+		t.Errorf("versions = %v, want %v", got, sorted(second, third))
+	}
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+const (
+	// outputSizeWarningPercent is how full a ConfigMap the output may fill
+	// before every write warns about it
+	outputSizeWarningPercent = 90
+
+	// largestIconsListed is how many icons size warnings name
+	largestIconsListed = 3
+)
+
+// warnOutputSize logs and emits a warning Event on the OperatorOverview
+// when the output written to key takes over outputSizeWarningPercent of
+// what a ConfigMap holds, so the catalog can be slimmed down (e.g. with
+// Config.IconsKey) before writes start failing.
+func (r *DashboardAppReconciler) warnOutputSize(ctx context.Context, key types.NamespacedName, kind string, size int, result *assembler.AssemblyResult) {
+	if size*100 <= maxConfigMapBytes*outputSizeWarningPercent {
+		return
+	}
+	icons := largestIcons(result.IconBytes, largestIconsListed)
+	logr.FromContextOrDiscard(ctx).Info("Output nearing the ConfigMap size limit", "name", key.Name, "namespace", key.Namespace,
+		"bytes", size, "limit", maxConfigMapBytes, "largestIcons", icons)
+
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	if err := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview); err != nil {
+		return
+	}
+	msg := fmt.Sprintf("%s %s is %d bytes, %d%% of the %d bytes a ConfigMap can hold", outputKindName(kind), key, size,
+		size*100/maxConfigMapBytes, maxConfigMapBytes)
+	if icons != "" {
+		msg += "; largest icons: " + icons
+	}
+	r.Recorder.Event(overview, corev1.EventTypeWarning, "OutputNearSizeLimit", msg)
+}
+
+// largestIcons lists the n apps with the largest icons in iconBytes, with
+// their size, largest first.
+func largestIcons(iconBytes map[string]int, n int) string {
+	sources := slices.SortedFunc(maps.Keys(iconBytes), func(a, b string) int {
+		return cmp.Or(cmp.Compare(iconBytes[b], iconBytes[a]), strings.Compare(a, b))
+	})
+	if len(sources) > n {
+		sources = sources[:n]
+	}
+	listed := make([]string, len(sources))
+	for i, source := range sources {
+		listed[i] = fmt.Sprintf("%s (%d bytes)", source, iconBytes[source])
+	}
+	return strings.Join(listed, ", ")
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/hashing"
+	"github.com/fredericrous/duro-operator/pkg/history"
+	"github.com/fredericrous/duro-operator/pkg/redact"
+)
+
+// maxOverviewErrors bounds OperatorOverview status.lastErrors
+const maxOverviewErrors = 10
+
+// reconcileSummary collects what a reconcile did for the OperatorOverview
+type reconcileSummary struct {
+	apps       int
+	entries    int
+	categories int
+	configHash string
+
+	// delta is how the entries changed since the previous assembly; nil
+	// if there is none to compare with
+	delta *history.Delta
+
+	// resync is the overview's resync annotation acted upon by a full
+	// rebuild; empty if none was requested
+	resync string
+
+	// targets is the state of each output document after the write; nil
+	// if no write was attempted
+	targets []dashboardv1alpha1.OutputTargetStatus
+
+	// catalog is the apps document to publish in the status; nil when
+	// catalog publishing is disabled
+	catalog *dashboardv1alpha1.PublishedCatalog
+
+	// err is a failure handled by requeueing rather than returned
+	err error
+}
+
+// recordOverview writes the outcome of a reconcile to the OperatorOverview
+// of this instance, creating it if needed. Failures are logged and otherwise
+// ignored: the overview is informational.
+func (r *DashboardAppReconciler) recordOverview(ctx context.Context, traceID string, duration time.Duration, summary *reconcileSummary, reconcileErr error) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	if reconcileErr == nil {
+		reconcileErr = summary.err
+	}
+	now := metav1.Now()
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		overview := &dashboardv1alpha1.OperatorOverview{}
+		err := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview)
+		if errors.IsNotFound(err) {
+			overview.Name = r.Config.Identity()
+			err = r.Create(ctx, overview)
+		}
+		if err != nil {
+			return err
+		}
+
+		status := &overview.Status
+		status.LastReconcileTime = &now
+		status.LastDuration = &metav1.Duration{Duration: duration}
+		status.LastTraceID = traceID
+		status.ReconcileCount++
+		if reconcileErr != nil {
+			status.LastResult = dashboardv1alpha1.ReconcileFailed
+			status.LastErrors = append([]dashboardv1alpha1.ReconcileError{{
+				Time:    now,
+				TraceID: traceID,
+				Message: redact.String(reconcileErr.Error()),
+			}}, status.LastErrors...)
+			if len(status.LastErrors) > maxOverviewErrors {
+				status.LastErrors = status.LastErrors[:maxOverviewErrors]
+			}
+		} else {
+			status.LastResult = dashboardv1alpha1.ReconcileSucceeded
+			status.Apps = summary.apps
+			status.Entries = summary.entries
+			status.Categories = summary.categories
+			status.ConfigHash = summary.configHash
+			if summary.resync != "" {
+				status.ObservedResync = summary.resync
+			}
+			status.Catalog = summary.catalog
+		}
+		if summary.targets != nil {
+			status.Targets = mergeTargets(status.Targets, summary.targets, now)
+		}
+		return r.Status().Update(ctx, overview)
+	})
+	if err != nil {
+		log.V(1).Info("Failed to update OperatorOverview", "error", err.Error())
+	}
+}
+
+// recordHistory adds the outcome of a reconcile to the reconcile history.
+func (r *DashboardAppReconciler) recordHistory(req ctrl.Request, traceID string, start time.Time, summary *reconcileSummary, reconcileErr error) {
+	if r.History == nil {
+		return
+	}
+	if reconcileErr == nil {
+		reconcileErr = summary.err
+	}
+	rec := history.Reconcile{
+		Time:     start,
+		TraceID:  traceID,
+		Trigger:  req.String(),
+		Duration: metav1.Duration{Duration: time.Since(start)},
+		Apps:     summary.apps,
+		Entries:  summary.entries,
+		Delta:    summary.delta,
+	}
+	if reconcileErr != nil {
+		rec.Error = redact.String(reconcileErr.Error())
+	}
+	r.History.Add(rec)
+}
+
+// outputTargets describes each document of the apps ConfigMap after a write
+// of result that failed with writeErr (nil on success).
+func (r *DashboardAppReconciler) outputTargets(result *assembler.AssemblyResult, writeErr error) []dashboardv1alpha1.OutputTargetStatus {
+	// A format failing to render fails the write too, reported in writeErr
+	data, _ := outputData(result, r.Config.OutputFormats, r.Config.FormatVersions)
+	sums := hashing.SumEach(r.Config.HashAlgorithm, data)
+	keys := make([]string, 0, len(sums))
+	for key := range sums {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	name := r.outputKey(data).String()
+	targets := make([]dashboardv1alpha1.OutputTargetStatus, 0, len(keys))
+	for _, key := range keys {
+		t := dashboardv1alpha1.OutputTargetStatus{Kind: outputKindName(r.Config.OutputKind), Name: name, Key: key}
+		if writeErr != nil {
+			t.LastError = redact.String(writeErr.Error())
+		} else {
+			t.Hash = sums[key]
+		}
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+// mergeTargets carries the hash and write time of previously recorded targets
+// over to current: a failed target keeps its last good hash, and a target's
+// write time only moves when its hash changes.
+func mergeTargets(previous, current []dashboardv1alpha1.OutputTargetStatus, now metav1.Time) []dashboardv1alpha1.OutputTargetStatus {
+	for i := range current {
+		t := &current[i]
+		idx := slices.IndexFunc(previous, func(p dashboardv1alpha1.OutputTargetStatus) bool {
+			return p.Kind == t.Kind && p.Name == t.Name && p.Key == t.Key
+		})
+		var prev *dashboardv1alpha1.OutputTargetStatus
+		if idx >= 0 {
+			prev = &previous[idx]
+		}
+		switch {
+		case t.LastError != "":
+			if prev != nil {
+				t.Hash, t.LastWriteTime = prev.Hash, prev.LastWriteTime
+			}
+		case prev != nil && prev.Hash == t.Hash:
+			t.LastWriteTime = prev.LastWriteTime
+		default:
+			t.LastWriteTime = &now
+		}
+	}
+	return current
+}
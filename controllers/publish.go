@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/hashing"
+)
+
+// maxPublishedCatalogBytes bounds the compressed apps document published in
+// the OperatorOverview status, keeping the object well under the etcd limit
+const maxPublishedCatalogBytes = 512 << 10
+
+// publishedCatalog compresses the apps document of result for the
+// OperatorOverview status (see Config.CatalogStatus). A document too large
+// to publish is left out, its hash and size still recorded.
+func (r *DashboardAppReconciler) publishedCatalog(result *assembler.AssemblyResult) *dashboardv1alpha1.PublishedCatalog {
+	catalog := &dashboardv1alpha1.PublishedCatalog{
+		Hash: hashing.Sum(r.Config.HashAlgorithm, map[string]string{"apps.json": result.AppsJSON}),
+		Size: len(result.AppsJSON),
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(result.AppsJSON)); err != nil {
+		catalog.Omitted = fmt.Sprintf("compression failed: %v", err)
+		return catalog
+	}
+	if err := zw.Close(); err != nil {
+		catalog.Omitted = fmt.Sprintf("compression failed: %v", err)
+		return catalog
+	}
+	if buf.Len() > maxPublishedCatalogBytes {
+		catalog.Omitted = fmt.Sprintf("compressed document is %d bytes, over the %d bytes limit", buf.Len(), maxPublishedCatalogBytes)
+		return catalog
+	}
+	catalog.Data = buf.Bytes()
+	return catalog
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"math/rand/v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+var _ = Describe("Published catalog", func() {
+	It("compresses the apps document and leaves out oversized ones", func() {
+		r := &DashboardAppReconciler{Config: config.NewDefaultConfig()}
+
+		apps := `{"apps":[{"name":"Grafana"}]}`
+		catalog := r.publishedCatalog(&assembler.AssemblyResult{AppsJSON: apps})
+		Expect(catalog.Omitted).To(BeEmpty())
+		Expect(catalog.Size).To(Equal(len(apps)))
+		Expect(catalog.Hash).NotTo(BeEmpty())
+		zr, err := gzip.NewReader(bytes.NewReader(catalog.Data))
+		Expect(err).NotTo(HaveOccurred())
+		data, err := io.ReadAll(zr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(apps))
+
+		// Random data does not compress under the limit
+		noise := make([]byte, 2*maxPublishedCatalogBytes)
+		_, _ = rand.NewChaCha8([32]byte{}).Read(noise)
+		large := base64.StdEncoding.EncodeToString(noise)
+		catalog = r.publishedCatalog(&assembler.AssemblyResult{AppsJSON: large})
+		Expect(catalog.Data).To(BeEmpty())
+		Expect(catalog.Omitted).To(ContainSubstring("over the"))
+		Expect(catalog.Size).To(Equal(len(large)))
+	})
+})
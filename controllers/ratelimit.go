@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/config"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// defaultRetryDelay is the requeue delay after a failed write that carries
+// no retry hint
+const defaultRetryDelay = config.DefaultRetryDelay
+
+// writeDeferredError is returned when a write to an output target is held
+// back by the minimum write interval
+type writeDeferredError struct {
+	target string
+	wait   time.Duration
+}
+
+func (e *writeDeferredError) Error() string {
+	return fmt.Sprintf("write to %s deferred for %s by the minimum write interval", e.target, e.wait)
+}
+
+// minWriteInterval returns the minimum write interval of the target written
+// for owner: the Dashboard's own spec.minWriteInterval when it sets one, the
+// operator's otherwise.
+func (r *DashboardAppReconciler) minWriteInterval(owner client.Object) time.Duration {
+	if dash, ok := owner.(*dashboardv1alpha1.Dashboard); ok && dash.Spec.MinWriteInterval != nil {
+		return dash.Spec.MinWriteInterval.Duration
+	}
+	return r.Config.MinWriteInterval
+}
+
+// writeDelay returns how long to wait before target may be written again,
+// based on its last-write annotation. The annotation lives on the target so
+// the limit holds across operator restarts and replicas.
+func writeDelay(target client.Object, interval time.Duration, now time.Time) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	lastWrite, err := time.Parse(time.RFC3339, target.GetAnnotations()[lastWriteAnnotation])
+	if err != nil {
+		return 0
+	}
+	return max(lastWrite.Add(interval).Sub(now), 0)
+}
+
+// retryDelay returns how long to wait before retrying a failed write: the
+// target's own hint when it sent one (a RetryAfterError, or an API server
+// throttling response), otherwise defaultRetryDelay.
+func retryDelay(err error) time.Duration {
+	if after, ok := operrors.RetryAfter(err); ok {
+		return max(after, time.Second)
+	}
+	if secs, ok := apierrors.SuggestsClientDelay(err); ok {
+		return max(time.Duration(secs)*time.Second, time.Second)
+	}
+	return defaultRetryDelay
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/config"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+func TestWriteDelay(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	written := func(ago time.Duration) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{lastWriteAnnotation: now.Add(-ago).Format(time.RFC3339)}}}
+	}
+	tests := []struct {
+		name     string
+		target   *corev1.ConfigMap
+		interval time.Duration
+		want     time.Duration
+	}{
+		{"limit off", written(time.Second), 0, 0},
+		{"never written", &corev1.ConfigMap{}, time.Minute, 0},
+		{"unreadable annotation", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{lastWriteAnnotation: "yesterday"}}}, time.Minute, 0},
+		{"written recently", written(20 * time.Second), time.Minute, 40 * time.Second},
+		{"interval over", written(2 * time.Minute), time.Minute, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := writeDelay(tt.target, tt.interval, now); got != tt.want {
+				t.Errorf("writeDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinWriteInterval(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.MinWriteInterval = time.Minute
+	r := &DashboardAppReconciler{Config: cfg}
+	override := &dashboardv1alpha1.Dashboard{Spec: dashboardv1alpha1.DashboardSpec{MinWriteInterval: &metav1.Duration{Duration: 10 * time.Minute}}}
+	off := &dashboardv1alpha1.Dashboard{Spec: dashboardv1alpha1.DashboardSpec{MinWriteInterval: &metav1.Duration{}}}
+
+	tests := []struct {
+		name  string
+		owner client.Object
+		want  time.Duration
+	}{
+		{"main output", nil, time.Minute},
+		{"dashboard", &dashboardv1alpha1.Dashboard{}, time.Minute},
+		{"dashboard override", override, 10 * time.Minute},
+		{"dashboard turning it off", off, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.minWriteInterval(tt.owner); got != tt.want {
+				t.Errorf("minWriteInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateAppsConfig_Deferred(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.MinWriteInterval = time.Hour
+	r := newFakeReconciler(t, cfg)
+	output := func(apps string) *assembler.AssemblyResult {
+		return &assembler.AssemblyResult{AppsJSON: apps, CategoriesJSON: "[]", GroupCatalogJSON: "{}", TagsJSON: "[]"}
+	}
+
+	served, err := r.updateAppsConfig(context.Background(), output(`[{"name":"plex"}]`), "trace", false)
+	if err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	hash, err := r.updateAppsConfig(context.Background(), output(`[{"name":"sonarr"}]`), "trace", false)
+	var deferred *writeDeferredError
+	if !errors.As(err, &deferred) || deferred.wait <= 0 {
+		t.Fatalf("second write error = %v, want it deferred", err)
+	}
+	if hash != served {
+		t.Errorf("deferred write hash = %q, want the served output's %q", hash, served)
+	}
+	if _, err := r.updateAppsConfig(context.Background(), output(`[{"name":"sonarr"}]`), "trace", true); err != nil {
+		t.Errorf("forced write error = %v, want it written right away", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{"no hint", errors.New("connection refused"), defaultRetryDelay},
+		{"retry-after", operrors.NewRetryAfterError(42*time.Second, errors.New("429")), 42 * time.Second},
+		{"wrapped retry-after", fmt.Errorf("write failed: %w", operrors.NewRetryAfterError(time.Minute, errors.New("503"))), time.Minute},
+		{"retry-after below a second", operrors.NewRetryAfterError(0, errors.New("429")), time.Second},
+		{"API server throttling", apierrors.NewTooManyRequests("slow down", 7), 7 * time.Second},
+		{"API server conflict", apierrors.NewConflict(configMaps, "duro-apps", errors.New("modified")), defaultRetryDelay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDelay(tt.err); got != tt.want {
+				t.Errorf("retryDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/groups"
+)
+
+// applyRBACGroups fills in spec.groups of apps that leave it empty with the
+// groups bound by RoleBindings in their namespace, so their visibility tracks
+// cluster access. Only the in-memory copies are changed; apps that set
+// groups, or access groups granting access, keep them.
+func (r *DashboardAppReconciler) applyRBACGroups(ctx context.Context, apps []dashboardv1alpha1.DashboardApp) error {
+	if !r.Config.RBACGroups {
+		return nil
+	}
+	log := logr.FromContextOrDiscard(ctx)
+	byNamespace := make(map[string][]string)
+	for i := range apps {
+		app := &apps[i]
+		if app.HasAccessGroups() {
+			continue
+		}
+		bound, ok := byNamespace[app.Namespace]
+		if !ok {
+			var bindings rbacv1.RoleBindingList
+			if err := r.List(ctx, &bindings, client.InNamespace(app.Namespace)); err != nil {
+				return operrors.NewTransientError("failed to list RoleBindings", err)
+			}
+			bound = groups.FromRoleBindings(bindings.Items)
+			byNamespace[app.Namespace] = bound
+		}
+		if len(bound) == 0 {
+			log.V(1).Info("No RoleBinding grants a group access to the app's namespace", "app", app.Name, "namespace", app.Namespace)
+		}
+		app.Spec.Groups = bound
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+func TestApplyRBACGroups(t *testing.T) {
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "viewers", Namespace: "media"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+		Subjects: []rbacv1.Subject{
+			{Kind: rbacv1.GroupKind, Name: "family"},
+			{Kind: rbacv1.GroupKind, Name: "system:authenticated"},
+		},
+	}
+	cfg := config.NewDefaultConfig()
+	cfg.RBACGroups = true
+	r := newFakeReconciler(t, cfg, binding)
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "sonarr", Namespace: "media"}, Spec: dashboardv1alpha1.DashboardAppSpec{Groups: []string{"admins"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}},
+	}
+	if err := r.applyRBACGroups(context.Background(), apps); err != nil {
+		t.Fatalf("applyRBACGroups() error = %v", err)
+	}
+	// Apps with groups of their own keep them
+	want := [][]string{{"family"}, {"admins"}, nil}
+	for i, app := range apps {
+		if !slices.Equal(app.Spec.Groups, want[i]) {
+			t.Errorf("%s groups = %v, want %v", app.Name, app.Spec.Groups, want[i])
+		}
+	}
+}
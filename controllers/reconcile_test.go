@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+func TestReconcile_Bookmarks(t *testing.T) {
+	newBookmark := func(name, env string) *dashboardv1alpha1.DashboardBookmark {
+		return &dashboardv1alpha1.DashboardBookmark{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "docs", Labels: map[string]string{"env": env}},
+			Spec:       dashboardv1alpha1.DashboardBookmarkSpec{URL: "https://" + name + ".example.com"},
+		}
+	}
+	cfg := config.NewDefaultConfig()
+	r := newFakeReconciler(t, cfg, newBookmark("wiki", "home"), newBookmark("runbooks", "lab"))
+	r.selector = labels.SelectorFromSet(labels.Set{"env": "home"})
+
+	// Bookmarks are written even when there is no app yet, and only those
+	// of the instance
+	if _, err := r.reconcile(context.Background(), "trace", &reconcileSummary{}); err != nil {
+		t.Fatalf("reconcile() error = %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: cfg.DuroConfigMapName, Namespace: cfg.DuroNamespace}, cm); err != nil {
+		t.Fatalf("output not written: %v", err)
+	}
+	bookmarks := cm.Data["bookmarks.json"]
+	if !strings.Contains(bookmarks, "wiki.example.com") || strings.Contains(bookmarks, "runbooks") {
+		t.Errorf("bookmarks.json = %s, want only the wiki of the instance", bookmarks)
+	}
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+// applyRemovalGrace splits off deleted apps. Every app carries the removal
+// finalizer, so a deleted app is still listed when its deletion is
+// reconciled: it is kept in the catalog, marked removed, until the removal
+// grace period has passed since its deletion, then returned as removed so
+// its finalizer is released once the catalog without it is written (see
+// releaseRemoved). It returns the apps to assemble, the removed apps and the
+// earliest upcoming removal (zero if none).
+func (r *DashboardAppReconciler) applyRemovalGrace(ctx context.Context, apps []dashboardv1alpha1.DashboardApp, now time.Time) ([]dashboardv1alpha1.DashboardApp, []dashboardv1alpha1.DashboardApp, time.Time) {
+	log := logr.FromContextOrDiscard(ctx)
+	grace := r.Config.RemovalGracePeriod
+
+	kept := make([]dashboardv1alpha1.DashboardApp, 0, len(apps))
+	var removed []dashboardv1alpha1.DashboardApp
+	var next time.Time
+	for i := range apps {
+		app := &apps[i]
+		held := controllerutil.ContainsFinalizer(app, dashboardv1alpha1.RemovalFinalizer)
+
+		switch {
+		case app.DeletionTimestamp.IsZero():
+			if !held {
+				orig := app.DeepCopy()
+				controllerutil.AddFinalizer(app, dashboardv1alpha1.RemovalFinalizer)
+				if err := r.Patch(ctx, app, client.MergeFrom(orig)); err != nil {
+					log.Error(err, "Failed to add removal finalizer", "app", client.ObjectKeyFromObject(app))
+				}
+			}
+		case !held:
+			// Held by someone else's finalizer; ours is already released
+			continue
+		case grace > 0 && now.Before(app.DeletionTimestamp.Add(grace)):
+			next = earliest(next, app.DeletionTimestamp.Add(grace))
+		default:
+			removed = append(removed, *app)
+			continue
+		}
+		kept = append(kept, *app)
+	}
+	return kept, removed, next
+}
+
+// releaseRemoved releases the removal finalizer of apps whose entries are no
+// longer in the written catalog, recording an event on each. Failures are
+// logged; the finalizer is released on a later reconcile.
+func (r *DashboardAppReconciler) releaseRemoved(ctx context.Context, removed []dashboardv1alpha1.DashboardApp) {
+	log := logr.FromContextOrDiscard(ctx)
+	for i := range removed {
+		app := &removed[i]
+		orig := app.DeepCopy()
+		controllerutil.RemoveFinalizer(app, dashboardv1alpha1.RemovalFinalizer)
+		if err := r.Patch(ctx, app, client.MergeFrom(orig)); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				log.Error(err, "Failed to release removal finalizer", "app", client.ObjectKeyFromObject(app))
+			}
+			continue
+		}
+		log.Info("Removed DashboardApp from the dashboard", "app", client.ObjectKeyFromObject(app))
+		r.Recorder.Event(app, corev1.EventTypeNormal, "Removed", "Entry removed from the dashboard")
+	}
+}
+
+// releaseFinalizer releases the removal finalizer of an app leaving the
+// instance's scope, which is not in the catalog to be removed from.
+func (r *DashboardAppReconciler) releaseFinalizer(ctx context.Context, app *dashboardv1alpha1.DashboardApp) error {
+	if !controllerutil.ContainsFinalizer(app, dashboardv1alpha1.RemovalFinalizer) {
+		return nil
+	}
+	orig := app.DeepCopy()
+	controllerutil.RemoveFinalizer(app, dashboardv1alpha1.RemovalFinalizer)
+	if err := r.Patch(ctx, app, client.MergeFrom(orig)); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	logr.FromContextOrDiscard(ctx).V(1).Info("Released removal finalizer of app out of scope", "app", client.ObjectKeyFromObject(app))
+	return nil
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func TestReleaseFinalizer(t *testing.T) {
+	app := &dashboardv1alpha1.DashboardApp{ObjectMeta: metav1.ObjectMeta{
+		Name: "plex", Namespace: "media", Labels: map[string]string{"env": "test"},
+		Finalizers: []string{dashboardv1alpha1.RemovalFinalizer},
+	}}
+	r := newFakeReconciler(t, nil, app)
+	sel, err := labels.Parse("env=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Apps out of the selector are still reconciled while they hold the finalizer
+	if !selectedPredicate(sel).Update(event.UpdateEvent{ObjectOld: app, ObjectNew: app}) {
+		t.Error("app holding the finalizer out of the selector is not reconciled")
+	}
+
+	ctx := context.Background()
+	if err := r.releaseFinalizer(ctx, app.DeepCopy()); err != nil {
+		t.Fatalf("releaseFinalizer() error = %v", err)
+	}
+	released := &dashboardv1alpha1.DashboardApp{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(app), released); err != nil {
+		t.Fatal(err)
+	}
+	if len(released.Finalizers) > 0 {
+		t.Errorf("finalizers = %v, want none", released.Finalizers)
+	}
+
+	// and left alone once released
+	if selectedPredicate(sel).Update(event.UpdateEvent{ObjectOld: released, ObjectNew: released}) {
+		t.Error("released app out of the selector is reconciled")
+	}
+}
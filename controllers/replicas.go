@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/metrics"
+)
+
+const (
+	// leaderVersionAnnotation holds the operator version of the leader on
+	// the OperatorOverview
+	leaderVersionAnnotation = "dashboard.homelab.io/leader-version"
+
+	// leaderConfigAnnotation holds the config fingerprint of the leader on
+	// the OperatorOverview
+	leaderConfigAnnotation = "dashboard.homelab.io/leader-config-fingerprint"
+)
+
+// ReplicaSkewChecker detects replicas running a different version or
+// configuration than the leader, which would render a different catalog
+// once they take over. The leader publishes its identity on the
+// OperatorOverview; every other replica compares its own against it at
+// startup and then periodically.
+type ReplicaSkewChecker struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+
+	// OverviewName is the OperatorOverview of this instance
+	OverviewName string
+
+	// Version and ConfigFingerprint identify this replica
+	Version           string
+	ConfigFingerprint string
+
+	// Interval is how often the check runs
+	Interval time.Duration
+
+	// Elected is closed once this replica leads (mgr.Elected()); nil
+	// means it always leads
+	Elected <-chan struct{}
+
+	// skewed remembers the last result so events fire on transitions only
+	skewed map[string]bool
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable: every
+// replica runs the check.
+func (c *ReplicaSkewChecker) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs the check until ctx is done.
+func (c *ReplicaSkewChecker) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		if err := c.check(ctx); err != nil {
+			c.Log.V(1).Info("Replica skew check failed", "error", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-c.Elected:
+			// Publish as soon as this replica takes over
+			c.Elected = nil
+		}
+	}
+}
+
+// check publishes this replica's identity if it leads, and otherwise
+// compares it with the leader's.
+func (c *ReplicaSkewChecker) check(ctx context.Context) error {
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	if err := c.Get(ctx, client.ObjectKey{Name: c.OverviewName}, overview); err != nil {
+		// Created by the leader's first reconcile
+		return client.IgnoreNotFound(err)
+	}
+
+	if c.leading() {
+		c.report(overview, nil)
+		if overview.Annotations[leaderVersionAnnotation] == c.Version &&
+			overview.Annotations[leaderConfigAnnotation] == c.ConfigFingerprint {
+			return nil
+		}
+		if overview.Annotations == nil {
+			overview.Annotations = map[string]string{}
+		}
+		overview.Annotations[leaderVersionAnnotation] = c.Version
+		overview.Annotations[leaderConfigAnnotation] = c.ConfigFingerprint
+		if err := c.Update(ctx, overview); err != nil && !errors.IsConflict(err) {
+			return err
+		}
+		return nil
+	}
+
+	leader := overview.Annotations
+	if leader[leaderVersionAnnotation] == "" {
+		// No leader has published yet
+		return nil
+	}
+	skew := map[string]string{}
+	if v := leader[leaderVersionAnnotation]; v != c.Version {
+		skew["version"] = fmt.Sprintf("version %s, leader runs %s", c.Version, v)
+	}
+	if f := leader[leaderConfigAnnotation]; f != c.ConfigFingerprint {
+		skew["config"] = fmt.Sprintf("config fingerprint %s, leader has %s", c.ConfigFingerprint, f)
+	}
+	c.report(overview, skew)
+	return nil
+}
+
+// report updates the skew metric and emits an event for each kind of skew
+// that newly appeared.
+func (c *ReplicaSkewChecker) report(overview *dashboardv1alpha1.OperatorOverview, skew map[string]string) {
+	if c.skewed == nil {
+		c.skewed = map[string]bool{}
+	}
+	for _, kind := range []string{"version", "config"} {
+		msg, found := skew[kind]
+		if found {
+			metrics.ReplicaSkew.WithLabelValues(kind).Set(1)
+		} else {
+			metrics.ReplicaSkew.WithLabelValues(kind).Set(0)
+		}
+		if found && !c.skewed[kind] {
+			c.Log.Info("Replica differs from the leader", "kind", kind, "detail", msg)
+			c.Recorder.Event(overview, corev1.EventTypeWarning, "ReplicaSkew", "Replica runs "+msg)
+		}
+		c.skewed[kind] = found
+	}
+}
+
+func (c *ReplicaSkewChecker) leading() bool {
+	if c.Elected == nil {
+		return true
+	}
+	select {
+	case <-c.Elected:
+		return true
+	default:
+		return false
+	}
+}
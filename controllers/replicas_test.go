@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+var _ = Describe("Replica skew checker", func() {
+	It("publishes the leader's identity and flags replicas that differ", func() {
+		overview := &dashboardv1alpha1.OperatorOverview{}
+		overview.Name = dashboardv1alpha1.OperatorOverviewName
+		err := k8sClient.Create(ctx, overview)
+		Expect(client.IgnoreAlreadyExists(err)).To(Succeed())
+
+		elected := make(chan struct{})
+		close(elected)
+		leader := &ReplicaSkewChecker{
+			Client: k8sClient, Log: logf.Log, Recorder: record.NewFakeRecorder(10),
+			OverviewName: dashboardv1alpha1.OperatorOverviewName,
+			Version:      "v1.2.0", ConfigFingerprint: "abc", Elected: elected,
+		}
+		Expect(leader.check(ctx)).To(Succeed())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: dashboardv1alpha1.OperatorOverviewName}, overview)).To(Succeed())
+		Expect(overview.Annotations).To(HaveKeyWithValue(leaderVersionAnnotation, "v1.2.0"))
+		Expect(overview.Annotations).To(HaveKeyWithValue(leaderConfigAnnotation, "abc"))
+
+		recorder := record.NewFakeRecorder(10)
+		follower := &ReplicaSkewChecker{
+			Client: k8sClient, Log: logf.Log, Recorder: recorder,
+			OverviewName: dashboardv1alpha1.OperatorOverviewName,
+			Version:      "v1.2.0", ConfigFingerprint: "def", Elected: make(chan struct{}),
+		}
+		Expect(follower.check(ctx)).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring("ReplicaSkew")))
+
+		// Events fire on transitions only
+		Expect(follower.check(ctx)).To(Succeed())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+})
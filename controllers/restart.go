@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// podTemplateHashAnnotation carries the output hash on the pod template of
+// the duro Deployment (see Config.RestartDeployment)
+const podTemplateHashAnnotation = "dashboard.homelab.io/config-hash"
+
+// restartDuro rolls the duro Deployment out again when the output changed
+// since it was last rolled out, by stamping the output hash on its pod
+// template. Stamping the hash rather than a time keeps it idempotent: pods
+// already running with the hash are left alone, after an operator restart
+// too.
+func (r *DashboardAppReconciler) restartDuro(ctx context.Context, configHash string) error {
+	if r.Config.RestartDeployment == "" || configHash == r.restartedFor {
+		return nil
+	}
+	key := r.Config.RestartDeploymentKey()
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"annotations": map[string]string{podTemplateHashAnnotation: configHash},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	if err := r.Patch(ctx, deployment, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return operrors.NewTransientError(fmt.Sprintf("failed to roll out Deployment %s", key), err)
+	}
+	logr.FromContextOrDiscard(ctx).Info("Stamped the output hash on the duro Deployment", "deployment", key, "hash", configHash)
+	r.restartedFor = configHash
+	return nil
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+func TestRestartDuro(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.RestartDeployment = "duro"
+	duro := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "duro", Namespace: cfg.DuroNamespace}}
+	r := newFakeReconciler(t, cfg, duro)
+	ctx := context.Background()
+	stamped := func() *appsv1.Deployment {
+		t.Helper()
+		d := &appsv1.Deployment{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(duro), d); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	if err := r.restartDuro(ctx, "abc"); err != nil {
+		t.Fatalf("restartDuro() error = %v", err)
+	}
+	first := stamped()
+	if got := first.Spec.Template.Annotations[podTemplateHashAnnotation]; got != "abc" {
+		t.Errorf("pod template hash = %q, want abc", got)
+	}
+
+	// The Deployment is left alone while the output is unchanged
+	if err := r.restartDuro(ctx, "abc"); err != nil {
+		t.Fatalf("restartDuro() error = %v", err)
+	}
+	if got := stamped().ResourceVersion; got != first.ResourceVersion {
+		t.Errorf("Deployment patched again: resource version %s, want %s", got, first.ResourceVersion)
+	}
+
+	// and rolled out again on a new output
+	if err := r.restartDuro(ctx, "def"); err != nil {
+		t.Fatalf("restartDuro() error = %v", err)
+	}
+	if got := stamped().Spec.Template.Annotations[podTemplateHashAnnotation]; got != "def" {
+		t.Errorf("pod template hash = %q, want def", got)
+	}
+}
+
+func TestRestartDuro_MissingDeployment(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.RestartDeployment = "apps/duro"
+	r := newFakeReconciler(t, cfg)
+	if err := r.restartDuro(context.Background(), "abc"); err == nil {
+		t.Error("restartDuro() succeeded without a Deployment")
+	}
+	if r.restartedFor != "" {
+		t.Errorf("restartedFor = %q, want the restart retried", r.restartedFor)
+	}
+}
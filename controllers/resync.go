@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+// resyncRequestedPredicate passes updates changing the resync annotation.
+func resyncRequestedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetAnnotations()[dashboardv1alpha1.ResyncAnnotation] !=
+				e.ObjectNew.GetAnnotations()[dashboardv1alpha1.ResyncAnnotation]
+		},
+	}
+}
+
+// pendingRebuild returns the resync annotation of the instance's
+// OperatorOverview if a full rebuild was requested and not done yet.
+func (r *DashboardAppReconciler) pendingRebuild(ctx context.Context) (string, error) {
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	if err := r.Get(ctx, client.ObjectKey{Name: r.Config.Identity()}, overview); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if v := overview.Annotations[dashboardv1alpha1.ResyncAnnotation]; v != "" && v != overview.Status.ObservedResync {
+		return v, nil
+	}
+	return "", nil
+}
+
+// resyncApp re-validates an app whose resync annotation changed, or every
+// app on a full rebuild, recording the request as observed. It returns
+// whether the status changed.
+func (r *DashboardAppReconciler) resyncApp(app *dashboardv1alpha1.DashboardApp, rebuild bool) bool {
+	requested := app.Annotations[dashboardv1alpha1.ResyncAnnotation]
+	own := requested != app.Status.ObservedResync
+	if !own && !rebuild {
+		return false
+	}
+	if own {
+		// A full rebuild forgets every icon up front, see forgetIcons
+		r.forgetIcons(*app)
+	}
+	violations := r.Assembler.Violations(app)
+	cond := conformingCondition(violations)
+	cond.ObservedGeneration = app.Generation
+	meta.SetStatusCondition(&app.Status.Conditions, cond)
+	app.Status.ObservedResync = requested
+	// A full rebuild touches every app; only report resyncs asked for
+	switch {
+	case !own:
+	case len(violations) > 0:
+		r.Recorder.Event(app, corev1.EventTypeWarning, "Resynced", "Resynced, app breaks the current rules: "+cond.Message)
+	default:
+		r.Recorder.Event(app, corev1.EventTypeNormal, "Resynced", "Resynced and re-validated")
+	}
+	return true
+}
+
+// forgetIcons drops the cached icons of apps, so that a resync fetches them
+// again rather than serving a copy up to the icon refresh interval old.
+func (r *DashboardAppReconciler) forgetIcons(apps ...dashboardv1alpha1.DashboardApp) {
+	forgetter, ok := r.Assembler.IconResolver.(assembler.IconForgetter)
+	if !ok {
+		return
+	}
+	var urls []string
+	for i := range apps {
+		if u := r.Assembler.IconURL(&apps[i]); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	forgetter.Forget(urls...)
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func TestResyncApp(t *testing.T) {
+	newApp := func(name, resync string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "media", Annotations: map[string]string{dashboardv1alpha1.ResyncAnnotation: resync}},
+			Spec:       dashboardv1alpha1.DashboardAppSpec{Name: name, IconURL: "https://icons.lan/" + name + ".svg"},
+		}
+	}
+	resolver := &forgettingResolver{}
+	r := newFakeReconciler(t, nil)
+	r.Assembler.IconResolver = resolver
+
+	// The icon of an app asking for a resync is fetched again
+	plex := newApp("plex", "1")
+	if !r.resyncApp(&plex, false) {
+		t.Error("resyncApp() = false for a new resync request")
+	}
+	if want := []string{"https://icons.lan/plex.svg"}; !slices.Equal(resolver.forgotten, want) {
+		t.Errorf("forgotten = %v, want %v", resolver.forgotten, want)
+	}
+
+	// but not once the resync is done
+	resolver.forgotten = nil
+	if r.resyncApp(&plex, false) {
+		t.Error("resyncApp() = true for a resync already done")
+	}
+	if len(resolver.forgotten) > 0 {
+		t.Errorf("forgotten = %v, want none", resolver.forgotten)
+	}
+
+	// Every icon is fetched again on a full rebuild
+	r.forgetIcons(plex, newApp("sonarr", ""))
+	slices.Sort(resolver.forgotten)
+	if want := []string{"https://icons.lan/plex.svg", "https://icons.lan/sonarr.svg"}; !slices.Equal(resolver.forgotten, want) {
+		t.Errorf("forgotten = %v, want %v", resolver.forgotten, want)
+	}
+}
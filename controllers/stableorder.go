@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// entryOrderAnnotation records the entry IDs of each category in the order
+// they were written, as a JSON object keyed by category (see
+// Config.StableOrder). It lives on the output so the order survives
+// operator restarts.
+const entryOrderAnnotation = "dashboard.homelab.io/entry-order"
+
+// previousOrder reads the entry order recorded by the last write of the
+// output, nil when stable ordering is off or nothing was recorded yet.
+func (r *DashboardAppReconciler) previousOrder(ctx context.Context) (map[string][]string, error) {
+	if !r.Config.StableOrder {
+		return nil, nil
+	}
+	log := logr.FromContextOrDiscard(ctx)
+
+	key, found, err := r.currentOutputKey(ctx)
+	if err != nil || !found {
+		return nil, err
+	}
+	output := newOutputObject(r.Config.OutputKind)
+	err = r.Get(ctx, key, output)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, operrors.NewTransientError("failed to get duro apps output", err)
+	}
+	raw, ok := output.GetAnnotations()[entryOrderAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var order map[string][]string
+	if err := json.Unmarshal([]byte(raw), &order); err != nil {
+		// Ordering by name until the next write records a valid order
+		log.Info("Ignoring invalid entry order annotation", "error", err.Error())
+		return nil, nil
+	}
+	return order, nil
+}
+
+// stampEntryOrder records the entry order of result on an output object's
+// annotations, or removes it when stable ordering is off.
+func (r *DashboardAppReconciler) stampEntryOrder(annotations map[string]string, result *assembler.AssemblyResult) {
+	if !r.Config.StableOrder {
+		delete(annotations, entryOrderAnnotation)
+		return
+	}
+	data, _ := json.Marshal(assembler.EntryOrder(result.Entries))
+	annotations[entryOrderAnnotation] = string(data)
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/usage"
+)
+
+// statusInputs is what a reconcile derives an app's status from besides the
+// app itself: its entry in the output and the assembly's findings about it
+type statusInputs struct {
+	Entry       *assembler.AppEntry          `json:"entry,omitempty"`
+	Strict      string                       `json:"strict,omitempty"`
+	Dangling    string                       `json:"dangling,omitempty"`
+	Duplicate   []string                     `json:"duplicate,omitempty"`
+	Priorities  bool                         `json:"priorities,omitempty"`
+	Collision   *assembler.PriorityCollision `json:"collision,omitempty"`
+	Suggestion  int                          `json:"suggestion,omitempty"`
+	Violations  []string                     `json:"violations,omitempty"`
+	IconFailure string                       `json:"iconFailure,omitempty"`
+	Inferred    string                       `json:"inferred,omitempty"`
+	Usage       *dashboardv1alpha1.AppUsage  `json:"usage,omitempty"`
+}
+
+// statusFingerprint fingerprints the status inputs of app, whose entry is
+// nil when the app is not in the output. report and counts are nil when
+// priority analysis and usage import are off.
+func statusFingerprint(app *dashboardv1alpha1.DashboardApp, entry *assembler.AppEntry, result *assembler.AssemblyResult,
+	report *assembler.PriorityReport, counts usage.Counts, ranks map[string]int, inferred string) string {
+	source := app.Namespace + "/" + app.Name
+	in := statusInputs{
+		Entry:       entry,
+		Dangling:    result.DanglingCategories[source],
+		Priorities:  report != nil,
+		Violations:  result.Violations[source],
+		IconFailure: result.IconFailures[source],
+		Inferred:    inferred,
+	}
+	if failure, ok := assembler.StrictFailureFor(result.StrictFailures, app.Namespace); ok {
+		in.Strict = failure.Message()
+	}
+	if d, ok := assembler.DuplicateFor(result.DuplicateNames, source); ok {
+		in.Duplicate = d.Sources
+	}
+	if report != nil {
+		if c, ok := report.CollisionFor(source); ok {
+			in.Collision = &c
+			in.Suggestion = report.Suggestions[source]
+		}
+	}
+	if counts != nil {
+		id := app.Name
+		if entry != nil {
+			id = entry.ID
+		}
+		in.Usage = &dashboardv1alpha1.AppUsage{Count: counts[id], Rank: ranks[id]}
+	}
+	data, _ := json.Marshal(in)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// statusMemo remembers, per app, the resource version and status inputs the
+// app's status was last brought up to date with, so a reconcile only fans
+// status updates out to the apps that changed or whose entry or findings
+// did, rather than re-deriving every app's status. It is safe for
+// concurrent use.
+type statusMemo struct {
+	mu   sync.Mutex
+	apps map[string]statusMemoEntry
+}
+
+type statusMemoEntry struct {
+	resourceVersion string
+	inputs          string
+}
+
+// current reports whether the status of the app source, at resourceVersion,
+// was derived from inputs already.
+func (m *statusMemo) current(source, resourceVersion, inputs string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.apps[source]
+	return ok && e.resourceVersion == resourceVersion && e.inputs == inputs
+}
+
+// record notes that the status of the app source, at resourceVersion, is
+// derived from inputs.
+func (m *statusMemo) record(source, resourceVersion, inputs string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.apps == nil {
+		m.apps = make(map[string]statusMemoEntry)
+	}
+	m.apps[source] = statusMemoEntry{resourceVersion: resourceVersion, inputs: inputs}
+}
+
+// retain forgets the apps not in sources.
+func (m *statusMemo) retain(sources map[string]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for source := range m.apps {
+		if !sources[source] {
+			delete(m.apps, source)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+var _ = Describe("Status fan-out", func() {
+	It("only updates the status of apps that changed or whose assembly outcome did", func() {
+		plex := &dashboardv1alpha1.DashboardApp{ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media", ResourceVersion: "1"}}
+		sonarr := &dashboardv1alpha1.DashboardApp{ObjectMeta: metav1.ObjectMeta{Name: "sonarr", Namespace: "media", ResourceVersion: "1"}}
+		entry := &assembler.AppEntry{ID: "plex", Name: "Plex", Source: "media/plex"}
+		result := &assembler.AssemblyResult{}
+
+		var memo statusMemo
+		plexInputs := statusFingerprint(plex, entry, result, nil, nil, nil, "")
+		sonarrInputs := statusFingerprint(sonarr, nil, result, nil, nil, nil, "")
+		Expect(memo.current("media/plex", "1", plexInputs)).To(BeFalse())
+		memo.record("media/plex", "1", plexInputs)
+		memo.record("media/sonarr", "1", sonarrInputs)
+		Expect(memo.current("media/plex", "1", plexInputs)).To(BeTrue())
+
+		By("updating an app whose entry changed")
+		renamed := *entry
+		renamed.Name = "Plex Media Server"
+		Expect(memo.current("media/plex", "1", statusFingerprint(plex, &renamed, result, nil, nil, nil, ""))).To(BeFalse())
+
+		By("updating an app with a new finding, and no other")
+		result.IconFailures = map[string]string{"media/sonarr": "404 Not Found"}
+		Expect(memo.current("media/sonarr", "1", statusFingerprint(sonarr, nil, result, nil, nil, nil, ""))).To(BeFalse())
+		Expect(memo.current("media/plex", "1", statusFingerprint(plex, entry, result, nil, nil, nil, ""))).To(BeTrue())
+
+		By("updating an app changed since")
+		Expect(memo.current("media/plex", "2", plexInputs)).To(BeFalse())
+
+		By("forgetting deleted apps")
+		memo.retain(map[string]bool{"media/plex": true})
+		Expect(memo.current("media/sonarr", "1", sonarrInputs)).To(BeFalse())
+		Expect(memo.current("media/plex", "1", plexInputs)).To(BeTrue())
+	})
+})
@@ -73,6 +73,32 @@ var _ = BeforeSuite(func() {
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&HelmReleaseReconciler{
+		Client:   k8sManager.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("HelmRelease"),
+		Recorder: k8sManager.GetEventRecorderFor("helmrelease-controller"),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
+	for _, newObject := range WorkloadKinds() {
+		err = (&WorkloadReconciler{
+			Client:    k8sManager.GetClient(),
+			Scheme:    k8sManager.GetScheme(),
+			Log:       ctrl.Log.WithName("controllers").WithName("Workload"),
+			Recorder:  k8sManager.GetEventRecorderFor("workload-controller"),
+			NewObject: newObject,
+		}).SetupWithManager(k8sManager)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	err = (&IngressReconciler{
+		Client:   k8sManager.GetClient(),
+		Scheme:   k8sManager.GetScheme(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Ingress"),
+		Recorder: k8sManager.GetEventRecorderFor("ingress-controller"),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	go func() {
 		defer GinkgoRecover()
 		Expect(k8sManager.Start(ctx)).To(Succeed())
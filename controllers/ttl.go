@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+// expiresAt returns when the app's TTL runs out, or zero if it has none.
+func expiresAt(app *dashboardv1alpha1.DashboardApp) time.Time {
+	if app.Spec.TTL == nil || app.Spec.TTL.Duration <= 0 {
+		return time.Time{}
+	}
+	return app.LastSeen().Add(app.Spec.TTL.Duration)
+}
+
+// pruneExpired deletes apps whose TTL has run out and returns the remaining
+// ones together with the earliest upcoming expiry (zero if none). Apps that
+// fail to delete are dropped from the catalog anyway and retried on the next
+// reconcile. Apps already being deleted are left to applyRemovalGrace.
+func (r *DashboardAppReconciler) pruneExpired(ctx context.Context, apps []dashboardv1alpha1.DashboardApp, now time.Time) ([]dashboardv1alpha1.DashboardApp, time.Time) {
+	log := logr.FromContextOrDiscard(ctx)
+
+	live := make([]dashboardv1alpha1.DashboardApp, 0, len(apps))
+	var next time.Time
+	for i := range apps {
+		app := &apps[i]
+		expiry := expiresAt(app)
+		if expiry.IsZero() || !app.DeletionTimestamp.IsZero() {
+			live = append(live, *app)
+			continue
+		}
+		if expiry.After(now) {
+			live = append(live, *app)
+			next = earliest(next, expiry)
+			continue
+		}
+
+		log.Info("DashboardApp TTL expired, deleting", "app", client.ObjectKeyFromObject(app), "lastSeen", app.LastSeen())
+		r.Recorder.Eventf(app, corev1.EventTypeNormal, "Expired", "TTL of %s elapsed since %s", app.Spec.TTL.Duration, app.LastSeen().Format(time.RFC3339))
+		if err := r.Delete(ctx, app); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to delete expired DashboardApp", "app", client.ObjectKeyFromObject(app))
+		}
+	}
+	return live, next
+}
+
+// earliest returns the earlier of two times, treating zero as "never".
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
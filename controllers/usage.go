@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/usage"
+)
+
+// usageSource builds the configured usage importer, or nil if none is.
+func (r *DashboardAppReconciler) usageSource() usage.Source {
+	switch {
+	case r.Config.UsageConfigMap != "":
+		return &usage.ConfigMapSource{
+			Reader: r.Client,
+			Name:   types.NamespacedName{Name: r.Config.UsageConfigMap, Namespace: r.Config.DuroNamespace},
+		}
+	case r.Config.UsageURL != "":
+		return usage.NewHTTPSource(r.Config.UsageURL)
+	}
+	return nil
+}
+
+// loadUsage imports usage counts. Import failures are logged and the catalog
+// is assembled without usage rather than failing the reconcile.
+func (r *DashboardAppReconciler) loadUsage(ctx context.Context) usage.Counts {
+	if r.Usage == nil {
+		return nil
+	}
+	counts, err := r.Usage.Load(ctx)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Info("Failed to import usage counts, continuing without", "error", err.Error())
+		return nil
+	}
+	return counts
+}
+
+// setUsage records the usage count and rank of the app, whose entry ID is id,
+// in its status. A nil counts (no importer or failed import) leaves the
+// status untouched. Returns true if the status changed.
+func setUsage(app *dashboardv1alpha1.DashboardApp, id string, counts usage.Counts, ranks map[string]int) bool {
+	if counts == nil {
+		return false
+	}
+	u := dashboardv1alpha1.AppUsage{Count: counts[id], Rank: ranks[id]}
+	if app.Status.Usage != nil && *app.Status.Usage == u {
+		return false
+	}
+	app.Status.Usage = &u
+	return true
+}
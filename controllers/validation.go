@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/metrics"
+)
+
+const (
+	// ConditionConforming is False when the app breaks a rule of the current
+	// configuration, as found by the last validation sweep
+	ConditionConforming = "Conforming"
+
+	// maxSweepListed bounds the apps listed in the sweep event and status
+	maxSweepListed = 50
+)
+
+// setupValidationSweep registers the validation sweep, which runs once the
+// instance's OperatorOverview is first seen after startup and again whenever
+// its validate annotation changes. The sweep also checks that instances
+// partition apps and outputs between them.
+func (r *DashboardAppReconciler) setupValidationSweep(mgr ctrl.Manager) error {
+	ownOverview := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == r.Config.Identity()
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("validationsweep").
+		For(&dashboardv1alpha1.OperatorOverview{},
+			builder.WithPredicates(ownOverview, predicate.AnnotationChangedPredicate{}),
+		).
+		Complete(reconcile.Func(r.reconcileValidationSweep))
+}
+
+func (r *DashboardAppReconciler) reconcileValidationSweep(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithName("validation")
+
+	overview := &dashboardv1alpha1.OperatorOverview{}
+	if err := r.Get(ctx, req.NamespacedName, overview); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	trigger := overview.Annotations[dashboardv1alpha1.ValidateAnnotation]
+	if last := overview.Status.Validation; r.swept.Load() && last != nil && last.Trigger == trigger {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.checkPartitions(ctx, overview); err != nil {
+		return ctrl.Result{}, operrors.NewTransientError("failed to check instance partitions", err)
+	}
+
+	appList := &dashboardv1alpha1.DashboardAppList{}
+	if err := r.List(ctx, appList, client.MatchingLabelsSelector{Selector: r.selector}); err != nil {
+		return ctrl.Result{}, operrors.NewTransientError("failed to list DashboardApps", err)
+	}
+
+	var nonConforming []string
+	for i := range appList.Items {
+		app := &appList.Items[i]
+		violations := r.Assembler.Violations(app)
+		if len(violations) > 0 {
+			nonConforming = append(nonConforming, app.Namespace+"/"+app.Name)
+		}
+		if err := r.setConformingCondition(ctx, client.ObjectKeyFromObject(app), violations); err != nil {
+			return ctrl.Result{}, operrors.NewTransientError("failed to update DashboardApp status", err)
+		}
+	}
+
+	metrics.NonConformingApps.Set(float64(len(nonConforming)))
+	listed := nonConforming
+	if len(listed) > maxSweepListed {
+		listed = listed[:maxSweepListed]
+	}
+	if len(nonConforming) == 0 {
+		r.Recorder.Eventf(overview, corev1.EventTypeNormal, "ValidationSweep",
+			"All %d DashboardApps conform to the current rules", len(appList.Items))
+	} else {
+		more := ""
+		if n := len(nonConforming) - len(listed); n > 0 {
+			more = fmt.Sprintf(" and %d more", n)
+		}
+		r.Recorder.Eventf(overview, corev1.EventTypeWarning, "NonConformingApps",
+			"%d of %d DashboardApps break the current rules: %s%s", len(nonConforming), len(appList.Items), strings.Join(listed, ", "), more)
+	}
+	log.Info("Validation sweep done", "checked", len(appList.Items), "nonConforming", len(nonConforming), "trigger", trigger)
+
+	now := metav1.Now()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(ctx, req.NamespacedName, overview); err != nil {
+			return err
+		}
+		overview.Status.Validation = &dashboardv1alpha1.ValidationSweepStatus{
+			LastSweepTime:      &now,
+			Trigger:            trigger,
+			Checked:            len(appList.Items),
+			NonConformingCount: len(nonConforming),
+			NonConforming:      listed,
+		}
+		return r.Status().Update(ctx, overview)
+	})
+	if err != nil {
+		return ctrl.Result{}, operrors.NewTransientError("failed to record validation sweep", err)
+	}
+	r.swept.Store(true)
+	return ctrl.Result{}, nil
+}
+
+// conformingCondition is the Conforming condition of an app with the given
+// violations.
+func conformingCondition(violations []string) metav1.Condition {
+	cond := metav1.Condition{
+		Type:    ConditionConforming,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Conforming",
+		Message: "App conforms to the current rules",
+	}
+	if len(violations) > 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "PolicyViolation"
+		cond.Message = strings.Join(violations, "; ")
+	}
+	return cond
+}
+
+// setConformingCondition records the app's violations, writing the status
+// only if the condition changed.
+func (r *DashboardAppReconciler) setConformingCondition(ctx context.Context, key client.ObjectKey, violations []string) error {
+	cond := conformingCondition(violations)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		app := &dashboardv1alpha1.DashboardApp{}
+		if err := r.Get(ctx, key, app); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		cond.ObservedGeneration = app.Generation
+		if !meta.SetStatusCondition(&app.Status.Conditions, cond) {
+			return nil
+		}
+		return r.Status().Update(ctx, app)
+	})
+}
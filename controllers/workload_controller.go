@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/workload"
+)
+
+// WorkloadReconciler synthesizes DashboardApps for workloads of one kind
+// (Deployment, StatefulSet or DaemonSet) that opt in through duro.* labels
+// or annotations on the workload or its pod template. The DashboardApp is
+// named after the workload and controlled by it, so it is garbage collected
+// along with the workload, and deleted once duro.enable is removed.
+type WorkloadReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder record.EventRecorder
+
+	// NewObject returns an empty workload of the watched kind
+	NewObject func() client.Object
+
+	// Namespaces, if set, restricts discovery to the namespaces it accepts
+	// (see Config.WatchesNamespace)
+	Namespaces func(namespace string) bool
+}
+
+// WorkloadKinds returns constructors for the workload kinds discovery
+// watches
+func WorkloadKinds() []func() client.Object {
+	return []func() client.Object{
+		func() client.Object { return &appsv1.Deployment{} },
+		func() client.Object { return &appsv1.StatefulSet{} },
+		func() client.Object { return &appsv1.DaemonSet{} },
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *WorkloadReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	obj := r.NewObject()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("workload-"+kindName(obj)).
+		For(obj, builder.WithPredicates(namespacePredicate(r.Namespaces))).
+		Owns(&dashboardv1alpha1.DashboardApp{}).
+		Complete(r)
+}
+
+// kindName names the controller after the Go type of the workload.
+func kindName(obj client.Object) string {
+	switch obj.(type) {
+	case *appsv1.Deployment:
+		return "deployment"
+	case *appsv1.StatefulSet:
+		return "statefulset"
+	case *appsv1.DaemonSet:
+		return "daemonset"
+	}
+	return strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
+}
+
+// podTemplateAnnotations returns the pod template annotations of a workload.
+func podTemplateAnnotations(obj client.Object) map[string]string {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		return w.Spec.Template.Annotations
+	case *appsv1.StatefulSet:
+		return w.Spec.Template.Annotations
+	case *appsv1.DaemonSet:
+		return w.Spec.Template.Annotations
+	}
+	return nil
+}
+
+// Reconcile handles the reconciliation loop
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch
+
+func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := r.NewObject()
+	log := r.Log.WithValues(kindName(obj), req.NamespacedName)
+
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		// The DashboardApp of a deleted workload is garbage collected
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var spec *dashboardv1alpha1.DashboardAppSpec
+	if obj.GetDeletionTimestamp().IsZero() {
+		var enabled bool
+		var err error
+		// Pod template annotations, then workload labels and annotations
+		spec, enabled, err = workload.AppSpec(req.Name, podTemplateAnnotations(obj), obj.GetLabels(), obj.GetAnnotations())
+		if err != nil {
+			log.Info("Workload declares an invalid dashboard entry", "error", err.Error())
+			r.Recorder.Eventf(obj, corev1.EventTypeWarning, "InvalidDashboardLabels", "%v", err)
+			return ctrl.Result{}, nil
+		}
+		if !enabled {
+			spec = nil
+		}
+	}
+
+	d := &discoverer{Client: r.Client, Scheme: r.Scheme, Recorder: r.Recorder, kind: "Workload", source: workload.SourceWorkload}
+	return ctrl.Result{}, d.syncApp(ctx, log, obj, spec)
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/workload"
+)
+
+var _ = Describe("Workload controller", func() {
+	const (
+		timeout  = 10 * time.Second
+		interval = 250 * time.Millisecond
+	)
+
+	It("synthesizes a DashboardApp for an opted-in Deployment and removes it when disabled", func() {
+		labels := map[string]string{"app": "workload-uptime"}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "workload-uptime",
+				Namespace:   "default",
+				Labels:      map[string]string{"duro.enable": "true", "duro.groups": "admins", "duro.category": "monitoring"},
+				Annotations: map[string]string{"duro.url": "https://uptime.example.test"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "uptime-kuma"}}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, deployment)).To(Succeed())
+		DeferCleanup(func() { _ = k8sClient.Delete(ctx, deployment) })
+
+		key := types.NamespacedName{Name: "workload-uptime", Namespace: "default"}
+		Eventually(func(g Gomega) {
+			var app dashboardv1alpha1.DashboardApp
+			g.Expect(k8sClient.Get(ctx, key, &app)).To(Succeed())
+			g.Expect(app.Labels).To(HaveKeyWithValue(dashboardv1alpha1.SourceLabel, workload.SourceWorkload))
+			g.Expect(metav1.IsControlledBy(&app, deployment)).To(BeTrue())
+			g.Expect(app.Spec.URL).To(Equal("https://uptime.example.test"))
+			g.Expect(app.Spec.Category).To(Equal("monitoring"))
+			g.Expect(app.Spec.Groups).To(Equal([]string{"admins"}))
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, key, deployment); err != nil {
+				return err
+			}
+			delete(deployment.Labels, workload.KeyEnable)
+			return k8sClient.Update(ctx, deployment)
+		}, timeout, interval).Should(Succeed())
+		Eventually(func() bool {
+			var app dashboardv1alpha1.DashboardApp
+			return errors.IsNotFound(k8sClient.Get(ctx, key, &app))
+		}, timeout, interval).Should(BeTrue())
+	})
+})
@@ -3,34 +3,63 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	uberzap "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	dashboardv1beta1 "github.com/fredericrous/duro-operator/api/v1beta1"
 	"github.com/fredericrous/duro-operator/controllers"
+	"github.com/fredericrous/duro-operator/pkg/alerting"
 	"github.com/fredericrous/duro-operator/pkg/apiserver"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
 	"github.com/fredericrous/duro-operator/pkg/config"
+	"github.com/fredericrous/duro-operator/pkg/conformance"
+	"github.com/fredericrous/duro-operator/pkg/hashing"
+	"github.com/fredericrous/duro-operator/pkg/healthcheck"
+	"github.com/fredericrous/duro-operator/pkg/helm"
+	"github.com/fredericrous/duro-operator/pkg/history"
+	"github.com/fredericrous/duro-operator/pkg/iconfetch"
+	"github.com/fredericrous/duro-operator/pkg/iconpolicy"
+	"github.com/fredericrous/duro-operator/pkg/linkcheck"
+	"github.com/fredericrous/duro-operator/pkg/logging"
+	"github.com/fredericrous/duro-operator/pkg/metrics"
+	"github.com/fredericrous/duro-operator/pkg/redact"
 )
 
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	// version is set at build time with -ldflags "-X main.version=..."
+	version = "dev"
 )
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(dashboardv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(dashboardv1beta1.AddToScheme(scheme))
 }
 
 func main() {
@@ -38,19 +67,102 @@ func main() {
 		metricsAddr          = flag.String("metrics-bind-address", ":8080", "The address the metric endpoint binds to")
 		probeAddr            = flag.String("health-probe-bind-address", ":8081", "The address the probe endpoint binds to")
 		enableLeaderElection = flag.Bool("leader-elect", false, "Enable leader election for controller manager")
-		leaderElectionID     = flag.String("leader-election-id", "duro-operator", "Leader election ID")
+		leaderElectionID     = flag.String("leader-election-id", "", "Leader election ID (defaults to duro-operator, or duro-operator-<instance-name>)")
+		leaderElectionLock   = flag.String("leader-election-resource-lock", resourcelock.LeasesResourceLock, "Resource lock type for leader election (only leases is supported)")
+		leaderElectionNS     = flag.String("leader-election-namespace", "", "Namespace for the leader election lock (defaults to the operator's namespace)")
+		instanceName         = flag.String("instance-name", "", "Name distinguishing this operator deployment from others in the cluster (e.g. prod, test)")
+		appSelector          = flag.String("app-selector", "", "Label selector restricting the DashboardApps this instance renders, e.g. env=prod")
 
 		maxConcurrentReconciles = flag.Int("max-concurrent-reconciles", 3, "Maximum number of concurrent reconciles")
+		priorityLanes           = flag.Bool("priority-lanes", false, "Reconcile changes users make to dashboard resources ahead of background work (health probes, heartbeats, discovery)")
 		reconcileTimeout        = flag.Duration("reconcile-timeout", 5*time.Minute, "Timeout for each reconcile operation")
+		slowReconcileThreshold  = flag.Duration("slow-reconcile-threshold", 0, "Soft deadline below --reconcile-timeout; slower reconciles raise a warning event, e.g. 1m (0 disables)")
+		minWriteInterval        = flag.Duration("min-write-interval", 0, "Minimum time between two writes to the same output target, e.g. 10s, longer than --aggregate-debounce and at most 30s (0 disables); a Dashboard may set its own in spec.minWriteInterval")
+		aggregateDebounce       = flag.Duration("aggregate-debounce", time.Second, "How long app changes settle before the catalog is assembled again, so bursts are assembled once (0 assembles after every change)")
+		removalGracePeriod      = flag.Duration("removal-grace-period", 0, "How long a deleted app stays in the output marked removed, e.g. 1h (0 removes it right away)")
+		reconcileHistorySize    = flag.Int("reconcile-history", history.DefaultSize, "How many recent reconcile outcomes the API server serves at /debug/reconciles, read with duroctl reconciles (0 disables)")
 
-		apiAddr = flag.String("api-bind-address", ":9090", "The address the REST API binds to")
+		enableWebhooks    = flag.Bool("enable-webhooks", false, "Serve the DashboardApp defaulting webhook and the v1alpha1/v1beta1 conversion webhook (requires a MutatingWebhookConfiguration and serving certificates; v1beta1 is only served with the config/crd/conversion component)")
+		simulateAdmission = flag.Bool("simulate-admission", false, "Also serve a DashboardApp validating webhook rejecting apps that would break the catalog (entry ID collisions, output size overflow, strict mode), by assembling it with the incoming app (requires --enable-webhooks and a ValidatingWebhookConfiguration)")
+		webhookPort       = flag.Int("webhook-port", 9443, "The port the webhook server listens on")
+		webhookCertDir    = flag.String("webhook-cert-dir", "", "Directory holding the webhook server's tls.crt and tls.key (defaults to controller-runtime's)")
+		apiAddr           = flag.String("api-bind-address", ":9090", "The address the REST API binds to")
+		apiTokenFile      = flag.String("api-token-file", "", "File containing the bearer token for authenticated API endpoints (preview, registrations, and with --output-kind=secret the catalog and icons)")
+		dispatchSecret    = flag.String("dispatch-secret-file", "", "File containing the secret signing GitHub/Gitea repository dispatch webhooks, enabling the /api/v1/dispatch receiver")
+		registrationNS    = flag.String("registration-namespace", "", "Namespace where apps registered through the API or dispatched by repositories are created (defaults to --duro-namespace)")
+		watchNamespaces   = flag.String("watch-namespaces", "", "Comma-separated namespaces DashboardApps are read from and discovered in, ignoring apps anywhere else (empty watches every namespace); apps taken out of scope keep their removal finalizer until duroctl release")
+		excludeNamespaces = flag.String("exclude-namespaces", "", "Comma-separated namespaces whose DashboardApps are ignored and where no app is discovered")
 
 		duroNamespace     = flag.String("duro-namespace", "duro", "Namespace where duro is deployed")
 		duroConfigMapName = flag.String("duro-configmap", "duro-apps", "Name of the duro apps ConfigMap")
+		outputKind        = flag.String("output-kind", config.OutputKindConfigMap, "Kind of object the output is written to: configmap, or secret when the app list is sensitive (the API server then requires --api-token-file)")
+		outputLabels      = flag.String("output-labels", "", "Comma-separated key=value labels kept on the duro apps ConfigMap, e.g. team=platform")
+		outputAnnots      = flag.String("output-annotations", "", "Comma-separated key=value annotations kept on the duro apps ConfigMap, e.g. argocd.argoproj.io/compare-options=IgnoreExtraneous")
+		clusterDomain     = flag.String("cluster-domain", "cluster.local", "Cluster domain exposed to spec.url templates as {{ .clusterDomain }}")
+		externalSuffix    = flag.String("external-suffix", "", "External domain suffix exposed to spec.url templates as {{ .externalSuffix }}")
+		substitutionsCM   = flag.String("substitutions-configmap", "", "ConfigMap in the duro namespace whose key/values are available to DashboardApp templates")
+		rbacGroups        = flag.Bool("rbac-groups", false, "Give apps without spec.groups the groups bound by RoleBindings in their namespace")
+		categoryLabel     = flag.String("category-label", "", "Namespace label giving apps without spec.category their category, e.g. homelab.io/category")
+		priorityAnalysis  = flag.Bool("priority-analysis", false, "Report priority collisions within a category and suggest normalized priorities")
+		groupOutputs      = flag.String("group-outputs", "", "Comma-separated groups for which a filtered apps-<group>.json key is written")
+		outputFormats     = flag.String("output-formats", "", "Comma-separated dashboard formats also written to the output, e.g. homer (Homer's config.yml), homepage (gethomepage's services.yaml and settings.yaml)")
+		formatVersions    = flag.String("format-versions", "1", "Comma-separated format versions duro's documents are written in, e.g. 1,2 while upgrading duro (1 is the original apps.json layout, later versions are enveloped under apps.v<N>.json)")
+		outputNameHash    = flag.Bool("output-name-hash", false, "Write every version of the output to a new immutable object named after its content hash, like kustomize's configMapGenerator; the current name is published on the OperatorOverview")
+		restartDeployment = flag.String("restart-deployment", "", "Deployment of duro (namespace/name, or a name in --duro-namespace) rolled out again whenever the output changes, as duro only reads it at startup (empty disables)")
+		fallbackCategory  = flag.String("fallback-category", assembler.DefaultFallbackCategory, "Category listed last, holding apps whose category is neither a DashboardCategory nor built in (e.g. after the DashboardCategory was deleted); empty keeps them in their own category")
+		duplicateNames    = flag.String("duplicate-name-policy", assembler.DuplicateNamesFlag, "What to do with apps sharing a display name: off, flag (DuplicateName condition) or suffix (also suffix their names with their namespace)")
+		shardByCategory   = flag.Bool("shard-by-category", false, "Also write one category-<id>.json key per category, so consumers can mount only the categories they show")
+		catalogStatus     = flag.Bool("catalog-status", false, "Also publish apps.json, gzip-compressed, in the OperatorOverview status so API clients can read the catalog without the ConfigMap")
+		dashboards        = flag.Bool("dashboards", false, "Also write the apps selected by each Dashboard resource to its own ConfigMap, so separate dashboards can show different app sets")
+		checksums         = flag.Bool("checksums", false, "Also write a checksums.json key fingerprinting every entry and icon, for fine-grained cache invalidation by duro")
+		usageCM           = flag.String("usage-configmap", "", "ConfigMap in the duro namespace holding usage counts exported by duro (key usage.json)")
+		usageURL          = flag.String("usage-url", "", "HTTP endpoint serving usage counts exported by duro")
+		usageRefresh      = flag.Duration("usage-refresh-interval", 10*time.Minute, "How often usage counts are re-imported")
+		idTemplate        = flag.String("id-template", "", "Template generating entry IDs from name, namespace, category and hash, e.g. '{{ .namespace }}-{{ .name }}' (defaults to the app name)")
+		iconsKey          = flag.Bool("icons-key", false, "Keep app icons out of apps.json in an icons.json key of the output, for catalogs whose inline icons outgrow the ConfigMap")
+		iconConfigMap     = flag.String("icon-configmap", "", "Keep app icons out of apps.json in a ConfigMap of this name in each app's namespace, owned by the apps (empty disables)")
+		iconBaseURL       = flag.String("icon-base-url", "", "URL the /icons endpoint of the API server is reachable at; icons are then referenced by URL instead of inlined in apps.json")
+		iconPolicy        = flag.String("icon-policy", string(iconpolicy.ModeOff), "What to do with icons referencing external resources or embedding large raster data: off, rewrite or reject")
+		iconMaxDataURI    = flag.Int("icon-max-data-uri-bytes", iconpolicy.DefaultMaxDataURIBytes, "Largest raster data URI an icon may embed under --icon-policy")
+		iconURLPassthru   = flag.Bool("icon-url-passthrough", false, "Publish spec.iconURL as the app's icon instead of fetching and inlining the SVG")
+		iconURLMaxBytes   = flag.Int("icon-url-max-bytes", iconfetch.DefaultMaxBytes, "Largest icon fetched from spec.iconURL")
+		iconURLRefresh    = flag.Duration("icon-url-refresh-interval", iconfetch.DefaultRefresh, "How long an icon fetched from spec.iconURL is used before it is fetched again")
+		iconURLBudget     = flag.Duration("icon-url-fetch-budget", iconfetch.DefaultFetchBudget, "How long an assembly waits on icon fetches; icons still being fetched are published by the next assembly (0 waits for every fetch)")
+		iconURLNetworks   = flag.String("icon-url-allowed-networks", "", "Comma-separated loopback, private or link-local CIDRs icons may be fetched from, e.g. 10.43.0.0/16 for an in-cluster icon server (refused by default)")
+		iconLibraries     = flag.String("icon-libraries", "", "Comma-separated prefix=URL templates adding or overriding spec.icon shorthands (built in: sh, si, mdi), e.g. sh=https://mirror.lan/selfhst/{name}.svg")
+		strict            = flag.Bool("strict", false, "Leave every app of a namespace out of the output, with Ready=false, when one of them has a validation finding (rule violation, missing category, shared display name)")
+		entryHooks        = flag.String("entry-hooks", "", "Comma-separated absolute paths of executables transforming the assembled entries (JSON on stdin, JSON on stdout), run in order")
+		hookTimeout       = flag.Duration("hook-timeout", 5*time.Second, "How long a single --entry-hooks executable may run")
+		hookFailure       = flag.String("hook-failure-policy", assembler.HookFailureIgnore, "What a failing entry hook does: ignore (publish the entries it was given) or fail (keep the previous output)")
+		alertRules        = flag.Bool("alert-rules", false, "Maintain a PrometheusRule per DashboardApp alerting when it is reported down (requires the Prometheus Operator CRDs); opt out with the label dashboard.homelab.io/alerts=false")
+		alertFor          = flag.Duration("alert-for", alerting.DefaultFor, "How long an app must be down before its --alert-rules alert fires")
+		helmDiscovery     = flag.Bool("helm-discovery", false, "Create DashboardApps for Helm releases whose chart declares dashboard.homelab.io/* annotations (reads release Secrets)")
+		workloadDiscovery = flag.Bool("workload-discovery", false, "Create DashboardApps for Deployments, StatefulSets and DaemonSets labelled or annotated duro.enable=true")
+		ingressDiscovery  = flag.Bool("ingress-discovery", false, "Create DashboardApps for Ingresses annotated dashboard.homelab.io/enabled=true, with the URL of their first rule")
+		conformanceURL    = flag.String("conformance-url", "", "duro endpoint serving the full apps document (e.g. http://duro.duro.svc/api/apps), polled after each write to confirm it is served")
+		conformanceEvery  = flag.Duration("conformance-interval", conformance.DefaultInterval, "How often --conformance-url is polled")
+		replicaSkewEvery  = flag.Duration("replica-skew-check-interval", 5*time.Minute, "How often replicas compare their version and config with the leader's (0 disables)")
+		deadLinkEvery     = flag.Duration("dead-link-sweep-interval", 0, "How often every app URL is probed for dead links, reported in the OperatorOverview, e.g. 168h (0 disables)")
+		deadLinkTimeout   = flag.Duration("dead-link-timeout", 10*time.Second, "Timeout of each dead-link probe")
+		deadLinkEvents    = flag.Bool("dead-link-events", false, "Emit one Event on the OperatorOverview summarizing the dead links found by each sweep")
+		healthProbes      = flag.Bool("health-probes", false, "Probe the apps setting spec.healthCheck over HTTP and publish their health in the output")
+		healthProbeConc   = flag.Int("health-probe-concurrency", healthcheck.DefaultConcurrency, "Maximum number of health probes in flight")
+		factsCM           = flag.String("facts-configmap", "", "ConfigMap in the duro namespace whose key/values are exposed to spec.condition as flags")
+		factsRefresh      = flag.Duration("facts-refresh-interval", 5*time.Minute, "How often cluster facts are re-gathered while some app sets spec.condition")
+		sortOrder         = flag.String("sort", assembler.SortCategory, "Order of apps within a category: category (priority), alphabetical, mostUsed or recentlyAdded")
+		stableOrder       = flag.Bool("stable-order", false, "Keep apps of equal priority in the order they were last written, so restarts and renames don't reshuffle them (category sort only)")
+		healthDamping     = flag.Int("health-damping", 0, "How many consecutive health check results must report a new app health state before it shows in the output, e.g. 2 to ride out one failed probe (0 or 1 publishes every change; apps without spec.healthCheck are not damped)")
+		newBadgeWindow    = flag.Duration("new-badge-window", 0, "Badge apps created less than this long ago as new, e.g. 168h (0 disables)")
+		hashAlgorithm     = flag.String("hash-algorithm", hashing.SHA256, "Change-detection hash recorded on the output (sha256 or xxhash)")
+		hashScope         = flag.String("hash-scope", hashing.ScopeDocument, "What the change-detection hash covers (document or entries)")
 
 		logLevel   = flag.String("zap-log-level", "info", "Zap log level (debug, info, warn, error)")
 		logDevel   = flag.Bool("zap-devel", false, "Enable development mode logging")
 		logEncoder = flag.String("zap-encoder", "json", "Zap log encoding (json or console)")
+		logLevels  = flag.String("log-levels", "", "Per-component log level overrides, e.g. assembler=debug,apiserver=warn (levels: debug, info, warn, error or a verbosity number)")
+
+		logSamplingInitial    = flag.Int("log-sampling-initial", 0, "Log the first N identical messages per second before sampling (0 disables sampling)")
+		logSamplingThereafter = flag.Int("log-sampling-thereafter", 100, "After --log-sampling-initial, log every Nth identical message per second")
 	)
 
 	flag.Parse()
@@ -73,6 +185,18 @@ func main() {
 		opts.Level = zapcore.InfoLevel
 	}
 
+	componentLevels, err := logging.ParseComponentLevels(*logLevels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --log-levels: %v\n", err)
+		os.Exit(1)
+	}
+	defaultLevel := opts.Level.(zapcore.Level)
+	opts.Level = logging.MinLevel(defaultLevel, componentLevels)
+	sampling := logging.Sampling{Initial: *logSamplingInitial, Thereafter: *logSamplingThereafter}
+	opts.ZapOpts = append(opts.ZapOpts, uberzap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return sampling.Wrap(logging.NewComponentCore(redact.NewCore(core), defaultLevel, componentLevels))
+	}))
+
 	if *logEncoder == "console" {
 		opts.Encoder = zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
 			TimeKey:        "ts",
@@ -91,16 +215,133 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	outputLabelValues, err := config.ParseKeyValues(*outputLabels)
+	if err != nil {
+		setupLog.Error(err, "Invalid --output-labels")
+		os.Exit(1)
+	}
+	outputAnnotationValues, err := config.ParseKeyValues(*outputAnnots)
+	if err != nil {
+		setupLog.Error(err, "Invalid --output-annotations")
+		os.Exit(1)
+	}
+	formatVersionValues, err := config.ParseFormatVersions(*formatVersions)
+	if err != nil {
+		setupLog.Error(err, "Invalid --format-versions")
+		os.Exit(1)
+	}
+
+	iconLibraryValues, err := config.ParseKeyValues(*iconLibraries)
+	if err != nil {
+		setupLog.Error(err, "Invalid --icon-libraries")
+		os.Exit(1)
+	}
+
 	cfg := &config.OperatorConfig{
-		MetricsAddr:             *metricsAddr,
-		ProbeAddr:               *probeAddr,
-		ApiAddr:                 *apiAddr,
-		EnableLeaderElection:    *enableLeaderElection,
-		LeaderElectionID:        *leaderElectionID,
-		MaxConcurrentReconciles: *maxConcurrentReconciles,
-		ReconcileTimeout:        *reconcileTimeout,
-		DuroNamespace:           *duroNamespace,
-		DuroConfigMapName:       *duroConfigMapName,
+		MetricsAddr:                *metricsAddr,
+		ProbeAddr:                  *probeAddr,
+		ApiAddr:                    *apiAddr,
+		EnableWebhooks:             *enableWebhooks,
+		SimulateAdmission:          *simulateAdmission,
+		WebhookPort:                *webhookPort,
+		WebhookCertDir:             *webhookCertDir,
+		EnableLeaderElection:       *enableLeaderElection,
+		LeaderElectionID:           *leaderElectionID,
+		InstanceName:               *instanceName,
+		AppSelector:                *appSelector,
+		WatchNamespaces:            splitList(*watchNamespaces),
+		ExcludeNamespaces:          splitList(*excludeNamespaces),
+		LeaderElectionResourceLock: *leaderElectionLock,
+		LeaderElectionNamespace:    *leaderElectionNS,
+		MaxConcurrentReconciles:    *maxConcurrentReconciles,
+		PriorityLanes:              *priorityLanes,
+		ReconcileTimeout:           *reconcileTimeout,
+		SlowReconcileThreshold:     *slowReconcileThreshold,
+		MinWriteInterval:           *minWriteInterval,
+		AggregateDebounce:          *aggregateDebounce,
+		RemovalGracePeriod:         *removalGracePeriod,
+		ReconcileHistory:           *reconcileHistorySize,
+		DuroNamespace:              *duroNamespace,
+		DuroConfigMapName:          *duroConfigMapName,
+		OutputKind:                 *outputKind,
+		OutputLabels:               outputLabelValues,
+		OutputAnnotations:          outputAnnotationValues,
+		ClusterDomain:              *clusterDomain,
+		ExternalSuffix:             *externalSuffix,
+		SubstitutionsConfigMap:     *substitutionsCM,
+		RegistrationNamespace:      *registrationNS,
+		GroupOutputs:               splitList(*groupOutputs),
+		OutputFormats:              splitList(*outputFormats),
+		FormatVersions:             formatVersionValues,
+		OutputNameHash:             *outputNameHash,
+		RestartDeployment:          *restartDeployment,
+		ShardByCategory:            *shardByCategory,
+		Checksums:                  *checksums,
+		CatalogStatus:              *catalogStatus,
+		Dashboards:                 *dashboards,
+		FallbackCategory:           *fallbackCategory,
+		DuplicateNamePolicy:        *duplicateNames,
+		PriorityAnalysis:           *priorityAnalysis,
+		RBACGroups:                 *rbacGroups,
+		CategoryLabel:              *categoryLabel,
+		UsageConfigMap:             *usageCM,
+		UsageURL:                   *usageURL,
+		UsageRefreshInterval:       *usageRefresh,
+		IDTemplate:                 *idTemplate,
+		IconBaseURL:                *iconBaseURL,
+		IconConfigMap:              *iconConfigMap,
+		IconsKey:                   *iconsKey,
+		IconPolicy:                 *iconPolicy,
+		IconMaxDataURIBytes:        *iconMaxDataURI,
+		IconURLPassthrough:         *iconURLPassthru,
+		IconURLMaxBytes:            *iconURLMaxBytes,
+		IconURLRefresh:             *iconURLRefresh,
+		IconURLFetchBudget:         *iconURLBudget,
+		IconURLAllowedNetworks:     splitList(*iconURLNetworks),
+		IconLibraries:              iconLibraryValues,
+		Strict:                     *strict,
+		EntryHooks:                 splitList(*entryHooks),
+		HookTimeout:                *hookTimeout,
+		HookFailurePolicy:          *hookFailure,
+		AlertRules:                 *alertRules,
+		AlertFor:                   *alertFor,
+		HelmDiscovery:              *helmDiscovery,
+		WorkloadDiscovery:          *workloadDiscovery,
+		IngressDiscovery:           *ingressDiscovery,
+		ConformanceURL:             *conformanceURL,
+		ConformanceInterval:        *conformanceEvery,
+		ReplicaSkewCheckInterval:   *replicaSkewEvery,
+		DeadLinkSweepInterval:      *deadLinkEvery,
+		DeadLinkTimeout:            *deadLinkTimeout,
+		DeadLinkEvents:             *deadLinkEvents,
+		HealthProbes:               *healthProbes,
+		HealthProbeConcurrency:     *healthProbeConc,
+		FactsConfigMap:             *factsCM,
+		FactsRefreshInterval:       *factsRefresh,
+		Sort:                       *sortOrder,
+		StableOrder:                *stableOrder,
+		NewBadgeWindow:             *newBadgeWindow,
+		HealthDamping:              *healthDamping,
+		HashAlgorithm:              *hashAlgorithm,
+		HashScope:                  *hashScope,
+	}
+
+	if *apiTokenFile != "" {
+		token, err := os.ReadFile(*apiTokenFile)
+		if err != nil {
+			setupLog.Error(err, "Failed to read API token file", "path", *apiTokenFile)
+			os.Exit(1)
+		}
+		cfg.APIToken = strings.TrimSpace(string(token))
+	}
+
+	if *dispatchSecret != "" {
+		secret, err := os.ReadFile(*dispatchSecret)
+		if err != nil {
+			setupLog.Error(err, "Failed to read dispatch secret file", "path", *dispatchSecret)
+			os.Exit(1)
+		}
+		cfg.DispatchSecret = strings.TrimSpace(string(secret))
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -109,6 +350,12 @@ func main() {
 	}
 
 	setupLog.Info("Starting duro-operator",
+		"version", version,
+		"instance", cfg.Identity(),
+		"appSelector", cfg.AppSelector,
+		"watchNamespaces", cfg.WatchNamespaces,
+		"excludeNamespaces", cfg.ExcludeNamespaces,
+		"configFingerprint", cfg.Fingerprint(),
 		"duroNamespace", cfg.DuroNamespace,
 		"metricsAddr", cfg.MetricsAddr,
 		"probeAddr", cfg.ProbeAddr,
@@ -116,26 +363,69 @@ func main() {
 		"maxConcurrentReconciles", cfg.MaxConcurrentReconciles,
 	)
 
+	cacheOpts := cache.Options{ByObject: map[client.Object]cache.ByObject{}}
+	switch {
+	case cfg.HelmDiscovery && cfg.WritesSecrets():
+		// Both Helm release Secrets and outputs are read, cache them all
+	case cfg.HelmDiscovery:
+		// Only Helm release Secrets are ever read; don't cache the rest
+		cacheOpts.ByObject[&corev1.Secret{}] = cache.ByObject{Label: labels.SelectorFromSet(labels.Set{helm.OwnerLabel: "helm"})}
+	case cfg.WritesSecrets():
+		// Only outputs are read, and without Dashboards only the main one
+		// in the duro namespace
+		byObject := cache.ByObject{Label: labels.SelectorFromSet(labels.Set{"app.kubernetes.io/managed-by": "duro-operator"})}
+		if !cfg.Dashboards {
+			byObject.Namespaces = map[string]cache.Config{cfg.DuroNamespace: {}}
+		}
+		cacheOpts.ByObject[&corev1.Secret{}] = byObject
+	}
+	if cfg.IconConfigMap == "" && !cfg.Dashboards {
+		// ConfigMaps are only read and written in the duro namespace, so a
+		// Role there is enough (see duroctl rbac)
+		cacheOpts.ByObject[&corev1.ConfigMap{}] = cache.ByObject{Namespaces: map[string]cache.Config{cfg.DuroNamespace: {}}}
+	}
+
+	if len(cfg.WatchNamespaces) > 0 || len(cfg.ExcludeNamespaces) > 0 {
+		// Apps outside the approved namespaces never reach the cache
+		cacheOpts.ByObject[&dashboardv1alpha1.DashboardApp{}] = namespacesCache(cfg)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		Metrics:                metricsserver.Options{BindAddress: cfg.MetricsAddr},
-		HealthProbeBindAddress: cfg.ProbeAddr,
-		LeaderElection:         cfg.EnableLeaderElection,
-		LeaderElectionID:       cfg.LeaderElectionID,
+		Scheme:                     scheme,
+		Cache:                      cacheOpts,
+		Metrics:                    metricsserver.Options{BindAddress: cfg.MetricsAddr},
+		HealthProbeBindAddress:     cfg.ProbeAddr,
+		WebhookServer:              webhook.NewServer(webhook.Options{Port: cfg.WebhookPort, CertDir: cfg.WebhookCertDir}),
+		LeaderElection:             cfg.EnableLeaderElection,
+		LeaderElectionID:           cfg.LeaderElectionIDOrDefault(),
+		LeaderElectionResourceLock: cfg.LeaderElectionResourceLock,
+		LeaderElectionNamespace:    cfg.LeaderElectionNamespace,
 	})
 	if err != nil {
 		setupLog.Error(err, "Failed to create manager")
 		os.Exit(1)
 	}
 
-	recorder := mgr.GetEventRecorderFor("duro-operator")
+	recorder := redact.NewRecorder(mgr.GetEventRecorderFor("duro-operator"))
+
+	catalogStore := catalog.NewStore()
+
+	var reconcileHistory *history.Ring
+	if cfg.ReconcileHistory > 0 {
+		reconcileHistory = history.NewRing(cfg.ReconcileHistory)
+	}
+
+	metrics.InstanceInfo.WithLabelValues(cfg.Identity(), version).Set(1)
 
 	reconciler := &controllers.DashboardAppReconciler{
-		Client:   mgr.GetClient(),
+		Client:   client.WithFieldOwner(mgr.GetClient(), cfg.Identity()),
 		Log:      ctrl.Log.WithName("controllers").WithName("DashboardApp"),
 		Scheme:   mgr.GetScheme(),
 		Recorder: recorder,
 		Config:   cfg,
+		Catalog:  catalogStore,
+		History:  reconcileHistory,
+		Version:  version,
 	}
 
 	if err := reconciler.SetupWithManager(mgr); err != nil {
@@ -143,6 +433,122 @@ func main() {
 		os.Exit(1)
 	}
 
+	if cfg.EnableWebhooks {
+		if err := (&dashboardv1alpha1.DashboardAppDefaulter{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Failed to setup DashboardApp webhook")
+			os.Exit(1)
+		}
+		if cfg.SimulateAdmission {
+			if err := reconciler.SetupSimulationWebhook(mgr); err != nil {
+				setupLog.Error(err, "Failed to setup DashboardApp simulation webhook")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if cfg.HelmDiscovery {
+		if err := (&controllers.HelmReleaseReconciler{
+			Client:     mgr.GetClient(),
+			Log:        ctrl.Log.WithName("controllers").WithName("HelmRelease"),
+			Recorder:   recorder,
+			Namespaces: cfg.WatchesNamespace,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "Failed to setup Helm release controller")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.WorkloadDiscovery {
+		for _, newObject := range controllers.WorkloadKinds() {
+			if err := (&controllers.WorkloadReconciler{
+				Client:     mgr.GetClient(),
+				Scheme:     mgr.GetScheme(),
+				Log:        ctrl.Log.WithName("controllers").WithName("Workload"),
+				Recorder:   recorder,
+				Namespaces: cfg.WatchesNamespace,
+				NewObject:  newObject,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "Failed to setup workload controller")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if cfg.IngressDiscovery {
+		if err := (&controllers.IngressReconciler{
+			Client:     mgr.GetClient(),
+			Scheme:     mgr.GetScheme(),
+			Log:        ctrl.Log.WithName("controllers").WithName("Ingress"),
+			Recorder:   recorder,
+			Namespaces: cfg.WatchesNamespace,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "Failed to setup Ingress controller")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.AlertRules {
+		if _, err := mgr.GetRESTMapper().RESTMapping(alerting.GroupVersionKind.GroupKind(), alerting.GroupVersionKind.Version); err != nil {
+			setupLog.Info("PrometheusRule CRD not found, alert rules disabled", "error", err.Error())
+		} else if err := (&controllers.AlertRuleReconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			Log:      ctrl.Log.WithName("controllers").WithName("AlertRule"),
+			Recorder: recorder,
+			For:      cfg.AlertFor,
+			Selector: cfg.Selector(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "Failed to setup alert rule controller")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.ReplicaSkewCheckInterval > 0 {
+		if err := mgr.Add(&controllers.ReplicaSkewChecker{
+			Client:            mgr.GetClient(),
+			Log:               ctrl.Log.WithName("controllers").WithName("ReplicaSkew"),
+			Recorder:          recorder,
+			OverviewName:      cfg.Identity(),
+			Version:           version,
+			ConfigFingerprint: cfg.Fingerprint(),
+			Interval:          cfg.ReplicaSkewCheckInterval,
+			Elected:           mgr.Elected(),
+		}); err != nil {
+			setupLog.Error(err, "Failed to add replica skew checker")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.DeadLinkSweepInterval > 0 {
+		if err := mgr.Add(&controllers.DeadLinkSweeper{
+			Client:       mgr.GetClient(),
+			Log:          ctrl.Log.WithName("controllers").WithName("DeadLinks"),
+			Recorder:     recorder,
+			Catalog:      catalogStore,
+			OverviewName: cfg.Identity(),
+			Interval:     cfg.DeadLinkSweepInterval,
+			Prober:       linkcheck.NewProber(cfg.DeadLinkTimeout),
+			Events:       cfg.DeadLinkEvents,
+		}); err != nil {
+			setupLog.Error(err, "Failed to add dead-link sweeper")
+			os.Exit(1)
+		}
+	}
+
+	if cfg.HealthProbes {
+		if err := mgr.Add(&controllers.HealthProber{
+			Client:      mgr.GetClient(),
+			Log:         ctrl.Log.WithName("controllers").WithName("HealthProbes"),
+			Catalog:     catalogStore,
+			Prober:      healthcheck.NewProber(),
+			Concurrency: cfg.HealthProbeConcurrency,
+			Damping:     cfg.HealthDamping,
+		}); err != nil {
+			setupLog.Error(err, "Failed to add health prober")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", func(req *http.Request) error {
 		return nil
 	}); err != nil {
@@ -160,7 +566,40 @@ func main() {
 	// Start REST API server as a managed runnable
 	if cfg.ApiAddr != "" && cfg.ApiAddr != "0" {
 		apiMux := http.NewServeMux()
-		apiMux.Handle("/api/v1/apps", apiserver.NewAppsHandler(mgr.GetClient(), ctrl.Log.WithName("apiserver")))
+		apiLog := ctrl.Log.WithName("apiserver")
+		// An output written to a Secret is sensitive, so is the catalog it
+		// is served from: its routes then require the API token, which
+		// Validate ensures is set
+		catalogRoute := func(h http.Handler) http.Handler {
+			if cfg.OutputKind == config.OutputKindSecret {
+				return apiserver.RequireBearerToken(cfg.APIToken, h)
+			}
+			return h
+		}
+		apiMux.Handle("/api/v1/apps", catalogRoute(apiserver.NewCatalogAppsHandler(catalogStore,
+			apiserver.NewAppsHandler(mgr.GetClient(), apiLog), apiLog)))
+		apiMux.Handle("/api/v1/apps/{id}", catalogRoute(apiserver.NewCatalogAppHandler(catalogStore, apiLog)))
+		apiMux.Handle("/api/v1/health", catalogRoute(apiserver.NewHealthHandler(catalogStore, apiLog)))
+		apiMux.Handle(apiserver.CatalogExportPath, catalogRoute(apiserver.NewCatalogExportHandler(catalogStore, apiLog)))
+		apiMux.Handle("/api/v1/openapi.json", apiserver.NewOpenAPIHandler(apiLog))
+		apiMux.Handle("/icons/{hash}", catalogRoute(apiserver.NewIconHandler(catalogStore, apiLog)))
+		if reconcileHistory != nil {
+			apiMux.Handle("/debug/reconciles", apiserver.NewReconcilesHandler(reconcileHistory, apiLog))
+		}
+		if cfg.APIToken != "" {
+			apiMux.Handle("/preview", apiserver.RequireBearerToken(cfg.APIToken,
+				apiserver.NewPreviewHandler(catalogStore, apiLog)))
+			registrations := apiserver.RequireBearerToken(cfg.APIToken,
+				apiserver.NewRegistrationHandler(mgr.GetClient(), cfg.RegistrationNamespaceOrDefault(), apiLog))
+			apiMux.Handle("/api/v1/registrations", registrations)
+			apiMux.Handle("/api/v1/registrations/{id}", registrations)
+		} else {
+			setupLog.Info("No API token configured, preview and registration endpoints disabled")
+		}
+		if cfg.DispatchSecret != "" {
+			apiMux.Handle("/api/v1/dispatch", apiserver.NewDispatchHandler(mgr.GetClient(),
+				cfg.RegistrationNamespaceOrDefault(), cfg.DispatchSecret, apiLog))
+		}
 		apiMux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		})
@@ -189,3 +628,32 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// namespacesCache restricts a cached kind to the namespaces cfg watches:
+// the watched namespaces when listed, otherwise every namespace but the
+// excluded ones.
+func namespacesCache(cfg *config.OperatorConfig) cache.ByObject {
+	if len(cfg.WatchNamespaces) > 0 {
+		namespaces := make(map[string]cache.Config, len(cfg.WatchNamespaces))
+		for _, namespace := range cfg.WatchNamespaces {
+			namespaces[namespace] = cache.Config{}
+		}
+		return cache.ByObject{Namespaces: namespaces}
+	}
+	excluded := make([]fields.Selector, 0, len(cfg.ExcludeNamespaces))
+	for _, namespace := range cfg.ExcludeNamespaces {
+		excluded = append(excluded, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+	}
+	return cache.ByObject{Field: fields.AndSelectors(excluded...)}
+}
+
+// splitList parses a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
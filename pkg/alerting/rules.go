@@ -0,0 +1,94 @@
+// Package alerting builds Prometheus Operator PrometheusRules alerting on
+// DashboardApps reported down by the duro_app_health_status metric.
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+const (
+	// OptOutLabel set to "false" on a DashboardApp keeps it from getting an
+	// alerting rule
+	OptOutLabel = "dashboard.homelab.io/alerts"
+
+	// SourceLabel marks PrometheusRules generated by the operator
+	SourceLabel = "dashboard.homelab.io/generated-by"
+
+	// DefaultFor is how long an app must be down before the alert fires
+	DefaultFor = 5 * time.Minute
+
+	// AlertName names the generated alert
+	AlertName = "DuroAppDown"
+)
+
+// GroupVersionKind is the PrometheusRule kind of the Prometheus Operator
+var GroupVersionKind = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+// Enabled reports whether the app wants an alerting rule.
+func Enabled(app *dashboardv1alpha1.DashboardApp) bool {
+	return app.Labels[OptOutLabel] != "false"
+}
+
+// RuleName is the name of the PrometheusRule generated for an app, in the
+// app's namespace.
+func RuleName(app *dashboardv1alpha1.DashboardApp) string {
+	return "duro-app-" + app.Name
+}
+
+// NewRule returns an empty PrometheusRule, e.g. to Get one into.
+func NewRule() *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(GroupVersionKind)
+	return u
+}
+
+// Spec returns the spec of the PrometheusRule alerting when the app has
+// been down for longer than forDuration.
+func Spec(app *dashboardv1alpha1.DashboardApp, forDuration time.Duration) map[string]interface{} {
+	name := app.Spec.Name
+	if name == "" {
+		name = app.Name
+	}
+	return map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name": "duro-app-" + app.Namespace + "-" + app.Name,
+				"rules": []interface{}{
+					map[string]interface{}{
+						"alert": AlertName,
+						"expr":  fmt.Sprintf(`duro_app_health_status{namespace=%q,app=%q,state="down"} == 1`, app.Namespace, app.Name),
+						"for":   promDuration(forDuration),
+						"labels": map[string]interface{}{
+							"severity":  "warning",
+							"namespace": app.Namespace,
+							"app":       app.Name,
+						},
+						"annotations": map[string]interface{}{
+							"summary":     fmt.Sprintf("%s is down", name),
+							"description": fmt.Sprintf("Dashboard app %s/%s (%s) has been reported down for more than %s.", app.Namespace, app.Name, app.Spec.URL, promDuration(forDuration)),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// promDuration formats a duration the way Prometheus expects it, e.g. "5m" or
+// "90s".
+func promDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
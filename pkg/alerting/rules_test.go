@@ -0,0 +1,72 @@
+package alerting
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func TestSpec(t *testing.T) {
+	app := &dashboardv1alpha1.DashboardApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"},
+		Spec:       dashboardv1alpha1.DashboardAppSpec{Name: "Plex", URL: "https://plex.example.test"},
+	}
+	spec := Spec(app, 90*time.Second)
+	group := spec["groups"].([]interface{})[0].(map[string]interface{})
+	rule := group["rules"].([]interface{})[0].(map[string]interface{})
+
+	if rule["alert"] != AlertName {
+		t.Errorf("alert = %v, want %s", rule["alert"], AlertName)
+	}
+	if want := `duro_app_health_status{namespace="media",app="plex",state="down"} == 1`; rule["expr"] != want {
+		t.Errorf("expr = %v, want %s", rule["expr"], want)
+	}
+	if rule["for"] != "90s" {
+		t.Errorf("for = %v, want 90s", rule["for"])
+	}
+	summary := rule["annotations"].(map[string]interface{})["summary"].(string)
+	if !strings.Contains(summary, "Plex") {
+		t.Errorf("summary = %q, want the display name", summary)
+	}
+}
+
+func TestPromDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{5 * time.Minute, "5m"},
+		{2 * time.Hour, "2h"},
+		{90 * time.Second, "90s"},
+		{1500 * time.Millisecond, "1s"},
+	}
+	for _, tt := range tests {
+		if got := promDuration(tt.in); got != tt.want {
+			t.Errorf("promDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   bool
+	}{
+		{"no label", nil, true},
+		{"opted in", map[string]string{OptOutLabel: "true"}, true},
+		{"opted out", map[string]string{OptOutLabel: "false"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &dashboardv1alpha1.DashboardApp{ObjectMeta: metav1.ObjectMeta{Labels: tt.labels}}
+			if got := Enabled(app); got != tt.want {
+				t.Errorf("Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
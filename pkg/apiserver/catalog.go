@@ -0,0 +1,111 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+)
+
+// HealthResponse summarizes the state of the live catalog.
+type HealthResponse struct {
+	// Status is "ok" once a catalog has been assembled, "pending" before
+	Status      string         `json:"status"`
+	AssembledAt *time.Time     `json:"assembledAt,omitempty"`
+	Apps        int            `json:"apps"`
+	AppHealth   map[string]int `json:"appHealth,omitempty"`
+}
+
+// NewCatalogAppsHandler returns an http.Handler serving the assembled catalog
+// entries, in dashboard order. Until the first assembly completes, requests
+// are delegated to fallback (typically NewAppsHandler reading the CR cache).
+func NewCatalogAppsHandler(store *catalog.Store, fallback http.Handler, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		result, _ := store.Get()
+		if result == nil {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, result.Entries, log)
+	})
+}
+
+// NewCatalogAppHandler returns an http.Handler serving a single catalog entry
+// by ID, taken from the {id} path value.
+func NewCatalogAppHandler(store *catalog.Store, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		result, _ := store.Get()
+		if result == nil {
+			http.Error(w, `{"error":"catalog not assembled yet"}`, http.StatusServiceUnavailable)
+			return
+		}
+
+		id := r.PathValue("id")
+		for _, e := range result.Entries {
+			if e.ID == id {
+				writeJSON(w, http.StatusOK, e, log)
+				return
+			}
+		}
+		http.Error(w, `{"error":"app not found"}`, http.StatusNotFound)
+	})
+}
+
+// NewHealthHandler returns an http.Handler reporting whether a catalog has
+// been assembled, how many apps it holds and their health breakdown.
+func NewHealthHandler(store *catalog.Store, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		result, assembledAt := store.Get()
+		if result == nil {
+			writeJSON(w, http.StatusServiceUnavailable, HealthResponse{Status: "pending"}, log)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, HealthResponse{
+			Status:      "ok",
+			AssembledAt: &assembledAt,
+			Apps:        len(result.Entries),
+			AppHealth:   healthCounts(result.Entries),
+		}, log)
+	})
+}
+
+func healthCounts(entries []assembler.AppEntry) map[string]int {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		state := e.Health
+		if state == "" {
+			state = "unknown"
+		}
+		counts[state]++
+	}
+	return counts
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any, log logr.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error(err, "Failed to encode response")
+	}
+}
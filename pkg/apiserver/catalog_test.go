@@ -0,0 +1,114 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+)
+
+func newCatalogMux(store *catalog.Store, fallback http.Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/apps", NewCatalogAppsHandler(store, fallback, logr.Discard()))
+	mux.Handle("/api/v1/apps/{id}", NewCatalogAppHandler(store, logr.Discard()))
+	mux.Handle("/api/v1/health", NewHealthHandler(store, logr.Discard()))
+	return mux
+}
+
+func TestCatalogAPI(t *testing.T) {
+	store := catalog.NewStore()
+	store.Set(&assembler.AssemblyResult{Entries: []assembler.AppEntry{
+		{ID: "plex", Name: "Plex", Category: "media", Health: "up"},
+		{ID: "gitea", Name: "Gitea", Category: "development", Health: "down"},
+		{ID: "wiki", Name: "Wiki", Category: "productivity"},
+	}})
+	mux := newCatalogMux(store, http.NotFoundHandler())
+
+	t.Run("list", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rr.Code)
+		}
+		var got []assembler.AppEntry
+		if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(got) != 3 || got[0].ID != "plex" {
+			t.Errorf("unexpected apps %+v", got)
+		}
+	})
+
+	t.Run("get by id", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps/gitea", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rr.Code)
+		}
+		var got assembler.AppEntry
+		if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.Name != "Gitea" {
+			t.Errorf("got %+v, want Gitea", got)
+		}
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps/nope", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", rr.Code)
+		}
+	})
+
+	t.Run("health", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rr.Code)
+		}
+		var got HealthResponse
+		if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.Status != "ok" || got.Apps != 3 || got.AssembledAt == nil {
+			t.Errorf("unexpected health %+v", got)
+		}
+		if got.AppHealth["up"] != 1 || got.AppHealth["down"] != 1 || got.AppHealth["unknown"] != 1 {
+			t.Errorf("unexpected health breakdown %v", got.AppHealth)
+		}
+	})
+}
+
+func TestCatalogAPI_NotAssembled(t *testing.T) {
+	fallbackCalled := false
+	fallback := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+	mux := newCatalogMux(catalog.NewStore(), fallback)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps", nil))
+	if !fallbackCalled {
+		t.Errorf("list should fall back to the CR cache before the first assembly")
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/apps/plex", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("get: status = %d, want 503", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("health: status = %d, want 503", rr.Code)
+	}
+}
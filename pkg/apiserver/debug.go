@@ -0,0 +1,33 @@
+package apiserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fredericrous/duro-operator/pkg/history"
+)
+
+// NewReconcilesHandler returns an http.Handler serving the recent reconcile
+// outcomes, newest first. The optional limit query parameter bounds how
+// many are returned.
+func NewReconcilesHandler(ring *history.Ring, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		records := ring.List()
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit < 0 {
+				http.Error(w, `{"error":"limit must be a non-negative integer"}`, http.StatusBadRequest)
+				return
+			}
+			records = records[:min(limit, len(records))]
+		}
+		writeJSON(w, http.StatusOK, records, log)
+	})
+}
@@ -0,0 +1,58 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fredericrous/duro-operator/pkg/history"
+)
+
+func TestReconcilesHandler(t *testing.T) {
+	ring := history.NewRing(5)
+	ring.Add(history.Reconcile{TraceID: "a"})
+	ring.Add(history.Reconcile{TraceID: "b", Error: "boom"})
+	ring.Add(history.Reconcile{TraceID: "c"})
+	h := NewReconcilesHandler(ring, logr.Discard())
+
+	tests := []struct {
+		name     string
+		method   string
+		query    string
+		wantCode int
+		wantIDs  []string
+	}{
+		{name: "all", method: http.MethodGet, wantCode: http.StatusOK, wantIDs: []string{"c", "b", "a"}},
+		{name: "limit", method: http.MethodGet, query: "?limit=2", wantCode: http.StatusOK, wantIDs: []string{"c", "b"}},
+		{name: "limit above size", method: http.MethodGet, query: "?limit=10", wantCode: http.StatusOK, wantIDs: []string{"c", "b", "a"}},
+		{name: "invalid limit", method: http.MethodGet, query: "?limit=-1", wantCode: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, wantCode: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(tt.method, "/debug/reconciles"+tt.query, nil))
+			if rr.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var got []history.Reconcile
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("got %d reconciles, want %d", len(got), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if got[i].TraceID != id {
+					t.Errorf("reconcile %d = %q, want %q", i, got[i].TraceID, id)
+				}
+			}
+		})
+	}
+}
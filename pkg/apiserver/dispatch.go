@@ -0,0 +1,145 @@
+package apiserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	goerrors "errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SourceDispatch marks DashboardApps created through the dispatch
+	// receiver; only those may be updated or deleted through it
+	SourceDispatch = "dispatch"
+
+	// RepositoryAnnotation records the repository that dispatched an app
+	RepositoryAnnotation = "dashboard.homelab.io/repository"
+
+	// DispatchEventType is the repository dispatch event type publishing
+	// an app; DispatchRemovedEventType removes it
+	DispatchEventType        = "dashboard-app"
+	DispatchRemovedEventType = "dashboard-app-removed"
+)
+
+// DispatchPayload is a repository dispatch event as GitHub delivers it to
+// webhooks. CI without repository dispatch (e.g. Gitea Actions) posts the
+// same document itself.
+type DispatchPayload struct {
+	// Action is the event type of the dispatch
+	Action string `json:"action"`
+
+	// ClientPayload describes the app; only its id is needed to remove it
+	ClientPayload RegistrationRequest `json:"client_payload"`
+
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// NewDispatchHandler returns an http.Handler receiving repository dispatch
+// webhooks from GitHub or Gitea, so a repository's CI can publish its app
+// without cluster credentials. Deliveries must be signed with secret
+// (X-Hub-Signature-256, or X-Gitea-Signature); a dashboard-app event
+// creates or updates the DashboardApp named after the payload ID in
+// namespace, a dashboard-app-removed event deletes it. Other events are
+// acknowledged and ignored. Apps not created through the receiver are never
+// touched.
+func NewDispatchHandler(c client.Client, namespace, secret string, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRegistrationBody))
+		if err != nil {
+			http.Error(w, `{"error":"body too large"}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+		if !validSignature(r.Header, body, secret) {
+			http.Error(w, `{"error":"invalid signature"}`, http.StatusUnauthorized)
+			return
+		}
+
+		event := r.Header.Get("X-GitHub-Event")
+		if event == "" {
+			event = r.Header.Get("X-Gitea-Event")
+		}
+		if event != "" && event != "repository_dispatch" {
+			// Pings and events the hook was subscribed to by mistake
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var payload DispatchPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, `{"error":"invalid JSON body"}`, http.StatusBadRequest)
+			return
+		}
+		req := payload.ClientPayload
+		log := log.WithValues("id", req.ID, "repository", payload.Repository.FullName)
+
+		switch payload.Action {
+		case DispatchEventType:
+			if err := req.Validate(); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()}, log)
+				return
+			}
+			created, err := upsertApp(r.Context(), c, namespace, req.ID, SourceDispatch, req.spec(),
+				map[string]string{RepositoryAnnotation: payload.Repository.FullName})
+			switch {
+			case goerrors.Is(err, errNotOwned):
+				http.Error(w, `{"error":"app exists and was not dispatched"}`, http.StatusConflict)
+			case err != nil:
+				log.Error(err, "Failed to apply dispatched DashboardApp")
+				http.Error(w, `{"error":"failed to apply app"}`, http.StatusInternalServerError)
+			case created:
+				log.Info("Created dispatched app")
+				writeJSON(w, http.StatusCreated, req, log)
+			default:
+				log.V(1).Info("Updated dispatched app")
+				writeJSON(w, http.StatusOK, req, log)
+			}
+		case DispatchRemovedEventType:
+			switch err := deleteApp(r.Context(), c, namespace, req.ID, SourceDispatch); {
+			case apierrors.IsNotFound(err):
+				w.WriteHeader(http.StatusNoContent)
+			case goerrors.Is(err, errNotOwned):
+				http.Error(w, `{"error":"app was not dispatched"}`, http.StatusConflict)
+			case err != nil:
+				log.Error(err, "Failed to delete dispatched DashboardApp")
+				http.Error(w, `{"error":"failed to remove app"}`, http.StatusInternalServerError)
+			default:
+				log.Info("Removed dispatched app")
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			// Dispatches meant for other consumers
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+// validSignature checks the HMAC-SHA256 signature of body, as sent by GitHub
+// ("sha256=<hex>" in X-Hub-Signature-256) or Gitea (hex in
+// X-Gitea-Signature).
+func validSignature(h http.Header, body []byte, secret string) bool {
+	sig, ok := strings.CutPrefix(h.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		sig = h.Get("X-Gitea-Signature")
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
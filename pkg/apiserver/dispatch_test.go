@@ -0,0 +1,100 @@
+package apiserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+const dispatchSecret = "s3cret"
+
+func dispatchBody(action, id string) string {
+	return `{"action":"` + action + `","repository":{"full_name":"homelab/nas"},"client_payload":` +
+		strings.Replace(registrationBody, `"nas"`, `"`+id+`"`, 1) + `}`
+}
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(dispatchSecret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestDispatchHandler(t *testing.T) {
+	managed := &dashboardv1alpha1.DashboardApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "duro"},
+		Spec:       dashboardv1alpha1.DashboardAppSpec{Name: "Plex"},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(managed).Build()
+	h := NewDispatchHandler(c, "duro", dispatchSecret, logr.Discard())
+
+	tests := []struct {
+		name    string
+		body    string
+		headers map[string]string
+		want    int
+	}{
+		{"unsigned", dispatchBody(DispatchEventType, "nas"), nil, http.StatusUnauthorized},
+		{"bad signature", dispatchBody(DispatchEventType, "nas"),
+			map[string]string{"X-Hub-Signature-256": "sha256=" + sign("other")}, http.StatusUnauthorized},
+		{"github create", dispatchBody(DispatchEventType, "nas"),
+			map[string]string{"X-GitHub-Event": "repository_dispatch", "X-Hub-Signature-256": "sha256=" + sign(dispatchBody(DispatchEventType, "nas"))}, http.StatusCreated},
+		{"gitea update", dispatchBody(DispatchEventType, "nas"),
+			map[string]string{"X-Gitea-Signature": sign(dispatchBody(DispatchEventType, "nas"))}, http.StatusOK},
+		{"ping ignored", `{"zen":"hi"}`,
+			map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": "sha256=" + sign(`{"zen":"hi"}`)}, http.StatusNoContent},
+		{"other dispatch ignored", dispatchBody("deploy", "nas"),
+			map[string]string{"X-Gitea-Signature": sign(dispatchBody("deploy", "nas"))}, http.StatusNoContent},
+		{"invalid app", dispatchBody(DispatchEventType, "Not_A_Label"),
+			map[string]string{"X-Gitea-Signature": sign(dispatchBody(DispatchEventType, "Not_A_Label"))}, http.StatusBadRequest},
+		{"app not dispatched", dispatchBody(DispatchEventType, "plex"),
+			map[string]string{"X-Gitea-Signature": sign(dispatchBody(DispatchEventType, "plex"))}, http.StatusConflict},
+		{"remove app not dispatched", dispatchBody(DispatchRemovedEventType, "plex"),
+			map[string]string{"X-Gitea-Signature": sign(dispatchBody(DispatchRemovedEventType, "plex"))}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/dispatch", strings.NewReader(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rr.Code, tt.want, rr.Body.String())
+			}
+		})
+	}
+
+	app := &dashboardv1alpha1.DashboardApp{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "duro", Name: "nas"}, app); err != nil {
+		t.Fatalf("get dispatched app: %v", err)
+	}
+	if app.Labels[SourceLabel] != SourceDispatch || app.Annotations[RepositoryAnnotation] != "homelab/nas" {
+		t.Errorf("unexpected dispatched app metadata %+v", app.ObjectMeta)
+	}
+
+	body := dispatchBody(DispatchRemovedEventType, "nas")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/dispatch", strings.NewReader(body))
+	req.Header.Set("X-Gitea-Signature", sign(body))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("remove: status = %d, want 204", rr.Code)
+	}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "duro", Name: "nas"}, app); err == nil {
+		t.Errorf("dispatched app still exists after removal")
+	}
+}
@@ -0,0 +1,203 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/csv"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+)
+
+// Media types of the catalog export
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeYAML = "application/yaml"
+	MediaTypeCSV  = "text/csv"
+)
+
+// exportFormats maps the format query parameter and the accepted aliases of
+// each media type to the media type served
+var exportFormats = map[string]string{
+	"json":               MediaTypeJSON,
+	"yaml":               MediaTypeYAML,
+	"csv":                MediaTypeCSV,
+	MediaTypeJSON:        MediaTypeJSON,
+	MediaTypeYAML:        MediaTypeYAML,
+	"application/x-yaml": MediaTypeYAML,
+	"text/yaml":          MediaTypeYAML,
+	MediaTypeCSV:         MediaTypeCSV,
+}
+
+// CatalogRecord is an app of the catalog export, flattened for inventory
+// tools. Its JSON form is described by the CatalogRecord schema of
+// OpenAPIDocument; the CSV columns are its JSON field names, lists joined
+// with ";".
+type CatalogRecord struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	URL          string   `json:"url"`
+	InternalURL  string   `json:"internalURL"`
+	Category     string   `json:"category"`
+	CategoryName string   `json:"categoryName"`
+	Description  string   `json:"description"`
+	Tags         []string `json:"tags"`
+	Groups       []string `json:"groups"`
+	Health       string   `json:"health"`
+	Source       string   `json:"source"`
+}
+
+// csvColumns are the CSV header, in CatalogRecord field order
+var csvColumns = []string{"id", "name", "url", "internalURL", "category", "categoryName", "description", "tags", "groups", "health", "source"}
+
+func (c CatalogRecord) csvRow() []string {
+	return []string{c.ID, c.Name, c.URL, c.InternalURL, c.Category, c.CategoryName, c.Description,
+		strings.Join(c.Tags, ";"), strings.Join(c.Groups, ";"), c.Health, c.Source}
+}
+
+// NewCatalogExportHandler returns an http.Handler exporting the assembled
+// catalog as JSON, YAML or CSV, chosen by the format query parameter (json,
+// yaml, csv) or else by the Accept header, JSON by default. Apps kept
+// listed during their removal grace period are left out.
+func NewCatalogExportHandler(store *catalog.Store, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		mediaType, ok := exportMediaType(r)
+		if !ok {
+			http.Error(w, `{"error":"supported formats are application/json, application/yaml and text/csv"}`, http.StatusNotAcceptable)
+			return
+		}
+
+		result, assembledAt := store.Get()
+		if result == nil {
+			http.Error(w, `{"error":"catalog not assembled yet"}`, http.StatusServiceUnavailable)
+			return
+		}
+		records := catalogRecords(result)
+		w.Header().Set("Last-Modified", assembledAt.UTC().Format(http.TimeFormat))
+
+		var body []byte
+		var err error
+		switch mediaType {
+		case MediaTypeJSON:
+			writeJSON(w, http.StatusOK, records, log)
+			return
+		case MediaTypeYAML:
+			body, err = yaml.Marshal(records)
+		case MediaTypeCSV:
+			body, err = catalogCSV(records)
+		}
+		if err != nil {
+			log.Error(err, "Failed to encode catalog export", "mediaType", mediaType)
+			http.Error(w, `{"error":"failed to encode catalog"}`, http.StatusInternalServerError)
+			return
+		}
+		if mediaType == MediaTypeCSV {
+			w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="catalog.csv"`)
+		} else {
+			w.Header().Set("Content-Type", mediaType)
+		}
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(body); err != nil {
+			log.V(1).Info("Failed to write catalog export", "error", err.Error())
+		}
+	})
+}
+
+// exportMediaType picks the media type of an export request. An explicit
+// format parameter wins, as spreadsheet imports can't set headers;
+// otherwise the Accept media range with the highest quality is served, the
+// first listed on ties.
+func exportMediaType(r *http.Request) (string, bool) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		mediaType, ok := exportFormats[strings.ToLower(format)]
+		return mediaType, ok
+	}
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return MediaTypeJSON, true
+	}
+
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		var mediaType string
+		switch mediaRange {
+		case "*/*", "application/*":
+			mediaType = MediaTypeJSON
+		case "text/*":
+			mediaType = MediaTypeCSV
+		default:
+			mediaType = exportFormats[mediaRange]
+		}
+		if mediaType != "" && q > bestQ {
+			best, bestQ = mediaType, q
+		}
+	}
+	return best, best != ""
+}
+
+// catalogRecords flattens the entries of result, in dashboard order.
+func catalogRecords(result *assembler.AssemblyResult) []CatalogRecord {
+	names := make(map[string]string, len(result.Categories))
+	for _, c := range result.Categories {
+		names[c.ID] = c.DisplayName
+	}
+	records := make([]CatalogRecord, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		if e.Removed {
+			continue
+		}
+		// Lists are empty rather than null, as the schema says
+		records = append(records, CatalogRecord{
+			ID:           e.ID,
+			Name:         e.Name,
+			URL:          e.URL,
+			InternalURL:  e.InternalURL,
+			Category:     e.Category,
+			CategoryName: names[e.Category],
+			Description:  e.Description,
+			Tags:         append([]string{}, e.Tags...),
+			Groups:       append([]string{}, e.Groups...),
+			Health:       e.Health,
+			Source:       e.Source,
+		})
+	}
+	return records
+}
+
+// catalogCSV writes records as CSV with a header row.
+func catalogCSV(records []CatalogRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	if err := cw.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		if err := cw.Write(rec.csvRow()); err != nil {
+			return nil, err
+		}
+	}
+	cw.Flush()
+	return buf.Bytes(), cw.Error()
+}
@@ -0,0 +1,142 @@
+package apiserver
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+)
+
+func TestCatalogExport(t *testing.T) {
+	store := catalog.NewStore()
+	store.Set(&assembler.AssemblyResult{
+		Entries: []assembler.AppEntry{
+			{ID: "plex", Name: "Plex", Category: "media", URL: "https://plex.lan", Tags: []string{"video", "family"}, Health: "up", Source: "media/plex"},
+			{ID: "old", Name: "Old", Category: "media", Removed: true},
+			{ID: "wiki", Name: "Wiki, internal", Category: "productivity", Groups: []string{"staff"}},
+		},
+		Categories: []assembler.CategoryEntry{{ID: "media", DisplayName: "Media"}, {ID: "productivity", DisplayName: "Productivity"}},
+	})
+	handler := NewCatalogExportHandler(store, logr.Discard())
+
+	tests := []struct {
+		name     string
+		query    string
+		accept   string
+		wantCode int
+		wantType string
+	}{
+		{"default", "", "", http.StatusOK, MediaTypeJSON},
+		{"any", "", "*/*", http.StatusOK, MediaTypeJSON},
+		{"yaml", "", "application/yaml", http.StatusOK, MediaTypeYAML},
+		{"yaml alias", "", "text/yaml", http.StatusOK, MediaTypeYAML},
+		{"csv", "", "text/csv", http.StatusOK, MediaTypeCSV},
+		{"quality", "", "application/json;q=0.5, text/csv;q=0.9, */*;q=0.1", http.StatusOK, MediaTypeCSV},
+		{"format wins", "?format=yaml", "text/csv", http.StatusOK, MediaTypeYAML},
+		{"unsupported accept", "", "application/xml", http.StatusNotAcceptable, ""},
+		{"unsupported format", "?format=xml", "", http.StatusNotAcceptable, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, CatalogExportPath+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Fatalf("Content-Type = %q, want %s", got, tt.wantType)
+			}
+
+			var records []CatalogRecord
+			switch tt.wantType {
+			case MediaTypeJSON:
+				if err := json.Unmarshal(rr.Body.Bytes(), &records); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+			case MediaTypeYAML:
+				if err := yaml.Unmarshal(rr.Body.Bytes(), &records); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+			case MediaTypeCSV:
+				rows, err := csv.NewReader(rr.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if !slices.Equal(rows[0], csvColumns) || len(rows) != 3 {
+					t.Fatalf("rows = %q", rows)
+				}
+				if rows[1][7] != "video;family" || rows[2][1] != "Wiki, internal" {
+					t.Errorf("rows = %q", rows)
+				}
+				return
+			}
+			if len(records) != 2 || records[0].CategoryName != "Media" || records[0].Source != "media/plex" || records[1].ID != "wiki" {
+				t.Errorf("records = %+v", records)
+			}
+		})
+	}
+}
+
+func TestCatalogExport_NotAssembled(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewCatalogExportHandler(catalog.NewStore(), logr.Discard()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, CatalogExportPath, nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rr.Code)
+	}
+}
+
+// TestOpenAPIDocument keeps the published schema in step with the records
+// actually served.
+func TestOpenAPIDocument(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewOpenAPIHandler(logr.Discard()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	var doc struct {
+		Paths      map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, ok := doc.Paths[CatalogExportPath]; !ok {
+		t.Errorf("paths = %v, want %s", doc.Paths, CatalogExportPath)
+	}
+
+	var fields []string
+	typ := reflect.TypeOf(CatalogRecord{})
+	for i := range typ.NumField() {
+		fields = append(fields, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+	}
+	if !slices.Equal(fields, csvColumns) {
+		t.Errorf("CSV columns = %v, want %v", csvColumns, fields)
+	}
+	var properties []string
+	for name := range doc.Components.Schemas["CatalogRecord"].Properties {
+		properties = append(properties, name)
+	}
+	slices.Sort(properties)
+	slices.Sort(fields)
+	if !slices.Equal(properties, fields) {
+		t.Errorf("schema properties = %v, want %v", properties, fields)
+	}
+}
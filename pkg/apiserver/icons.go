@@ -0,0 +1,48 @@
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+)
+
+// NewIconHandler returns an http.Handler serving the catalog's externalized
+// icons by the {hash} path value. Icons are content-addressed, so responses
+// are cacheable forever; a changed icon gets a new URL in the output and the
+// old one is purged with the previous catalog.
+func NewIconHandler(store *catalog.Store, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		hash := r.PathValue("hash")
+		icon, ok := store.Icon(hash)
+		if !ok {
+			http.Error(w, "icon not found", http.StatusNotFound)
+			return
+		}
+
+		etag := `"` + hash + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		// Icons are user-supplied SVG; never let one run script when opened directly
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			if _, err := w.Write([]byte(icon)); err != nil {
+				log.V(1).Info("Failed to write icon", "hash", hash, "error", err.Error())
+			}
+		}
+	})
+}
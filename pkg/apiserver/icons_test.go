@@ -0,0 +1,60 @@
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+)
+
+func TestIconHandler(t *testing.T) {
+	store := catalog.NewStore()
+	key := assembler.IconKey("<svg/>")
+	store.Set(&assembler.AssemblyResult{Icons: map[string]string{key: "<svg/>"}})
+
+	mux := http.NewServeMux()
+	mux.Handle("/icons/{hash}", NewIconHandler(store, logr.Discard()))
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		ifNoneMatch string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"found", http.MethodGet, "/icons/" + key, "", http.StatusOK, "<svg/>"},
+		{"head", http.MethodHead, "/icons/" + key, "", http.StatusOK, ""},
+		{"not modified", http.MethodGet, "/icons/" + key, `"` + key + `"`, http.StatusNotModified, ""},
+		{"unknown", http.MethodGet, "/icons/deadbeef", "", http.StatusNotFound, "icon not found\n"},
+		{"wrong method", http.MethodPost, "/icons/" + key, "", http.StatusMethodNotAllowed, "method not allowed\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if rr.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rr.Body.String(), tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if ct := rr.Header().Get("Content-Type"); ct != "image/svg+xml" {
+					t.Errorf("Content-Type = %q", ct)
+				}
+				if cc := rr.Header().Get("Cache-Control"); cc == "" {
+					t.Error("missing Cache-Control")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/go-logr/logr"
+)
+
+// CatalogExportPath is where NewCatalogExportHandler is served, as
+// published in OpenAPIDocument
+const CatalogExportPath = "/api/v1/catalog"
+
+// OpenAPIDocument returns the OpenAPI 3.0 description of the catalog
+// export, for inventory tools generating their import from it.
+func OpenAPIDocument() map[string]any {
+	str := map[string]any{"type": "string"}
+	list := map[string]any{"type": "array", "items": str}
+	record := map[string]any{
+		"type":     "object",
+		"required": []string{"id", "name", "url", "category"},
+		"properties": map[string]any{
+			"id":           withDescription(str, "Unique ID of the app on the dashboard"),
+			"name":         withDescription(str, "Display name"),
+			"url":          withDescription(str, "URL the dashboard links to"),
+			"internalURL":  withDescription(str, "In-cluster URL, if any"),
+			"category":     withDescription(str, "Category ID"),
+			"categoryName": withDescription(str, "Display name of the category"),
+			"description":  withDescription(str, "Short description"),
+			"tags":         withDescription(list, "Search tags; joined with ; in CSV"),
+			"groups":       withDescription(list, "Groups allowed to see the app, everyone when empty; joined with ; in CSV"),
+			"health":       withDescription(str, "Last known health (up, down, degraded, unknown), empty when not checked"),
+			"source":       withDescription(str, "namespace/name of the DashboardApp"),
+		},
+	}
+	records := map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/CatalogRecord"}}
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{"description": description}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "duro-operator catalog",
+			"version": "v1",
+		},
+		"paths": map[string]any{
+			CatalogExportPath: map[string]any{
+				"get": map[string]any{
+					"operationId": "exportCatalog",
+					"summary":     "Export the apps of the dashboard in dashboard order",
+					"parameters": []any{
+						map[string]any{
+							"name":        "format",
+							"in":          "query",
+							"description": "Overrides the Accept header",
+							"schema":      map[string]any{"type": "string", "enum": []string{"json", "yaml", "csv"}},
+						},
+					},
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "The catalog",
+							"content": map[string]any{
+								MediaTypeJSON: map[string]any{"schema": records},
+								MediaTypeYAML: map[string]any{"schema": records},
+								MediaTypeCSV: map[string]any{"schema": map[string]any{
+									"type":        "string",
+									"description": "One row per CatalogRecord after a header row naming its properties",
+								}},
+							},
+						},
+						"406": errorResponse("None of the accepted media types is supported"),
+						"503": errorResponse("No catalog has been assembled yet"),
+					},
+				},
+			},
+		},
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"CatalogRecord": record,
+			},
+		},
+	}
+}
+
+func withDescription(schema map[string]any, description string) map[string]any {
+	out := make(map[string]any, len(schema)+1)
+	for k, v := range schema {
+		out[k] = v
+	}
+	out["description"] = description
+	return out
+}
+
+// NewOpenAPIHandler returns an http.Handler serving OpenAPIDocument as JSON.
+func NewOpenAPIHandler(log logr.Logger) http.Handler {
+	doc := OpenAPIDocument()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, doc, log)
+	})
+}
@@ -0,0 +1,54 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+)
+
+// PreviewResponse is the catalog as seen by a user in the requested groups.
+type PreviewResponse struct {
+	Groups      []string             `json:"groups"`
+	AssembledAt time.Time            `json:"assembledAt"`
+	Apps        []assembler.AppEntry `json:"apps"`
+}
+
+// NewPreviewHandler returns an http.Handler rendering the live catalog as a
+// member of the groups given in the query (e.g. /preview?group=family&group=media/kids)
+// would see it, so visibility rules can be checked without logging into duro.
+func NewPreviewHandler(store *catalog.Store, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		userGroups := r.URL.Query()["group"]
+		if len(userGroups) == 0 {
+			http.Error(w, `{"error":"at least one group query parameter is required"}`, http.StatusBadRequest)
+			return
+		}
+
+		result, assembledAt := store.Get()
+		if result == nil {
+			http.Error(w, `{"error":"catalog not assembled yet"}`, http.StatusServiceUnavailable)
+			return
+		}
+
+		resp := PreviewResponse{
+			Groups:      userGroups,
+			AssembledAt: assembledAt,
+			Apps:        assembler.ForGroups(result.Entries, userGroups),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Error(err, "Failed to encode preview response")
+		}
+	})
+}
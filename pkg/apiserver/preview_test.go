@@ -0,0 +1,81 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/catalog"
+)
+
+func TestNewPreviewHandler(t *testing.T) {
+	store := catalog.NewStore()
+	store.Set(&assembler.AssemblyResult{Entries: []assembler.AppEntry{
+		{ID: "plex", Name: "Plex", Groups: []string{"family", "media/*"}},
+		{ID: "gitea", Name: "Gitea", Groups: []string{"lldap_admin"}},
+		{ID: "minecraft", Name: "Minecraft", Groups: []string{"media/kids"}, HiddenGroups: []string{"media/kids"}},
+	}})
+	h := NewPreviewHandler(store, logr.Discard())
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"single group", "?group=family", []string{"plex"}},
+		{"hierarchical group", "?group=media/kids", []string{"plex"}},
+		{"multiple groups", "?group=family&group=lldap_admin", []string{"plex", "gitea"}},
+		{"no match", "?group=strangers", []string{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/preview"+tc.query, nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200; body=%s", rr.Code, rr.Body.String())
+			}
+			var got PreviewResponse
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			ids := make([]string, 0, len(got.Apps))
+			for _, a := range got.Apps {
+				ids = append(ids, a.ID)
+			}
+			if len(ids) != len(tc.want) {
+				t.Fatalf("apps = %v, want %v", ids, tc.want)
+			}
+			for i := range ids {
+				if ids[i] != tc.want[i] {
+					t.Errorf("apps = %v, want %v", ids, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPreviewHandler_Errors(t *testing.T) {
+	empty := NewPreviewHandler(catalog.NewStore(), logr.Discard())
+
+	rr := httptest.NewRecorder()
+	empty.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/preview?group=family", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("empty store: status = %d, want 503", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	empty.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/preview", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("missing group: status = %d, want 400", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	empty.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/preview?group=family", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status = %d, want 405", rr.Code)
+	}
+}
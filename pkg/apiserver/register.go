@@ -0,0 +1,217 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/groups"
+)
+
+const (
+	// SourceLabel records who manages a DashboardApp
+	SourceLabel = dashboardv1alpha1.SourceLabel
+
+	// SourceExternal marks DashboardApps created through the registration
+	// endpoint; only those may be updated or deleted through it
+	SourceExternal = "external"
+
+	// maxRegistrationBody bounds the request body (icons are inline SVG)
+	maxRegistrationBody = 1 << 20
+)
+
+// RegistrationRequest describes an app running outside Kubernetes.
+type RegistrationRequest struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	Category string   `json:"category"`
+	Icon     string   `json:"icon"`
+	Groups   []string `json:"groups"`
+	Priority int      `json:"priority,omitempty"`
+
+	// TTL, a Go duration such as "10m", removes the app unless it is
+	// registered again within that time
+	TTL string `json:"ttl,omitempty"`
+}
+
+// Validate checks the request carries everything a DashboardApp needs.
+func (r *RegistrationRequest) Validate() error {
+	if errs := validation.IsDNS1123Label(r.ID); len(errs) > 0 {
+		return fmt.Errorf("id %q: %s", r.ID, strings.Join(errs, "; "))
+	}
+	required := []struct{ field, value string }{
+		{"name", r.Name}, {"url", r.URL}, {"category", r.Category}, {"icon", r.Icon},
+	}
+	for _, f := range required {
+		if f.value == "" {
+			return fmt.Errorf("%s is required", f.field)
+		}
+	}
+	if len(r.Groups) == 0 {
+		return fmt.Errorf("at least one group is required")
+	}
+	for _, g := range r.Groups {
+		if err := groups.ValidatePattern(g); err != nil {
+			return err
+		}
+	}
+	if r.TTL != "" {
+		if d, err := time.ParseDuration(r.TTL); err != nil || d <= 0 {
+			return fmt.Errorf("ttl %q must be a positive duration", r.TTL)
+		}
+	}
+	return nil
+}
+
+// NewRegistrationHandler returns an http.Handler that materializes external
+// registrations as DashboardApp CRs in namespace. POST creates or updates the
+// app named after the request ID and refreshes its heartbeat, so agents can
+// re-register periodically to keep a TTL-bound entry alive; DELETE on the {id}
+// path removes it. Apps not carrying the external source label are never
+// touched.
+func NewRegistrationHandler(c client.Client, namespace string, log logr.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			register(w, r, c, namespace, log)
+		case http.MethodDelete:
+			deregister(w, r, c, namespace, log)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func register(w http.ResponseWriter, r *http.Request, c client.Client, namespace string, log logr.Logger) {
+	var req RegistrationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRegistrationBody)).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid JSON body"}`, http.StatusBadRequest)
+		return
+	}
+	if id := r.PathValue("id"); id != "" && id != req.ID {
+		http.Error(w, `{"error":"id in body does not match path"}`, http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()}, log)
+		return
+	}
+
+	heartbeat := time.Now().UTC().Format(time.RFC3339)
+	created, err := upsertApp(r.Context(), c, namespace, req.ID, SourceExternal, req.spec(),
+		map[string]string{dashboardv1alpha1.HeartbeatAnnotation: heartbeat})
+	switch {
+	case goerrors.Is(err, errNotOwned):
+		http.Error(w, `{"error":"app exists and is not externally registered"}`, http.StatusConflict)
+	case err != nil:
+		log.Error(err, "Failed to register DashboardApp", "id", req.ID)
+		http.Error(w, `{"error":"failed to register app"}`, http.StatusInternalServerError)
+	case created:
+		log.Info("Registered external app", "id", req.ID)
+		writeJSON(w, http.StatusCreated, req, log)
+	default:
+		writeJSON(w, http.StatusOK, req, log)
+	}
+}
+
+func deregister(w http.ResponseWriter, r *http.Request, c client.Client, namespace string, log logr.Logger) {
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, `{"error":"app id is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	switch err := deleteApp(r.Context(), c, namespace, id, SourceExternal); {
+	case apierrors.IsNotFound(err):
+		http.Error(w, `{"error":"app not found"}`, http.StatusNotFound)
+	case goerrors.Is(err, errNotOwned):
+		http.Error(w, `{"error":"app is not externally registered"}`, http.StatusConflict)
+	case err != nil:
+		log.Error(err, "Failed to deregister DashboardApp", "id", id)
+		http.Error(w, `{"error":"failed to deregister app"}`, http.StatusInternalServerError)
+	default:
+		log.Info("Deregistered external app", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// errNotOwned reports a DashboardApp carrying another source label than the
+// one of the endpoint asked to change it
+var errNotOwned = goerrors.New("app is managed by another source")
+
+// upsertApp creates the DashboardApp id in namespace with spec, labelled
+// with source, or updates it if it carries that label already. annotations
+// are added to it. It reports whether the app was created.
+func upsertApp(ctx context.Context, c client.Client, namespace, id, source string, spec dashboardv1alpha1.DashboardAppSpec, annotations map[string]string) (bool, error) {
+	app := &dashboardv1alpha1.DashboardApp{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: id}, app)
+	switch {
+	case apierrors.IsNotFound(err):
+		app = &dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        id,
+				Namespace:   namespace,
+				Labels:      map[string]string{SourceLabel: source},
+				Annotations: annotations,
+			},
+			Spec: spec,
+		}
+		return true, c.Create(ctx, app)
+	case err != nil:
+		return false, err
+	case app.Labels[SourceLabel] != source:
+		return false, errNotOwned
+	}
+
+	app.Spec = spec
+	if app.Annotations == nil {
+		app.Annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(app.Annotations, annotations)
+	return false, c.Update(ctx, app)
+}
+
+// deleteApp deletes the DashboardApp id in namespace if it is labelled with
+// source.
+func deleteApp(ctx context.Context, c client.Client, namespace, id, source string) error {
+	app := &dashboardv1alpha1.DashboardApp{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: id}, app); err != nil {
+		return err
+	}
+	if app.Labels[SourceLabel] != source {
+		return errNotOwned
+	}
+	return client.IgnoreNotFound(c.Delete(ctx, app))
+}
+
+func (r *RegistrationRequest) spec() dashboardv1alpha1.DashboardAppSpec {
+	priority := r.Priority
+	if priority == 0 {
+		priority = 100
+	}
+	spec := dashboardv1alpha1.DashboardAppSpec{
+		Name:     r.Name,
+		URL:      r.URL,
+		Category: r.Category,
+		Icon:     r.Icon,
+		Groups:   r.Groups,
+		Priority: priority,
+	}
+	if ttl, err := time.ParseDuration(r.TTL); err == nil {
+		spec.TTL = &metav1.Duration{Duration: ttl}
+	}
+	return spec
+}
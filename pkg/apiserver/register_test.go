@@ -0,0 +1,99 @@
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+const registrationBody = `{"id":"nas","name":"NAS","url":"http://nas.lan","category":"storage","icon":"<svg/>","groups":["admins"]}`
+
+func newRegistrationMux(c client.Client) *http.ServeMux {
+	h := NewRegistrationHandler(c, "duro", logr.Discard())
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/registrations", h)
+	mux.Handle("/api/v1/registrations/{id}", h)
+	return mux
+}
+
+func TestRegistrationHandler(t *testing.T) {
+	managed := &dashboardv1alpha1.DashboardApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "duro"},
+		Spec:       dashboardv1alpha1.DashboardAppSpec{Name: "Plex"},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(managed).Build()
+	mux := newRegistrationMux(c)
+
+	do := func(method, path, body string) int {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr.Code
+	}
+
+	if code := do(http.MethodPost, "/api/v1/registrations", registrationBody); code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201", code)
+	}
+	app := &dashboardv1alpha1.DashboardApp{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "duro", Name: "nas"}, app); err != nil {
+		t.Fatalf("get registered app: %v", err)
+	}
+	if app.Labels[SourceLabel] != SourceExternal || app.Spec.Name != "NAS" || app.Spec.Priority != 100 {
+		t.Errorf("unexpected registered app %+v", app)
+	}
+	if _, ok := app.Annotations[dashboardv1alpha1.HeartbeatAnnotation]; !ok {
+		t.Errorf("registered app has no heartbeat annotation")
+	}
+	if app.Spec.TTL != nil {
+		t.Errorf("ttl = %v, want none", app.Spec.TTL)
+	}
+
+	updated := strings.Replace(registrationBody, `"NAS"`, `"Storage","ttl":"10m"`, 1)
+	if code := do(http.MethodPost, "/api/v1/registrations/nas", updated); code != http.StatusOK {
+		t.Fatalf("update: status = %d, want 200", code)
+	}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "duro", Name: "nas"}, app); err != nil {
+		t.Fatalf("get registered app: %v", err)
+	}
+	if app.Spec.Name != "Storage" {
+		t.Errorf("name = %q, want Storage", app.Spec.Name)
+	}
+	if app.Spec.TTL == nil || app.Spec.TTL.Duration != 10*time.Minute {
+		t.Errorf("ttl = %v, want 10m", app.Spec.TTL)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"path mismatch", http.MethodPost, "/api/v1/registrations/other", registrationBody, http.StatusBadRequest},
+		{"invalid id", http.MethodPost, "/api/v1/registrations", strings.Replace(registrationBody, `"nas"`, `"NAS!"`, 1), http.StatusBadRequest},
+		{"missing groups", http.MethodPost, "/api/v1/registrations", strings.Replace(registrationBody, `["admins"]`, `[]`, 1), http.StatusBadRequest},
+		{"invalid ttl", http.MethodPost, "/api/v1/registrations", strings.Replace(registrationBody, `"NAS"`, `"NAS","ttl":"-1s"`, 1), http.StatusBadRequest},
+		{"malformed body", http.MethodPost, "/api/v1/registrations", "{", http.StatusBadRequest},
+		{"overwrite managed app", http.MethodPost, "/api/v1/registrations", strings.Replace(registrationBody, `"nas"`, `"plex"`, 1), http.StatusConflict},
+		{"delete managed app", http.MethodDelete, "/api/v1/registrations/plex", "", http.StatusConflict},
+		{"delete unknown app", http.MethodDelete, "/api/v1/registrations/nope", "", http.StatusNotFound},
+		{"wrong method", http.MethodGet, "/api/v1/registrations", "", http.StatusMethodNotAllowed},
+		{"delete", http.MethodDelete, "/api/v1/registrations/nas", "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := do(tt.method, tt.path, tt.body); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package assembler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/groups"
+)
+
+// EntryAction is a quick action of an entry, rendered by duro as a button
+// (see dashboardv1alpha1.AppAction)
+type EntryAction struct {
+	Name   string `json:"name"`
+	Icon   string `json:"icon,omitempty"`
+	URL    string `json:"url"`
+	Method string `json:"method"`
+
+	// Groups restricts the action to their members; everyone seeing the
+	// entry gets it when empty
+	Groups []string `json:"groups,omitempty"`
+}
+
+// actionMethod returns the method of an action, GET if unset.
+func actionMethod(action *dashboardv1alpha1.AppAction) string {
+	if action.Method == "" {
+		return "GET"
+	}
+	return strings.ToUpper(action.Method)
+}
+
+// actionViolations lists what is wrong with the app's actions.
+func (a *Assembler) actionViolations(app *dashboardv1alpha1.DashboardApp) []string {
+	var out []string
+	seen := make(map[string]bool, len(app.Spec.Actions))
+	for i := range app.Spec.Actions {
+		action := &app.Spec.Actions[i]
+		if seen[action.Name] {
+			out = append(out, fmt.Sprintf("action %q is listed twice", action.Name))
+		}
+		seen[action.Name] = true
+		if method := actionMethod(action); !slices.Contains(dashboardv1alpha1.ActionMethods, method) {
+			out = append(out, fmt.Sprintf("action %q: unsupported method %s (want one of %s)", action.Name, method, strings.Join(dashboardv1alpha1.ActionMethods, ", ")))
+		}
+		if _, err := a.renderTemplate(app, "action "+action.Name+" url", action.URL); err != nil {
+			out = append(out, err.Error())
+		}
+		for _, g := range action.Groups {
+			if err := groups.ValidatePattern(g); err != nil {
+				out = append(out, fmt.Sprintf("action %q: %v", action.Name, err))
+			}
+		}
+	}
+	return out
+}
+
+// entryActions renders the app's actions for its entry. Actions that are
+// invalid (see actionViolations) are left out rather than failing the
+// assembly.
+func (a *Assembler) entryActions(app *dashboardv1alpha1.DashboardApp) []EntryAction {
+	if len(app.Spec.Actions) == 0 {
+		return nil
+	}
+	actions := make([]EntryAction, 0, len(app.Spec.Actions))
+	for i := range app.Spec.Actions {
+		action := &app.Spec.Actions[i]
+		method := actionMethod(action)
+		if !slices.Contains(dashboardv1alpha1.ActionMethods, method) ||
+			slices.ContainsFunc(actions, func(e EntryAction) bool { return e.Name == action.Name }) {
+			a.Log.Info("Leaving out invalid action", "app", app.Name, "namespace", app.Namespace, "action", action.Name)
+			continue
+		}
+		url, err := a.renderTemplate(app, "action "+action.Name+" url", action.URL)
+		if err != nil {
+			a.Log.Info("Leaving out action with an invalid URL", "app", app.Name, "namespace", app.Namespace, "action", action.Name, "error", err.Error())
+			continue
+		}
+		icon := action.Icon
+		if u, ok := a.IconLibraries.URL(icon); ok {
+			icon = u
+		}
+		actions = append(actions, EntryAction{Name: action.Name, Icon: icon, URL: url, Method: method, Groups: action.Groups})
+	}
+	return actions
+}
+
+// forUser returns the entry as seen by a user belonging to the given
+// groups, without the actions restricted to other groups.
+func (e AppEntry) forUser(userGroups []string) AppEntry {
+	if !slices.ContainsFunc(e.Actions, func(action EntryAction) bool { return len(action.Groups) > 0 }) {
+		return e
+	}
+	e.Actions = slices.DeleteFunc(slices.Clone(e.Actions), func(action EntryAction) bool {
+		return len(action.Groups) > 0 && !groups.Visible(action.Groups, userGroups)
+	})
+	return e
+}
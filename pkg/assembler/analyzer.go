@@ -0,0 +1,92 @@
+package assembler
+
+import (
+	"cmp"
+	"slices"
+)
+
+// normalizedPriorityStep is the spacing used when suggesting priorities, so
+// new apps can be slotted between existing ones without renumbering
+const normalizedPriorityStep = 10
+
+// PriorityCollision lists entries sharing the same priority within a category.
+// Their relative order then depends on the name tie-breaker only.
+type PriorityCollision struct {
+	Category string   `json:"category"`
+	Priority int      `json:"priority"`
+	Sources  []string `json:"sources"`
+}
+
+// PriorityReport is the result of AnalyzePriorities
+type PriorityReport struct {
+	Collisions []PriorityCollision `json:"collisions"`
+
+	// Suggestions maps entry sources (namespace/name) in categories with
+	// collisions to a normalized priority preserving the current order
+	Suggestions map[string]int `json:"suggestions"`
+}
+
+// CollisionFor returns the collision the source takes part in, if any.
+func (r *PriorityReport) CollisionFor(source string) (PriorityCollision, bool) {
+	for _, c := range r.Collisions {
+		if slices.Contains(c.Sources, source) {
+			return c, true
+		}
+	}
+	return PriorityCollision{}, false
+}
+
+// AnalyzePriorities reports priority collisions within each category of the
+// (already sorted) entries and suggests evenly spaced priorities that keep
+// today's effective order deterministic.
+func AnalyzePriorities(entries []AppEntry) *PriorityReport {
+	report := &PriorityReport{
+		Collisions:  make([]PriorityCollision, 0),
+		Suggestions: make(map[string]int),
+	}
+
+	byCategory := make(map[string][]AppEntry)
+	var categories []string
+	for _, e := range entries {
+		if _, ok := byCategory[e.Category]; !ok {
+			categories = append(categories, e.Category)
+		}
+		byCategory[e.Category] = append(byCategory[e.Category], e)
+	}
+
+	for _, category := range categories {
+		catEntries := byCategory[category]
+
+		byPriority := make(map[int][]string)
+		for _, e := range catEntries {
+			byPriority[e.Priority] = append(byPriority[e.Priority], e.Source)
+		}
+
+		collided := false
+		for priority, sources := range byPriority {
+			if len(sources) < 2 {
+				continue
+			}
+			collided = true
+			report.Collisions = append(report.Collisions, PriorityCollision{
+				Category: category,
+				Priority: priority,
+				Sources:  sources,
+			})
+		}
+
+		if collided {
+			for i, e := range catEntries {
+				report.Suggestions[e.Source] = (i + 1) * normalizedPriorityStep
+			}
+		}
+	}
+
+	slices.SortFunc(report.Collisions, func(a, b PriorityCollision) int {
+		if c := cmp.Compare(a.Category, b.Category); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+	return report
+}
@@ -1,24 +1,142 @@
 package assembler
 
 import (
-	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
+	"maps"
 	"slices"
+	"time"
 
 	"github.com/go-logr/logr"
 
 	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/facts"
+	"github.com/fredericrous/duro-operator/pkg/groups"
+	"github.com/fredericrous/duro-operator/pkg/iconlib"
+	"github.com/fredericrous/duro-operator/pkg/iconpolicy"
+	"github.com/fredericrous/duro-operator/pkg/usage"
 )
 
 // Assembler handles DashboardApp configuration assembly
 type Assembler struct {
 	Log logr.Logger
+
+	// OutputGroups lists concrete groups for which a filtered copy of the
+	// apps JSON is rendered (see AssemblyResult.GroupsJSON)
+	OutputGroups []string
+
+	// ShardByCategory renders one apps JSON per category (see
+	// AssemblyResult.CategoryShards)
+	ShardByCategory bool
+
+	// Checksums renders a per-entry and per-icon checksum document (see
+	// AssemblyResult.ChecksumsJSON)
+	Checksums bool
+
+	// Variables are operator-level values (cluster domain, external suffix)
+	// available to spec.url templates
+	Variables map[string]string
+
+	// Categories holds DashboardCategory specs keyed by category ID
+	Categories map[string]dashboardv1alpha1.DashboardCategorySpec
+
+	// Bookmarks are listed in the bookmarks section of the output, next to
+	// the apps (see WithBookmarks)
+	Bookmarks []dashboardv1alpha1.DashboardBookmark
+
+	// FallbackCategory, if set, receives entries whose category is dangling
+	// (see AssemblyResult.DanglingCategories) instead of a ghost category
+	FallbackCategory string
+
+	// Usage holds per-app usage counts keyed by app ID, if imported
+	Usage usage.Counts
+
+	// Facts are the cluster facts spec.condition is evaluated against
+	Facts *facts.Facts
+
+	// IDTemplate, if set, generates entry IDs from the app's variables
+	// (name, namespace, category, hash, and Variables) instead of using the
+	// app name, e.g. "{{ .namespace }}-{{ .name }}"
+	IDTemplate string
+
+	// IconPolicy keeps icons free of external references and oversized
+	// raster data (see iconpolicy.Policy); the zero value is off
+	IconPolicy iconpolicy.Policy
+
+	// IconResolver, if set, fetches the icons apps reference by
+	// spec.iconURL or an icon library shorthand so they are inlined like
+	// raw SVG; otherwise the URL is passed through as the entry's icon
+	IconResolver IconResolver
+
+	// IconFetchBudget bounds how long Assemble waits on IconResolver; icons
+	// still being fetched are left empty and the catalog is assembled again
+	// shortly (0 waits for every fetch)
+	IconFetchBudget time.Duration
+
+	// IconLibraries resolves spec.icon shorthands such as "sh:plex" to icon
+	// URLs; spec.icon is taken as raw SVG when nil
+	IconLibraries iconlib.Libraries
+
+	// IconBaseURL, if set, replaces inline icons in the output with
+	// IconBaseURL/<IconKey> and collects them in AssemblyResult.Icons to be
+	// served separately
+	IconBaseURL string
+
+	// IconConfigMap, if set, moves app icons out of the output into a
+	// ConfigMap of this name in each app's namespace, referenced from the
+	// entry's IconRef and collected in AssemblyResult.NamespaceIcons
+	IconConfigMap string
+
+	// IconsKey moves app icons out of the apps documents into the icons.json
+	// document of the output (AssemblyResult.IconsJSON), referenced from the
+	// entry's IconRef, for catalogs whose inline icons outgrow the
+	// ConfigMap's apps key. It has no effect with IconConfigMap
+	IconsKey bool
+
+	// HealthDamping is how many consecutive health check results must report
+	// a new health state before it shows in the output (0 or 1 publishes
+	// every change)
+	HealthDamping int
+
+	// DuplicateNamePolicy is what happens to apps sharing a display name:
+	// DuplicateNamesOff (default), DuplicateNamesFlag or DuplicateNamesSuffix
+	DuplicateNamePolicy string
+
+	// Strict leaves every app of a namespace out of the output when one of
+	// them has a validation finding (see AssemblyResult.StrictFailures)
+	// instead of rendering what it can
+	Strict bool
+
+	// Hooks transform the sorted entries before categories are built and
+	// the output is formatted
+	Hooks []EntryHook
+
+	// HookFailurePolicy is what a failing hook does: HookFailureIgnore
+	// (default) or HookFailureFail
+	HookFailurePolicy string
+
+	// Sort names the strategy ordering entries within a category (see
+	// RegisterSort; SortCategory if empty)
+	Sort string
+
+	// PreviousOrder lists entry IDs by category in the order of a previous
+	// output; with the default sort, entries of equal priority keep their
+	// previous relative position (see WithPreviousOrder)
+	PreviousOrder map[string][]string
+
+	// NewWindow badges entries created less than this long ago as new
+	// (0 disables the badge)
+	NewWindow time.Duration
+
+	// Clock returns the time time-dependent features (visibility schedules,
+	// heartbeats, new badges) are evaluated at
+	Clock func() time.Time
 }
 
 // NewAssembler creates a new Assembler
 func NewAssembler(log logr.Logger) *Assembler {
-	return &Assembler{Log: log}
+	return &Assembler{Log: log, Clock: time.Now}
 }
 
 // AppEntry represents a single app in the output JSON
@@ -30,6 +148,71 @@ type AppEntry struct {
 	Icon     string   `json:"icon"`
 	Groups   []string `json:"groups"`
 	Priority int      `json:"priority"`
+
+	// Actions are the app's quick actions; per-group outputs only carry
+	// those the group may use
+	Actions []EntryAction `json:"actions,omitempty"`
+
+	// Access carries the app's spec.access, for dashboards combining
+	// groups; Groups lists its anyOf groups too, for those that don't
+	Access *EntryAccess `json:"access,omitempty"`
+
+	// InternalURL is the app's cluster/LAN endpoint; URL stays the
+	// external one
+	InternalURL string `json:"internalURL,omitempty"`
+
+	// Description is a short blurb shown under the app's tile
+	Description string `json:"description,omitempty"`
+
+	// Tags are the app's tags, deduplicated and sorted
+	Tags []string `json:"tags,omitempty"`
+
+	// Extra holds the app's spec.extra fields verbatim
+	Extra map[string]string `json:"extra,omitempty"`
+
+	// Health is the app's effective health after rolling up dependencies
+	Health string `json:"health,omitempty"`
+
+	// HealthReason explains a non-up health state (e.g. "dependency db/postgres is down")
+	HealthReason string `json:"healthReason,omitempty"`
+
+	// DependsOn lists the IDs of apps this app depends on
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Usage is how often the app was opened, when usage counts are imported
+	Usage int64 `json:"usage,omitempty"`
+
+	// New is set for apps created within the assembler's NewWindow
+	New bool `json:"new,omitempty"`
+
+	// Stale is set when the app's agent stopped sending heartbeats
+	Stale bool `json:"stale,omitempty"`
+
+	// Removed is set while a deleted app is kept listed for the removal
+	// grace period, so the dashboard can grey it out
+	Removed bool `json:"removed,omitempty"`
+
+	// IconRef points at the icon in the app's namespace when icons are
+	// kept in namespace ConfigMaps (see Assembler.IconConfigMap)
+	IconRef *IconRef `json:"iconRef,omitempty"`
+
+	// HiddenGroups lists groups the app is temporarily hidden from by its
+	// visibility schedule
+	HiddenGroups []string `json:"hiddenGroups,omitempty"`
+
+	// Source is the namespace/name of the DashboardApp the entry was built from
+	Source string `json:"-"`
+
+	// CreatedAt is when the DashboardApp was created
+	CreatedAt time.Time `json:"-"`
+}
+
+// EntryAccess is who sees an entry beyond the OR logic of its groups (see
+// AppEntry.VisibleTo)
+type EntryAccess struct {
+	AnyOf  []string `json:"anyOf,omitempty"`
+	AllOf  []string `json:"allOf,omitempty"`
+	NoneOf []string `json:"noneOf,omitempty"`
 }
 
 // categoryOrder defines the display order for categories
@@ -45,49 +228,430 @@ var categoryOrder = map[string]int{
 type AssemblyResult struct {
 	Entries  []AppEntry
 	AppsJSON string
+
+	Categories     []CategoryEntry
+	CategoriesJSON string
+
+	// IDCollisions lists apps dropped because another app has the same ID
+	IDCollisions []IDCollision
+
+	// DanglingCategories maps apps (namespace/name) to the category they
+	// reference that is neither a DashboardCategory nor built in
+	DanglingCategories map[string]string
+
+	// DuplicateNames lists apps sharing a display name, unless
+	// DuplicateNamePolicy is off
+	DuplicateNames []NameDuplicate
+
+	// StrictFailures lists the namespaces left out of the output under
+	// Strict, in namespace order
+	StrictFailures []StrictFailure
+
+	// Icons holds the externalized icons keyed by IconKey (see IconBaseURL)
+	Icons map[string]string
+
+	// NamespaceIcons holds the app icons moved out of the output, keyed by
+	// namespace then ConfigMap key (see IconConfigMap)
+	NamespaceIcons map[string]map[string]string
+
+	// IconsJSON holds the app icons moved out of the entries keyed by
+	// IconRefKey, when IconsKey is set
+	IconsJSON string
+
+	// IconBytes maps apps (namespace/name) to the size of the icon left in
+	// their entry, to tell what takes up the output
+	IconBytes map[string]int
+
+	// GroupCatalog lists the groups the published apps are visible to
+	GroupCatalog     []GroupEntry
+	GroupCatalogJSON string
+
+	// Tags lists the tags of the published apps
+	Tags     []TagEntry
+	TagsJSON string
+
+	// Bookmarks are the links of the bookmarks section, BookmarksJSON empty
+	// when there are none
+	Bookmarks     []BookmarkEntry
+	BookmarksJSON string
+
+	// ChecksumsJSON holds the Checksums of the published entries when
+	// Checksums is set
+	ChecksumsJSON string
+
+	// GroupsJSON holds the apps JSON as seen by each of OutputGroups, keyed by group
+	GroupsJSON map[string]string
+
+	// CategoryShards holds the apps JSON of each category, keyed by
+	// category ID, when ShardByCategory is set
+	CategoryShards map[string]string
+
+	// Violations maps apps (namespace/name) to the rules they break under
+	// the current configuration (see Assembler.Violations)
+	Violations map[string][]string
+
+	// IconFailures maps apps (namespace/name) whose icon could not be
+	// resolved to the error, the app being listed without an icon
+	IconFailures map[string]string
+
+	// LeftOut maps the apps (namespace/name) left out of the output because
+	// they could not be rendered (template, schedule, condition or ID
+	// failures) to the error; it is reported in Violations too
+	LeftOut map[string]string
+
+	// NextTransition is the next time the output changes on its own (e.g. a
+	// visibility window opens or closes); zero if it never does
+	NextTransition time.Time
 }
 
 // Assemble processes all DashboardApps and produces a JSON array
 func (a *Assembler) Assemble(ctx context.Context, apps []dashboardv1alpha1.DashboardApp) (*AssemblyResult, error) {
 	entries := make([]AppEntry, 0, len(apps))
+	now := a.Clock()
+	var nextTransition time.Time
+	health := a.rollupHealth(apps, now)
+	ids, idFailures, err := a.entryIDs(apps)
+	if err != nil {
+		return nil, err
+	}
+	var dangling map[string]string
+	violations := make(map[string][]string)
+	iconFailures := make(map[string]string)
+	resolved := a.resolveIcons(ctx, apps)
+
+	// An app that can't be rendered is left out on its own, its error
+	// reported as a violation, rather than failing the whole catalog
+	leftOut := make(map[string]string)
+	leaveOut := func(app *dashboardv1alpha1.DashboardApp, err error) {
+		source := app.Namespace + "/" + app.Name
+		a.Log.Info("Leaving out app that can't be rendered", "app", app.Name, "namespace", app.Namespace, "error", err.Error())
+		if !slices.Contains(violations[source], err.Error()) {
+			violations[source] = append(violations[source], err.Error())
+		}
+		leftOut[source] = err.Error()
+	}
+
+	for i := range apps {
+		app := &apps[i]
+		if v := a.Violations(app); len(v) > 0 {
+			violations[app.Namespace+"/"+app.Name] = v
+		}
+
+		if app.Disabled() {
+			a.Log.V(1).Info("App disabled", "app", app.Name, "namespace", app.Namespace)
+			continue
+		}
+		if err := idFailures[app.Namespace+"/"+app.Name]; err != nil {
+			leaveOut(app, err)
+			continue
+		}
+
+		met, err := a.conditionMet(app)
+		if err != nil {
+			leaveOut(app, err)
+			continue
+		}
+		if !met {
+			a.Log.V(1).Info("App hidden by its condition", "app", app.Name, "namespace", app.Namespace)
+			continue
+		}
+
+		url, err := a.renderTemplate(app, "url", app.Spec.URL)
+		if err != nil {
+			leaveOut(app, err)
+			continue
+		}
+		internalURL, err := a.renderTemplate(app, "internalURL", app.Spec.InternalURL)
+		if err != nil {
+			leaveOut(app, err)
+			continue
+		}
+		description, err := a.renderTemplate(app, "description", app.Spec.Description)
+		if err != nil {
+			leaveOut(app, err)
+			continue
+		}
+
+		hidden, next, err := hiddenGroups(app, now)
+		if err != nil {
+			leaveOut(app, err)
+			continue
+		}
+		nextTransition = earliest(nextTransition, next)
+
+		if !app.HasAccessGroups() {
+			a.Log.V(1).Info("App has no groups, not listing it", "app", app.Name, "namespace", app.Namespace)
+			continue
+		}
+		if app.Spec.Category == "" {
+			a.Log.V(1).Info("App has no category, not listing it", "app", app.Name, "namespace", app.Namespace)
+			continue
+		}
+		anyOf, err := validAnyOf(app)
+		if err != nil {
+			leaveOut(app, err)
+			continue
+		}
+		entryGroups := withoutGroups(anyOf, hidden)
+		access := entryAccess(app, entryGroups)
+		// Hidden anyOf groups grant nothing, and hidden allOf groups can't be met
+		hiddenFromAll := len(anyOf) > 0 && len(entryGroups) == 0
+		if access != nil && len(withoutGroups(access.AllOf, hidden)) < len(access.AllOf) {
+			hiddenFromAll = true
+		}
+		if hiddenFromAll {
+			a.Log.V(1).Info("App hidden from all its groups by visibility schedule", "app", app.Name, "namespace", app.Namespace)
+			continue
+		}
 
-	for _, app := range apps {
 		priority := app.Spec.Priority
 		if priority == 0 {
 			priority = 100
 		}
 
+		isNew, newUntil := a.recentlyAdded(app, now)
+		nextTransition = earliest(nextTransition, newUntil)
+
+		stale, staleAt := HeartbeatStale(app, now)
+		if !stale {
+			nextTransition = earliest(nextTransition, staleAt)
+		}
+
+		var dependsOn []string
+		for _, dep := range app.Spec.DependsOn {
+			id, ok := ids[dependencyKey(app, dep)]
+			if !ok {
+				id = dep.Name
+			}
+			dependsOn = append(dependsOn, id)
+		}
+
+		source := app.Namespace + "/" + app.Name
+		id := ids[source]
+		category := app.Spec.Category
+		if a.danglingCategory(category) {
+			if dangling == nil {
+				dangling = make(map[string]string)
+			}
+			dangling[source] = category
+			if a.FallbackCategory != "" {
+				category = a.FallbackCategory
+			}
+		}
+		icon, err := a.appIcon(app, resolved)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			// Published by the next assembly once the fetch completes
+			a.Log.V(1).Info("App icon still being fetched", "app", app.Name, "namespace", app.Namespace)
+			nextTransition = earliest(nextTransition, now.Add(iconPendingRecheck))
+		case err != nil:
+			a.Log.Info("Failed to fetch app icon", "app", app.Name, "namespace", app.Namespace, "error", err.Error())
+			iconFailures[source] = err.Error()
+		}
+		extra, err := extraFields(app.Spec.Extra)
+		if err != nil {
+			a.Log.Info("Leaving out oversized extra fields", "app", app.Name, "namespace", app.Namespace, "error", err.Error())
+		}
 		entries = append(entries, AppEntry{
-			ID:       app.Name,
-			Name:     app.Spec.Name,
-			URL:      app.Spec.URL,
-			Category: app.Spec.Category,
-			Icon:     app.Spec.Icon,
-			Groups:   app.Spec.Groups,
-			Priority: priority,
+			ID:           id,
+			Name:         app.Spec.Name,
+			URL:          url,
+			Category:     category,
+			Icon:         icon,
+			Groups:       entryGroups,
+			Access:       access,
+			Actions:      a.entryActions(app),
+			Priority:     priority,
+			InternalURL:  internalURL,
+			Description:  description,
+			Tags:         normalizeTags(app.Spec.Tags),
+			Extra:        extra,
+			Health:       string(health[source].State),
+			HealthReason: health[source].Reason,
+			DependsOn:    dependsOn,
+			Usage:        a.Usage[id],
+			New:          isNew,
+			Stale:        stale,
+			Removed:      !app.DeletionTimestamp.IsZero(),
+			HiddenGroups: hidden,
+			Source:       source,
+			CreatedAt:    app.CreationTimestamp.Time,
 		})
 	}
 
-	// Sort by category order, then priority, then name
-	slices.SortFunc(entries, func(a, b AppEntry) int {
-		ca := categoryOrder[a.Category]
-		cb := categoryOrder[b.Category]
-		if c := cmp.Compare(ca, cb); c != 0 {
-			return c
+	entries, collisions := dropIDCollisions(entries)
+	for _, c := range collisions {
+		a.Log.Info("Apps share an ID, keeping the first", "id", c.ID, "apps", c.Sources)
+	}
+
+	duplicates := a.resolveDuplicateNames(entries)
+	for _, d := range duplicates {
+		a.Log.V(1).Info("Apps share a display name", "name", d.Name, "apps", d.Sources)
+	}
+
+	var strictFailures []StrictFailure
+	if a.Strict {
+		entries, strictFailures = enforceStrict(entries, violations, dangling, duplicates, slices.Sorted(maps.Keys(leftOut)))
+		for _, f := range strictFailures {
+			a.Log.Info("Namespace left out of the output in strict mode", "namespace", f.Namespace, "apps", len(f.Findings))
 		}
-		if c := cmp.Compare(a.Priority, b.Priority); c != 0 {
-			return c
+	}
+
+	a.sortEntries(entries)
+	entries, err = a.runHooks(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	categories := a.buildCategories(entries)
+	a.enforceIconPolicy(entries, categories)
+	namespaceIcons := a.localizeIcons(entries)
+	keyIcons := a.keyIcons(entries)
+	icons := a.externalizeIcons(entries, categories)
+	iconBytes := make(map[string]int)
+	for _, e := range entries {
+		if e.Icon != "" {
+			iconBytes[e.Source] = len(e.Icon)
 		}
-		return cmp.Compare(a.Name, b.Name)
-	})
+	}
 
 	jsonBytes, err := json.MarshalIndent(entries, "", "  ")
 	if err != nil {
 		return nil, err
 	}
 
-	return &AssemblyResult{
-		Entries:  entries,
-		AppsJSON: string(jsonBytes),
-	}, nil
+	categoriesBytes, err := json.MarshalIndent(categories, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	groupCatalog := buildGroupCatalog(entries, categories)
+	groupCatalogBytes, err := json.MarshalIndent(groupCatalog, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	tags := buildTagIndex(entries)
+	tagsBytes, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AssemblyResult{
+		Entries:            entries,
+		AppsJSON:           string(jsonBytes),
+		Categories:         categories,
+		CategoriesJSON:     string(categoriesBytes),
+		GroupCatalog:       groupCatalog,
+		GroupCatalogJSON:   string(groupCatalogBytes),
+		Tags:               tags,
+		TagsJSON:           string(tagsBytes),
+		IDCollisions:       collisions,
+		DanglingCategories: dangling,
+		DuplicateNames:     duplicates,
+		StrictFailures:     strictFailures,
+		Icons:              icons,
+		NamespaceIcons:     namespaceIcons,
+		IconBytes:          iconBytes,
+		Violations:         violations,
+		LeftOut:            leftOut,
+		IconFailures:       iconFailures,
+		NextTransition:     nextTransition,
+	}
+
+	if keyIcons != nil {
+		iconsBytes, err := json.MarshalIndent(keyIcons, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		result.IconsJSON = string(iconsBytes)
+	}
+
+	if bookmarks := a.buildBookmarks(); len(bookmarks) > 0 {
+		bookmarksBytes, err := json.MarshalIndent(bookmarks, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		result.Bookmarks = bookmarks
+		result.BookmarksJSON = string(bookmarksBytes)
+	}
+
+	if a.Checksums {
+		sums, err := EntryChecksums(entries)
+		if err != nil {
+			return nil, err
+		}
+		sumsBytes, err := json.MarshalIndent(sums, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		result.ChecksumsJSON = string(sumsBytes)
+	}
+
+	if len(a.OutputGroups) > 0 {
+		result.GroupsJSON = make(map[string]string, len(a.OutputGroups))
+		for _, group := range a.OutputGroups {
+			groupBytes, err := json.MarshalIndent(ForGroup(entries, group), "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			result.GroupsJSON[group] = string(groupBytes)
+		}
+	}
+
+	if a.ShardByCategory {
+		result.CategoryShards = make(map[string]string, len(categories))
+		for _, cat := range categories {
+			shardBytes, err := json.MarshalIndent(ForCategory(entries, cat.ID), "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			result.CategoryShards[cat.ID] = string(shardBytes)
+		}
+	}
+
+	return result, nil
+}
+
+// ForGroup returns the entries visible to a member of the given group,
+// honouring wildcard and hierarchical patterns in each entry's groups and
+// hidden groups.
+func ForGroup(entries []AppEntry, group string) []AppEntry {
+	return ForGroups(entries, []string{group})
+}
+
+// ForGroups returns the entries visible to a user belonging to any of the
+// given groups, in catalog order, with only the actions the user may use.
+func ForGroups(entries []AppEntry, userGroups []string) []AppEntry {
+	visible := make([]AppEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.VisibleTo(userGroups) {
+			visible = append(visible, e.forUser(userGroups))
+		}
+	}
+	return visible
+}
+
+// VisibleTo reports whether a user belonging to the given groups sees the
+// entry: one of their groups is among the entry's groups, they belong to
+// groups matching each of its allOf groups and to none of its noneOf
+// groups. Groups the entry is hidden from grant nothing.
+func (e *AppEntry) VisibleTo(userGroups []string) bool {
+	if e.Access != nil {
+		if groups.Visible(e.Access.NoneOf, userGroups) {
+			return false
+		}
+		granted := slices.DeleteFunc(slices.Clone(userGroups), func(g string) bool { return groups.MatchAny(e.HiddenGroups, g) })
+		if !groups.VisibleAll(e.Access.AllOf, granted) {
+			return false
+		}
+		if len(e.Groups) == 0 {
+			return len(e.Access.AllOf) > 0
+		}
+	}
+	for _, g := range userGroups {
+		if groups.MatchAny(e.Groups, g) && !groups.MatchAny(e.HiddenGroups, g) {
+			return true
+		}
+	}
+	return false
 }
@@ -3,12 +3,23 @@ package assembler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/facts"
+	"github.com/fredericrous/duro-operator/pkg/iconlib"
+	"github.com/fredericrous/duro-operator/pkg/iconpolicy"
+	"github.com/fredericrous/duro-operator/pkg/usage"
 )
 
 func TestAssembler_Assemble(t *testing.T) {
@@ -163,6 +174,121 @@ func TestAssembler_DefaultPriority(t *testing.T) {
 	}
 }
 
+func TestAssembler_Description(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "default"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name:        "App1",
+				URL:         "https://app1.example.com",
+				Category:    "admin",
+				Icon:        "<svg/>",
+				Groups:      []string{"lldap_admin"},
+				Description: "Manages the cluster",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "app2", Namespace: "default"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name:     "App2",
+				URL:      "https://app2.example.com",
+				Category: "admin",
+				Icon:     "<svg/>",
+				Groups:   []string{"lldap_admin"},
+			},
+		},
+	}
+
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(result.AppsJSON), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal AppsJSON: %v", err)
+	}
+	if got := entries[0]["description"]; got != "Manages the cluster" {
+		t.Errorf("Expected description %q, got %v", "Manages the cluster", got)
+	}
+	if _, ok := entries[1]["description"]; ok {
+		t.Errorf("Expected no description for app2, got %v", entries[1]["description"])
+	}
+
+	t.Run("templated", func(t *testing.T) {
+		a := NewAssembler(log)
+		a.Variables = map[string]string{"clusterDomain": "cluster.local"}
+		templated := slices.Clone(apps)
+		templated[0].Spec.Description = "Manages {{ .clusterDomain }} from {{ .namespace }}"
+		templated[1].Spec.Description = "{{ .nope }}"
+		result, err := a.Assemble(context.Background(), templated)
+		if err != nil {
+			t.Fatalf("Assemble() error = %v", err)
+		}
+		if len(result.Entries) != 1 || result.Entries[0].Description != "Manages cluster.local from default" {
+			t.Errorf("entries = %+v, want only app1 with its description rendered", result.Entries)
+		}
+		if !strings.Contains(result.LeftOut["default/app2"], "description template") {
+			t.Errorf("LeftOut = %v, want app2 left out for its description template", result.LeftOut)
+		}
+	})
+}
+
+func TestAssembler_InternalURL(t *testing.T) {
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	a.Variables = map[string]string{"clusterDomain": "cluster.local"}
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name:        "Plex",
+				URL:         "https://plex.example.com",
+				InternalURL: "http://{{ .name }}.{{ .namespace }}.svc.{{ .clusterDomain }}:32400",
+				Category:    "media",
+				Icon:        "<svg/>",
+				Groups:      []string{"family"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "jellyfin", Namespace: "media"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name:     "Jellyfin",
+				URL:      "https://jellyfin.example.com",
+				Category: "media",
+				Icon:     "<svg/>",
+				Groups:   []string{"family"},
+			},
+		},
+	}
+
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(result.AppsJSON), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal AppsJSON: %v", err)
+	}
+	byID := map[string]map[string]interface{}{}
+	for _, e := range entries {
+		byID[e["id"].(string)] = e
+	}
+	if got := byID["plex"]["internalURL"]; got != "http://plex.media.svc.cluster.local:32400" {
+		t.Errorf("plex internalURL = %v", got)
+	}
+	if got := byID["plex"]["url"]; got != "https://plex.example.com" {
+		t.Errorf("plex url = %v", got)
+	}
+	if _, ok := byID["jellyfin"]["internalURL"]; ok {
+		t.Errorf("Expected no internalURL for jellyfin, got %v", byID["jellyfin"]["internalURL"])
+	}
+}
+
 func TestAssembler_EmptyInput(t *testing.T) {
 	log := zap.New(zap.UseDevMode(true))
 	a := NewAssembler(log)
@@ -182,3 +308,2053 @@ func TestAssembler_EmptyInput(t *testing.T) {
 		t.Fatalf("Failed to unmarshal empty AppsJSON: %v", err)
 	}
 }
+
+func TestAssembler_GroupOutputs(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+	a.OutputGroups = []string{"family", "media/kids"}
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "plex"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "Plex", URL: "https://plex.example.com", Category: "media",
+				Icon: "<svg/>", Groups: []string{"family", "media/*"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gitea", Namespace: "gitea"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "Gitea", URL: "https://gitea.example.com", Category: "development",
+				Icon: "<svg/>", Groups: []string{"lldap_admin"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "jellyfin", Namespace: "jellyfin"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "Jellyfin", URL: "https://jellyfin.example.com", Category: "media",
+				Icon: "<svg/>", Groups: []string{"fam*"},
+			},
+		},
+	}
+
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if len(result.GroupsJSON) != 2 {
+		t.Fatalf("Expected 2 group outputs, got %d", len(result.GroupsJSON))
+	}
+
+	names := func(group string) []string {
+		var entries []AppEntry
+		if err := json.Unmarshal([]byte(result.GroupsJSON[group]), &entries); err != nil {
+			t.Fatalf("Failed to unmarshal %s output: %v", group, err)
+		}
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+
+	if got := names("family"); len(got) != 2 || got[0] != "Jellyfin" || got[1] != "Plex" {
+		t.Errorf("family output = %v, want [Jellyfin Plex]", got)
+	}
+	if got := names("media/kids"); len(got) != 1 || got[0] != "Plex" {
+		t.Errorf("media/kids output = %v, want [Plex]", got)
+	}
+}
+
+func TestAssembler_ShardByCategory(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "plex"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "Plex", URL: "https://plex.example.com", Category: "media",
+				Icon: "<svg/>", Groups: []string{"family"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gitea", Namespace: "gitea"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "Gitea", URL: "https://gitea.example.com", Category: "development",
+				Icon: "<svg/>", Groups: []string{"lldap_admin"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "jellyfin", Namespace: "jellyfin"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "Jellyfin", URL: "https://jellyfin.example.com", Category: "media",
+				Icon: "<svg/>", Groups: []string{"family"},
+			},
+		},
+	}
+
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if result.CategoryShards != nil {
+		t.Fatalf("Expected no shards by default, got %v", result.CategoryShards)
+	}
+
+	a.ShardByCategory = true
+	result, err = a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if len(result.CategoryShards) != 2 {
+		t.Fatalf("Expected 2 category shards, got %d", len(result.CategoryShards))
+	}
+	var media []AppEntry
+	if err := json.Unmarshal([]byte(result.CategoryShards["media"]), &media); err != nil {
+		t.Fatalf("Failed to unmarshal media shard: %v", err)
+	}
+	if len(media) != 2 || media[0].Category != "media" || media[1].Category != "media" {
+		t.Errorf("media shard = %+v, want Plex and Jellyfin", media)
+	}
+
+	// Changing one category leaves the other shards untouched
+	apps[1].Spec.URL = "https://git.example.com"
+	changed, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if changed.CategoryShards["media"] != result.CategoryShards["media"] {
+		t.Error("media shard changed after editing a development app")
+	}
+	if changed.CategoryShards["development"] == result.CategoryShards["development"] {
+		t.Error("development shard did not change")
+	}
+}
+
+func TestAssembler_URLTemplate(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+	a.Variables = map[string]string{
+		"clusterDomain":  "cluster.local",
+		"externalSuffix": "home.example.com",
+		// per-app variables must not be overridable at operator level
+		"name": "ignored",
+	}
+
+	newApp := func(name, url string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "media"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: url, Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		want        string
+		wantLeftOut bool
+	}{
+		{"plain", "https://plex.example.com", "https://plex.example.com", false},
+		{"external", "https://{{ .name }}.{{ .externalSuffix }}", "https://plex.home.example.com", false},
+		{"internal", "http://{{ .name }}.{{ .namespace }}.svc.{{ .clusterDomain }}:32400", "http://plex.media.svc.cluster.local:32400", false},
+		{"unknown variable", "https://{{ .nope }}", "", true},
+		{"malformed", "https://{{ .name ", "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{newApp("plex", tc.url), newApp("sonarr", "https://sonarr")})
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			urls := map[string]string{}
+			for _, e := range result.Entries {
+				urls[e.ID] = e.URL
+			}
+			if urls["sonarr"] != "https://sonarr" {
+				t.Errorf("sonarr URL = %q, want the other apps listed", urls["sonarr"])
+			}
+			if tc.wantLeftOut {
+				if url, ok := urls["plex"]; ok {
+					t.Errorf("plex listed with URL %q, want it left out", url)
+				}
+				if result.LeftOut["media/plex"] == "" || len(result.Violations["media/plex"]) == 0 {
+					t.Errorf("LeftOut = %v, Violations = %v, want the template error reported for media/plex", result.LeftOut, result.Violations)
+				}
+				return
+			}
+			if got := urls["plex"]; got != tc.want {
+				t.Errorf("URL = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("strict mode leaves the namespace out", func(t *testing.T) {
+		strict := *a
+		strict.Strict = true
+		result, err := strict.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{newApp("plex", "https://{{ .nope }}"), newApp("sonarr", "https://sonarr")})
+		if err != nil {
+			t.Fatalf("Assemble() error = %v", err)
+		}
+		if len(result.Entries) != 0 {
+			t.Errorf("entries = %+v, want namespace media left out", result.Entries)
+		}
+		if _, ok := StrictFailureFor(result.StrictFailures, "media"); !ok {
+			t.Errorf("StrictFailures = %+v, want media", result.StrictFailures)
+		}
+	})
+}
+
+func TestAssembler_WithVariables(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	base := NewAssembler(log)
+	base.Variables = map[string]string{"externalSuffix": "old.example.com", "clusterDomain": "cluster.local"}
+
+	a := base.WithVariables(map[string]string{"externalSuffix": "new.example.com", "port": "8096"})
+
+	apps := []dashboardv1alpha1.DashboardApp{{
+		ObjectMeta: metav1.ObjectMeta{Name: "jellyfin", Namespace: "media"},
+		Spec: dashboardv1alpha1.DashboardAppSpec{
+			Name: "Jellyfin", URL: "https://{{ .name }}.{{ .externalSuffix }}:{{ .port }}",
+			Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+		},
+	}}
+
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if got, want := result.Entries[0].URL, "https://jellyfin.new.example.com:8096"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+	if base.Variables["externalSuffix"] != "old.example.com" {
+		t.Errorf("WithVariables must not mutate the receiver, got %v", base.Variables)
+	}
+}
+
+func TestAssembler_Categories(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log).WithCategories([]dashboardv1alpha1.DashboardCategory{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "games"},
+			Spec: dashboardv1alpha1.DashboardCategorySpec{
+				DisplayName: "Game Servers",
+				Icon:        "<svg>games</svg>",
+				Order:       -1,
+				Description: "Multiplayer servers",
+				Collapsed:   true,
+			},
+		},
+		{
+			// Not referenced by any app, must not appear in the output
+			ObjectMeta: metav1.ObjectMeta{Name: "unused"},
+			Spec:       dashboardv1alpha1.DashboardCategorySpec{Order: 50},
+		},
+	})
+
+	newApp := func(name, category string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: category, Icon: "<svg/>", Groups: []string{"family"},
+			},
+		}
+	}
+
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("gitea", "development"),
+		newApp("minecraft", "games"),
+		newApp("plex", "media"),
+		newApp("jellyfin", "media"),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	var categories []CategoryEntry
+	if err := json.Unmarshal([]byte(result.CategoriesJSON), &categories); err != nil {
+		t.Fatalf("Failed to unmarshal CategoriesJSON: %v", err)
+	}
+	if len(categories) != 3 {
+		t.Fatalf("Expected 3 categories, got %+v", categories)
+	}
+
+	// games (-1, from CR) → media (0, built-in) → development (3, built-in)
+	want := []CategoryEntry{
+		{ID: "games", DisplayName: "Game Servers", Icon: "<svg>games</svg>", Order: -1, Description: "Multiplayer servers", Collapsed: true},
+		{ID: "media", DisplayName: "media", Order: 0},
+		{ID: "development", DisplayName: "development", Order: 3},
+	}
+	for i := range want {
+		if categories[i] != want[i] {
+			t.Errorf("category %d = %+v, want %+v", i, categories[i], want[i])
+		}
+	}
+}
+
+func TestAnalyzePriorities(t *testing.T) {
+	entries := []AppEntry{
+		{Category: "media", Priority: 10, Name: "Jellyfin", Source: "media/jellyfin"},
+		{Category: "media", Priority: 10, Name: "Plex", Source: "media/plex"},
+		{Category: "media", Priority: 40, Name: "Sonarr", Source: "media/sonarr"},
+		{Category: "development", Priority: 10, Name: "Gitea", Source: "gitea/gitea"},
+		{Category: "development", Priority: 20, Name: "Woodpecker", Source: "ci/woodpecker"},
+	}
+
+	report := AnalyzePriorities(entries)
+
+	if len(report.Collisions) != 1 {
+		t.Fatalf("Expected 1 collision, got %+v", report.Collisions)
+	}
+	c := report.Collisions[0]
+	if c.Category != "media" || c.Priority != 10 || len(c.Sources) != 2 {
+		t.Errorf("Unexpected collision %+v", c)
+	}
+
+	// Only categories with collisions get suggestions, preserving current order
+	want := map[string]int{"media/jellyfin": 10, "media/plex": 20, "media/sonarr": 30}
+	if len(report.Suggestions) != len(want) {
+		t.Errorf("Suggestions = %v, want %v", report.Suggestions, want)
+	}
+	for source, priority := range want {
+		if report.Suggestions[source] != priority {
+			t.Errorf("Suggestion for %s = %d, want %d", source, report.Suggestions[source], priority)
+		}
+	}
+
+	if _, ok := report.CollisionFor("gitea/gitea"); ok {
+		t.Errorf("gitea should not be reported as colliding")
+	}
+	if _, ok := report.CollisionFor("media/plex"); !ok {
+		t.Errorf("plex should be reported as colliding")
+	}
+}
+
+func TestAssembler_VisibilitySchedule(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+	a.OutputGroups = []string{"kids", "family"}
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "minecraft", Namespace: "games"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "Minecraft", URL: "https://mc.example.com", Category: "games",
+				Icon: "<svg/>", Groups: []string{"kids", "family"},
+				VisibilitySchedule: []dashboardv1alpha1.VisibilityWindow{{
+					Groups:   []string{"kids"},
+					Schedule: "0 20 * * 0-4",
+					Duration: metav1.Duration{Duration: 11 * time.Hour},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "roblox", Namespace: "games"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "Roblox", URL: "https://roblox.example.com", Category: "games",
+				Icon: "<svg/>", Groups: []string{"kids"},
+				VisibilitySchedule: []dashboardv1alpha1.VisibilityWindow{{
+					Groups:   []string{"kids"},
+					Schedule: "0 20 * * 0-4",
+					Duration: metav1.Duration{Duration: 11 * time.Hour},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		wantApps    []string
+		wantKids    int
+		wantFamily  int
+		wantNextUTC time.Time
+	}{
+		{
+			name:        "wednesday night",
+			now:         time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC),
+			wantApps:    []string{"Minecraft"},
+			wantKids:    0,
+			wantFamily:  1,
+			wantNextUTC: time.Date(2025, 1, 16, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "saturday afternoon",
+			now:         time.Date(2025, 1, 18, 15, 0, 0, 0, time.UTC),
+			wantApps:    []string{"Minecraft", "Roblox"},
+			wantKids:    2,
+			wantFamily:  1,
+			wantNextUTC: time.Date(2025, 1, 19, 20, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a.Clock = func() time.Time { return tc.now }
+			result, err := a.Assemble(context.Background(), apps)
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			var names []string
+			for _, e := range result.Entries {
+				names = append(names, e.Name)
+			}
+			if !slices.Equal(names, tc.wantApps) {
+				t.Errorf("entries = %v, want %v", names, tc.wantApps)
+			}
+			var kids, family []AppEntry
+			_ = json.Unmarshal([]byte(result.GroupsJSON["kids"]), &kids)
+			_ = json.Unmarshal([]byte(result.GroupsJSON["family"]), &family)
+			if len(kids) != tc.wantKids || len(family) != tc.wantFamily {
+				t.Errorf("kids=%d family=%d, want kids=%d family=%d", len(kids), len(family), tc.wantKids, tc.wantFamily)
+			}
+			if !result.NextTransition.Equal(tc.wantNextUTC) {
+				t.Errorf("NextTransition = %s, want %s", result.NextTransition, tc.wantNextUTC)
+			}
+		})
+	}
+}
+
+func TestAssembler_InvalidVisibilitySchedule(t *testing.T) {
+	newApp := func(name, schedule string) dashboardv1alpha1.DashboardApp {
+		app := dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "games"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "games", Icon: "<svg/>", Groups: []string{"kids"},
+			},
+		}
+		if schedule != "" {
+			app.Spec.VisibilitySchedule = []dashboardv1alpha1.VisibilityWindow{{
+				Groups: []string{"kids"}, Schedule: schedule, Duration: metav1.Duration{Duration: time.Hour},
+			}}
+		}
+		return app
+	}
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{newApp("minecraft", "not a cron"), newApp("roblox", "")})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].ID != "roblox" {
+		t.Errorf("entries = %+v, want only roblox", result.Entries)
+	}
+	if !strings.Contains(result.LeftOut["games/minecraft"], "invalid visibility schedule") {
+		t.Errorf("LeftOut = %v, want the schedule error of games/minecraft", result.LeftOut)
+	}
+}
+
+func TestAssembler_Access(t *testing.T) {
+	newApp := func(name string, groups []string, access *dashboardv1alpha1.AppAccess) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "media"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: groups, Access: access,
+			},
+		}
+	}
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	a.OutputGroups = []string{"family"}
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("jellyfin", []string{"family"}, nil),
+		newApp("plex", []string{"family"}, &dashboardv1alpha1.AppAccess{AnyOf: []string{"friends"}, NoneOf: []string{"family/kids"}}),
+		newApp("stash", nil, &dashboardv1alpha1.AppAccess{AllOf: []string{"family", "adults"}}),
+		newApp("nobody", nil, &dashboardv1alpha1.AppAccess{NoneOf: []string{"family/kids"}}),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	entries := make(map[string]AppEntry)
+	for _, e := range result.Entries {
+		entries[e.Name] = e
+	}
+	if _, ok := entries["nobody"]; ok || len(entries) != 3 {
+		t.Fatalf("entries = %v, want jellyfin, plex and stash", slices.Collect(maps.Keys(entries)))
+	}
+	plex := entries["plex"]
+	if !slices.Equal(plex.Groups, []string{"family", "friends"}) || plex.Access == nil || !slices.Equal(plex.Access.NoneOf, []string{"family/kids"}) {
+		t.Errorf("plex groups = %v, access = %+v", plex.Groups, plex.Access)
+	}
+	if !strings.Contains(result.AppsJSON, `"allOf": [`) {
+		t.Errorf("apps JSON has no allOf groups:\n%s", result.AppsJSON)
+	}
+
+	tests := []struct {
+		app        string
+		userGroups []string
+		want       bool
+	}{
+		{"jellyfin", []string{"family/kids"}, false},
+		{"plex", []string{"friends"}, true},
+		{"plex", []string{"family", "family/kids"}, false},
+		{"stash", []string{"family"}, false},
+		{"stash", []string{"family", "adults"}, true},
+	}
+	for _, tc := range tests {
+		e := entries[tc.app]
+		if got := e.VisibleTo(tc.userGroups); got != tc.want {
+			t.Errorf("%s visible to %v = %v, want %v", tc.app, tc.userGroups, got, tc.want)
+		}
+	}
+
+	var family []AppEntry
+	if err := json.Unmarshal([]byte(result.GroupsJSON["family"]), &family); err != nil {
+		t.Fatalf("invalid family apps JSON: %v", err)
+	}
+	if len(family) != 2 {
+		t.Errorf("family sees %d apps, want jellyfin and plex", len(family))
+	}
+	var catalog []GroupEntry
+	if err := json.Unmarshal([]byte(result.GroupCatalogJSON), &catalog); err != nil {
+		t.Fatalf("invalid GroupCatalogJSON: %v", err)
+	}
+	if !slices.ContainsFunc(catalog, func(g GroupEntry) bool { return g.Group == "adults" && g.Apps == 1 }) ||
+		slices.ContainsFunc(catalog, func(g GroupEntry) bool { return g.Group == "family/kids" }) {
+		t.Errorf("group catalog = %+v, want adults and no family/kids", catalog)
+	}
+}
+
+func TestAssembler_Actions(t *testing.T) {
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	a.IconLibraries = iconlib.Defaults
+	a.OutputGroups = []string{"family", "admins"}
+	app := dashboardv1alpha1.DashboardApp{
+		ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"},
+		Spec: dashboardv1alpha1.DashboardAppSpec{
+			Name: "Plex", URL: "https://plex.lan", Category: "media", Icon: "<svg/>", Groups: []string{"family", "admins"},
+			Actions: []dashboardv1alpha1.AppAction{
+				{Name: "Logs", Icon: "mdi:text", URL: "https://logs.lan/?app={{ .name }}"},
+				{Name: "Restart", URL: "https://plex.lan/restart", Method: "post", Groups: []string{"admins"}},
+				{Name: "Wipe", URL: "https://plex.lan/wipe", Method: "TRACE"},
+			},
+		},
+	}
+	if violations := a.Violations(&app); len(violations) != 1 || !strings.Contains(violations[0], "unsupported method TRACE") {
+		t.Errorf("Violations() = %v, want the TRACE method", violations)
+	}
+
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{app})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	actions := result.Entries[0].Actions
+	if len(actions) != 2 {
+		t.Fatalf("actions = %+v, want Logs and Restart", actions)
+	}
+	if actions[0].URL != "https://logs.lan/?app=plex" || actions[0].Method != "GET" || !strings.HasPrefix(actions[0].Icon, "https://") {
+		t.Errorf("Logs action = %+v", actions[0])
+	}
+	if actions[1].Method != "POST" {
+		t.Errorf("Restart method = %s, want POST", actions[1].Method)
+	}
+
+	for group, want := range map[string]int{"family": 1, "admins": 2} {
+		var entries []AppEntry
+		if err := json.Unmarshal([]byte(result.GroupsJSON[group]), &entries); err != nil {
+			t.Fatalf("invalid %s apps JSON: %v", group, err)
+		}
+		if len(entries[0].Actions) != want {
+			t.Errorf("%s sees %d actions, want %d", group, len(entries[0].Actions), want)
+		}
+	}
+	if len(result.Entries[0].Actions) != 2 {
+		t.Error("filtering per group changed the catalog's actions")
+	}
+}
+
+func TestAssembler_HealthRollup(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+
+	newApp := func(ns, name string, state dashboardv1alpha1.HealthState, deps ...dashboardv1alpha1.AppReference) dashboardv1alpha1.DashboardApp {
+		app := dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>",
+				Groups: []string{"family"}, DependsOn: deps,
+			},
+		}
+		if state != "" {
+			app.Status.Health = &dashboardv1alpha1.AppHealth{State: state}
+		}
+		return app
+	}
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		newApp("db", "postgres", dashboardv1alpha1.HealthDown),
+		newApp("media", "nas", dashboardv1alpha1.HealthUp),
+		newApp("media", "jellyfin", dashboardv1alpha1.HealthUp,
+			dashboardv1alpha1.AppReference{Name: "nas"},
+			dashboardv1alpha1.AppReference{Name: "postgres", Namespace: "db"}),
+		newApp("media", "jellyseerr", "", dashboardv1alpha1.AppReference{Name: "jellyfin"}),
+		newApp("media", "sonarr", dashboardv1alpha1.HealthUp, dashboardv1alpha1.AppReference{Name: "missing"}),
+		newApp("media", "plex", dashboardv1alpha1.HealthUp, dashboardv1alpha1.AppReference{Name: "nas"}),
+		// cycle must not degrade either side on its own
+		newApp("loop", "a", dashboardv1alpha1.HealthUp, dashboardv1alpha1.AppReference{Name: "b"}),
+		newApp("loop", "b", dashboardv1alpha1.HealthUp, dashboardv1alpha1.AppReference{Name: "a"}),
+	}
+
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	bySource := map[string]AppEntry{}
+	for _, e := range result.Entries {
+		bySource[e.Source] = e
+	}
+
+	tests := []struct {
+		source     string
+		wantHealth string
+		wantReason string
+	}{
+		{"db/postgres", "down", ""},
+		{"media/nas", "up", ""},
+		{"media/jellyfin", "degraded", "dependency db/postgres is down"},
+		{"media/jellyseerr", "degraded", "dependency media/jellyfin is degraded"},
+		{"media/sonarr", "degraded", "dependency media/missing not found"},
+		{"media/plex", "up", ""},
+		{"loop/a", "up", ""},
+		{"loop/b", "up", ""},
+	}
+	for _, tc := range tests {
+		e := bySource[tc.source]
+		if e.Health != tc.wantHealth || e.HealthReason != tc.wantReason {
+			t.Errorf("%s: health=%q reason=%q, want %q %q", tc.source, e.Health, e.HealthReason, tc.wantHealth, tc.wantReason)
+		}
+	}
+	if deps := bySource["media/jellyfin"].DependsOn; !slices.Equal(deps, []string{"nas", "postgres"}) {
+		t.Errorf("jellyfin dependsOn = %v", deps)
+	}
+}
+
+func TestAssembler_Heartbeat(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	a.Clock = func() time.Time { return now }
+
+	newApp := func(name string, heartbeat time.Time) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "external",
+				CreationTimestamp: metav1.NewTime(now.Add(-24 * time.Hour)),
+				Annotations: map[string]string{
+					dashboardv1alpha1.HeartbeatAnnotation: heartbeat.Format(time.RFC3339),
+				},
+			},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>",
+				Groups:           []string{"family"},
+				HeartbeatTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+		}
+	}
+
+	fresh := newApp("fresh", now.Add(-time.Minute))
+	lapsed := newApp("lapsed", now.Add(-10*time.Minute))
+	dependent := newApp("dependent", now)
+	dependent.Spec.HeartbeatTimeout = nil
+	dependent.Spec.DependsOn = []dashboardv1alpha1.AppReference{{Name: "lapsed"}}
+
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{fresh, lapsed, dependent})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	bySource := map[string]AppEntry{}
+	for _, e := range result.Entries {
+		bySource[e.Source] = e
+	}
+
+	tests := []struct {
+		source     string
+		wantHealth string
+		wantStale  bool
+	}{
+		{"external/fresh", "up", false},
+		{"external/lapsed", "unknown", true},
+		// unknown is not a failure, so dependents are not degraded
+		{"external/dependent", "", false},
+	}
+	for _, tc := range tests {
+		e := bySource[tc.source]
+		if e.Health != tc.wantHealth || e.Stale != tc.wantStale {
+			t.Errorf("%s: health=%q stale=%v, want %q %v", tc.source, e.Health, e.Stale, tc.wantHealth, tc.wantStale)
+		}
+	}
+
+	if want := now.Add(4 * time.Minute); !result.NextTransition.Equal(want) {
+		t.Errorf("NextTransition = %v, want %v (when fresh goes stale)", result.NextTransition, want)
+	}
+}
+
+func TestAssembler_MostUsed(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+	a.Sort = SortMostUsed
+
+	newApp := func(name, category string, priority int) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: category, Icon: "<svg/>",
+				Groups: []string{"family"}, Priority: priority,
+			},
+		}
+	}
+	apps := []dashboardv1alpha1.DashboardApp{
+		newApp("plex", "media", 1),
+		newApp("jellyfin", "media", 2),
+		newApp("navidrome", "media", 3),
+		newApp("openwebui", "ai", 1),
+	}
+
+	result, err := a.WithUsage(usage.Counts{"navidrome": 40, "jellyfin": 12, "openwebui": 99}).
+		Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range result.Entries {
+		got = append(got, e.ID)
+	}
+	// categories still come first; within media usage wins over priority and
+	// unused apps fall back to priority
+	want := []string{"navidrome", "jellyfin", "plex", "openwebui"}
+	if !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if result.Entries[0].Usage != 40 {
+		t.Errorf("navidrome usage = %d, want 40", result.Entries[0].Usage)
+	}
+	if a.Usage != nil {
+		t.Errorf("WithUsage modified the receiver")
+	}
+}
+
+func TestAssembler_NewBadge(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	a.Clock = func() time.Time { return now }
+	a.NewWindow = 7 * 24 * time.Hour
+
+	newApp := func(name string, age time.Duration) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+			},
+		}
+	}
+
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("fresh", 24*time.Hour),
+		newApp("fresher", time.Hour),
+		newApp("old", 30*24*time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	for _, e := range result.Entries {
+		if want := e.ID != "old"; e.New != want {
+			t.Errorf("%s: new = %v, want %v", e.ID, e.New, want)
+		}
+	}
+	if want := now.Add(6 * 24 * time.Hour); !result.NextTransition.Equal(want) {
+		t.Errorf("NextTransition = %v, want %v (when fresh loses its badge)", result.NextTransition, want)
+	}
+
+	a.NewWindow = 0
+	result, err = a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{newApp("fresh", time.Hour)})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if result.Entries[0].New || !result.NextTransition.IsZero() {
+		t.Errorf("badge disabled: got new=%v next=%v", result.Entries[0].New, result.NextTransition)
+	}
+}
+
+func TestAssembler_Condition(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log).WithFacts(&facts.Facts{
+		CRDs:       []string{"ingressroutes.traefik.io"},
+		Namespaces: []string{"media"},
+		Flags:      map[string]string{"ai": "false"},
+	})
+
+	newApp := func(name, condition string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+				Condition: condition,
+			},
+		}
+	}
+
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("plain", ""),
+		newApp("jellyfin", `"media" in namespaces && "ingressroutes.traefik.io" in crds`),
+		newApp("ollama", `flags["ai"] == "true"`),
+		newApp("games", `flags["games"] == "true"`),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	var ids []string
+	for _, e := range result.Entries {
+		ids = append(ids, e.ID)
+	}
+	if want := []string{"jellyfin", "plain"}; !slices.Equal(ids, want) {
+		t.Errorf("entries = %v, want %v", ids, want)
+	}
+
+	if !strings.Contains(result.LeftOut["apps/games"], "condition failed to evaluate") {
+		t.Errorf("LeftOut = %v, want the evaluation error of apps/games", result.LeftOut)
+	}
+
+	result, err = a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{newApp("broken", `"media" in`), newApp("plain", "")})
+	if err != nil {
+		t.Fatalf("invalid condition: Assemble() error = %v, want the app left out", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].ID != "plain" {
+		t.Errorf("entries = %+v, want only plain", result.Entries)
+	}
+	if !slices.ContainsFunc(result.Violations["apps/broken"], func(v string) bool { return strings.Contains(v, "invalid condition") }) {
+		t.Errorf("Violations = %v, want the invalid condition of apps/broken", result.Violations)
+	}
+}
+
+func TestAssembler_Checksums(t *testing.T) {
+	newApp := func(name, icon string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: icon, Groups: []string{"family"},
+			},
+		}
+	}
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	a.Checksums = true
+	checksums := func(apps ...dashboardv1alpha1.DashboardApp) Checksums {
+		t.Helper()
+		result, err := a.Assemble(context.Background(), apps)
+		if err != nil {
+			t.Fatalf("Assemble() error = %v", err)
+		}
+		var sums Checksums
+		if err := json.Unmarshal([]byte(result.ChecksumsJSON), &sums); err != nil {
+			t.Fatalf("invalid ChecksumsJSON: %v", err)
+		}
+		return sums
+	}
+
+	before := checksums(newApp("plex", "<svg/>"), newApp("jellyfin", "<svg/>"))
+	if len(before.Entries) != 2 || len(before.Icons) != 2 {
+		t.Fatalf("checksums = %+v, want 2 entries and 2 icons", before)
+	}
+
+	// A new icon only changes the icon checksum
+	after := checksums(newApp("plex", "<svg id=\"new\"/>"), newApp("jellyfin", "<svg/>"))
+	if after.Entries["plex"] != before.Entries["plex"] || after.Icons["plex"] == before.Icons["plex"] {
+		t.Errorf("icon change: entries %q -> %q, icons %q -> %q",
+			before.Entries["plex"], after.Entries["plex"], before.Icons["plex"], after.Icons["plex"])
+	}
+	if after.Entries["jellyfin"] != before.Entries["jellyfin"] || after.Icons["jellyfin"] != before.Icons["jellyfin"] {
+		t.Error("unchanged entry got new checksums")
+	}
+
+	renamed := newApp("plex", "<svg/>")
+	renamed.Spec.Name = "Plex Media Server"
+	if sums := checksums(renamed); sums.Entries["plex"] == before.Entries["plex"] {
+		t.Error("entry change kept the entry checksum")
+	}
+
+	a.Checksums = false
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{newApp("plex", "<svg/>")})
+	if err != nil || result.ChecksumsJSON != "" {
+		t.Errorf("checksums disabled: ChecksumsJSON = %q, err = %v", result.ChecksumsJSON, err)
+	}
+}
+
+func TestAssembler_GroupCatalog(t *testing.T) {
+	newApp := func(name, category string, groups ...string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: category, Icon: "<svg/>", Groups: groups,
+			},
+		}
+	}
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("grafana", "admin", "admins"),
+		newApp("plex", "media", "family", "admins"),
+		newApp("ollama", "ai", "family"),
+		newApp("jellyfin", "media", "family"),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	want := []GroupEntry{
+		{Group: "admins", Apps: 2, Categories: []string{"media", "admin"}},
+		{Group: "family", Apps: 3, Categories: []string{"media", "ai"}},
+	}
+	var got []GroupEntry
+	if err := json.Unmarshal([]byte(result.GroupCatalogJSON), &got); err != nil {
+		t.Fatalf("invalid GroupCatalogJSON: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("group catalog = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Group != want[i].Group || got[i].Apps != want[i].Apps || !slices.Equal(got[i].Categories, want[i].Categories) {
+			t.Errorf("group %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAssembler_Tags(t *testing.T) {
+	newApp := func(name string, tags ...string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+				Tags: tags,
+			},
+		}
+	}
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("plex", "streaming", "video", "streaming"),
+		newApp("jellyfin", " video "),
+		newApp("navidrome"),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	tags := map[string][]string{}
+	for _, e := range result.Entries {
+		tags[e.ID] = e.Tags
+	}
+	if got := tags["plex"]; !slices.Equal(got, []string{"streaming", "video"}) {
+		t.Errorf("plex tags = %v, want [streaming video]", got)
+	}
+	if got := tags["navidrome"]; got != nil {
+		t.Errorf("navidrome tags = %v, want none", got)
+	}
+
+	want := []TagEntry{{Tag: "streaming", Apps: 1}, {Tag: "video", Apps: 2}}
+	var got []TagEntry
+	if err := json.Unmarshal([]byte(result.TagsJSON), &got); err != nil {
+		t.Fatalf("invalid TagsJSON: %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("tag index = %+v, want %+v", got, want)
+	}
+}
+
+func TestAssembler_Bookmarks(t *testing.T) {
+	newBookmark := func(name string, spec dashboardv1alpha1.DashboardBookmarkSpec) dashboardv1alpha1.DashboardBookmark {
+		return dashboardv1alpha1.DashboardBookmark{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "links"}, Spec: spec}
+	}
+	a := NewAssembler(zap.New(zap.UseDevMode(true))).WithBookmarks([]dashboardv1alpha1.DashboardBookmark{
+		newBookmark("wiki", dashboardv1alpha1.DashboardBookmarkSpec{URL: "https://wiki.example.com"}),
+		newBookmark("grafana", dashboardv1alpha1.DashboardBookmarkSpec{Name: "Grafana", URL: "https://grafana.lan", Category: "Admin", Groups: []string{"admins"}}),
+		newBookmark("jellyfin-docs", dashboardv1alpha1.DashboardBookmarkSpec{Name: "Jellyfin docs", URL: " https://jellyfin.org/docs ", Category: "media"}),
+	})
+	result, err := a.Assemble(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	want := []BookmarkEntry{
+		{Name: "Jellyfin docs", URL: "https://jellyfin.org/docs", Category: "media"},
+		{Name: "Grafana", URL: "https://grafana.lan", Category: "admin", Groups: []string{"admins"}},
+		{Name: "wiki", URL: "https://wiki.example.com"},
+	}
+	var got []BookmarkEntry
+	if err := json.Unmarshal([]byte(result.BookmarksJSON), &got); err != nil {
+		t.Fatalf("invalid BookmarksJSON: %v", err)
+	}
+	equal := func(x, y BookmarkEntry) bool {
+		return x.Name == y.Name && x.URL == y.URL && x.Category == y.Category && slices.Equal(x.Groups, y.Groups)
+	}
+	if !slices.EqualFunc(got, want, equal) {
+		t.Errorf("bookmarks = %+v, want %+v", got, want)
+	}
+
+	result, err = NewAssembler(zap.New(zap.UseDevMode(true))).Assemble(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if result.BookmarksJSON != "" {
+		t.Errorf("BookmarksJSON = %q without bookmarks, want none", result.BookmarksJSON)
+	}
+}
+
+func TestAssembler_Extra(t *testing.T) {
+	newApp := func(name string, extra map[string]string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+				Extra: extra,
+			},
+		}
+	}
+	apps := []dashboardv1alpha1.DashboardApp{
+		newApp("plex", map[string]string{"accent": "#e5a00d", "widget": "plex"}),
+		newApp("jellyfin", map[string]string{"notes": strings.Repeat("x", MaxExtraBytes)}),
+	}
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	extra := map[string]map[string]string{}
+	for _, e := range result.Entries {
+		extra[e.ID] = e.Extra
+	}
+	if got := extra["plex"]; !maps.Equal(got, apps[0].Spec.Extra) {
+		t.Errorf("plex extra = %v, want %v", got, apps[0].Spec.Extra)
+	}
+	if got, ok := extra["jellyfin"]; !ok || got != nil {
+		t.Errorf("jellyfin extra = %v, want the entry without its oversized extra fields", got)
+	}
+	if !strings.Contains(result.AppsJSON, `"accent": "#e5a00d"`) {
+		t.Errorf("AppsJSON does not pass plex's extra fields through: %s", result.AppsJSON)
+	}
+	if v := a.Violations(&apps[1]); len(v) != 1 || !strings.Contains(v[0], "extra fields") {
+		t.Errorf("Violations() = %v, want the oversized extra fields", v)
+	}
+}
+
+// iconResolverFunc adapts a function to IconResolver
+type iconResolverFunc func(ctx context.Context, url string) (string, error)
+
+func (f iconResolverFunc) Resolve(ctx context.Context, url string) (string, error) {
+	return f(ctx, url)
+}
+
+func TestAssembler_IconURL(t *testing.T) {
+	newApp := func(name, icon, iconURL string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Groups: []string{"family"},
+				Icon: icon, IconURL: iconURL,
+			},
+		}
+	}
+	apps := []dashboardv1alpha1.DashboardApp{
+		newApp("inline", "<svg id=\"inline\"/>", "https://icons.example.test/ignored.svg"),
+		newApp("fetched", "", "https://icons.example.test/plex.svg"),
+		newApp("broken", "", "https://icons.example.test/missing.svg"),
+		newApp("shorthand", "sh:plex", ""),
+	}
+	resolver := iconResolverFunc(func(_ context.Context, url string) (string, error) {
+		if strings.HasSuffix(url, "missing.svg") {
+			return "", errors.New("server returned 404 Not Found")
+		}
+		return "<svg id=\"" + url + "\"/>", nil
+	})
+
+	tests := []struct {
+		name       string
+		resolver   IconResolver
+		want       map[string]string
+		wantFailed []string
+	}{
+		{
+			name:     "inlined",
+			resolver: resolver,
+			want: map[string]string{
+				"inline":    "<svg id=\"inline\"/>",
+				"fetched":   "<svg id=\"https://icons.example.test/plex.svg\"/>",
+				"broken":    "",
+				"shorthand": "<svg id=\"https://icons.example.test/sh/plex.svg\"/>",
+			},
+			wantFailed: []string{"apps/broken"},
+		},
+		{
+			name: "passed through",
+			want: map[string]string{
+				"inline":    "<svg id=\"inline\"/>",
+				"fetched":   "https://icons.example.test/plex.svg",
+				"broken":    "https://icons.example.test/missing.svg",
+				"shorthand": "https://icons.example.test/sh/plex.svg",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(zap.New(zap.UseDevMode(true)))
+			a.IconResolver = tt.resolver
+			a.IconLibraries = iconlib.Libraries{"sh": "https://icons.example.test/sh/{name}.svg"}
+			result, err := a.Assemble(context.Background(), apps)
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			for _, e := range result.Entries {
+				if e.Icon != tt.want[e.ID] {
+					t.Errorf("%s icon = %q, want %q", e.ID, e.Icon, tt.want[e.ID])
+				}
+			}
+			failed := slices.Sorted(maps.Keys(result.IconFailures))
+			if want := tt.wantFailed; !slices.Equal(failed, want) {
+				t.Errorf("icon failures = %v, want %v", failed, want)
+			}
+		})
+	}
+}
+
+func TestAssembler_IconFetchBudget(t *testing.T) {
+	newApp := func(name string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Groups: []string{"family"},
+				IconURL: "https://icons.example.test/" + name + ".svg",
+			},
+		}
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	a.Clock = func() time.Time { return now }
+	a.IconFetchBudget = 50 * time.Millisecond
+	a.IconResolver = iconResolverFunc(func(ctx context.Context, url string) (string, error) {
+		if strings.HasSuffix(url, "slow.svg") {
+			<-ctx.Done()
+			return "", fmt.Errorf("icon %s: still fetching: %w", url, ctx.Err())
+		}
+		return "<svg/>", nil
+	})
+
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{newApp("slow"), newApp("fast")})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	for _, e := range result.Entries {
+		want := map[string]string{"slow": "", "fast": "<svg/>"}[e.ID]
+		if e.Icon != want {
+			t.Errorf("%s icon = %q, want %q", e.ID, e.Icon, want)
+		}
+	}
+	if len(result.IconFailures) != 0 {
+		t.Errorf("icon failures = %v, want none for an icon still being fetched", result.IconFailures)
+	}
+	if want := now.Add(iconPendingRecheck); !result.NextTransition.Equal(want) {
+		t.Errorf("next transition = %v, want %v to publish the slow icon", result.NextTransition, want)
+	}
+}
+
+func TestAssembler_Disabled(t *testing.T) {
+	newApp := func(name string, enabled *bool) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+				Enabled: enabled,
+			},
+		}
+	}
+	enabled, disabled := true, false
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("defaulted", nil),
+		newApp("enabled", &enabled),
+		newApp("disabled", &disabled),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	var ids []string
+	for _, e := range result.Entries {
+		ids = append(ids, e.ID)
+	}
+	if want := []string{"defaulted", "enabled"}; !slices.Equal(ids, want) {
+		t.Errorf("entries = %v, want %v", ids, want)
+	}
+}
+
+func TestAssembler_Removed(t *testing.T) {
+	newApp := func(name string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+			},
+		}
+	}
+	deleted := newApp("deleted")
+	deleted.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{newApp("live"), deleted})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	removed := map[string]bool{}
+	for _, e := range result.Entries {
+		removed[e.ID] = e.Removed
+	}
+	if want := map[string]bool{"live": false, "deleted": true}; !maps.Equal(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+}
+
+func TestAssembler_Strict(t *testing.T) {
+	newApp := func(namespace, name, displayName string, groups ...string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: displayName, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: groups,
+			},
+		}
+	}
+	apps := []dashboardv1alpha1.DashboardApp{
+		newApp("media", "plex", "Plex", "family"),
+		newApp("media", "broken", "Broken", "fam*ily"),
+		newApp("tools", "grafana", "Grafana", "admins"),
+		newApp("monitoring", "grafana", "Grafana", "admins"),
+		newApp("home", "hass", "Home Assistant", "family"),
+	}
+
+	tests := []struct {
+		name       string
+		strict     bool
+		wantIDs    []string
+		wantFailed []string
+	}{
+		{"best effort", false, []string{"home-hass", "media-plex", "monitoring-grafana", "tools-grafana"}, nil},
+		{"strict", true, []string{"home-hass"}, []string{"media", "monitoring", "tools"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(zap.New(zap.UseDevMode(true)))
+			a.DuplicateNamePolicy = DuplicateNamesFlag
+			a.Strict = tt.strict
+			a.IDTemplate = "{{ .namespace }}-{{ .name }}"
+			result, err := a.Assemble(context.Background(), apps)
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			var ids, failed []string
+			for _, e := range result.Entries {
+				ids = append(ids, e.ID)
+			}
+			for _, f := range result.StrictFailures {
+				failed = append(failed, f.Namespace)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("entries = %v, want %v", ids, tt.wantIDs)
+			}
+			if !slices.Equal(failed, tt.wantFailed) {
+				t.Errorf("failed namespaces = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	a.Strict = true
+	result, err := a.Assemble(context.Background(), apps[:2])
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	failure, ok := StrictFailureFor(result.StrictFailures, "media")
+	if !ok || len(failure.Findings) != 1 || !strings.Contains(failure.Message(), "media/broken") {
+		t.Errorf("media failure = %+v, want only media/broken", failure)
+	}
+	if got := slices.Sorted(maps.Keys(result.Violations)); !slices.Equal(got, []string{"media/broken"}) {
+		t.Errorf("violations = %v, want only media/broken", got)
+	}
+}
+
+func TestAssembler_IconBaseURL(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log).WithCategories([]dashboardv1alpha1.DashboardCategory{{
+		ObjectMeta: metav1.ObjectMeta{Name: "media"},
+		Spec:       dashboardv1alpha1.DashboardCategorySpec{DisplayName: "Media", Icon: "<svg>media</svg>"},
+	}})
+	a.IconBaseURL = "https://duro.example.test/icons/"
+
+	newApp := func(name, icon string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: icon, Groups: []string{"family"},
+			},
+		}
+	}
+
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("plex", "<svg>plex</svg>"),
+		newApp("jellyfin", "<svg>plex</svg>"),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	key := IconKey("<svg>plex</svg>")
+	for _, e := range result.Entries {
+		if want := "https://duro.example.test/icons/" + key; e.Icon != want {
+			t.Errorf("%s: icon = %q, want %q", e.ID, e.Icon, want)
+		}
+	}
+	if want := "https://duro.example.test/icons/" + IconKey("<svg>media</svg>"); result.Categories[0].Icon != want {
+		t.Errorf("category icon = %q, want %q", result.Categories[0].Icon, want)
+	}
+	if len(result.Icons) != 2 || result.Icons[key] != "<svg>plex</svg>" {
+		t.Errorf("Icons = %v", result.Icons)
+	}
+	if strings.Contains(result.AppsJSON, "<svg>") {
+		t.Error("apps JSON still inlines icons")
+	}
+}
+
+func TestAssembler_IconConfigMap(t *testing.T) {
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	a.IconConfigMap = "duro-icons"
+	a.Checksums = true
+
+	newApp := func(namespace, name, icon string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: icon, Groups: []string{"family"},
+			},
+		}
+	}
+
+	result, err := a.Assemble(context.Background(), []dashboardv1alpha1.DashboardApp{
+		newApp("media", "plex", "<svg>plex</svg>"),
+		newApp("media", "jellyfin", "<svg>jellyfin</svg>"),
+		newApp("books", "kavita", "<svg>kavita</svg>"),
+	})
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+
+	key := IconRefKey("<svg>plex</svg>")
+	for _, e := range result.Entries {
+		if e.Icon != "" || e.IconRef == nil || e.IconRef.ConfigMap != "duro-icons" {
+			t.Errorf("%s: icon = %q, ref = %+v", e.ID, e.Icon, e.IconRef)
+		}
+		if e.ID == "plex" && *e.IconRef != (IconRef{Namespace: "media", ConfigMap: "duro-icons", Key: key}) {
+			t.Errorf("plex ref = %+v", e.IconRef)
+		}
+	}
+	if len(result.NamespaceIcons["media"]) != 2 || len(result.NamespaceIcons["books"]) != 1 {
+		t.Errorf("NamespaceIcons = %v", result.NamespaceIcons)
+	}
+	if result.NamespaceIcons["media"][key] != "<svg>plex</svg>" {
+		t.Errorf("media icons = %v", result.NamespaceIcons["media"])
+	}
+	if strings.Contains(result.AppsJSON, "<svg>") {
+		t.Error("apps JSON still inlines icons")
+	}
+
+	var sums Checksums
+	if err := json.Unmarshal([]byte(result.ChecksumsJSON), &sums); err != nil {
+		t.Fatalf("invalid ChecksumsJSON: %v", err)
+	}
+	if sums.Icons["plex"] != IconKey("<svg>plex</svg>") {
+		t.Errorf("plex icon checksum = %q, want %q", sums.Icons["plex"], IconKey("<svg>plex</svg>"))
+	}
+}
+
+func TestAssembler_IconsKey(t *testing.T) {
+	a := NewAssembler(zap.New(zap.UseDevMode(true)))
+	newApp := func(name, icon string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "media"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: icon, Groups: []string{"family"},
+			},
+		}
+	}
+	apps := []dashboardv1alpha1.DashboardApp{newApp("plex", "<svg>plex</svg>"), newApp("sonarr", "")}
+
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	if result.IconsJSON != "" || result.IconBytes["media/plex"] != len("<svg>plex</svg>") {
+		t.Errorf("icons inline: IconsJSON = %q, IconBytes = %v", result.IconsJSON, result.IconBytes)
+	}
+	if _, ok := result.IconBytes["media/sonarr"]; ok {
+		t.Errorf("IconBytes counts an app without icon: %v", result.IconBytes)
+	}
+
+	a.IconsKey = true
+	result, err = a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	key := IconRefKey("<svg>plex</svg>")
+	for _, e := range result.Entries {
+		if e.ID == "plex" && (e.Icon != "" || e.IconRef == nil || *e.IconRef != (IconRef{Key: key})) {
+			t.Errorf("plex: icon = %q, ref = %+v", e.Icon, e.IconRef)
+		}
+		if e.ID == "sonarr" && e.IconRef != nil {
+			t.Errorf("sonarr ref = %+v, want none", e.IconRef)
+		}
+	}
+	var icons map[string]string
+	if err := json.Unmarshal([]byte(result.IconsJSON), &icons); err != nil {
+		t.Fatalf("invalid IconsJSON: %v", err)
+	}
+	if len(icons) != 1 || icons[key] != "<svg>plex</svg>" {
+		t.Errorf("IconsJSON = %v", icons)
+	}
+	if strings.Contains(result.AppsJSON, "<svg>") || !strings.Contains(result.AppsJSON, `"iconRef": {
+      "key": "`+key+`"`) {
+		t.Errorf("apps JSON = %s", result.AppsJSON)
+	}
+	if len(result.IconBytes) != 0 {
+		t.Errorf("IconBytes = %v, want none left in the entries", result.IconBytes)
+	}
+}
+
+func TestAssembler_IconPolicy(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+	a.IconPolicy = iconpolicy.Policy{Mode: iconpolicy.ModeReject}
+
+	apps := []dashboardv1alpha1.DashboardApp{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "clean", Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "clean", URL: "https://clean", Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "tracker", Namespace: "apps"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: "tracker", URL: "https://tracker", Category: "media",
+				Icon: `<svg><image href="https://tracker.test/p.gif"/></svg>`, Groups: []string{"family"},
+			},
+		},
+	}
+	result, err := a.Assemble(context.Background(), apps)
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	for _, e := range result.Entries {
+		want := map[string]string{"clean": "<svg/>", "tracker": ""}[e.ID]
+		if e.Icon != want {
+			t.Errorf("%s: icon = %q, want %q", e.ID, e.Icon, want)
+		}
+	}
+}
+
+func TestAssembler_IDTemplate(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+
+	newApp := func(namespace, name string, deps ...string) dashboardv1alpha1.DashboardApp {
+		app := dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+			},
+		}
+		for _, d := range deps {
+			app.Spec.DependsOn = append(app.Spec.DependsOn, dashboardv1alpha1.AppReference{Name: d})
+		}
+		return app
+	}
+	apps := []dashboardv1alpha1.DashboardApp{
+		newApp("media", "plex", "postgres"),
+		newApp("media", "postgres"),
+		newApp("staging", "plex"),
+	}
+
+	tests := []struct {
+		name           string
+		template       string
+		wantIDs        []string
+		wantCollisions []IDCollision
+		wantLeftOut    []string
+	}{
+		{
+			name:           "default uses the app name",
+			wantIDs:        []string{"plex", "postgres"},
+			wantCollisions: []IDCollision{{ID: "plex", Sources: []string{"media/plex", "staging/plex"}}},
+		},
+		{
+			name:     "namespaced",
+			template: "{{ .namespace }}-{{ .name }}",
+			wantIDs:  []string{"media-plex", "media-postgres", "staging-plex"},
+		},
+		{
+			name:           "category",
+			template:       "{{ .category }}",
+			wantIDs:        []string{"media"},
+			wantCollisions: []IDCollision{{ID: "media", Sources: []string{"media/plex", "media/postgres", "staging/plex"}}},
+		},
+		{
+			name:        "unknown variable",
+			template:    "{{ .owner }}",
+			wantLeftOut: []string{"media/plex", "media/postgres", "staging/plex"},
+		},
+		{
+			name:        "only the failing app is left out",
+			template:    `{{ if eq .namespace "staging" }}{{ .owner }}{{ else }}{{ .name }}{{ end }}`,
+			wantIDs:     []string{"plex", "postgres"},
+			wantLeftOut: []string{"staging/plex"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(log)
+			a.IDTemplate = tt.template
+			result, err := a.Assemble(context.Background(), slices.Clone(apps))
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			if got := slices.Sorted(maps.Keys(result.LeftOut)); !slices.Equal(got, tt.wantLeftOut) {
+				t.Errorf("left out = %v, want %v", got, tt.wantLeftOut)
+			}
+			for _, source := range tt.wantLeftOut {
+				if !slices.ContainsFunc(result.Violations[source], func(v string) bool { return strings.Contains(v, "ID template") }) {
+					t.Errorf("violations of %s = %v, want the ID template error", source, result.Violations[source])
+				}
+			}
+			var ids []string
+			for _, e := range result.Entries {
+				ids = append(ids, e.ID)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if len(result.IDCollisions) != len(tt.wantCollisions) {
+				t.Fatalf("collisions = %v, want %v", result.IDCollisions, tt.wantCollisions)
+			}
+			for i, c := range tt.wantCollisions {
+				got := result.IDCollisions[i]
+				if got.ID != c.ID || !slices.Equal(got.Sources, c.Sources) {
+					t.Errorf("collision %d = %+v, want %+v", i, got, c)
+				}
+			}
+		})
+	}
+
+	// Dependencies point at the dependency's generated ID
+	a := NewAssembler(log)
+	a.IDTemplate = "{{ .namespace }}-{{ .name }}-{{ .hash }}"
+	result, err := a.Assemble(context.Background(), slices.Clone(apps[:2]))
+	if err != nil {
+		t.Fatalf("Assemble() error = %v", err)
+	}
+	for _, e := range result.Entries {
+		if e.Source == "media/plex" {
+			if len(e.DependsOn) != 1 || !strings.HasPrefix(e.DependsOn[0], "media-postgres-") || len(e.DependsOn[0]) != len("media-postgres-")+8 {
+				t.Errorf("dependsOn = %v, want the generated ID of media/postgres", e.DependsOn)
+			}
+		}
+	}
+}
+
+func TestAssembler_Violations(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	a := NewAssembler(log)
+	a.IconPolicy = iconpolicy.Policy{Mode: iconpolicy.ModeReject}
+
+	tests := []struct {
+		name   string
+		mutate func(spec *dashboardv1alpha1.DashboardAppSpec)
+		want   int
+	}{
+		{"conforming", func(*dashboardv1alpha1.DashboardAppSpec) {}, 0},
+		{"bad group", func(s *dashboardv1alpha1.DashboardAppSpec) { s.Groups = []string{"me*dia", "family//kids"} }, 2},
+		{"bad url template", func(s *dashboardv1alpha1.DashboardAppSpec) { s.URL = "https://{{ .nope }}" }, 1},
+		{"bad condition", func(s *dashboardv1alpha1.DashboardAppSpec) { s.Condition = "flags[" }, 1},
+		{"external icon", func(s *dashboardv1alpha1.DashboardAppSpec) {
+			s.Icon = `<svg><image href="https://tracker.test/p.gif"/></svg>`
+		}, 1},
+		{"bad schedule", func(s *dashboardv1alpha1.DashboardAppSpec) {
+			s.VisibilitySchedule = []dashboardv1alpha1.VisibilityWindow{{
+				Groups: []string{"kids"}, Schedule: "not a cron", Duration: metav1.Duration{Duration: time.Hour},
+			}}
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := dashboardv1alpha1.DashboardApp{
+				ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"},
+				Spec: dashboardv1alpha1.DashboardAppSpec{
+					Name: "Plex", URL: "https://plex", Category: "media", Icon: "<svg/>", Groups: []string{"family"},
+				},
+			}
+			tt.mutate(&app.Spec)
+			if got := a.Violations(&app); len(got) != tt.want {
+				t.Errorf("Violations() = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssembler_DanglingCategories(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	newApp := func(name, category string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: category, Icon: "<svg/>", Groups: []string{"family"},
+			},
+		}
+	}
+	apps := []dashboardv1alpha1.DashboardApp{
+		newApp("minecraft", "games"),
+		newApp("plex", "media"),
+		newApp("grafana", "monitoring"),
+	}
+
+	tests := []struct {
+		name         string
+		categories   []dashboardv1alpha1.DashboardCategory
+		fallback     string
+		wantDangling map[string]string
+		wantCategory string // of minecraft
+	}{
+		{
+			name:         "categories not loaded",
+			wantCategory: "games",
+		},
+		{
+			name:         "defined category",
+			categories:   []dashboardv1alpha1.DashboardCategory{{ObjectMeta: metav1.ObjectMeta{Name: "games"}}, {ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}}},
+			wantCategory: "games",
+		},
+		{
+			name:         "deleted category is flagged",
+			categories:   []dashboardv1alpha1.DashboardCategory{{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}}},
+			wantDangling: map[string]string{"default/minecraft": "games"},
+			wantCategory: "games",
+		},
+		{
+			name:         "fallback category",
+			categories:   []dashboardv1alpha1.DashboardCategory{},
+			fallback:     "other",
+			wantDangling: map[string]string{"default/minecraft": "games", "default/grafana": "monitoring"},
+			wantCategory: "other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(log)
+			if tt.categories != nil {
+				a = a.WithCategories(tt.categories)
+			}
+			a.FallbackCategory = tt.fallback
+			result, err := a.Assemble(context.Background(), apps)
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			if len(result.DanglingCategories) != len(tt.wantDangling) {
+				t.Fatalf("DanglingCategories = %v, want %v", result.DanglingCategories, tt.wantDangling)
+			}
+			for source, category := range tt.wantDangling {
+				if result.DanglingCategories[source] != category {
+					t.Errorf("DanglingCategories[%s] = %q, want %q", source, result.DanglingCategories[source], category)
+				}
+			}
+			for _, e := range result.Entries {
+				if e.ID == "minecraft" && e.Category != tt.wantCategory {
+					t.Errorf("minecraft category = %q, want %q", e.Category, tt.wantCategory)
+				}
+			}
+		})
+	}
+}
+
+type hookFunc func([]AppEntry) ([]AppEntry, error)
+
+func (f hookFunc) Name() string { return "test" }
+
+func (f hookFunc) Transform(_ context.Context, entries []AppEntry) ([]AppEntry, error) {
+	return f(entries)
+}
+
+func TestAssembler_Hooks(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	apps := []dashboardv1alpha1.DashboardApp{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "plex", Namespace: "media"},
+			Spec:       dashboardv1alpha1.DashboardAppSpec{Name: "Plex", URL: "https://plex", Category: "media", Groups: []string{"family"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "sonarr", Namespace: "media"},
+			Spec:       dashboardv1alpha1.DashboardAppSpec{Name: "Sonarr", URL: "https://sonarr", Category: "media", Groups: []string{"admins"}},
+		},
+	}
+	rename := hookFunc(func(in []AppEntry) ([]AppEntry, error) {
+		out := slices.Clone(in)
+		for i := range out {
+			out[i].Source = ""
+			out[i].Name = strings.ToUpper(out[i].Name)
+		}
+		return out, nil
+	})
+	failing := hookFunc(func([]AppEntry) ([]AppEntry, error) {
+		return nil, operrors.NewPermanentError("boom", nil)
+	})
+	duplicate := hookFunc(func(in []AppEntry) ([]AppEntry, error) {
+		return append(slices.Clone(in), in[0]), nil
+	})
+
+	tests := []struct {
+		name      string
+		hooks     []EntryHook
+		policy    string
+		wantErr   bool
+		wantNames []string
+	}{
+		{name: "no hooks", wantNames: []string{"Plex", "Sonarr"}},
+		{name: "transform", hooks: []EntryHook{rename}, wantNames: []string{"PLEX", "SONARR"}},
+		{name: "failure ignored", hooks: []EntryHook{failing, rename}, wantNames: []string{"PLEX", "SONARR"}},
+		{name: "invalid output ignored", hooks: []EntryHook{duplicate}, wantNames: []string{"Plex", "Sonarr"}},
+		{name: "failure fails", hooks: []EntryHook{rename, failing}, policy: HookFailureFail, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(log)
+			a.Hooks = tt.hooks
+			a.HookFailurePolicy = tt.policy
+			result, err := a.Assemble(context.Background(), apps)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Assemble() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !operrors.ShouldRetry(err) {
+					t.Errorf("hook failure should be retried: %v", err)
+				}
+				return
+			}
+			var names []string
+			for _, e := range result.Entries {
+				names = append(names, e.Name)
+				if e.Source == "" {
+					t.Errorf("entry %s lost its source", e.ID)
+				}
+			}
+			if !slices.Equal(names, tt.wantNames) {
+				t.Errorf("names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestAssembler_DuplicateNames(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	app := func(namespace, name, display string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       dashboardv1alpha1.DashboardAppSpec{Name: display, URL: "https://" + name, Category: "admin", Groups: []string{"admins"}},
+		}
+	}
+	apps := []dashboardv1alpha1.DashboardApp{
+		app("monitoring", "grafana", "Grafana"),
+		app("staging", "grafana-staging", "grafana"),
+		app("media", "plex", "Plex"),
+		app("tools", "wiki", "Wiki"),
+		app("tools", "wiki-old", "Wiki"),
+	}
+
+	tests := []struct {
+		name      string
+		policy    string
+		wantDups  []NameDuplicate
+		wantNames map[string]string
+	}{
+		{
+			name:      "off",
+			wantNames: map[string]string{"grafana": "Grafana", "grafana-staging": "grafana", "wiki": "Wiki"},
+		},
+		{
+			name:   "flag",
+			policy: DuplicateNamesFlag,
+			wantDups: []NameDuplicate{
+				{Name: "Grafana", Sources: []string{"monitoring/grafana", "staging/grafana-staging"}},
+				{Name: "Wiki", Sources: []string{"tools/wiki", "tools/wiki-old"}},
+			},
+			wantNames: map[string]string{"grafana": "Grafana", "grafana-staging": "grafana", "wiki": "Wiki"},
+		},
+		{
+			name:   "suffix",
+			policy: DuplicateNamesSuffix,
+			wantDups: []NameDuplicate{
+				{Name: "Grafana", Sources: []string{"monitoring/grafana", "staging/grafana-staging"}},
+				{Name: "Wiki", Sources: []string{"tools/wiki", "tools/wiki-old"}},
+			},
+			wantNames: map[string]string{
+				"grafana":         "Grafana (monitoring)",
+				"grafana-staging": "grafana (staging)",
+				"wiki":            "Wiki (wiki)",
+				"wiki-old":        "Wiki (wiki-old)",
+				"plex":            "Plex",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(log)
+			a.DuplicateNamePolicy = tt.policy
+			result, err := a.Assemble(context.Background(), apps)
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			if len(result.DuplicateNames) != len(tt.wantDups) {
+				t.Fatalf("DuplicateNames = %v, want %v", result.DuplicateNames, tt.wantDups)
+			}
+			for i, d := range tt.wantDups {
+				got := result.DuplicateNames[i]
+				if got.Name != d.Name || !slices.Equal(got.Sources, d.Sources) {
+					t.Errorf("DuplicateNames[%d] = %+v, want %+v", i, got, d)
+				}
+			}
+			for _, e := range result.Entries {
+				if want, ok := tt.wantNames[e.ID]; ok && e.Name != want {
+					t.Errorf("%s name = %q, want %q", e.ID, e.Name, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAssembler_UndefinedCategoriesLast(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true))
+	newApp := func(name, category string) dashboardv1alpha1.DashboardApp {
+		return dashboardv1alpha1.DashboardApp{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: dashboardv1alpha1.DashboardAppSpec{
+				Name: name, URL: "https://" + name, Category: category, Icon: "<svg/>", Groups: []string{"family"},
+			},
+		}
+	}
+	apps := []dashboardv1alpha1.DashboardApp{
+		newApp("minecraft", "games"),
+		newApp("plex", "media"),
+		newApp("grafana", "monitoring"),
+		newApp("gitea", "development"),
+	}
+	defined := []dashboardv1alpha1.DashboardCategory{
+		{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}, Spec: dashboardv1alpha1.DashboardCategorySpec{Order: 10}},
+	}
+
+	tests := []struct {
+		name           string
+		categories     []dashboardv1alpha1.DashboardCategory
+		fallback       string
+		wantCategories []string
+		wantOrders     []int
+		wantEntries    []string
+	}{
+		{
+			name:           "undefined category kept after known ones",
+			categories:     defined,
+			wantCategories: []string{"media", "development", "monitoring", "games"},
+			wantOrders:     []int{0, 3, 10, 11},
+			wantEntries:    []string{"plex", "gitea", "grafana", "minecraft"},
+		},
+		{
+			name:           "fallback bucket last",
+			categories:     defined,
+			fallback:       DefaultFallbackCategory,
+			wantCategories: []string{"media", "development", "monitoring", "uncategorized"},
+			wantOrders:     []int{0, 3, 10, 12},
+			wantEntries:    []string{"plex", "gitea", "grafana", "minecraft"},
+		},
+		{
+			name:           "defined fallback keeps its order",
+			categories:     append(slices.Clone(defined), dashboardv1alpha1.DashboardCategory{ObjectMeta: metav1.ObjectMeta{Name: "other"}, Spec: dashboardv1alpha1.DashboardCategorySpec{Order: 1}}),
+			fallback:       "other",
+			wantCategories: []string{"media", "other", "development", "monitoring"},
+			wantOrders:     []int{0, 1, 3, 10},
+			wantEntries:    []string{"plex", "minecraft", "gitea", "grafana"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAssembler(log).WithCategories(tt.categories)
+			a.FallbackCategory = tt.fallback
+			result, err := a.Assemble(context.Background(), apps)
+			if err != nil {
+				t.Fatalf("Assemble() error = %v", err)
+			}
+			var ids []string
+			var orders []int
+			for _, c := range result.Categories {
+				ids = append(ids, c.ID)
+				orders = append(orders, c.Order)
+			}
+			if !slices.Equal(ids, tt.wantCategories) || !slices.Equal(orders, tt.wantOrders) {
+				t.Errorf("categories = %v (orders %v), want %v (orders %v)", ids, orders, tt.wantCategories, tt.wantOrders)
+			}
+			var entries []string
+			for _, e := range result.Entries {
+				entries = append(entries, e.ID)
+			}
+			if !slices.Equal(entries, tt.wantEntries) {
+				t.Errorf("entries = %v, want %v", entries, tt.wantEntries)
+			}
+		})
+	}
+}
+
+func TestDampedHealth(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	sample := func(state dashboardv1alpha1.HealthState, count int32) dashboardv1alpha1.HealthSample {
+		return dashboardv1alpha1.HealthSample{State: state, Time: metav1.NewTime(now), Count: count}
+	}
+	up, down := dashboardv1alpha1.HealthUp, dashboardv1alpha1.HealthDown
+	probed := &dashboardv1alpha1.HealthCheck{}
+
+	tests := []struct {
+		name        string
+		healthCheck *dashboardv1alpha1.HealthCheck
+		health      *dashboardv1alpha1.AppHealth
+		history     []dashboardv1alpha1.HealthSample
+		threshold   int
+		wantState   dashboardv1alpha1.HealthState
+	}{
+		{
+			name:        "no health",
+			healthCheck: probed,
+			threshold:   3,
+			wantState:   "",
+		},
+		{
+			name:        "damping off",
+			healthCheck: probed,
+			health:      &dashboardv1alpha1.AppHealth{State: down},
+			history:     []dashboardv1alpha1.HealthSample{sample(up, 3), sample(down, 1)},
+			wantState:   down,
+		},
+		{
+			name:        "short failure is damped",
+			healthCheck: probed,
+			health:      &dashboardv1alpha1.AppHealth{State: down},
+			history:     []dashboardv1alpha1.HealthSample{sample(up, 3), sample(down, 2)},
+			threshold:   3,
+			wantState:   up,
+		},
+		{
+			name:        "repeated failure shows",
+			healthCheck: probed,
+			health:      &dashboardv1alpha1.AppHealth{State: down},
+			history:     []dashboardv1alpha1.HealthSample{sample(up, 3), sample(down, 3)},
+			threshold:   3,
+			wantState:   down,
+		},
+		{
+			name:        "flapping keeps the last stable state",
+			healthCheck: probed,
+			health:      &dashboardv1alpha1.AppHealth{State: up},
+			history:     []dashboardv1alpha1.HealthSample{sample(up, 2), sample(down, 1), sample(up, 1), sample(down, 1), sample(up, 1)},
+			threshold:   2,
+			wantState:   up,
+		},
+		{
+			name:        "flapping after a failure keeps it",
+			healthCheck: probed,
+			health:      &dashboardv1alpha1.AppHealth{State: up},
+			history:     []dashboardv1alpha1.HealthSample{sample(down, 2), sample(up, 1)},
+			threshold:   2,
+			wantState:   down,
+		},
+		{
+			name:        "new app shows its first state",
+			healthCheck: probed,
+			health:      &dashboardv1alpha1.AppHealth{State: down},
+			history:     []dashboardv1alpha1.HealthSample{sample(down, 1)},
+			threshold:   3,
+			wantState:   down,
+		},
+		{
+			name:      "apps not probed are not damped",
+			health:    &dashboardv1alpha1.AppHealth{State: down},
+			history:   []dashboardv1alpha1.HealthSample{sample(up, 0), sample(down, 0)},
+			threshold: 3,
+			wantState: down,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &dashboardv1alpha1.DashboardApp{
+				Spec:   dashboardv1alpha1.DashboardAppSpec{HealthCheck: tt.healthCheck},
+				Status: dashboardv1alpha1.DashboardAppStatus{Health: tt.health, HealthHistory: tt.history},
+			}
+			if got := DampedHealth(app, tt.threshold); got.State != tt.wantState {
+				t.Errorf("state = %q, want %q", got.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCountHealthResult(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	up, down := dashboardv1alpha1.HealthUp, dashboardv1alpha1.HealthDown
+	app := &dashboardv1alpha1.DashboardApp{}
+
+	for i, tt := range []struct {
+		state       dashboardv1alpha1.HealthState
+		wantChanged bool
+		wantCounts  []int32
+	}{
+		{up, true, []int32{1}},
+		{up, true, []int32{2}},
+		{up, false, []int32{2}},
+		{down, true, []int32{2, 1}},
+		{up, true, []int32{2, 1, 1}},
+	} {
+		changed := CountHealthResult(app, tt.state, now, 2)
+		var counts []int32
+		for _, s := range app.Status.HealthHistory {
+			counts = append(counts, s.Count)
+		}
+		if changed != tt.wantChanged || !slices.Equal(counts, tt.wantCounts) {
+			t.Errorf("result %d: changed = %v, counts = %v, want %v and %v", i, changed, counts, tt.wantChanged, tt.wantCounts)
+		}
+	}
+}
+
+func TestRecordHealth(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	app := &dashboardv1alpha1.DashboardApp{}
+	if RecordHealth(app, now) {
+		t.Fatal("recorded a sample for an app without health")
+	}
+
+	states := []dashboardv1alpha1.HealthState{dashboardv1alpha1.HealthUp, dashboardv1alpha1.HealthDown}
+	for i := 0; i < HealthHistorySize+3; i++ {
+		app.Status.Health = &dashboardv1alpha1.AppHealth{State: states[i%2]}
+		if !RecordHealth(app, now.Add(time.Duration(i)*time.Minute)) {
+			t.Fatalf("sample %d not recorded", i)
+		}
+		if RecordHealth(app, now.Add(time.Duration(i)*time.Minute+time.Second)) {
+			t.Fatalf("unchanged state %d recorded twice", i)
+		}
+	}
+	history := app.Status.HealthHistory
+	if len(history) != HealthHistorySize {
+		t.Fatalf("history has %d samples, want %d", len(history), HealthHistorySize)
+	}
+	if want := now.Add(time.Duration(HealthHistorySize+2) * time.Minute); !history[len(history)-1].Time.Time.Equal(want) {
+		t.Errorf("last sample at %v, want %v", history[len(history)-1].Time, want)
+	}
+}
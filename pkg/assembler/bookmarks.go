@@ -0,0 +1,76 @@
+package assembler
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+// BookmarkEntry is a link of the bookmarks section of the output (see
+// dashboardv1alpha1.DashboardBookmark)
+type BookmarkEntry struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Category string `json:"category,omitempty"`
+
+	// Groups restricts the bookmark to their members; everyone sees it
+	// when empty
+	Groups []string `json:"groups,omitempty"`
+}
+
+// WithBookmarks returns a copy of the Assembler that lists the given
+// DashboardBookmark objects in the bookmarks section of the output.
+func (a *Assembler) WithBookmarks(bookmarks []dashboardv1alpha1.DashboardBookmark) *Assembler {
+	c := *a
+	c.Bookmarks = bookmarks
+	return &c
+}
+
+// buildBookmarks renders the bookmarks, ordered by category like the apps,
+// uncategorized ones last, then by name. Bookmarks do not go through the
+// checks apps do: they carry no template, icon or health.
+func (a *Assembler) buildBookmarks() []BookmarkEntry {
+	type sourced struct {
+		BookmarkEntry
+		source string
+	}
+	bookmarks := make([]sourced, 0, len(a.Bookmarks))
+	for i := range a.Bookmarks {
+		b := &a.Bookmarks[i]
+		url := strings.TrimSpace(b.Spec.URL)
+		if url == "" {
+			a.Log.Info("Leaving out bookmark without a URL", "bookmark", b.Name, "namespace", b.Namespace)
+			continue
+		}
+		bookmarks = append(bookmarks, sourced{
+			BookmarkEntry: BookmarkEntry{
+				Name:     cmp.Or(strings.TrimSpace(b.Spec.Name), b.Name),
+				URL:      url,
+				Category: strings.ToLower(strings.TrimSpace(b.Spec.Category)),
+				Groups:   b.Spec.Groups,
+			},
+			source: b.Namespace + "/" + b.Name,
+		})
+	}
+	slices.SortFunc(bookmarks, func(x, y sourced) int {
+		if (x.Category == "") != (y.Category == "") {
+			if x.Category == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(
+			cmp.Compare(a.categoryRank(x.Category), a.categoryRank(y.Category)),
+			strings.Compare(x.Category, y.Category),
+			strings.Compare(strings.ToLower(x.Name), strings.ToLower(y.Name)),
+			strings.Compare(x.source, y.source),
+		)
+	})
+	out := make([]BookmarkEntry, len(bookmarks))
+	for i, b := range bookmarks {
+		out[i] = b.BookmarkEntry
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package assembler
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+// CategoryEntry represents a category header in the output JSON
+type CategoryEntry struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Icon        string `json:"icon,omitempty"`
+	Order       int    `json:"order"`
+	Description string `json:"description,omitempty"`
+	Collapsed   bool   `json:"collapsed,omitempty"`
+}
+
+// WithCategories returns a copy of the Assembler that renders category
+// metadata from the given DashboardCategory objects. Categories without a
+// matching object fall back to the built-in order and their ID as display name.
+func (a *Assembler) WithCategories(categories []dashboardv1alpha1.DashboardCategory) *Assembler {
+	c := *a
+	c.Categories = make(map[string]dashboardv1alpha1.DashboardCategorySpec, len(categories))
+	for _, cat := range categories {
+		c.Categories[cat.Name] = cat.Spec
+	}
+	return &c
+}
+
+// DefaultFallbackCategory is the bucket apps with a dangling category are
+// listed under by default
+const DefaultFallbackCategory = "uncategorized"
+
+// danglingCategory reports whether a category is neither defined by a
+// DashboardCategory nor built in, e.g. because its DashboardCategory was
+// deleted. Nothing is dangling until categories are loaded (WithCategories).
+func (a *Assembler) danglingCategory(id string) bool {
+	return a.Categories != nil && !a.known(id)
+}
+
+// known reports whether a category is defined by a DashboardCategory or
+// built in.
+func (a *Assembler) known(id string) bool {
+	if _, ok := a.Categories[id]; ok {
+		return true
+	}
+	_, builtIn := categoryOrder[id]
+	return builtIn
+}
+
+// categoryRank orders categories: by their DashboardCategory order if
+// defined, else by built-in order. Other categories come last, followed by
+// FallbackCategory when it is not defined itself.
+func (a *Assembler) categoryRank(id string) int {
+	if spec, ok := a.Categories[id]; ok {
+		return spec.Order
+	}
+	if order, ok := categoryOrder[id]; ok {
+		return order
+	}
+	if id == a.FallbackCategory {
+		return math.MaxInt
+	}
+	return math.MaxInt - 1
+}
+
+// ForCategory returns the entries of a category, in catalog order.
+func ForCategory(entries []AppEntry, category string) []AppEntry {
+	shard := make([]AppEntry, 0)
+	for _, e := range entries {
+		if e.Category == category {
+			shard = append(shard, e)
+		}
+	}
+	return shard
+}
+
+// buildCategories returns one CategoryEntry per category referenced by the
+// entries, sorted by order then ID. Categories that are neither defined nor
+// built in are ordered after all others, the fallback bucket last.
+func (a *Assembler) buildCategories(entries []AppEntry) []CategoryEntry {
+	seen := make(map[string]bool)
+	categories := make([]CategoryEntry, 0)
+
+	for _, e := range entries {
+		if seen[e.Category] {
+			continue
+		}
+		seen[e.Category] = true
+
+		entry := CategoryEntry{
+			ID:          e.Category,
+			DisplayName: e.Category,
+			Order:       categoryOrder[e.Category],
+		}
+		if spec, ok := a.Categories[e.Category]; ok {
+			if spec.DisplayName != "" {
+				entry.DisplayName = spec.DisplayName
+			}
+			entry.Icon = spec.Icon
+			entry.Order = spec.Order
+			entry.Description = spec.Description
+			entry.Collapsed = spec.Collapsed
+		}
+		categories = append(categories, entry)
+	}
+
+	// Give undefined categories an order past the last known one, so
+	// consumers sorting by order agree with the entries
+	last := -1
+	for _, c := range categories {
+		if a.known(c.ID) {
+			last = max(last, c.Order)
+		}
+	}
+	for i := range categories {
+		if c := &categories[i]; !a.known(c.ID) {
+			c.Order = last + 1
+			if c.ID == a.FallbackCategory {
+				c.Order = last + 2
+			}
+		}
+	}
+
+	slices.SortFunc(categories, func(a, b CategoryEntry) int {
+		if c := cmp.Compare(a.Order, b.Order); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return categories
+}
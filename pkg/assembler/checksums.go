@@ -0,0 +1,44 @@
+package assembler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// Checksums fingerprints each published entry and icon by entry ID, so
+// clients can invalidate what changed instead of reloading the catalog
+type Checksums struct {
+	// Entries hashes each entry's content, leaving out its icon
+	Entries map[string]string `json:"entries"`
+
+	// Icons hashes each entry's icon; entries without one are left out
+	Icons map[string]string `json:"icons"`
+}
+
+// EntryChecksums computes the checksums of entries. An icon change only
+// changes the icon's checksum, not the entry's.
+func EntryChecksums(entries []AppEntry) (Checksums, error) {
+	sums := Checksums{
+		Entries: make(map[string]string, len(entries)),
+		Icons:   make(map[string]string, len(entries)),
+	}
+	for _, e := range entries {
+		icon, ref := e.Icon, e.IconRef
+		e.Icon, e.IconRef = "", nil
+		data, err := json.Marshal(e)
+		if err != nil {
+			return Checksums{}, err
+		}
+		sum := sha256.Sum256(data)
+		sums.Entries[e.ID] = hex.EncodeToString(sum[:16])
+		switch {
+		case icon != "":
+			sums.Icons[e.ID] = IconKey(icon)
+		case ref != nil:
+			sums.Icons[e.ID] = strings.TrimSuffix(ref.Key, ".svg")
+		}
+	}
+	return sums, nil
+}
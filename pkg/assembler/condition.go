@@ -0,0 +1,46 @@
+package assembler
+
+import (
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/facts"
+)
+
+// WithFacts returns a copy of the Assembler that evaluates spec.condition
+// against the given cluster facts.
+func (a *Assembler) WithFacts(f *facts.Facts) *Assembler {
+	c := *a
+	c.Facts = f
+	return &c
+}
+
+// conditionMet evaluates the app's spec.condition. An expression that does
+// not compile or fails to evaluate (e.g. a flag that is not set) is an
+// error, for which the app is left out with a violation like a failing URL
+// template.
+func (a *Assembler) conditionMet(app *dashboardv1alpha1.DashboardApp) (bool, error) {
+	if app.Spec.Condition == "" {
+		return true, nil
+	}
+	if err := facts.Validate(app.Spec.Condition); err != nil {
+		return false, operrors.NewPermanentError("invalid condition", err).
+			WithContext("app", app.Namespace+"/"+app.Name)
+	}
+	ok, err := a.Facts.Eval(app.Spec.Condition)
+	if err != nil {
+		return false, operrors.NewPermanentError("condition failed to evaluate", err).
+			WithContext("app", app.Namespace+"/"+app.Name)
+	}
+	return ok, nil
+}
+
+// HasConditions reports whether any app sets spec.condition, i.e. whether
+// cluster facts need to be gathered.
+func HasConditions(apps []dashboardv1alpha1.DashboardApp) bool {
+	for i := range apps {
+		if apps[i].Spec.Condition != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package assembler
+
+import (
+	"fmt"
+	"maps"
+)
+
+// MaxExtraBytes bounds the total size of an app's extra fields (keys and
+// values), keeping them from bloating the output
+const MaxExtraBytes = 4096
+
+// extraFields returns a copy of extra for the app's entry, or an error if
+// the fields are over MaxExtraBytes.
+func extraFields(extra map[string]string) (map[string]string, error) {
+	if len(extra) == 0 {
+		return nil, nil
+	}
+	size := 0
+	for k, v := range extra {
+		size += len(k) + len(v)
+	}
+	if size > MaxExtraBytes {
+		return nil, fmt.Errorf("extra fields take %d bytes, over the %d bytes limit", size, MaxExtraBytes)
+	}
+	return maps.Clone(extra), nil
+}
@@ -0,0 +1,90 @@
+package assembler
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Format versions of duro's documents. Version 1 is the original layout:
+// bare documents under plain keys (apps.json). Later versions wrap each
+// document in an envelope carrying its version,
+// {"formatVersion":2,"data":[...]}, under keys naming the version
+// (apps.v2.json). Several versions can be written side by side, so duro and
+// the operator can be upgraded independently: the operator writes both the
+// version the running duro reads and the one the next duro reads, and the
+// old one is dropped once every duro is upgraded.
+const (
+	// FormatVersionLegacy is the original, unversioned layout
+	FormatVersionLegacy = 1
+	// LatestFormatVersion is the newest format version the operator writes
+	LatestFormatVersion = 2
+)
+
+// FormatVersionsKey is the document listing the format versions written,
+// for consumers picking the newest one they understand; only written next
+// to versions other than FormatVersionLegacy
+const FormatVersionsKey = "formats.json"
+
+// envelope wraps a document of a format version after FormatVersionLegacy
+type envelope struct {
+	FormatVersion int             `json:"formatVersion"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// ValidateFormatVersion checks version is a format version the operator
+// writes.
+func ValidateFormatVersion(version int) error {
+	if version < FormatVersionLegacy || version > LatestFormatVersion {
+		return fmt.Errorf("unsupported format version %d (want %d to %d)", version, FormatVersionLegacy, LatestFormatVersion)
+	}
+	return nil
+}
+
+// VersionedKey returns the key of the document key in format version.
+func VersionedKey(key string, version int) string {
+	if version == FormatVersionLegacy {
+		return key
+	}
+	ext := ""
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		key, ext = key[:i], key[i:]
+	}
+	return key + ".v" + strconv.Itoa(version) + ext
+}
+
+// Versioned returns the JSON documents docs in each of versions, under
+// their versioned keys, along with FormatVersionsKey unless only
+// FormatVersionLegacy is written.
+func Versioned(docs map[string]string, versions []int) (map[string]string, error) {
+	out := make(map[string]string, len(docs)*len(versions)+1)
+	for _, version := range versions {
+		if err := ValidateFormatVersion(version); err != nil {
+			return nil, err
+		}
+		for key, doc := range docs {
+			if version == FormatVersionLegacy {
+				out[key] = doc
+				continue
+			}
+			wrapped, err := json.Marshal(envelope{FormatVersion: version, Data: json.RawMessage(doc)})
+			if err != nil {
+				return nil, fmt.Errorf("format version %d of %s: %w", version, key, err)
+			}
+			out[VersionedKey(key, version)] = string(wrapped)
+		}
+	}
+	if len(versions) > 0 && !slices.Equal(versions, []int{FormatVersionLegacy}) {
+		sorted := slices.Sorted(slices.Values(versions))
+		manifest, err := json.Marshal(struct {
+			FormatVersions []int `json:"formatVersions"`
+		}{sorted})
+		if err != nil {
+			return nil, err
+		}
+		out[FormatVersionsKey] = string(manifest)
+	}
+	return out, nil
+}
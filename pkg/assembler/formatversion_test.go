@@ -0,0 +1,46 @@
+package assembler
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVersioned(t *testing.T) {
+	docs := map[string]string{"apps.json": `[{"id":"plex"}]`, "apps-media_tv.json": `[]`}
+
+	legacy, err := Versioned(docs, []int{FormatVersionLegacy})
+	if err != nil {
+		t.Fatalf("Versioned() error = %v", err)
+	}
+	if len(legacy) != 2 || legacy["apps.json"] != docs["apps.json"] {
+		t.Errorf("legacy documents = %v, want the documents as they are", legacy)
+	}
+
+	both, err := Versioned(docs, []int{2, FormatVersionLegacy})
+	if err != nil {
+		t.Fatalf("Versioned() error = %v", err)
+	}
+	if both["apps.json"] != docs["apps.json"] {
+		t.Errorf("apps.json = %s, want it left as it is next to later versions", both["apps.json"])
+	}
+	var env struct {
+		FormatVersion int              `json:"formatVersion"`
+		Data          []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(both["apps.v2.json"]), &env); err != nil {
+		t.Fatalf("apps.v2.json = %s: %v", both["apps.v2.json"], err)
+	}
+	if env.FormatVersion != 2 || len(env.Data) != 1 || env.Data[0]["id"] != "plex" {
+		t.Errorf("apps.v2.json = %+v, want plex in a version 2 envelope", env)
+	}
+	if _, ok := both["apps-media_tv.v2.json"]; !ok {
+		t.Errorf("documents = %v, want apps-media_tv.v2.json", both)
+	}
+	if both[FormatVersionsKey] != `{"formatVersions":[1,2]}` {
+		t.Errorf("%s = %s, want both versions in order", FormatVersionsKey, both[FormatVersionsKey])
+	}
+
+	if _, err := Versioned(docs, []int{LatestFormatVersion + 1}); err == nil {
+		t.Error("Versioned() with an unknown version succeeded, want an error")
+	}
+}
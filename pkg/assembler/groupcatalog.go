@@ -0,0 +1,64 @@
+package assembler
+
+import (
+	"slices"
+	"strings"
+)
+
+// GroupEntry describes one group (or group pattern) referenced by the
+// published apps, for duro's admin view
+type GroupEntry struct {
+	Group string `json:"group"`
+
+	// Apps is how many published apps the group sees
+	Apps int `json:"apps"`
+
+	// Categories lists the categories of those apps, in display order
+	Categories []string `json:"categories"`
+}
+
+// buildGroupCatalog lists the groups granting access to the entries (their
+// groups and allOf groups, not noneOf ones), sorted by name. Like the apps output, it reflects current visibility: groups an app
+// is hidden from by its visibility schedule do not count.
+func buildGroupCatalog(entries []AppEntry, categories []CategoryEntry) []GroupEntry {
+	rank := make(map[string]int, len(categories))
+	for i, cat := range categories {
+		rank[cat.ID] = i
+	}
+	byGroup := make(map[string]*GroupEntry)
+	for _, e := range entries {
+		for _, g := range entryGroupPatterns(&e) {
+			ge, ok := byGroup[g]
+			if !ok {
+				ge = &GroupEntry{Group: g}
+				byGroup[g] = ge
+			}
+			ge.Apps++
+			if !slices.Contains(ge.Categories, e.Category) {
+				ge.Categories = append(ge.Categories, e.Category)
+			}
+		}
+	}
+	catalog := make([]GroupEntry, 0, len(byGroup))
+	for _, ge := range byGroup {
+		slices.SortFunc(ge.Categories, func(x, y string) int { return rank[x] - rank[y] })
+		catalog = append(catalog, *ge)
+	}
+	slices.SortFunc(catalog, func(x, y GroupEntry) int { return strings.Compare(x.Group, y.Group) })
+	return catalog
+}
+
+// entryGroupPatterns lists the groups granting access to the entry, once
+// each.
+func entryGroupPatterns(e *AppEntry) []string {
+	if e.Access == nil || len(e.Access.AllOf) == 0 {
+		return e.Groups
+	}
+	patterns := slices.Clone(e.Groups)
+	for _, g := range e.Access.AllOf {
+		if !slices.Contains(patterns, g) {
+			patterns = append(patterns, g)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,177 @@
+package assembler
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+// effectiveHealth is an app's health after rolling up its dependencies
+type effectiveHealth struct {
+	State  dashboardv1alpha1.HealthState
+	Reason string
+}
+
+// HealthHistorySize bounds status.healthHistory
+const HealthHistorySize = 10
+
+// RecordHealth appends the app's current status.health state to its health
+// history if it differs from the last recorded one, dropping the oldest
+// samples beyond HealthHistorySize. Returns true if the history changed.
+func RecordHealth(app *dashboardv1alpha1.DashboardApp, now time.Time) bool {
+	sample, ok := observedSample(app, now)
+	if !ok {
+		return false
+	}
+	history := app.Status.HealthHistory
+	if n := len(history); n > 0 && history[n-1].State == sample.State {
+		return false
+	}
+	appendSample(app, sample)
+	return true
+}
+
+// CountHealthResult records a health check result of the app in its health
+// history: it counts one more result for the last sample if it has the same
+// state, up to limit, or appends a new sample otherwise. Returns true if the
+// history changed.
+func CountHealthResult(app *dashboardv1alpha1.DashboardApp, state dashboardv1alpha1.HealthState, now time.Time, limit int) bool {
+	history := app.Status.HealthHistory
+	if n := len(history); n > 0 && history[n-1].State == state {
+		if int(history[n-1].Count) >= max(limit, 1) {
+			return false
+		}
+		history[n-1].Count++
+		return true
+	}
+	appendSample(app, dashboardv1alpha1.HealthSample{State: state, Time: metav1.NewTime(now), Count: 1})
+	return true
+}
+
+// appendSample appends sample to the app's health history, dropping the
+// oldest samples beyond HealthHistorySize.
+func appendSample(app *dashboardv1alpha1.DashboardApp, sample dashboardv1alpha1.HealthSample) {
+	history := append(app.Status.HealthHistory, sample)
+	if len(history) > HealthHistorySize {
+		history = history[len(history)-HealthHistorySize:]
+	}
+	app.Status.HealthHistory = history
+}
+
+// observedSample is the app's status.health as a history sample, dated by
+// its last transition.
+func observedSample(app *dashboardv1alpha1.DashboardApp, now time.Time) (dashboardv1alpha1.HealthSample, bool) {
+	h := app.Status.Health
+	if h == nil || h.State == "" {
+		return dashboardv1alpha1.HealthSample{}, false
+	}
+	sample := dashboardv1alpha1.HealthSample{State: h.State, Time: metav1.NewTime(now)}
+	if h.LastTransitionTime != nil {
+		sample.Time = *h.LastTransitionTime
+	}
+	return sample, true
+}
+
+// DampedHealth returns the app's status.health as published under a damping
+// threshold: a new state only shows once that many consecutive health check
+// results reported it, until then the last state that did is kept, so a
+// momentarily failing app doesn't blink on the dashboard. Only apps probed
+// by the operator (spec.healthCheck) are damped: states written by others
+// carry no result count.
+func DampedHealth(app *dashboardv1alpha1.DashboardApp, threshold int) dashboardv1alpha1.AppHealth {
+	if app.Status.Health == nil {
+		return dashboardv1alpha1.AppHealth{}
+	}
+	observed := *app.Status.Health
+	samples := app.Status.HealthHistory
+	n := len(samples)
+	if threshold <= 1 || app.Spec.HealthCheck == nil || n == 0 || samples[n-1].State != observed.State {
+		return observed
+	}
+	for i := n - 1; i >= 0; i-- {
+		if int(samples[i].Count) < threshold {
+			continue
+		}
+		if i == n-1 {
+			return observed
+		}
+		return dashboardv1alpha1.AppHealth{
+			State:  samples[i].State,
+			Reason: fmt.Sprintf("%s for %d of %d health checks, damped", observed.State, samples[n-1].Count, threshold),
+		}
+	}
+	// Nothing was reported often enough yet, e.g. a new app: show what is
+	// known
+	return observed
+}
+
+// rollupHealth computes the effective health of every app, keyed by
+// namespace/name. An app whose own state is down stays down; otherwise it is
+// degraded if any dependency (transitively) is down, degraded or missing.
+// Apps without observed health and healthy dependencies have an empty state.
+// An app's own state comes from its heartbeat when it has a heartbeat timeout,
+// from status.health otherwise, damped by HealthDamping.
+func (a *Assembler) rollupHealth(apps []dashboardv1alpha1.DashboardApp, now time.Time) map[string]effectiveHealth {
+	byKey := make(map[string]*dashboardv1alpha1.DashboardApp, len(apps))
+	for i := range apps {
+		byKey[apps[i].Namespace+"/"+apps[i].Name] = &apps[i]
+	}
+
+	result := make(map[string]effectiveHealth, len(apps))
+	visiting := make(map[string]bool)
+
+	var resolve func(key string) effectiveHealth
+	resolve = func(key string) effectiveHealth {
+		if h, ok := result[key]; ok {
+			return h
+		}
+		app := byKey[key]
+
+		var h effectiveHealth
+		if hb, ok := HeartbeatHealth(app, now); ok {
+			h = effectiveHealth{State: hb.State, Reason: hb.Reason}
+		} else if app.Status.Health != nil {
+			damped := DampedHealth(app, a.HealthDamping)
+			h = effectiveHealth{State: damped.State, Reason: damped.Reason}
+		}
+
+		if h.State != dashboardv1alpha1.HealthDown && !visiting[key] {
+			visiting[key] = true
+			for _, dep := range app.Spec.DependsOn {
+				depKey := dependencyKey(app, dep)
+				if _, ok := byKey[depKey]; !ok {
+					h = effectiveHealth{State: dashboardv1alpha1.HealthDegraded, Reason: fmt.Sprintf("dependency %s not found", depKey)}
+					break
+				}
+				if visiting[depKey] {
+					// dependency cycle: don't let it mark the whole loop degraded
+					continue
+				}
+				if d := resolve(depKey); d.State == dashboardv1alpha1.HealthDown || d.State == dashboardv1alpha1.HealthDegraded {
+					h = effectiveHealth{State: dashboardv1alpha1.HealthDegraded, Reason: fmt.Sprintf("dependency %s is %s", depKey, d.State)}
+					break
+				}
+			}
+			delete(visiting, key)
+		}
+
+		result[key] = h
+		return h
+	}
+
+	for key := range byKey {
+		resolve(key)
+	}
+	return result
+}
+
+func dependencyKey(app *dashboardv1alpha1.DashboardApp, dep dashboardv1alpha1.AppReference) string {
+	ns := dep.Namespace
+	if ns == "" {
+		ns = app.Namespace
+	}
+	return ns + "/" + dep.Name
+}
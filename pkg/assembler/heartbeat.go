@@ -0,0 +1,36 @@
+package assembler
+
+import (
+	"fmt"
+	"time"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+// HeartbeatStale reports whether an app with spec.heartbeatTimeout has gone
+// without a heartbeat for longer than the timeout at now, and the time at
+// which it becomes (or became) stale. Apps without a timeout are never stale
+// and return a zero time.
+func HeartbeatStale(app *dashboardv1alpha1.DashboardApp, now time.Time) (bool, time.Time) {
+	if app.Spec.HeartbeatTimeout == nil || app.Spec.HeartbeatTimeout.Duration <= 0 {
+		return false, time.Time{}
+	}
+	staleAt := app.LastSeen().Add(app.Spec.HeartbeatTimeout.Duration)
+	return !now.Before(staleAt), staleAt
+}
+
+// HeartbeatHealth is the health an app with spec.heartbeatTimeout reports on
+// its own: up while heartbeats arrive in time, unknown once they lapse. The
+// second return value is false for apps without a timeout.
+func HeartbeatHealth(app *dashboardv1alpha1.DashboardApp, now time.Time) (dashboardv1alpha1.AppHealth, bool) {
+	if app.Spec.HeartbeatTimeout == nil {
+		return dashboardv1alpha1.AppHealth{}, false
+	}
+	if stale, _ := HeartbeatStale(app, now); stale {
+		return dashboardv1alpha1.AppHealth{
+			State:  dashboardv1alpha1.HealthUnknown,
+			Reason: fmt.Sprintf("no heartbeat since %s", app.LastSeen().UTC().Format(time.RFC3339)),
+		}, true
+	}
+	return dashboardv1alpha1.AppHealth{State: dashboardv1alpha1.HealthUp, Reason: "heartbeat received"}, true
+}
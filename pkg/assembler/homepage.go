@@ -0,0 +1,122 @@
+package assembler
+
+import (
+	"slices"
+	"strings"
+
+	yaml "go.yaml.in/yaml/v2"
+)
+
+const (
+	// HomepageServicesKey and HomepageSettingsKey are the keys of
+	// gethomepage's configuration files in the output
+	HomepageServicesKey = "services.yaml"
+	HomepageSettingsKey = "settings.yaml"
+
+	// HomepageExtraPrefix prefixes the spec.extra fields read by the homepage
+	// format: homepage.icon overrides the icon with one of Homepage's own
+	// (e.g. mdi-plex or plex.png), homepage.widget.<field> sets a field of
+	// the service widget (e.g. homepage.widget.type=plex). Widget values are
+	// read as YAML, so lists and numbers keep their type.
+	HomepageExtraPrefix = "homepage."
+)
+
+// homepageService is a service of Homepage's services.yaml
+type homepageService struct {
+	Icon        string        `yaml:"icon,omitempty"`
+	Href        string        `yaml:"href"`
+	Description string        `yaml:"description,omitempty"`
+	SiteMonitor string        `yaml:"siteMonitor,omitempty"`
+	Widget      yaml.MapSlice `yaml:"widget,omitempty"`
+}
+
+// homepageLayout is a group of the layout in Homepage's settings.yaml
+type homepageLayout struct {
+	Icon               string `yaml:"icon,omitempty"`
+	InitiallyCollapsed bool   `yaml:"initiallyCollapsed,omitempty"`
+}
+
+// renderHomepage writes the entries as gethomepage services.yaml, one
+// service group per category in output order, and the layout of
+// settings.yaml keeping the groups in that order. Internal URLs are
+// monitored by Homepage's site monitor. Apps kept listed during their
+// removal grace period are left out.
+func renderHomepage(result *AssemblyResult) (map[string]string, error) {
+	services := make(map[string][]yaml.MapSlice)
+	for _, e := range result.Entries {
+		if e.Removed {
+			continue
+		}
+		svc := homepageService{
+			Icon:        homepageIcon(e.Icon),
+			Href:        e.URL,
+			Description: e.Description,
+			SiteMonitor: e.InternalURL,
+		}
+		if icon := e.Extra[HomepageExtraPrefix+"icon"]; icon != "" {
+			svc.Icon = icon
+		}
+		svc.Widget = homepageWidget(e.Extra)
+		services[e.Category] = append(services[e.Category], yaml.MapSlice{{Key: e.Name, Value: svc}})
+	}
+
+	groups := make([]yaml.MapSlice, 0, len(result.Categories))
+	layout := yaml.MapSlice{}
+	for _, c := range result.Categories {
+		if len(services[c.ID]) == 0 {
+			continue
+		}
+		groups = append(groups, yaml.MapSlice{{Key: c.DisplayName, Value: services[c.ID]}})
+		layout = append(layout, yaml.MapItem{Key: c.DisplayName, Value: homepageLayout{Icon: homepageIcon(c.Icon), InitiallyCollapsed: c.Collapsed}})
+	}
+
+	servicesDoc, err := yaml.Marshal(groups)
+	if err != nil {
+		return nil, err
+	}
+	settingsDoc, err := yaml.Marshal(yaml.MapSlice{{Key: "layout", Value: layout}})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{HomepageServicesKey: string(servicesDoc), HomepageSettingsKey: string(settingsDoc)}, nil
+}
+
+// homepageWidget builds the service widget from the homepage.widget.*
+// extra fields, type first.
+func homepageWidget(extra map[string]string) yaml.MapSlice {
+	var fields []string
+	for key := range extra {
+		if field, ok := strings.CutPrefix(key, HomepageExtraPrefix+"widget."); ok && field != "" {
+			fields = append(fields, field)
+		}
+	}
+	slices.SortFunc(fields, func(x, y string) int {
+		switch {
+		case x == "type":
+			return -1
+		case y == "type":
+			return 1
+		}
+		return strings.Compare(x, y)
+	})
+
+	var widget yaml.MapSlice
+	for _, field := range fields {
+		raw := extra[HomepageExtraPrefix+"widget."+field]
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+			value = raw
+		}
+		widget = append(widget, yaml.MapItem{Key: field, Value: value})
+	}
+	return widget
+}
+
+// homepageIcon keeps icons Homepage can load, i.e. URLs; inline SVG has no
+// place in its configuration.
+func homepageIcon(icon string) string {
+	if strings.HasPrefix(icon, "/") || strings.HasPrefix(icon, "http://") || strings.HasPrefix(icon, "https://") {
+		return icon
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package assembler
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// HomerConfigKey is the key of Homer's configuration in the output
+const HomerConfigKey = "config.yml"
+
+// homerConfig is the part of Homer's config.yml the operator owns; Homer
+// falls back to its defaults for the rest (title, theme, links)
+type homerConfig struct {
+	Services []homerGroup `json:"services"`
+}
+
+// homerGroup is a Homer service group, one per category
+type homerGroup struct {
+	Name  string      `json:"name"`
+	Logo  string      `json:"logo,omitempty"`
+	Items []homerItem `json:"items"`
+}
+
+// homerItem is a Homer service, one per entry
+type homerItem struct {
+	Name     string `json:"name"`
+	Logo     string `json:"logo,omitempty"`
+	Subtitle string `json:"subtitle,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Keywords string `json:"keywords,omitempty"`
+	URL      string `json:"url"`
+	Target   string `json:"target"`
+}
+
+// renderHomer writes the entries as Homer services, grouped by category in
+// output order. Apps kept listed during their removal grace period are
+// left out, Homer having no way to grey them out.
+func renderHomer(result *AssemblyResult) (map[string]string, error) {
+	items := make(map[string][]homerItem)
+	for _, e := range result.Entries {
+		if e.Removed {
+			continue
+		}
+		item := homerItem{
+			Name:     e.Name,
+			Logo:     homerLogo(e.Icon),
+			Subtitle: e.Description,
+			Keywords: strings.Join(e.Tags, " "),
+			URL:      e.URL,
+			Target:   "_blank",
+		}
+		if e.New {
+			item.Tag = "new"
+		}
+		items[e.Category] = append(items[e.Category], item)
+	}
+
+	config := homerConfig{Services: make([]homerGroup, 0, len(result.Categories))}
+	for _, c := range result.Categories {
+		if len(items[c.ID]) == 0 {
+			continue
+		}
+		config.Services = append(config.Services, homerGroup{Name: c.DisplayName, Logo: homerLogo(c.Icon), Items: items[c.ID]})
+	}
+	doc, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{HomerConfigKey: string(doc)}, nil
+}
+
+// homerLogo turns an icon into something Homer can show as a logo: inline
+// SVG becomes a data URI, URLs are kept, anything else is dropped.
+func homerLogo(icon string) string {
+	icon = strings.TrimSpace(icon)
+	switch {
+	case strings.HasPrefix(icon, "<"):
+		return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(icon))
+	case strings.HasPrefix(icon, "data:"), strings.HasPrefix(icon, "/"), strings.Contains(icon, "://"):
+		return icon
+	}
+	return ""
+}
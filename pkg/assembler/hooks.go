@@ -0,0 +1,79 @@
+package assembler
+
+import (
+	"context"
+	"fmt"
+
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// Hook failure policies
+const (
+	// HookFailureIgnore logs a failing hook and keeps the entries it was given
+	HookFailureIgnore = "ignore"
+	// HookFailureFail aborts the assembly, keeping the previous output
+	HookFailureFail = "fail"
+)
+
+// EntryHook transforms the entry list after assembly and before it is
+// formatted, for site-specific tweaks without forking the operator.
+type EntryHook interface {
+	// Name identifies the hook in logs and errors
+	Name() string
+	// Transform returns the entries to publish
+	Transform(ctx context.Context, entries []AppEntry) ([]AppEntry, error)
+}
+
+// ValidateHookFailurePolicy checks that a hook failure policy is known.
+func ValidateHookFailurePolicy(policy string) error {
+	switch policy {
+	case "", HookFailureIgnore, HookFailureFail:
+		return nil
+	}
+	return fmt.Errorf("unknown hook failure policy %q (want %s or %s)", policy, HookFailureIgnore, HookFailureFail)
+}
+
+// runHooks passes the entries through each hook in turn. Fields hooks never
+// see (source, creation time) are restored by ID; entries must keep unique,
+// non-empty IDs and names.
+func (a *Assembler) runHooks(ctx context.Context, entries []AppEntry) ([]AppEntry, error) {
+	for _, hook := range a.Hooks {
+		out, err := hook.Transform(ctx, entries)
+		if err == nil {
+			err = restoreHidden(entries, out)
+		}
+		if err != nil {
+			if a.HookFailurePolicy == HookFailureFail {
+				return nil, operrors.NewTransientError("entry hook "+hook.Name()+" failed", err)
+			}
+			a.Log.Info("Entry hook failed, ignoring its changes", "hook", hook.Name(), "error", err.Error())
+			continue
+		}
+		entries = out
+	}
+	return entries, nil
+}
+
+// restoreHidden copies the fields not serialized to hooks from the entries
+// given to a hook onto the ones it returned, validating the latter.
+func restoreHidden(in, out []AppEntry) error {
+	byID := make(map[string]*AppEntry, len(in))
+	for i := range in {
+		byID[in[i].ID] = &in[i]
+	}
+	seen := make(map[string]bool, len(out))
+	for i := range out {
+		e := &out[i]
+		if e.ID == "" || e.Name == "" {
+			return fmt.Errorf("entry %d has no id or name", i)
+		}
+		if seen[e.ID] {
+			return fmt.Errorf("duplicate entry id %q", e.ID)
+		}
+		seen[e.ID] = true
+		if orig, ok := byID[e.ID]; ok {
+			e.Source, e.CreatedAt = orig.Source, orig.CreatedAt
+		}
+	}
+	return nil
+}
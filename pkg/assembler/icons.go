@@ -0,0 +1,228 @@
+package assembler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/iconpolicy"
+)
+
+// IconResolver turns an icon URL into the SVG it serves. Resolve is called
+// concurrently, and should return once ctx ends with an error wrapping
+// ctx.Err() if the icon is not ready yet.
+type IconResolver interface {
+	Resolve(ctx context.Context, url string) (string, error)
+}
+
+const (
+	// iconFetchConcurrency bounds the icons fetched at once
+	iconFetchConcurrency = 8
+
+	// iconPendingRecheck is when the catalog is assembled again after an
+	// icon fetch outlasted IconFetchBudget, to publish it
+	iconPendingRecheck = 10 * time.Second
+)
+
+// resolvedIcon is the outcome of resolving an icon URL
+type resolvedIcon struct {
+	icon string
+	err  error
+}
+
+// IconForgetter is implemented by IconResolvers caching icons, so the icons
+// of apps leaving the catalog can be dropped
+type IconForgetter interface {
+	Forget(urls ...string)
+}
+
+// IconURL returns the URL the icon of app is fetched from: spec.iconURL, or
+// the URL of its icon library shorthand. It is empty for inline icons.
+func (a *Assembler) IconURL(app *dashboardv1alpha1.DashboardApp) string {
+	if app.Spec.Icon == "" {
+		return app.Spec.IconURL
+	}
+	u, _ := a.IconLibraries.URL(app.Spec.Icon)
+	return u
+}
+
+// resolveIcons resolves the icon URLs of the enabled apps concurrently,
+// waiting at most IconFetchBudget when it is set, and returns the outcomes
+// keyed by URL.
+func (a *Assembler) resolveIcons(ctx context.Context, apps []dashboardv1alpha1.DashboardApp) map[string]resolvedIcon {
+	if a.IconResolver == nil {
+		return nil
+	}
+	var urls []string
+	seen := make(map[string]bool)
+	for i := range apps {
+		if apps[i].Disabled() {
+			continue
+		}
+		if u := a.IconURL(&apps[i]); u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	if a.IconFetchBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.IconFetchBudget)
+		defer cancel()
+	}
+
+	results := make([]resolvedIcon, len(urls))
+	sem := make(chan struct{}, iconFetchConcurrency)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].icon, results[i].err = a.IconResolver.Resolve(ctx, u)
+		}()
+	}
+	wg.Wait()
+
+	out := make(map[string]resolvedIcon, len(urls))
+	for i, u := range urls {
+		out[u] = results[i]
+	}
+	return out
+}
+
+// appIcon returns the icon of an app: spec.icon if it is raw SVG, else the
+// SVG its icon library shorthand (see IconLibraries) or spec.iconURL
+// resolved to in icons. An icon that cannot be fetched is returned empty
+// with the error, rather than failing the whole assembly.
+func (a *Assembler) appIcon(app *dashboardv1alpha1.DashboardApp, icons map[string]resolvedIcon) (string, error) {
+	iconURL := a.IconURL(app)
+	if iconURL == "" && app.Spec.Icon != "" {
+		return app.Spec.Icon, nil
+	}
+	if iconURL == "" || a.IconResolver == nil {
+		return iconURL, nil
+	}
+	r := icons[iconURL]
+	return r.icon, r.err
+}
+
+// IconKey returns the content address an icon is served under. Any change to
+// the icon changes its key, so clients can cache icon URLs forever.
+func IconKey(icon string) string {
+	sum := sha256.Sum256([]byte(icon))
+	return hex.EncodeToString(sum[:16])
+}
+
+// externalizeIcons replaces inline icons in the entries and categories with
+// URLs under IconBaseURL and returns the icons keyed by IconKey. It is a no-op
+// returning nil when IconBaseURL is empty.
+func (a *Assembler) externalizeIcons(entries []AppEntry, categories []CategoryEntry) map[string]string {
+	if a.IconBaseURL == "" {
+		return nil
+	}
+	base := strings.TrimSuffix(a.IconBaseURL, "/") + "/"
+	icons := make(map[string]string)
+	ref := func(icon *string) {
+		if *icon == "" {
+			return
+		}
+		key := IconKey(*icon)
+		icons[key] = *icon
+		*icon = base + key
+	}
+	for i := range entries {
+		ref(&entries[i].Icon)
+	}
+	for i := range categories {
+		ref(&categories[i].Icon)
+	}
+	return icons
+}
+
+// IconRef locates an icon kept in a namespace ConfigMap, or in the
+// icons.json document of the output when it has no Namespace and ConfigMap
+type IconRef struct {
+	Namespace string `json:"namespace,omitempty"`
+	ConfigMap string `json:"configMap,omitempty"`
+	Key       string `json:"key"`
+}
+
+// IconRefKey returns the ConfigMap key an icon is stored under, derived from
+// its IconKey.
+func IconRefKey(icon string) string {
+	return IconKey(icon) + ".svg"
+}
+
+// localizeIcons moves the entry icons into IconConfigMap of each app's
+// namespace, replacing them with an IconRef, and returns the icons keyed by
+// namespace then key. Category icons stay inline. It is a no-op returning
+// nil when IconConfigMap is empty.
+func (a *Assembler) localizeIcons(entries []AppEntry) map[string]map[string]string {
+	if a.IconConfigMap == "" {
+		return nil
+	}
+	out := make(map[string]map[string]string)
+	for i := range entries {
+		e := &entries[i]
+		if e.Icon == "" {
+			continue
+		}
+		namespace, _, _ := strings.Cut(e.Source, "/")
+		if out[namespace] == nil {
+			out[namespace] = make(map[string]string)
+		}
+		key := IconRefKey(e.Icon)
+		out[namespace][key] = e.Icon
+		e.IconRef = &IconRef{Namespace: namespace, ConfigMap: a.IconConfigMap, Key: key}
+		e.Icon = ""
+	}
+	return out
+}
+
+// keyIcons moves the entry icons into the icons.json document of the
+// output, replacing them with an IconRef, and returns the icons keyed by
+// IconRefKey. Category icons stay inline. It is a no-op returning nil unless
+// IconsKey is set and IconConfigMap is not.
+func (a *Assembler) keyIcons(entries []AppEntry) map[string]string {
+	if !a.IconsKey || a.IconConfigMap != "" {
+		return nil
+	}
+	out := make(map[string]string)
+	for i := range entries {
+		e := &entries[i]
+		if e.Icon == "" {
+			continue
+		}
+		key := IconRefKey(e.Icon)
+		out[key] = e.Icon
+		e.IconRef = &IconRef{Key: key}
+		e.Icon = ""
+	}
+	return out
+}
+
+// enforceIconPolicy applies IconPolicy to the entry and category icons in
+// place, logging every violation. Rejected icons are left empty.
+func (a *Assembler) enforceIconPolicy(entries []AppEntry, categories []CategoryEntry) {
+	if a.IconPolicy.Mode == "" || a.IconPolicy.Mode == iconpolicy.ModeOff {
+		return
+	}
+	apply := func(kind, id string, icon *string) {
+		out, violations := a.IconPolicy.Apply(*icon)
+		for _, v := range violations {
+			a.Log.Info("Icon violates policy", kind, id, "mode", a.IconPolicy.Mode, "violation", v.String())
+		}
+		*icon = out
+	}
+	for i := range entries {
+		apply("app", entries[i].Source, &entries[i].Icon)
+	}
+	for i := range categories {
+		apply("category", categories[i].ID, &categories[i].Icon)
+	}
+}
@@ -0,0 +1,134 @@
+package assembler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"text/template"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// Per-app variables available to the ID template in addition to VarName and
+// VarNamespace
+const (
+	VarCategory = "category"
+	// VarHash is a short stable hash of the app's namespace/name
+	VarHash = "hash"
+)
+
+// IDCollision lists DashboardApps whose IDs came out the same. Only the
+// first source (in namespace/name order) is kept in the output.
+type IDCollision struct {
+	ID      string
+	Sources []string
+}
+
+// ParseIDTemplate parses an entry ID template such as
+// "{{ .namespace }}-{{ .name }}".
+func ParseIDTemplate(text string) (*template.Template, error) {
+	return template.New("id").Option("missingkey=error").Parse(text)
+}
+
+// entryIDs returns the ID of every app keyed by namespace/name: IDTemplate
+// rendered with the app's variables, or the app name if there is no
+// template. Apps whose ID fails to render are returned apart with their
+// error, so that only they are left out; an unparsable template fails for
+// every app.
+func (a *Assembler) entryIDs(apps []dashboardv1alpha1.DashboardApp) (map[string]string, map[string]error, error) {
+	ids := make(map[string]string, len(apps))
+	if a.IDTemplate == "" {
+		for i := range apps {
+			ids[apps[i].Namespace+"/"+apps[i].Name] = apps[i].Name
+		}
+		return ids, nil, nil
+	}
+
+	tmpl, err := ParseIDTemplate(a.IDTemplate)
+	if err != nil {
+		return nil, nil, operrors.NewConfigError("invalid ID template", err)
+	}
+	var failed map[string]error
+	for i := range apps {
+		app := &apps[i]
+		source := app.Namespace + "/" + app.Name
+		id, err := a.renderID(tmpl, app)
+		if err != nil {
+			if failed == nil {
+				failed = make(map[string]error)
+			}
+			failed[source] = err
+			continue
+		}
+		ids[source] = id
+	}
+	return ids, failed, nil
+}
+
+// idViolation returns the error rendering the ID of app, if any. An
+// unparsable template is not the app's fault and is reported by
+// config.Validate instead.
+func (a *Assembler) idViolation(app *dashboardv1alpha1.DashboardApp) error {
+	if a.IDTemplate == "" {
+		return nil
+	}
+	tmpl, err := ParseIDTemplate(a.IDTemplate)
+	if err != nil {
+		return nil
+	}
+	_, err = a.renderID(tmpl, app)
+	return err
+}
+
+// renderID renders the ID template for app.
+func (a *Assembler) renderID(tmpl *template.Template, app *dashboardv1alpha1.DashboardApp) (string, error) {
+	source := app.Namespace + "/" + app.Name
+	sum := sha256.Sum256([]byte(source))
+
+	data := a.templateData(app)
+	data[VarCategory] = app.Spec.Category
+	data[VarHash] = hex.EncodeToString(sum[:4])
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", operrors.NewPermanentError("failed to render ID template", err).
+			WithContext("app", source)
+	}
+	id := strings.TrimSpace(out.String())
+	if id == "" {
+		return "", operrors.NewPermanentError("ID template rendered an empty ID", nil).
+			WithContext("app", source)
+	}
+	return id, nil
+}
+
+// dropIDCollisions removes entries whose ID is already taken by an entry
+// with a lower source, returning the kept entries and the collisions.
+func dropIDCollisions(entries []AppEntry) ([]AppEntry, []IDCollision) {
+	bySource := slices.Clone(entries)
+	slices.SortFunc(bySource, func(a, b AppEntry) int { return strings.Compare(a.Source, b.Source) })
+
+	owner := make(map[string]string, len(entries))
+	var collisions []IDCollision
+	dropped := make(map[string]bool)
+	for _, e := range bySource {
+		first, taken := owner[e.ID]
+		if !taken {
+			owner[e.ID] = e.Source
+			continue
+		}
+		dropped[e.Source] = true
+		idx := slices.IndexFunc(collisions, func(c IDCollision) bool { return c.ID == e.ID })
+		if idx < 0 {
+			collisions = append(collisions, IDCollision{ID: e.ID, Sources: []string{first}})
+			idx = len(collisions) - 1
+		}
+		collisions[idx].Sources = append(collisions[idx].Sources, e.Source)
+	}
+	if len(dropped) == 0 {
+		return entries, nil
+	}
+	return slices.DeleteFunc(entries, func(e AppEntry) bool { return dropped[e.Source] }), collisions
+}
@@ -0,0 +1,93 @@
+package assembler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Duplicate display name policies
+const (
+	// DuplicateNamesOff ignores apps sharing a display name
+	DuplicateNamesOff = "off"
+	// DuplicateNamesFlag reports apps sharing a display name (see
+	// AssemblyResult.DuplicateNames) without changing the output
+	DuplicateNamesFlag = "flag"
+	// DuplicateNamesSuffix also suffixes their names with their namespace,
+	// e.g. "Grafana (monitoring)"
+	DuplicateNamesSuffix = "suffix"
+)
+
+// NameDuplicate lists DashboardApps whose entries have the same display
+// name (compared case-insensitively), in namespace/name order.
+type NameDuplicate struct {
+	Name    string
+	Sources []string
+}
+
+// ValidateDuplicateNamePolicy checks that a duplicate name policy is known.
+func ValidateDuplicateNamePolicy(policy string) error {
+	switch policy {
+	case "", DuplicateNamesOff, DuplicateNamesFlag, DuplicateNamesSuffix:
+		return nil
+	}
+	return fmt.Errorf("unknown duplicate name policy %q (want %s, %s or %s)",
+		policy, DuplicateNamesOff, DuplicateNamesFlag, DuplicateNamesSuffix)
+}
+
+// resolveDuplicateNames finds entries sharing a display name and, under
+// DuplicateNamesSuffix, disambiguates them with their namespace, or their ID
+// when several share a namespace too.
+func (a *Assembler) resolveDuplicateNames(entries []AppEntry) []NameDuplicate {
+	if a.DuplicateNamePolicy == "" || a.DuplicateNamePolicy == DuplicateNamesOff {
+		return nil
+	}
+
+	byName := make(map[string][]int)
+	for i := range entries {
+		key := strings.ToLower(strings.TrimSpace(entries[i].Name))
+		byName[key] = append(byName[key], i)
+	}
+
+	var duplicates []NameDuplicate
+	for _, idx := range byName {
+		if len(idx) < 2 {
+			continue
+		}
+		slices.SortFunc(idx, func(x, y int) int { return strings.Compare(entries[x].Source, entries[y].Source) })
+		d := NameDuplicate{Name: entries[idx[0]].Name}
+		namespaces := make(map[string]int, len(idx))
+		for _, i := range idx {
+			d.Sources = append(d.Sources, entries[i].Source)
+			namespaces[sourceNamespace(entries[i].Source)]++
+		}
+		if a.DuplicateNamePolicy == DuplicateNamesSuffix {
+			for _, i := range idx {
+				suffix := sourceNamespace(entries[i].Source)
+				if namespaces[suffix] > 1 {
+					suffix = entries[i].ID
+				}
+				entries[i].Name = fmt.Sprintf("%s (%s)", entries[i].Name, suffix)
+			}
+		}
+		duplicates = append(duplicates, d)
+	}
+	slices.SortFunc(duplicates, func(x, y NameDuplicate) int { return strings.Compare(x.Sources[0], y.Sources[0]) })
+	return duplicates
+}
+
+// DuplicateFor returns the duplicate group source (namespace/name) belongs
+// to, if any.
+func DuplicateFor(duplicates []NameDuplicate, source string) (NameDuplicate, bool) {
+	for _, d := range duplicates {
+		if slices.Contains(d.Sources, source) {
+			return d, true
+		}
+	}
+	return NameDuplicate{}, false
+}
+
+func sourceNamespace(source string) string {
+	ns, _, _ := strings.Cut(source, "/")
+	return ns
+}
@@ -0,0 +1,95 @@
+package assembler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Renderer formats an assembly for a dashboard other than duro. Its
+// documents are written next to duro's, which are always written.
+type Renderer interface {
+	// Render returns the documents of the format, keyed by ConfigMap key
+	Render(result *AssemblyResult) (map[string]string, error)
+}
+
+// RendererFunc adapts a function to the Renderer interface
+type RendererFunc func(result *AssemblyResult) (map[string]string, error)
+
+// Render calls f(result)
+func (f RendererFunc) Render(result *AssemblyResult) (map[string]string, error) {
+	return f(result)
+}
+
+// Built-in output formats
+const (
+	// FormatHomer writes Homer's config.yml
+	FormatHomer = "homer"
+	// FormatHomepage writes gethomepage's services.yaml and settings.yaml
+	FormatHomepage = "homepage"
+)
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		FormatHomer:    RendererFunc(renderHomer),
+		FormatHomepage: RendererFunc(renderHomepage),
+	}
+)
+
+// RegisterRenderer makes an output format selectable by name, replacing any
+// renderer already registered under it.
+func RegisterRenderer(name string, r Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[name] = r
+}
+
+// FormatNames returns the registered output formats in alphabetical order.
+func FormatNames() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateFormat checks name is a registered output format.
+func ValidateFormat(name string) error {
+	renderersMu.RLock()
+	_, ok := renderers[name]
+	renderersMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unsupported output format %q (want one of %s)", name, strings.Join(FormatNames(), ", "))
+	}
+	return nil
+}
+
+// Render runs the renderers of formats on result and returns their
+// documents. Two formats producing the same key is an error.
+func Render(result *AssemblyResult, formats []string) (map[string]string, error) {
+	docs := make(map[string]string)
+	for _, format := range formats {
+		renderersMu.RLock()
+		r, ok := renderers[format]
+		renderersMu.RUnlock()
+		if !ok {
+			return nil, ValidateFormat(format)
+		}
+		rendered, err := r.Render(result)
+		if err != nil {
+			return nil, fmt.Errorf("%s output: %w", format, err)
+		}
+		for key, doc := range rendered {
+			if _, ok := docs[key]; ok {
+				return nil, fmt.Errorf("%s output: key %s is already written by another format", format, key)
+			}
+			docs[key] = doc
+		}
+	}
+	return docs, nil
+}
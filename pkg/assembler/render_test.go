@@ -0,0 +1,114 @@
+package assembler
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestRenderHomer(t *testing.T) {
+	result := &AssemblyResult{
+		Entries: []AppEntry{
+			{ID: "plex", Name: "Plex", Category: "media", URL: "https://plex.lan", Icon: "<svg/>", Description: "Movies", Tags: []string{"video", "family"}},
+			{ID: "jellyfin", Name: "Jellyfin", Category: "media", URL: "https://jellyfin.lan", Icon: "https://icons.lan/jellyfin.svg", New: true},
+			{ID: "old", Name: "Old", Category: "media", URL: "https://old.lan", Removed: true},
+			{ID: "grafana", Name: "Grafana", Category: "admin", URL: "https://grafana.lan", Icon: "sh:grafana"},
+		},
+		Categories: []CategoryEntry{
+			{ID: "media", DisplayName: "Media"},
+			{ID: "ai", DisplayName: "AI"},
+			{ID: "admin", DisplayName: "Admin"},
+		},
+	}
+
+	docs, err := Render(result, []string{FormatHomer})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	var config homerConfig
+	if err := yaml.Unmarshal([]byte(docs[HomerConfigKey]), &config); err != nil {
+		t.Fatalf("config.yml is not valid YAML: %v\n%s", err, docs[HomerConfigKey])
+	}
+
+	if len(config.Services) != 2 || config.Services[0].Name != "Media" || config.Services[1].Name != "Admin" {
+		t.Fatalf("services = %+v, want Media then Admin", config.Services)
+	}
+	media := config.Services[0].Items
+	if len(media) != 2 {
+		t.Fatalf("media items = %+v, want the removed app left out", media)
+	}
+	if !strings.HasPrefix(media[0].Logo, "data:image/svg+xml;base64,") || media[0].Subtitle != "Movies" || media[0].Keywords != "video family" {
+		t.Errorf("plex = %+v", media[0])
+	}
+	if media[1].Logo != "https://icons.lan/jellyfin.svg" || media[1].Tag != "new" || media[1].Target != "_blank" {
+		t.Errorf("jellyfin = %+v", media[1])
+	}
+	if grafana := config.Services[1].Items[0]; grafana.Logo != "" || grafana.URL != "https://grafana.lan" {
+		t.Errorf("grafana = %+v", grafana)
+	}
+}
+
+func TestRenderHomepage(t *testing.T) {
+	result := &AssemblyResult{
+		Entries: []AppEntry{
+			{ID: "plex", Name: "Plex", Category: "media", URL: "https://plex.lan", InternalURL: "http://plex.media:32400", Icon: "<svg/>", Description: "Movies",
+				Extra: map[string]string{"homepage.icon": "plex.png", "homepage.widget.url": "http://plex.media:32400", "homepage.widget.type": "plex", "homepage.widget.fields": "[streams, movies]"}},
+			{ID: "old", Name: "Old", Category: "media", URL: "https://old.lan", Removed: true},
+			{ID: "grafana", Name: "Grafana", Category: "admin", URL: "https://grafana.lan", Icon: "https://icons.lan/grafana.svg"},
+		},
+		Categories: []CategoryEntry{
+			{ID: "media", DisplayName: "Media", Collapsed: true},
+			{ID: "ai", DisplayName: "AI"},
+			{ID: "admin", DisplayName: "Admin", Icon: "/icons/admin.svg"},
+		},
+	}
+
+	docs, err := Render(result, []string{FormatHomepage})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	wantServices := `- Media:
+  - Plex:
+      icon: plex.png
+      href: https://plex.lan
+      description: Movies
+      siteMonitor: http://plex.media:32400
+      widget:
+        type: plex
+        fields:
+        - streams
+        - movies
+        url: http://plex.media:32400
+- Admin:
+  - Grafana:
+      icon: https://icons.lan/grafana.svg
+      href: https://grafana.lan
+`
+	if docs[HomepageServicesKey] != wantServices {
+		t.Errorf("services.yaml =\n%s\nwant\n%s", docs[HomepageServicesKey], wantServices)
+	}
+	wantSettings := `layout:
+  Media:
+    initiallyCollapsed: true
+  Admin:
+    icon: /icons/admin.svg
+`
+	if docs[HomepageSettingsKey] != wantSettings {
+		t.Errorf("settings.yaml =\n%s\nwant\n%s", docs[HomepageSettingsKey], wantSettings)
+	}
+}
+
+func TestRender(t *testing.T) {
+	if docs, err := Render(&AssemblyResult{}, nil); err != nil || len(docs) != 0 {
+		t.Errorf("Render(no formats) = %v, %v", docs, err)
+	}
+	if _, err := Render(&AssemblyResult{}, []string{"heimdall"}); err == nil || !strings.Contains(err.Error(), "unsupported output format") {
+		t.Errorf("Render(heimdall) error = %v", err)
+	}
+
+	RegisterRenderer("homer-copy", RendererFunc(renderHomer))
+	if _, err := Render(&AssemblyResult{}, []string{FormatHomer, "homer-copy"}); err == nil || !strings.Contains(err.Error(), "already written") {
+		t.Errorf("Render(clashing formats) error = %v", err)
+	}
+}
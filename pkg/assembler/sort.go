@@ -0,0 +1,125 @@
+package assembler
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Built-in sort strategies
+const (
+	// SortCategory orders by priority, then name (default)
+	SortCategory = "category"
+	// SortAlphabetical orders by display name
+	SortAlphabetical = "alphabetical"
+	// SortMostUsed orders by usage count (highest first), then priority and name
+	SortMostUsed = "mostUsed"
+	// SortRecentlyAdded orders by creation time (newest first), then name
+	SortRecentlyAdded = "recentlyAdded"
+)
+
+// Comparator orders two entries of the same category. Entries are always
+// grouped by category first, and ties left by the comparator are broken by
+// source so the order never depends on listing order.
+type Comparator func(x, y *AppEntry) int
+
+var (
+	sortsMu sync.RWMutex
+	sorts   = map[string]Comparator{
+		SortCategory:      byPriority,
+		SortAlphabetical:  byName,
+		SortMostUsed:      byUsage,
+		SortRecentlyAdded: byCreation,
+	}
+)
+
+// RegisterSort makes a sort strategy selectable by name, replacing any
+// strategy already registered under it.
+func RegisterSort(name string, c Comparator) {
+	sortsMu.Lock()
+	defer sortsMu.Unlock()
+	sorts[name] = c
+}
+
+// SortNames returns the registered sort strategies in alphabetical order.
+func SortNames() []string {
+	sortsMu.RLock()
+	defer sortsMu.RUnlock()
+	names := make([]string, 0, len(sorts))
+	for name := range sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateSort checks name is a registered sort strategy (empty means the
+// default).
+func ValidateSort(name string) error {
+	if name == "" {
+		return nil
+	}
+	sortsMu.RLock()
+	_, ok := sorts[name]
+	sortsMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unsupported sort %q (want one of %s)", name, strings.Join(SortNames(), ", "))
+	}
+	return nil
+}
+
+func comparator(name string) Comparator {
+	sortsMu.RLock()
+	defer sortsMu.RUnlock()
+	if c, ok := sorts[name]; ok {
+		return c
+	}
+	return sorts[SortCategory]
+}
+
+// sortEntries orders entries in place: by category order, then by the
+// strategy selected in a.Sort, then by source. With a previous order, the
+// default strategy breaks priority ties by previous position.
+func (a *Assembler) sortEntries(entries []AppEntry) {
+	within := comparator(a.Sort)
+	if len(a.PreviousOrder) > 0 && (a.Sort == "" || a.Sort == SortCategory) {
+		within = byPreviousPosition(a.previousPositions())
+	}
+	slices.SortFunc(entries, func(x, y AppEntry) int {
+		if c := cmp.Compare(a.categoryRank(x.Category), a.categoryRank(y.Category)); c != 0 {
+			return c
+		}
+		if c := within(&x, &y); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Source, y.Source)
+	})
+}
+
+func byPriority(x, y *AppEntry) int {
+	if c := cmp.Compare(x.Priority, y.Priority); c != 0 {
+		return c
+	}
+	return byName(x, y)
+}
+
+func byName(x, y *AppEntry) int {
+	return cmp.Compare(x.Name, y.Name)
+}
+
+func byUsage(x, y *AppEntry) int {
+	if c := cmp.Compare(y.Usage, x.Usage); c != 0 {
+		return c
+	}
+	return byPriority(x, y)
+}
+
+func byCreation(x, y *AppEntry) int {
+	if c := y.CreatedAt.Compare(x.CreatedAt); c != 0 {
+		return c
+	}
+	return byName(x, y)
+}
@@ -0,0 +1,117 @@
+package assembler
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func sortTestEntries() []AppEntry {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []AppEntry{
+		{ID: "plex", Name: "Plex", Category: "media", Priority: 10, Usage: 5, CreatedAt: t0, Source: "media/plex"},
+		{ID: "jellyfin", Name: "Jellyfin", Category: "media", Priority: 20, Usage: 50, CreatedAt: t0.Add(2 * time.Hour), Source: "media/jellyfin"},
+		{ID: "audiobookshelf", Name: "Audiobookshelf", Category: "media", Priority: 30, CreatedAt: t0.Add(time.Hour), Source: "media/audiobookshelf"},
+		// same name and priority as a media app in another namespace: only the source tells them apart
+		{ID: "plex", Name: "Plex", Category: "media", Priority: 10, Usage: 5, CreatedAt: t0, Source: "kids/plex"},
+		{ID: "openwebui", Name: "OpenWebUI", Category: "ai", Priority: 10, Usage: 100, CreatedAt: t0.Add(3 * time.Hour), Source: "ai/openwebui"},
+	}
+}
+
+func TestSortStrategies(t *testing.T) {
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"kids/plex", "media/plex", "media/jellyfin", "media/audiobookshelf", "ai/openwebui"}},
+		{SortCategory, []string{"kids/plex", "media/plex", "media/jellyfin", "media/audiobookshelf", "ai/openwebui"}},
+		{SortAlphabetical, []string{"media/audiobookshelf", "media/jellyfin", "kids/plex", "media/plex", "ai/openwebui"}},
+		{SortMostUsed, []string{"media/jellyfin", "kids/plex", "media/plex", "media/audiobookshelf", "ai/openwebui"}},
+		{SortRecentlyAdded, []string{"media/jellyfin", "media/audiobookshelf", "kids/plex", "media/plex", "ai/openwebui"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			a := &Assembler{Sort: tt.sort}
+			// the result must not depend on the input order
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < 20; i++ {
+				entries := sortTestEntries()
+				rng.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+				a.sortEntries(entries)
+
+				var got []string
+				for _, e := range entries {
+					got = append(got, e.Source)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Fatalf("order = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterSort(t *testing.T) {
+	if err := ValidateSort("byID"); err == nil {
+		t.Fatalf("ValidateSort(byID) should fail before registration")
+	}
+	RegisterSort("byID", func(x, y *AppEntry) int {
+		switch {
+		case x.ID < y.ID:
+			return -1
+		case x.ID > y.ID:
+			return 1
+		}
+		return 0
+	})
+	if err := ValidateSort("byID"); err != nil {
+		t.Fatalf("ValidateSort(byID) after registration: %v", err)
+	}
+	if !slices.Contains(SortNames(), "byID") {
+		t.Errorf("SortNames() = %v, missing byID", SortNames())
+	}
+
+	entries := sortTestEntries()
+	(&Assembler{Sort: "byID"}).sortEntries(entries)
+	if entries[0].ID != "audiobookshelf" {
+		t.Errorf("first entry = %s, want audiobookshelf", entries[0].ID)
+	}
+}
+
+func TestSortPreviousOrder(t *testing.T) {
+	entries := []AppEntry{
+		{ID: "sonarr", Name: "Sonarr", Category: "media", Priority: 10, Source: "media/sonarr"},
+		{ID: "radarr", Name: "radarr", Category: "media", Priority: 10, Source: "media/radarr"},
+		{ID: "lidarr", Name: "Lidarr", Category: "media", Priority: 10, Source: "media/lidarr"},
+		{ID: "plex", Name: "Plex", Category: "media", Priority: 5, Source: "media/plex"},
+	}
+	previous := map[string][]string{"media": {"plex", "sonarr", "radarr"}}
+
+	tests := []struct {
+		name     string
+		assemble *Assembler
+		want     []string
+	}{
+		{"by name without a previous order", &Assembler{}, []string{"plex", "lidarr", "sonarr", "radarr"}},
+		{"ties keep their previous position, new entries last", (&Assembler{}).WithPreviousOrder(previous), []string{"plex", "sonarr", "radarr", "lidarr"}},
+		{"priority still wins", (&Assembler{}).WithPreviousOrder(map[string][]string{"media": {"sonarr", "plex"}}), []string{"plex", "sonarr", "lidarr", "radarr"}},
+		{"other strategies ignore it", (&Assembler{Sort: SortAlphabetical}).WithPreviousOrder(previous), []string{"lidarr", "plex", "sonarr", "radarr"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := slices.Clone(entries)
+			tt.assemble.sortEntries(sorted)
+			var got []string
+			for _, e := range sorted {
+				got = append(got, e.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+			if order := EntryOrder(sorted); !slices.Equal(order["media"], tt.want) {
+				t.Errorf("EntryOrder() = %v, want %v", order["media"], tt.want)
+			}
+		})
+	}
+}
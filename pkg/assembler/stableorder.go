@@ -0,0 +1,61 @@
+package assembler
+
+import (
+	"cmp"
+)
+
+// WithPreviousOrder returns a copy of the Assembler that breaks priority ties
+// by the position entries had in a previous output (see EntryOrder), so apps
+// of equal priority keep their place across restarts and renames. Entries
+// that were not listed before follow those that were, by name.
+func (a *Assembler) WithPreviousOrder(order map[string][]string) *Assembler {
+	c := *a
+	c.PreviousOrder = order
+	return &c
+}
+
+// EntryOrder returns the IDs of entries by category, in output order.
+func EntryOrder(entries []AppEntry) map[string][]string {
+	order := make(map[string][]string)
+	for _, e := range entries {
+		order[e.Category] = append(order[e.Category], e.ID)
+	}
+	return order
+}
+
+// previousPositions indexes a.PreviousOrder by category and entry ID.
+func (a *Assembler) previousPositions() map[string]map[string]int {
+	positions := make(map[string]map[string]int, len(a.PreviousOrder))
+	for category, ids := range a.PreviousOrder {
+		positions[category] = make(map[string]int, len(ids))
+		for i, id := range ids {
+			if _, ok := positions[category][id]; !ok {
+				positions[category][id] = i
+			}
+		}
+	}
+	return positions
+}
+
+// byPreviousPosition orders entries of equal priority by their previous
+// position, entries new to their category last, then by name.
+func byPreviousPosition(positions map[string]map[string]int) Comparator {
+	return func(x, y *AppEntry) int {
+		if c := cmp.Compare(x.Priority, y.Priority); c != 0 {
+			return c
+		}
+		px, okx := positions[x.Category][x.ID]
+		py, oky := positions[y.Category][y.ID]
+		switch {
+		case okx && oky:
+			if c := cmp.Compare(px, py); c != 0 {
+				return c
+			}
+		case okx:
+			return -1
+		case oky:
+			return 1
+		}
+		return byName(x, y)
+	}
+}
@@ -0,0 +1,84 @@
+package assembler
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// StrictFailure is a namespace left out of the output under Strict because
+// some of its apps have validation findings
+type StrictFailure struct {
+	Namespace string
+	// Findings maps the offending apps (namespace/name) to their findings
+	Findings map[string][]string
+}
+
+// Message summarizes the failure for the apps of the namespace.
+func (f StrictFailure) Message() string {
+	sources := slices.Sorted(maps.Keys(f.Findings))
+	parts := make([]string, 0, len(sources))
+	for _, s := range sources {
+		parts = append(parts, fmt.Sprintf("%s: %s", s, strings.Join(f.Findings[s], "; ")))
+	}
+	return fmt.Sprintf("namespace %s left out of the output in strict mode: %s", f.Namespace, strings.Join(parts, ", "))
+}
+
+// StrictFailureFor returns the failure of the namespace an app is in, if any.
+func StrictFailureFor(failures []StrictFailure, namespace string) (StrictFailure, bool) {
+	for _, f := range failures {
+		if f.Namespace == namespace {
+			return f, true
+		}
+	}
+	return StrictFailure{}, false
+}
+
+// enforceStrict collects the findings of every listed app (rule violations,
+// dangling categories, shared display names) and of the apps left out for
+// failing to render, and drops the entries of each namespace holding an
+// offending app.
+func enforceStrict(entries []AppEntry, violations map[string][]string, dangling map[string]string, duplicates []NameDuplicate, leftOut []string) ([]AppEntry, []StrictFailure) {
+	findings := make(map[string][]string)
+	for _, source := range leftOut {
+		findings[source] = slices.Clone(violations[source])
+	}
+	for _, e := range entries {
+		found := slices.Clone(violations[e.Source])
+		if category, ok := dangling[e.Source]; ok {
+			found = append(found, fmt.Sprintf("category %q does not exist", category))
+		}
+		if d, ok := DuplicateFor(duplicates, e.Source); ok {
+			found = append(found, fmt.Sprintf("display name %q is shared with %d other apps", d.Name, len(d.Sources)-1))
+		}
+		if len(found) > 0 {
+			findings[e.Source] = found
+		}
+	}
+	if len(findings) == 0 {
+		return entries, nil
+	}
+
+	byNamespace := make(map[string]map[string][]string)
+	for source, found := range findings {
+		ns := sourceNamespace(source)
+		if byNamespace[ns] == nil {
+			byNamespace[ns] = make(map[string][]string)
+		}
+		byNamespace[ns][source] = found
+	}
+	var failures []StrictFailure
+	for ns, f := range byNamespace {
+		failures = append(failures, StrictFailure{Namespace: ns, Findings: f})
+	}
+	slices.SortFunc(failures, func(x, y StrictFailure) int { return strings.Compare(x.Namespace, y.Namespace) })
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if _, failed := byNamespace[sourceNamespace(e.Source)]; !failed {
+			kept = append(kept, e)
+		}
+	}
+	return kept, failures
+}
@@ -0,0 +1,43 @@
+package assembler
+
+import (
+	"slices"
+	"strings"
+)
+
+// TagEntry is one tag of the tag index, for duro's tag filter
+type TagEntry struct {
+	Tag string `json:"tag"`
+
+	// Apps is how many published apps carry the tag
+	Apps int `json:"apps"`
+}
+
+// normalizeTags returns tags trimmed, deduplicated and sorted, or nil when
+// there are none.
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			out = append(out, tag)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// buildTagIndex lists the tags of the entries, sorted by name.
+func buildTagIndex(entries []AppEntry) []TagEntry {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		for _, tag := range e.Tags {
+			counts[tag]++
+		}
+	}
+	index := make([]TagEntry, 0, len(counts))
+	for tag, n := range counts {
+		index = append(index, TagEntry{Tag: tag, Apps: n})
+	}
+	slices.SortFunc(index, func(x, y TagEntry) int { return strings.Compare(x.Tag, y.Tag) })
+	return index
+}
@@ -0,0 +1,64 @@
+package assembler
+
+import (
+	"maps"
+	"strings"
+	"text/template"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// Per-app variables available to spec templates in addition to Assembler.Variables
+const (
+	VarName      = "name"
+	VarNamespace = "namespace"
+)
+
+// WithVariables returns a copy of the Assembler whose template variables are
+// extended (and overridden) by vars. The receiver is left untouched so a
+// shared Assembler can be used safely from concurrent reconciles.
+func (a *Assembler) WithVariables(vars map[string]string) *Assembler {
+	if len(vars) == 0 {
+		return a
+	}
+	merged := make(map[string]string, len(a.Variables)+len(vars))
+	maps.Copy(merged, a.Variables)
+	maps.Copy(merged, vars)
+	c := *a
+	c.Variables = merged
+	return &c
+}
+
+// templateData builds the variables a given app's templates are rendered
+// with. Per-app values always win over operator-level ones.
+func (a *Assembler) templateData(app *dashboardv1alpha1.DashboardApp) map[string]string {
+	data := make(map[string]string, len(a.Variables)+2)
+	maps.Copy(data, a.Variables)
+	data[VarName] = app.Name
+	data[VarNamespace] = app.Namespace
+	return data
+}
+
+// renderTemplate resolves a spec field such as
+// "https://{{ .name }}.{{ .clusterDomain }}". Values without template
+// delimiters are returned unchanged. Unknown variables are an error rather
+// than rendering as "<no value>".
+func (a *Assembler) renderTemplate(app *dashboardv1alpha1.DashboardApp, field, value string) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", operrors.NewPermanentError("invalid "+field+" template", err).
+			WithContext("app", app.Namespace+"/"+app.Name)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, a.templateData(app)); err != nil {
+		return "", operrors.NewPermanentError("failed to render "+field+" template", err).
+			WithContext("app", app.Namespace+"/"+app.Name)
+	}
+	return out.String(), nil
+}
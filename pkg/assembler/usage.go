@@ -0,0 +1,13 @@
+package assembler
+
+import (
+	"github.com/fredericrous/duro-operator/pkg/usage"
+)
+
+// WithUsage returns a copy of the Assembler that annotates entries with the
+// given usage counts, keyed by app ID.
+func (a *Assembler) WithUsage(counts usage.Counts) *Assembler {
+	c := *a
+	c.Usage = counts
+	return &c
+}
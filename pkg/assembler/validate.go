@@ -0,0 +1,51 @@
+package assembler
+
+import (
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/facts"
+	"github.com/fredericrous/duro-operator/pkg/groups"
+	"github.com/fredericrous/duro-operator/pkg/iconpolicy"
+)
+
+// Violations lists the rules an app breaks under the current configuration.
+// Rules are enforced when the catalog is assembled rather than at admission,
+// so apps created before a rule was tightened (e.g. a stricter icon policy)
+// only show up here.
+func (a *Assembler) Violations(app *dashboardv1alpha1.DashboardApp) []string {
+	var out []string
+	for _, g := range accessPatterns(app) {
+		if err := groups.ValidatePattern(g); err != nil {
+			out = append(out, err.Error())
+		}
+	}
+	if _, err := a.renderTemplate(app, "url", app.Spec.URL); err != nil {
+		out = append(out, err.Error())
+	}
+	if _, err := a.renderTemplate(app, "internalURL", app.Spec.InternalURL); err != nil {
+		out = append(out, err.Error())
+	}
+	if _, err := a.renderTemplate(app, "description", app.Spec.Description); err != nil {
+		out = append(out, err.Error())
+	}
+	if err := a.idViolation(app); err != nil {
+		out = append(out, err.Error())
+	}
+	out = append(out, a.actionViolations(app)...)
+	if _, err := extraFields(app.Spec.Extra); err != nil {
+		out = append(out, err.Error())
+	}
+	if app.Spec.Condition != "" {
+		if err := facts.Validate(app.Spec.Condition); err != nil {
+			out = append(out, "invalid condition: "+err.Error())
+		}
+	}
+	if _, _, err := hiddenGroups(app, a.Clock()); err != nil {
+		out = append(out, err.Error())
+	}
+	if a.IconPolicy.Mode != "" && a.IconPolicy.Mode != iconpolicy.ModeOff {
+		for _, v := range a.IconPolicy.Check(app.Spec.Icon) {
+			out = append(out, "icon "+v.String())
+		}
+	}
+	return out
+}
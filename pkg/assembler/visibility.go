@@ -0,0 +1,121 @@
+package assembler
+
+import (
+	"slices"
+	"time"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/groups"
+	"github.com/fredericrous/duro-operator/pkg/schedule"
+)
+
+// hiddenGroups evaluates the app's visibility schedule at now. It returns the
+// group patterns the app is currently hidden from and the next time that set
+// may change (zero if the app has no schedule).
+func hiddenGroups(app *dashboardv1alpha1.DashboardApp, now time.Time) ([]string, time.Time, error) {
+	var hidden []string
+	var next time.Time
+
+	for i, w := range app.Spec.VisibilitySchedule {
+		window, err := schedule.NewWindow(w.Schedule, w.Duration.Duration, w.TimeZone)
+		if err != nil {
+			return nil, time.Time{}, operrors.NewPermanentError("invalid visibility schedule", err).
+				WithContext("app", app.Namespace+"/"+app.Name).
+				WithContext("window", i)
+		}
+
+		if active, _ := window.Active(now); active {
+			for _, g := range w.Groups {
+				if !slices.Contains(hidden, g) {
+					hidden = append(hidden, g)
+				}
+			}
+		}
+		next = earliest(next, window.NextChange(now))
+	}
+	return hidden, next, nil
+}
+
+// withoutGroups drops the hidden patterns from an entry's groups. Only exact
+// matches are removed; broader patterns stay and the frontend is expected to
+// honour hiddenGroups for them.
+func withoutGroups(entryGroups, hidden []string) []string {
+	if len(hidden) == 0 {
+		return entryGroups
+	}
+	kept := make([]string, 0, len(entryGroups))
+	for _, g := range entryGroups {
+		if !slices.Contains(hidden, g) {
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
+
+// recentlyAdded reports whether the app is badged as new at now and, if so,
+// when the badge expires.
+func (a *Assembler) recentlyAdded(app *dashboardv1alpha1.DashboardApp, now time.Time) (bool, time.Time) {
+	if a.NewWindow <= 0 || app.CreationTimestamp.IsZero() {
+		return false, time.Time{}
+	}
+	until := app.CreationTimestamp.Add(a.NewWindow)
+	if !now.Before(until) {
+		return false, time.Time{}
+	}
+	return true, until
+}
+
+// earliest returns the earlier of two times, treating zero as "never".
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// entryAccess returns the access of the app's entry, whose anyOf groups are
+// anyOf, or nil when the app doesn't set spec.access.
+func entryAccess(app *dashboardv1alpha1.DashboardApp, anyOf []string) *EntryAccess {
+	if app.Spec.Access == nil {
+		return nil
+	}
+	return &EntryAccess{AnyOf: anyOf, AllOf: app.Spec.Access.AllOf, NoneOf: app.Spec.Access.NoneOf}
+}
+
+// validAnyOf returns the anyOf groups of the app without its malformed
+// patterns, which grant nothing and are reported by Violations. It fails
+// when no anyOf pattern is left, or when an allOf or noneOf pattern is
+// malformed: dropping that would widen who sees the app.
+func validAnyOf(app *dashboardv1alpha1.DashboardApp) ([]string, error) {
+	if access := app.Spec.Access; access != nil {
+		for _, g := range slices.Concat(access.AllOf, access.NoneOf) {
+			if err := groups.ValidatePattern(g); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var valid []string
+	var err error
+	for _, g := range app.AnyOfGroups() {
+		if e := groups.ValidatePattern(g); e != nil {
+			err = e
+			continue
+		}
+		valid = append(valid, g)
+	}
+	if len(valid) == 0 && err != nil {
+		return nil, err
+	}
+	return valid, nil
+}
+
+// accessPatterns lists every group pattern deciding who sees the app.
+func accessPatterns(app *dashboardv1alpha1.DashboardApp) []string {
+	patterns := app.AnyOfGroups()
+	if access := app.Spec.Access; access != nil {
+		patterns = append(slices.Clone(patterns), access.AllOf...)
+		patterns = append(patterns, access.NoneOf...)
+	}
+	return patterns
+}
@@ -0,0 +1,49 @@
+package catalog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+// Store holds the most recently assembled catalog so it can be served outside
+// the reconcile loop (preview and API endpoints) without re-listing CRs.
+type Store struct {
+	mu        sync.RWMutex
+	result    *assembler.AssemblyResult
+	updatedAt time.Time
+}
+
+// NewStore creates an empty Store
+func NewStore() *Store {
+	return &Store{}
+}
+
+// Set replaces the current catalog
+func (s *Store) Set(result *assembler.AssemblyResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.result = result
+	s.updatedAt = time.Now()
+}
+
+// Get returns the current catalog and when it was assembled. The result is
+// nil until the first successful reconcile and must not be modified.
+func (s *Store) Get() (*assembler.AssemblyResult, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.result, s.updatedAt
+}
+
+// Icon returns an externalized icon of the current catalog by key. Icons
+// dropped from the catalog stop being served as soon as it is replaced.
+func (s *Store) Icon(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.result == nil {
+		return "", false
+	}
+	icon, ok := s.result.Icons[key]
+	return icon, ok
+}
@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+func TestStore_SetGet(t *testing.T) {
+	s := NewStore()
+	if result, updatedAt := s.Get(); result != nil || !updatedAt.IsZero() {
+		t.Fatalf("empty store returned %v at %v", result, updatedAt)
+	}
+
+	want := &assembler.AssemblyResult{AppsJSON: "[]"}
+	s.Set(want)
+	got, updatedAt := s.Get()
+	if got != want {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+	if updatedAt.IsZero() {
+		t.Errorf("updatedAt should be set after Set")
+	}
+}
+
+func TestStore_Concurrent(t *testing.T) {
+	s := NewStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.Set(&assembler.AssemblyResult{})
+		}()
+		go func() {
+			defer wg.Done()
+			s.Get()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestStore_Icon(t *testing.T) {
+	s := NewStore()
+	if _, ok := s.Icon("abc"); ok {
+		t.Fatal("empty store returned an icon")
+	}
+
+	s.Set(&assembler.AssemblyResult{Icons: map[string]string{"abc": "<svg/>"}})
+	if icon, ok := s.Icon("abc"); !ok || icon != "<svg/>" {
+		t.Errorf("Icon(abc) = %q, %v", icon, ok)
+	}
+
+	s.Set(&assembler.AssemblyResult{Icons: map[string]string{"def": "<svg></svg>"}})
+	if _, ok := s.Icon("abc"); ok {
+		t.Error("icon dropped from the catalog is still served")
+	}
+}
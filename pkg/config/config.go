@@ -1,8 +1,33 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/netip"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/fredericrous/duro-operator/pkg/alerting"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+	"github.com/fredericrous/duro-operator/pkg/conformance"
+	"github.com/fredericrous/duro-operator/pkg/groups"
+	"github.com/fredericrous/duro-operator/pkg/hashing"
+	"github.com/fredericrous/duro-operator/pkg/healthcheck"
+	"github.com/fredericrous/duro-operator/pkg/history"
+	"github.com/fredericrous/duro-operator/pkg/iconfetch"
+	"github.com/fredericrous/duro-operator/pkg/iconlib"
+	"github.com/fredericrous/duro-operator/pkg/iconpolicy"
 )
 
 // OperatorConfig holds the operator configuration
@@ -16,37 +41,391 @@ type OperatorConfig struct {
 	// ApiAddr is the address for the REST API endpoint (set to "0" to disable)
 	ApiAddr string
 
+	// EnableWebhooks serves the DashboardApp defaulting webhook
+	EnableWebhooks bool
+
+	// SimulateAdmission also serves a DashboardApp validating webhook
+	// assembling the catalog with the incoming app, rejecting apps that
+	// would take another app's entry ID, overflow the output or get their
+	// namespace left out in strict mode; requires EnableWebhooks
+	SimulateAdmission bool
+
+	// WebhookPort is the port the webhook server listens on
+	WebhookPort int
+
+	// WebhookCertDir holds the webhook server's tls.crt and tls.key
+	WebhookCertDir string
+
+	// APIToken is the bearer token required by authenticated API endpoints
+	// (preview, registrations); those endpoints are disabled when empty
+	APIToken string
+
+	// DispatchSecret is the secret signing repository dispatch webhooks;
+	// the dispatch receiver is disabled when empty
+	DispatchSecret string
+
+	// RegistrationNamespace is where DashboardApps registered through the API
+	// or dispatched by repositories are created (defaults to DuroNamespace)
+	RegistrationNamespace string
+
+	// InstanceName distinguishes operator deployments sharing a cluster
+	// (e.g. prod and test); it suffixes the identity used for the
+	// OperatorOverview, field manager, leader election ID and output labels
+	InstanceName string
+
+	// AppSelector is a label selector restricting the DashboardApps this
+	// instance renders, so instances can partition the apps between them
+	AppSelector string
+
+	// WatchNamespaces, if set, lists the only namespaces DashboardApps are
+	// read from and discovered in; apps elsewhere are never seen
+	WatchNamespaces []string
+
+	// ExcludeNamespaces lists namespaces whose DashboardApps are never seen
+	// and where no app is discovered
+	ExcludeNamespaces []string
+
 	// EnableLeaderElection enables leader election
 	EnableLeaderElection bool
 
-	// LeaderElectionID is the ID for leader election
+	// LeaderElectionID is the ID for leader election (defaults to Identity)
 	LeaderElectionID string
 
+	// LeaderElectionResourceLock is the kind of object holding the leader
+	// election lock; only leases is supported by the client library, older
+	// lock types are rejected with a migration hint
+	LeaderElectionResourceLock string
+
+	// LeaderElectionNamespace is where the lock lives (defaults to the
+	// operator's namespace when running in-cluster)
+	LeaderElectionNamespace string
+
 	// MaxConcurrentReconciles is the maximum number of concurrent reconciles
 	MaxConcurrentReconciles int
 
+	// PriorityLanes queues reconciles asked for by users' changes to
+	// dashboard resources ahead of those asked for by background work
+	// (health probes, heartbeats, discovery)
+	PriorityLanes bool
+
 	// ReconcileTimeout is the timeout for reconcile operations
 	ReconcileTimeout time.Duration
 
+	// SlowReconcileThreshold is a soft deadline below ReconcileTimeout;
+	// reconciles taking longer raise a warning event (0 disables)
+	SlowReconcileThreshold time.Duration
+
 	// DuroNamespace is the namespace where duro is deployed
 	DuroNamespace string
 
 	// DuroConfigMapName is the name of the duro apps ConfigMap
 	DuroConfigMapName string
+
+	// OutputKind is the kind of object the output is written to, configmap
+	// or secret for deployments treating the app list as sensitive
+	OutputKind string
+
+	// OutputNameHash writes the output the way kustomize's configMapGenerator
+	// does: every version goes to a new immutable object named
+	// DuroConfigMapName suffixed with a hash of its content. The name of the
+	// current one is published on the OperatorOverview, and the versions
+	// before the previous one are deleted
+	OutputNameHash bool
+
+	// RestartDeployment, if set, is the duro Deployment (namespace/name, or
+	// a name in DuroNamespace) rolled out again whenever the output
+	// changes, for duro versions that don't reload it
+	RestartDeployment string
+
+	// OutputLabels and OutputAnnotations are stamped on the output ConfigMap
+	// (e.g. argocd.argoproj.io/compare-options, backup exclusions) and kept
+	// there across writes; keys dropped from the configuration are removed
+	OutputLabels      map[string]string
+	OutputAnnotations map[string]string
+
+	// ClusterDomain is exposed to spec.url templates as {{ .clusterDomain }}
+	ClusterDomain string
+
+	// ExternalSuffix is exposed to spec.url templates as {{ .externalSuffix }}
+	ExternalSuffix string
+
+	// SubstitutionsConfigMap is the name of a ConfigMap in DuroNamespace whose
+	// key/values are available to all DashboardApp templates (empty disables)
+	SubstitutionsConfigMap string
+
+	// PriorityAnalysis enables reporting of priority collisions within a
+	// category (PriorityCollision condition, metric, logs)
+	PriorityAnalysis bool
+
+	// RBACGroups gives apps without spec.groups the groups bound by
+	// RoleBindings in their namespace
+	RBACGroups bool
+
+	// CategoryLabel, if set, is a namespace label giving apps without
+	// spec.category their category, e.g. homelab.io/category
+	CategoryLabel string
+
+	// GroupOutputs lists groups for which a filtered apps-<group>.json key is
+	// written alongside apps.json
+	GroupOutputs []string
+
+	// OutputFormats lists dashboard formats written alongside duro's
+	// documents (e.g. homer for Homer's config.yml)
+	OutputFormats []string
+
+	// FormatVersions lists the format versions duro's documents are
+	// written in, side by side during duro upgrades (see
+	// assembler.LatestFormatVersion)
+	FormatVersions []int
+
+	// Checksums writes checksums.json, fingerprinting every entry and icon
+	// so duro can invalidate its caches per app
+	Checksums bool
+
+	// FallbackCategory, if set, lists apps whose category is neither a
+	// DashboardCategory nor built in under this category instead; the
+	// bucket is shown after all other categories
+	FallbackCategory string
+
+	// DuplicateNamePolicy is what happens to apps sharing a display name:
+	// off, flag (DuplicateName condition) or suffix (also suffix their names
+	// with their namespace in the output)
+	DuplicateNamePolicy string
+
+	// CatalogStatus publishes the written apps document, compressed, in the
+	// OperatorOverview status for clients reading the catalog through the API
+	CatalogStatus bool
+
+	// Dashboards also writes the apps selected by each Dashboard resource to
+	// its target ConfigMap, in any namespace
+	Dashboards bool
+
+	// ShardByCategory writes a category-<id>.json key per category alongside
+	// apps.json, so consumers showing one category can mount only its key
+	ShardByCategory bool
+
+	// HashAlgorithm is the change-detection hash recorded on the output
+	// (sha256 or xxhash)
+	HashAlgorithm string
+
+	// HashScope selects what the hash covers: the whole output document or
+	// only the app entries
+	HashScope string
+
+	// UsageConfigMap is a ConfigMap in DuroNamespace holding usage counts
+	// exported by duro (key usage.json); mutually exclusive with UsageURL
+	UsageConfigMap string
+
+	// UsageURL is an HTTP endpoint serving usage counts exported by duro
+	UsageURL string
+
+	// UsageRefreshInterval is how often usage counts are re-imported
+	UsageRefreshInterval time.Duration
+
+	// IDTemplate generates entry IDs from app variables (name, namespace,
+	// category, hash), e.g. "{{ .namespace }}-{{ .name }}"; empty uses the
+	// app name
+	IDTemplate string
+
+	// IconBaseURL, if set, is the URL the operator's /icons endpoint is
+	// reachable at by dashboard clients; icons are then referenced by URL in
+	// the output instead of inlined
+	IconBaseURL string
+
+	// IconConfigMap, if set, keeps app icons out of the output in a
+	// ConfigMap of this name in each app's namespace, owned by the apps and
+	// referenced from their entries
+	IconConfigMap string
+
+	// IconsKey keeps app icons out of apps.json in an icons.json key of the
+	// output, referenced from the entries, so a catalog of many inline SVGs
+	// fits the size of a ConfigMap in fewer, smaller documents
+	IconsKey bool
+
+	// IconPolicy is what happens to icons referencing external resources or
+	// embedding oversized raster data: off, rewrite (strip the references)
+	// or reject (drop the icon)
+	IconPolicy string
+
+	// IconMaxDataURIBytes bounds raster data URIs embedded in icons under
+	// IconPolicy
+	IconMaxDataURIBytes int
+
+	// IconURLPassthrough publishes spec.iconURL as the entry's icon instead
+	// of fetching and inlining the SVG
+	IconURLPassthrough bool
+
+	// IconURLMaxBytes bounds the size of icons fetched from spec.iconURL
+	IconURLMaxBytes int
+
+	// IconURLRefresh is how long a fetched icon is used before it is
+	// fetched again
+	IconURLRefresh time.Duration
+
+	// IconURLFetchBudget bounds how long an assembly waits on icon fetches;
+	// icons still being fetched are published by the next assembly
+	IconURLFetchBudget time.Duration
+
+	// IconURLAllowedNetworks lists the loopback, private and link-local
+	// networks (CIDRs) icons may be fetched from; such addresses are
+	// refused otherwise
+	IconURLAllowedNetworks []string
+
+	// IconLibraries overrides or adds icon library URL templates by
+	// shorthand prefix (see iconlib.Defaults), e.g. to use a local mirror
+	IconLibraries map[string]string
+
+	// Strict leaves every app of a namespace out of the output when one of
+	// them has a validation finding (rule violation, dangling category,
+	// shared display name), for teams wanting guarantees over best-effort
+	// rendering
+	Strict bool
+
+	// EntryHooks lists absolute paths of executables run in turn on the
+	// assembled entries: each reads the entries as JSON on stdin and writes
+	// the entries to publish on stdout
+	EntryHooks []string
+
+	// HookTimeout bounds a single entry hook run
+	HookTimeout time.Duration
+
+	// HookFailurePolicy is what a failing entry hook does: ignore (publish
+	// the entries it was given) or fail (keep the previous output)
+	HookFailurePolicy string
+
+	// AlertRules maintains a PrometheusRule per DashboardApp alerting when
+	// it is reported down, if the PrometheusRule CRD exists
+	AlertRules bool
+
+	// AlertFor is how long an app must be down before its alert fires
+	AlertFor time.Duration
+
+	// HelmDiscovery synthesizes DashboardApps for Helm releases whose chart
+	// opts in through dashboard.homelab.io/* annotations or values
+	HelmDiscovery bool
+
+	// WorkloadDiscovery synthesizes DashboardApps for Deployments,
+	// StatefulSets and DaemonSets labelled or annotated duro.enable=true
+	WorkloadDiscovery bool
+
+	// IngressDiscovery synthesizes DashboardApps for Ingresses annotated
+	// dashboard.homelab.io/enabled=true
+	IngressDiscovery bool
+
+	// ConformanceURL, if set, is a duro endpoint serving the full apps
+	// document (e.g. http://duro.duro.svc/api/apps) polled after each write
+	// to confirm duro serves what was written
+	ConformanceURL string
+
+	// ConformanceInterval is how often ConformanceURL is polled
+	ConformanceInterval time.Duration
+
+	// ReplicaSkewCheckInterval is how often replicas compare their version
+	// and config with the leader's (0 disables)
+	ReplicaSkewCheckInterval time.Duration
+
+	// DeadLinkSweepInterval is how often every app URL of the written
+	// catalog is probed for dead links, reported in the OperatorOverview
+	// (0 disables)
+	DeadLinkSweepInterval time.Duration
+
+	// DeadLinkTimeout bounds each dead-link probe
+	DeadLinkTimeout time.Duration
+
+	// DeadLinkEvents emits one Event on the OperatorOverview summarizing
+	// the dead links found by each sweep
+	DeadLinkEvents bool
+
+	// HealthProbes probes the apps setting spec.healthCheck over HTTP and
+	// records the outcome in their status.health
+	HealthProbes bool
+
+	// HealthProbeConcurrency bounds the health probes in flight
+	HealthProbeConcurrency int
+
+	// FactsConfigMap is a ConfigMap in DuroNamespace whose key/values are
+	// exposed to spec.condition as feature flags (empty disables)
+	FactsConfigMap string
+
+	// FactsRefreshInterval is how often cluster facts are re-gathered while
+	// some app sets spec.condition
+	FactsRefreshInterval time.Duration
+
+	// Sort names the strategy ordering apps within a category (category,
+	// alphabetical, mostUsed, recentlyAdded)
+	Sort string
+
+	// StableOrder keeps apps of equal priority in the order they were last
+	// written, recorded on the output ConfigMap, instead of reordering them
+	// by name (category sort only)
+	StableOrder bool
+
+	// NewBadgeWindow badges apps created less than this long ago as new in
+	// the output (0 disables the badge)
+	NewBadgeWindow time.Duration
+
+	// HealthDamping is how many consecutive health check results must report
+	// a new health state before it shows in the output, so a momentarily
+	// failing app doesn't blink on the dashboard (0 or 1 publishes every
+	// change)
+	HealthDamping int
+
+	// MinWriteInterval is the minimum time between two writes to the same
+	// output target; changes arriving sooner are batched into one delayed
+	// write (0 disables the limit)
+	MinWriteInterval time.Duration
+
+	// AggregateDebounce is how long app changes are left to settle before
+	// the catalog is assembled again, so a burst of them is assembled once
+	// (0 assembles after every change)
+	AggregateDebounce time.Duration
+
+	// RemovalGracePeriod keeps a deleted app in the output, marked removed,
+	// for this long after its deletion (0 removes it right away)
+	RemovalGracePeriod time.Duration
+
+	// ReconcileHistory is how many recent reconcile outcomes are kept for
+	// the API server's /debug/reconciles endpoint (0 disables)
+	ReconcileHistory int
 }
 
 // NewDefaultConfig creates a default configuration
 func NewDefaultConfig() *OperatorConfig {
 	return &OperatorConfig{
-		MetricsAddr:             ":8080",
-		ProbeAddr:               ":8081",
-		ApiAddr:                 ":9090",
-		EnableLeaderElection:    false,
-		LeaderElectionID:        "duro-operator",
-		MaxConcurrentReconciles: 3,
-		ReconcileTimeout:        5 * time.Minute,
-		DuroNamespace:           "duro",
-		DuroConfigMapName:       "duro-apps",
+		MetricsAddr:                ":8080",
+		ProbeAddr:                  ":8081",
+		ApiAddr:                    ":9090",
+		WebhookPort:                9443,
+		EnableLeaderElection:       false,
+		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
+		MaxConcurrentReconciles:    3,
+		ReconcileTimeout:           5 * time.Minute,
+		DuroNamespace:              "duro",
+		DuroConfigMapName:          "duro-apps",
+		OutputKind:                 OutputKindConfigMap,
+		FormatVersions:             []int{assembler.FormatVersionLegacy},
+		ClusterDomain:              "cluster.local",
+		HashAlgorithm:              hashing.SHA256,
+		HashScope:                  hashing.ScopeDocument,
+		UsageRefreshInterval:       10 * time.Minute,
+		FactsRefreshInterval:       5 * time.Minute,
+		ConformanceInterval:        conformance.DefaultInterval,
+		ReplicaSkewCheckInterval:   5 * time.Minute,
+		DeadLinkTimeout:            10 * time.Second,
+		HealthProbeConcurrency:     healthcheck.DefaultConcurrency,
+		Sort:                       assembler.SortCategory,
+		IconPolicy:                 string(iconpolicy.ModeOff),
+		IconMaxDataURIBytes:        iconpolicy.DefaultMaxDataURIBytes,
+		IconURLMaxBytes:            iconfetch.DefaultMaxBytes,
+		IconURLRefresh:             iconfetch.DefaultRefresh,
+		IconURLFetchBudget:         iconfetch.DefaultFetchBudget,
+		ReconcileHistory:           history.DefaultSize,
+		FallbackCategory:           assembler.DefaultFallbackCategory,
+		DuplicateNamePolicy:        assembler.DuplicateNamesFlag,
+		AlertFor:                   alerting.DefaultFor,
+		HookTimeout:                5 * time.Second,
+		HookFailurePolicy:          assembler.HookFailureIgnore,
+		AggregateDebounce:          time.Second,
 	}
 }
 
@@ -58,8 +437,420 @@ func (c *OperatorConfig) Validate() error {
 	if c.ReconcileTimeout < time.Second {
 		return fmt.Errorf("reconcileTimeout must be at least 1 second")
 	}
+	if c.SlowReconcileThreshold < 0 || c.SlowReconcileThreshold >= c.ReconcileTimeout {
+		return fmt.Errorf("slowReconcileThreshold must be between 0 and reconcileTimeout (%s)", c.ReconcileTimeout)
+	}
+	if c.EnableWebhooks && (c.WebhookPort < 1 || c.WebhookPort > 65535) {
+		return fmt.Errorf("webhookPort must be between 1 and 65535")
+	}
+	if c.SimulateAdmission && !c.EnableWebhooks {
+		return fmt.Errorf("simulateAdmission requires enableWebhooks")
+	}
+	switch c.LeaderElectionResourceLock {
+	case "", resourcelock.LeasesResourceLock:
+	case "configmapsleases", "endpointsleases", "configmaps", "endpoints":
+		return fmt.Errorf("leaderElectionResourceLock %q is no longer supported, migrate to %q", c.LeaderElectionResourceLock, resourcelock.LeasesResourceLock)
+	default:
+		return fmt.Errorf("unknown leaderElectionResourceLock %q", c.LeaderElectionResourceLock)
+	}
+	if errs := validation.IsDNS1123Label(c.LeaderElectionNamespace); c.LeaderElectionNamespace != "" && len(errs) > 0 {
+		return fmt.Errorf("leaderElectionNamespace %q: %s", c.LeaderElectionNamespace, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsDNS1123Label(c.InstanceName); c.InstanceName != "" && len(errs) > 0 {
+		return fmt.Errorf("instanceName %q: %s", c.InstanceName, strings.Join(errs, "; "))
+	}
+	if _, err := labels.Parse(c.AppSelector); err != nil {
+		return fmt.Errorf("appSelector: %w", err)
+	}
+	for _, namespace := range slices.Concat(c.WatchNamespaces, c.ExcludeNamespaces) {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("namespace %q: %s", namespace, strings.Join(errs, "; "))
+		}
+	}
+	for _, namespace := range c.ExcludeNamespaces {
+		if slices.Contains(c.WatchNamespaces, namespace) {
+			return fmt.Errorf("excludeNamespaces: %s is also in watchNamespaces", namespace)
+		}
+	}
+	if (c.APIToken != "" || c.DispatchSecret != "") && !c.WatchesNamespace(c.RegistrationNamespaceOrDefault()) {
+		return fmt.Errorf("registrationNamespace %s is not watched, registered apps would be ignored", c.RegistrationNamespaceOrDefault())
+	}
 	if c.DuroNamespace == "" {
 		return fmt.Errorf("duroNamespace is required")
 	}
+	if c.NewBadgeWindow < 0 {
+		return fmt.Errorf("newBadgeWindow must not be negative")
+	}
+	if c.HealthDamping < 0 {
+		return fmt.Errorf("healthDamping must not be negative")
+	}
+	if c.MinWriteInterval < 0 {
+		return fmt.Errorf("minWriteInterval must not be negative")
+	}
+	if c.AggregateDebounce < 0 {
+		return fmt.Errorf("aggregateDebounce must not be negative")
+	}
+	if c.RemovalGracePeriod < 0 {
+		return fmt.Errorf("removalGracePeriod must not be negative")
+	}
+	if c.ReconcileHistory < 0 {
+		return fmt.Errorf("reconcileHistory must not be negative")
+	}
+	if c.UsageConfigMap != "" && c.UsageURL != "" {
+		return fmt.Errorf("usageConfigMap and usageURL are mutually exclusive")
+	}
+	if (c.UsageConfigMap != "" || c.UsageURL != "") && c.UsageRefreshInterval < time.Second {
+		return fmt.Errorf("usageRefreshInterval must be at least 1 second")
+	}
+	if c.FactsRefreshInterval < time.Second {
+		return fmt.Errorf("factsRefreshInterval must be at least 1 second")
+	}
+	if c.ConformanceURL != "" {
+		if u, err := url.Parse(c.ConformanceURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("conformanceURL must be an absolute http(s) URL")
+		}
+		if c.ConformanceInterval < time.Second {
+			return fmt.Errorf("conformanceInterval must be at least 1 second")
+		}
+	}
+	if c.AlertRules && c.AlertFor < time.Second {
+		return fmt.Errorf("alertFor must be at least 1 second")
+	}
+	if c.ReplicaSkewCheckInterval < 0 {
+		return fmt.Errorf("replicaSkewCheckInterval must not be negative")
+	}
+	if c.DeadLinkSweepInterval < 0 {
+		return fmt.Errorf("deadLinkSweepInterval must not be negative")
+	}
+	if c.HealthProbes && c.HealthProbeConcurrency < 1 {
+		return fmt.Errorf("healthProbeConcurrency must be at least 1")
+	}
+	if c.DeadLinkSweepInterval > 0 && c.DeadLinkTimeout < time.Second {
+		return fmt.Errorf("deadLinkTimeout must be at least 1 second")
+	}
+	if c.IconBaseURL != "" && (c.ApiAddr == "" || c.ApiAddr == "0") {
+		return fmt.Errorf("iconBaseURL requires the API server (apiAddr) to serve icons")
+	}
+	if c.OutputKind == OutputKindSecret && c.APIToken == "" && c.ApiAddr != "" && c.ApiAddr != "0" {
+		return fmt.Errorf("outputKind secret requires an API token (apiToken) for the API server to serve the catalog, or the API server (apiAddr) disabled")
+	}
+	if errs := validation.IsQualifiedName(c.CategoryLabel); c.CategoryLabel != "" && len(errs) > 0 {
+		return fmt.Errorf("categoryLabel %q: %s", c.CategoryLabel, strings.Join(errs, "; "))
+	}
+	if c.OutputNameHash && len(c.DuroConfigMapName) > validation.DNS1123SubdomainMaxLength-11 {
+		return fmt.Errorf("duroConfigMapName %q is too long to be suffixed with a hash (outputNameHash)", c.DuroConfigMapName)
+	}
+	if c.RestartDeployment != "" {
+		key := c.RestartDeploymentKey()
+		if errs := validation.IsDNS1123Label(key.Namespace); len(errs) > 0 {
+			return fmt.Errorf("restartDeployment namespace %q: %s", key.Namespace, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsDNS1123Subdomain(key.Name); len(errs) > 0 {
+			return fmt.Errorf("restartDeployment name %q: %s", key.Name, strings.Join(errs, "; "))
+		}
+	}
+	if c.IconConfigMap != "" {
+		if errs := validation.IsDNS1123Subdomain(c.IconConfigMap); len(errs) > 0 {
+			return fmt.Errorf("iconConfigMap %q: %s", c.IconConfigMap, strings.Join(errs, "; "))
+		}
+		if c.IconBaseURL != "" {
+			return fmt.Errorf("iconConfigMap and iconBaseURL are mutually exclusive")
+		}
+		if c.IconsKey {
+			return fmt.Errorf("iconConfigMap and iconsKey are mutually exclusive")
+		}
+	}
+	if err := (iconpolicy.Policy{Mode: iconpolicy.Mode(c.IconPolicy), MaxDataURIBytes: c.IconMaxDataURIBytes}).Validate(); err != nil {
+		return fmt.Errorf("iconPolicy: %w", err)
+	}
+	if !c.IconURLPassthrough && c.IconURLMaxBytes < 1 {
+		return fmt.Errorf("iconURLMaxBytes must be at least 1")
+	}
+	if !c.IconURLPassthrough && c.IconURLRefresh < time.Second {
+		return fmt.Errorf("iconURLRefresh must be at least 1 second")
+	}
+	if !c.IconURLPassthrough && c.IconURLFetchBudget < 0 {
+		return fmt.Errorf("iconURLFetchBudget must not be negative")
+	}
+	if _, err := c.IconURLNetworks(); err != nil {
+		return fmt.Errorf("iconURLAllowedNetworks: %w", err)
+	}
+	if err := iconlib.Defaults.With(c.IconLibraries).Validate(); err != nil {
+		return fmt.Errorf("iconLibraries: %w", err)
+	}
+	if c.IDTemplate != "" {
+		if _, err := assembler.ParseIDTemplate(c.IDTemplate); err != nil {
+			return fmt.Errorf("idTemplate: %w", err)
+		}
+	}
+	for _, h := range c.EntryHooks {
+		if !filepath.IsAbs(h) {
+			return fmt.Errorf("entryHooks must be absolute paths, got %q", h)
+		}
+	}
+	if len(c.EntryHooks) > 0 && c.HookTimeout < time.Second {
+		return fmt.Errorf("hookTimeout must be at least 1 second")
+	}
+	if err := assembler.ValidateDuplicateNamePolicy(c.DuplicateNamePolicy); err != nil {
+		return fmt.Errorf("duplicateNamePolicy: %w", err)
+	}
+	if err := assembler.ValidateHookFailurePolicy(c.HookFailurePolicy); err != nil {
+		return fmt.Errorf("hookFailurePolicy: %w", err)
+	}
+	if err := assembler.ValidateSort(c.Sort); err != nil {
+		return fmt.Errorf("sort: %w", err)
+	}
+	if err := ValidateOutputKind(c.OutputKind); err != nil {
+		return fmt.Errorf("outputKind: %w", err)
+	}
+	for _, format := range c.OutputFormats {
+		if err := assembler.ValidateFormat(format); err != nil {
+			return fmt.Errorf("outputFormats: %w", err)
+		}
+	}
+	if len(c.FormatVersions) == 0 {
+		return fmt.Errorf("formatVersions must list at least one version")
+	}
+	for i, version := range c.FormatVersions {
+		if err := assembler.ValidateFormatVersion(version); err != nil {
+			return fmt.Errorf("formatVersions: %w", err)
+		}
+		if slices.Contains(c.FormatVersions[:i], version) {
+			return fmt.Errorf("formatVersions: version %d is listed twice", version)
+		}
+	}
+	if err := hashing.ValidateAlgorithm(c.HashAlgorithm); err != nil {
+		return fmt.Errorf("hashAlgorithm: %w", err)
+	}
+	if err := hashing.ValidateScope(c.HashScope); err != nil {
+		return fmt.Errorf("hashScope: %w", err)
+	}
+	for key, value := range c.OutputLabels {
+		if err := validateOutputKey(key); err != nil {
+			return fmt.Errorf("outputLabels: %w", err)
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("outputLabels: value of %s: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	for key := range c.OutputAnnotations {
+		if err := validateOutputKey(key); err != nil {
+			return fmt.Errorf("outputAnnotations: %w", err)
+		}
+	}
+	groupKeys := make(map[string]string, len(c.GroupOutputs))
+	for _, g := range c.GroupOutputs {
+		if g == "" || strings.Contains(g, groups.Wildcard) {
+			return fmt.Errorf("groupOutputs must list concrete group names, got %q", g)
+		}
+		if err := groups.ValidatePattern(g); err != nil {
+			return fmt.Errorf("groupOutputs: %w", err)
+		}
+		// Groups name ConfigMap keys, with hierarchy separators as "_"
+		key := strings.ReplaceAll(g, groups.Separator, "_")
+		if errs := validation.IsConfigMapKey("apps-" + key + ".json"); len(errs) > 0 {
+			return fmt.Errorf("groupOutputs: group %q can't name a ConfigMap key: %s", g, strings.Join(errs, "; "))
+		}
+		if other, ok := groupKeys[key]; ok {
+			return fmt.Errorf("groupOutputs: groups %q and %q would be written to the same key", other, g)
+		}
+		groupKeys[key] = g
+	}
+	return c.validateTimings()
+}
+
+// validateTimings cross-checks settings that only make sense relative to
+// each other, once each has been validated on its own.
+func (c *OperatorConfig) validateTimings() error {
+	if len(c.EntryHooks) > 0 && c.HookTimeout >= c.ReconcileTimeout {
+		return fmt.Errorf("hookTimeout (%s) must be shorter than reconcileTimeout (%s), or a hanging hook fails the whole reconcile; lower hookTimeout or raise reconcileTimeout",
+			c.HookTimeout, c.ReconcileTimeout)
+	}
+	if c.HealthDamping > 1 {
+		if !c.HealthProbes {
+			return fmt.Errorf("healthDamping (%d) counts the results of the operator's health probes, which are off; enable healthProbes or set healthDamping to 0",
+				c.HealthDamping)
+		}
+		if window := time.Duration(c.HealthDamping) * healthcheck.DefaultInterval; c.ConformanceURL != "" && window < c.ConformanceInterval {
+			return fmt.Errorf("healthDamping (%d) times the default probe interval (%s) must be at least conformanceInterval (%s), or damped health changes are written faster than duro is polled and conformance never settles; raise healthDamping or lower conformanceInterval",
+				c.HealthDamping, healthcheck.DefaultInterval, c.ConformanceInterval)
+		}
+	}
+	if c.MinWriteInterval > 0 && c.MinWriteInterval <= c.AggregateDebounce {
+		return fmt.Errorf("minWriteInterval (%s) must be longer than aggregateDebounce (%s), or it never holds a write back; raise minWriteInterval or set it to 0",
+			c.MinWriteInterval, c.AggregateDebounce)
+	}
+	if c.MinWriteInterval > DefaultRetryDelay {
+		return fmt.Errorf("minWriteInterval (%s) must not be longer than the retry delay of failed writes (%s), or a change held back waits longer than a failed write does; lower minWriteInterval",
+			c.MinWriteInterval, DefaultRetryDelay)
+	}
+	usageImported := c.UsageConfigMap != "" || c.UsageURL != ""
+	if usageImported && c.MinWriteInterval >= c.UsageRefreshInterval {
+		return fmt.Errorf("minWriteInterval (%s) must be shorter than usageRefreshInterval (%s), or every usage refresh is deferred to a later write; lower minWriteInterval or raise usageRefreshInterval",
+			c.MinWriteInterval, c.UsageRefreshInterval)
+	}
 	return nil
 }
+
+// validateOutputKey checks an output label or annotation key, refusing the
+// keys the operator maintains itself.
+func validateOutputKey(key string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("key %q: %s", key, strings.Join(errs, "; "))
+	}
+	prefix, _, _ := strings.Cut(key, "/")
+	if prefix == "dashboard.homelab.io" || key == "app.kubernetes.io/managed-by" {
+		return fmt.Errorf("key %q is reserved for the operator", key)
+	}
+	return nil
+}
+
+// ParseKeyValues parses a flag value of the form "k1=v1,k2=v2" into a map.
+// Values may be empty ("k=") but every item needs an "=".
+func ParseKeyValues(s string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid item %q, want key=value", item)
+		}
+		out[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return out, nil
+}
+
+// ParseFormatVersions parses a flag value of the form "1,2" into format
+// versions.
+func ParseFormatVersions(s string) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(item, "v"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid format version %q", item)
+		}
+		out = append(out, version)
+	}
+	return out, nil
+}
+
+// Output kinds
+const (
+	OutputKindConfigMap = "configmap"
+	OutputKindSecret    = "secret"
+)
+
+// ValidateOutputKind returns an error unless kind is a known output kind.
+func ValidateOutputKind(kind string) error {
+	if kind != OutputKindConfigMap && kind != OutputKindSecret {
+		return fmt.Errorf("unsupported output kind %q, want %s or %s", kind, OutputKindConfigMap, OutputKindSecret)
+	}
+	return nil
+}
+
+// WritesSecrets reports whether outputs may be written to Secrets: the
+// main output, or any Dashboard, which chooses its own kind.
+func (c *OperatorConfig) WritesSecrets() bool {
+	return c.OutputKind == OutputKindSecret || c.Dashboards
+}
+
+// DefaultRetryDelay is how long the operator waits before retrying a failed
+// write that carries no retry hint
+const DefaultRetryDelay = 30 * time.Second
+
+// DefaultIdentity identifies the operator when no instance name is set
+const DefaultIdentity = "duro-operator"
+
+// Identity names this operator instance: "duro-operator", suffixed with the
+// instance name if set.
+func (c *OperatorConfig) Identity() string {
+	if c.InstanceName != "" {
+		return DefaultIdentity + "-" + c.InstanceName
+	}
+	return DefaultIdentity
+}
+
+// LeaderElectionIDOrDefault returns the leader election ID, which defaults
+// to the instance identity so instances do not compete for one lock.
+func (c *OperatorConfig) LeaderElectionIDOrDefault() string {
+	if c.LeaderElectionID != "" {
+		return c.LeaderElectionID
+	}
+	return c.Identity()
+}
+
+// Selector returns the parsed AppSelector (everything if empty). The
+// selector must have passed Validate.
+func (c *OperatorConfig) Selector() labels.Selector {
+	sel, err := labels.Parse(c.AppSelector)
+	if err != nil {
+		return labels.Nothing()
+	}
+	return sel
+}
+
+// WatchesNamespace reports whether DashboardApps in namespace are seen,
+// according to WatchNamespaces and ExcludeNamespaces.
+func (c *OperatorConfig) WatchesNamespace(namespace string) bool {
+	if len(c.WatchNamespaces) > 0 && !slices.Contains(c.WatchNamespaces, namespace) {
+		return false
+	}
+	return !slices.Contains(c.ExcludeNamespaces, namespace)
+}
+
+// RegistrationNamespaceOrDefault returns the namespace external registrations
+// are written to.
+func (c *OperatorConfig) RegistrationNamespaceOrDefault() string {
+	if c.RegistrationNamespace != "" {
+		return c.RegistrationNamespace
+	}
+	return c.DuroNamespace
+}
+
+// RestartDeploymentKey returns the Deployment RestartDeployment names.
+func (c *OperatorConfig) RestartDeploymentKey() types.NamespacedName {
+	namespace, name, found := strings.Cut(c.RestartDeployment, "/")
+	if !found {
+		return types.NamespacedName{Namespace: c.DuroNamespace, Name: c.RestartDeployment}
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}
+}
+
+// IconURLNetworks parses IconURLAllowedNetworks.
+func (c *OperatorConfig) IconURLNetworks() ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, cidr := range c.IconURLAllowedNetworks {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, prefix.Masked())
+	}
+	return out, nil
+}
+
+// TemplateVariables returns the operator-level variables available to
+// DashboardApp templates.
+func (c *OperatorConfig) TemplateVariables() map[string]string {
+	return map[string]string{
+		"clusterDomain":  c.ClusterDomain,
+		"externalSuffix": c.ExternalSuffix,
+	}
+}
+
+// Fingerprint hashes the configuration so replicas can tell whether they
+// run with the same settings. Secrets are left out.
+func (c *OperatorConfig) Fingerprint() string {
+	redacted := *c
+	redacted.APIToken = ""
+	b, _ := json.Marshal(redacted)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"maps"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -28,7 +30,94 @@ func TestValidate(t *testing.T) {
 		{"valid default", func(*OperatorConfig) {}, ""},
 		{"reconciles<1", func(c *OperatorConfig) { c.MaxConcurrentReconciles = 0 }, "maxConcurrentReconciles"},
 		{"timeout<1s", func(c *OperatorConfig) { c.ReconcileTimeout = 500 * time.Millisecond }, "reconcileTimeout"},
+		{"hook timeout past reconcile timeout", func(c *OperatorConfig) {
+			c.EntryHooks, c.HookTimeout = []string{"/hooks/tag"}, c.ReconcileTimeout
+		}, "hookTimeout"},
+		{"write interval past usage refresh", func(c *OperatorConfig) {
+			c.UsageURL, c.UsageRefreshInterval, c.MinWriteInterval = "http://duro/usage", 20*time.Second, 20*time.Second
+		}, "usageRefreshInterval"},
+		{"write interval within the debounce", func(c *OperatorConfig) { c.MinWriteInterval = c.AggregateDebounce }, "aggregateDebounce"},
+		{"write interval past the retry delay", func(c *OperatorConfig) { c.MinWriteInterval = time.Minute }, "retry delay"},
+		{"write interval", func(c *OperatorConfig) { c.MinWriteInterval = 10 * time.Second }, ""},
+		{"health damping without probes", func(c *OperatorConfig) { c.HealthDamping = 3 }, "healthProbes"},
+		{"health damping shorter than conformance polls", func(c *OperatorConfig) {
+			c.HealthProbes, c.HealthDamping, c.ConformanceURL, c.ConformanceInterval = true, 2, "http://duro/api/apps", 5*time.Minute
+		}, "conformanceInterval"},
+		{"health damping", func(c *OperatorConfig) {
+			c.HealthProbes, c.HealthDamping, c.ConformanceURL = true, 2, "http://duro/api/apps"
+		}, ""},
+		{"negative slow reconcile threshold", func(c *OperatorConfig) { c.SlowReconcileThreshold = -time.Second }, "slowReconcileThreshold"},
+		{"slow reconcile threshold past timeout", func(c *OperatorConfig) { c.SlowReconcileThreshold = c.ReconcileTimeout }, "slowReconcileThreshold"},
+		{"webhook port out of range", func(c *OperatorConfig) { c.EnableWebhooks, c.WebhookPort = true, 0 }, "webhookPort"},
+		{"admission simulation without webhooks", func(c *OperatorConfig) { c.SimulateAdmission = true }, "simulateAdmission"},
+		{"admission simulation", func(c *OperatorConfig) { c.EnableWebhooks, c.SimulateAdmission = true, true }, ""},
+		{"invalid category label", func(c *OperatorConfig) { c.CategoryLabel = "not a label" }, "categoryLabel"},
 		{"empty namespace", func(c *OperatorConfig) { c.DuroNamespace = "" }, "duroNamespace"},
+		{"invalid watch namespace", func(c *OperatorConfig) { c.WatchNamespaces = []string{"Media"} }, "namespace"},
+		{"namespace both watched and excluded", func(c *OperatorConfig) {
+			c.WatchNamespaces, c.ExcludeNamespaces = []string{"media", "games"}, []string{"games"}
+		}, "excludeNamespaces"},
+		{"excluded namespaces", func(c *OperatorConfig) { c.ExcludeNamespaces = []string{"sandbox"} }, ""},
+		{"registrations in an unwatched namespace", func(c *OperatorConfig) {
+			c.APIToken, c.WatchNamespaces = "set", []string{"media"}
+		}, "registrationNamespace"},
+		{"invalid output label key", func(c *OperatorConfig) { c.OutputLabels = map[string]string{"not a key": "x"} }, "outputLabels"},
+		{"invalid output label value", func(c *OperatorConfig) { c.OutputLabels = map[string]string{"team": "a b"} }, "outputLabels"},
+		{"reserved output annotation", func(c *OperatorConfig) {
+			c.OutputAnnotations = map[string]string{"dashboard.homelab.io/config-hash": "x"}
+		}, "outputAnnotations"},
+		{"wildcard group output", func(c *OperatorConfig) { c.GroupOutputs = []string{"media/*"} }, "groupOutputs"},
+		{"group output with a space", func(c *OperatorConfig) { c.GroupOutputs = []string{"media kids"} }, "can't name a ConfigMap key"},
+		{"group output with an empty level", func(c *OperatorConfig) { c.GroupOutputs = []string{"media//kids"} }, "empty hierarchy level"},
+		{"group outputs sharing a key", func(c *OperatorConfig) { c.GroupOutputs = []string{"media/kids", "media_kids"} }, "same key"},
+		{"negative new badge window", func(c *OperatorConfig) { c.NewBadgeWindow = -time.Hour }, "newBadgeWindow"},
+		{"negative health damping", func(c *OperatorConfig) { c.HealthDamping = -1 }, "healthDamping"},
+		{"negative write interval", func(c *OperatorConfig) { c.MinWriteInterval = -time.Second }, "minWriteInterval"},
+		{"no format version", func(c *OperatorConfig) { c.FormatVersions = nil }, "formatVersions"},
+		{"unknown format version", func(c *OperatorConfig) { c.FormatVersions = []int{1, 9} }, "formatVersions"},
+		{"format version twice", func(c *OperatorConfig) { c.FormatVersions = []int{2, 2} }, "formatVersions"},
+		{"hashed output name too long", func(c *OperatorConfig) { c.OutputNameHash = true; c.DuroConfigMapName = strings.Repeat("a", 250) }, "outputNameHash"},
+		{"restart deployment without name", func(c *OperatorConfig) { c.RestartDeployment = "duro/" }, "restartDeployment"},
+		{"invalid restart deployment namespace", func(c *OperatorConfig) { c.RestartDeployment = "Duro/duro" }, "restartDeployment"},
+		{"negative aggregate debounce", func(c *OperatorConfig) { c.AggregateDebounce = -time.Second }, "aggregateDebounce"},
+		{"negative removal grace period", func(c *OperatorConfig) { c.RemovalGracePeriod = -time.Minute }, "removalGracePeriod"},
+		{"negative reconcile history", func(c *OperatorConfig) { c.ReconcileHistory = -1 }, "reconcileHistory"},
+		{"two usage sources", func(c *OperatorConfig) { c.UsageConfigMap, c.UsageURL = "duro-usage", "http://duro/usage" }, "mutually exclusive"},
+		{"facts refresh<1s", func(c *OperatorConfig) { c.FactsRefreshInterval = 0 }, "factsRefreshInterval"},
+		{"relative conformance URL", func(c *OperatorConfig) { c.ConformanceURL = "/api/apps" }, "conformanceURL"},
+		{"conformance interval<1s", func(c *OperatorConfig) { c.ConformanceURL, c.ConformanceInterval = "http://duro/api/apps", 0 }, "conformanceInterval"},
+		{"alert for<1s", func(c *OperatorConfig) { c.AlertRules, c.AlertFor = true, 0 }, "alertFor"},
+		{"negative replica skew interval", func(c *OperatorConfig) { c.ReplicaSkewCheckInterval = -time.Minute }, "replicaSkewCheckInterval"},
+		{"dead-link timeout too short", func(c *OperatorConfig) {
+			c.DeadLinkSweepInterval = 168 * time.Hour
+			c.DeadLinkTimeout = 0
+		}, "deadLinkTimeout"},
+		{"icons without API server", func(c *OperatorConfig) { c.IconBaseURL, c.ApiAddr = "https://duro/icons", "0" }, "iconBaseURL"},
+		{"secret output served without a token", func(c *OperatorConfig) { c.OutputKind = OutputKindSecret }, "apiToken"},
+		{"invalid icon ConfigMap name", func(c *OperatorConfig) { c.IconConfigMap = "Duro_Icons" }, "iconConfigMap"},
+		{"icon ConfigMap with icons key", func(c *OperatorConfig) { c.IconConfigMap, c.IconsKey = "duro-icons", true }, "mutually exclusive"},
+		{"icon ConfigMap with icon base URL", func(c *OperatorConfig) { c.IconConfigMap, c.IconBaseURL = "duro-icons", "https://duro/icons" }, "mutually exclusive"},
+		{"icon URL max bytes<1", func(c *OperatorConfig) { c.IconURLMaxBytes = 0 }, "iconURLMaxBytes"},
+		{"icon URL refresh<1s", func(c *OperatorConfig) { c.IconURLRefresh = 0 }, "iconURLRefresh"},
+		{"icon URL allowed network not a CIDR", func(c *OperatorConfig) { c.IconURLAllowedNetworks = []string{"10.0.0.1"} }, "iconURLAllowedNetworks"},
+		{"icon library without name", func(c *OperatorConfig) { c.IconLibraries = map[string]string{"sh": "https://mirror/sh.svg"} }, "iconLibraries"},
+		{"unknown icon policy", func(c *OperatorConfig) { c.IconPolicy = "strict" }, "iconPolicy"},
+		{"unknown duplicate name policy", func(c *OperatorConfig) { c.DuplicateNamePolicy = "rename" }, "duplicateNamePolicy"},
+		{"relative entry hook", func(c *OperatorConfig) { c.EntryHooks = []string{"hooks/rename"} }, "entryHooks"},
+		{"hook timeout<1s", func(c *OperatorConfig) { c.EntryHooks, c.HookTimeout = []string{"/hooks/rename"}, 0 }, "hookTimeout"},
+		{"unknown hook failure policy", func(c *OperatorConfig) { c.HookFailurePolicy = "retry" }, "hookFailurePolicy"},
+		{"removed lock type", func(c *OperatorConfig) { c.LeaderElectionResourceLock = "configmapsleases" }, "migrate to \"leases\""},
+		{"unknown lock type", func(c *OperatorConfig) { c.LeaderElectionResourceLock = "secrets" }, "leaderElectionResourceLock"},
+		{"invalid instance name", func(c *OperatorConfig) { c.InstanceName = "Prod_1" }, "instanceName"},
+		{"invalid app selector", func(c *OperatorConfig) { c.AppSelector = "env in (prod" }, "appSelector"},
+		{"invalid election namespace", func(c *OperatorConfig) { c.LeaderElectionNamespace = "Kube_System" }, "leaderElectionNamespace"},
+		{"invalid ID template", func(c *OperatorConfig) { c.IDTemplate = "{{ .name" }, "idTemplate"},
+		{"unknown sort", func(c *OperatorConfig) { c.Sort = "random" }, "sort"},
+		{"no health probe workers", func(c *OperatorConfig) { c.HealthProbes = true; c.HealthProbeConcurrency = 0 }, "healthProbeConcurrency"},
+		{"unknown output kind", func(c *OperatorConfig) { c.OutputKind = "file" }, "outputKind"},
+		{"unknown output format", func(c *OperatorConfig) { c.OutputFormats = []string{"homer", "heimdall"} }, "outputFormats"},
+		{"unknown hash algorithm", func(c *OperatorConfig) { c.HashAlgorithm = "md5" }, "hashAlgorithm"},
+		{"unknown hash scope", func(c *OperatorConfig) { c.HashScope = "keys" }, "hashScope"},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -47,3 +136,83 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestFingerprint(t *testing.T) {
+	a, b := NewDefaultConfig(), NewDefaultConfig()
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Fatal("identical configs should share a fingerprint")
+	}
+	b.APIToken = "s3cr3t"
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("the API token should not be part of the fingerprint")
+	}
+	b.Sort = "alphabetical"
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Error("a changed setting should change the fingerprint")
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	c := NewDefaultConfig()
+	if c.Identity() != "duro-operator" || c.LeaderElectionIDOrDefault() != "duro-operator" {
+		t.Errorf("default identity = %q, leader election ID = %q", c.Identity(), c.LeaderElectionIDOrDefault())
+	}
+	c.InstanceName = "test"
+	if c.Identity() != "duro-operator-test" || c.LeaderElectionIDOrDefault() != "duro-operator-test" {
+		t.Errorf("instance identity = %q, leader election ID = %q", c.Identity(), c.LeaderElectionIDOrDefault())
+	}
+	c.LeaderElectionID = "custom"
+	if c.LeaderElectionIDOrDefault() != "custom" {
+		t.Errorf("explicit leader election ID = %q", c.LeaderElectionIDOrDefault())
+	}
+}
+
+func TestWatchesNamespace(t *testing.T) {
+	c := NewDefaultConfig()
+	if !c.WatchesNamespace("media") {
+		t.Error("every namespace should be watched by default")
+	}
+	c.ExcludeNamespaces = []string{"sandbox"}
+	if c.WatchesNamespace("sandbox") || !c.WatchesNamespace("media") {
+		t.Error("only excluded namespaces should be left out")
+	}
+	c.WatchNamespaces = []string{"media"}
+	if !c.WatchesNamespace("media") || c.WatchesNamespace("games") {
+		t.Error("only watched namespaces should be seen")
+	}
+}
+
+func TestParseFormatVersions(t *testing.T) {
+	got, err := ParseFormatVersions(" 1, v2 ,")
+	if err != nil || !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("ParseFormatVersions() = %v, %v, want [1 2]", got, err)
+	}
+	if _, err := ParseFormatVersions("latest"); err == nil {
+		t.Error("ParseFormatVersions(latest) succeeded, want an error")
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"", map[string]string{}, false},
+		{"team=media", map[string]string{"team": "media"}, false},
+		{" a=1 , b= ,", map[string]string{"a": "1", "b": ""}, false},
+		{"argocd.argoproj.io/compare-options=IgnoreExtraneous", map[string]string{"argocd.argoproj.io/compare-options": "IgnoreExtraneous"}, false},
+		{"novalue", nil, true},
+		{"=x", nil, true},
+	}
+	for _, tc := range tests {
+		got, err := ParseKeyValues(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseKeyValues(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !maps.Equal(got, tc.want) {
+			t.Errorf("ParseKeyValues(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Package conformance checks that duro actually serves what the operator
+// wrote: after each write it polls duro's /api/apps until the served
+// document matches, reporting the drift between "written" and "served".
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// DefaultInterval is how often the served document is polled
+const DefaultInterval = 30 * time.Second
+
+// Fingerprint hashes a JSON document independently of its formatting, so
+// a document re-encoded by duro still matches the one written.
+func Fingerprint(data []byte) (string, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return "", fmt.Errorf("invalid JSON document: %w", err)
+	}
+	canonical, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Result is the outcome of one check
+type Result struct {
+	// Written is the fingerprint of the last written document
+	Written string
+	// WrittenAt is when that document was first written
+	WrittenAt time.Time
+	// Served is the fingerprint of the document duro served; empty if the
+	// check failed
+	Served string
+	// CheckedAt is when the check ran
+	CheckedAt time.Time
+	// SyncedAt is when duro was first seen serving the written document;
+	// zero until then
+	SyncedAt time.Time
+	// Err is why the served document could not be fetched or decoded
+	Err error
+}
+
+// InSync reports whether duro serves the written document.
+func (r Result) InSync() bool {
+	return r.Err == nil && r.Served == r.Written
+}
+
+// Lag is how long the written document took to be served, or has not been
+// served for so far.
+func (r Result) Lag() time.Duration {
+	if !r.SyncedAt.IsZero() && r.InSync() {
+		return r.SyncedAt.Sub(r.WrittenAt)
+	}
+	return r.CheckedAt.Sub(r.WrittenAt)
+}
+
+// Verifier polls duro until it serves the last written document. It is a
+// manager.Runnable and only runs on the leader, which is the only writer.
+type Verifier struct {
+	// URL serves the full apps document, e.g. http://duro.duro.svc/api/apps
+	URL    string
+	Client *http.Client
+	// Interval is how often the served document is polled
+	Interval time.Duration
+	// Report receives the result of every check
+	Report func(ctx context.Context, res Result)
+
+	mu        sync.Mutex
+	written   string
+	writtenAt time.Time
+	syncedAt  time.Time
+	kick      chan struct{}
+
+	// retryAt is when duro may be polled again after a Retry-After,
+	// lastErr the error that carried it
+	retryAt time.Time
+	lastErr error
+}
+
+// NewVerifier returns a Verifier with a bounded request timeout.
+func NewVerifier(url string, interval time.Duration, report func(ctx context.Context, res Result)) *Verifier {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Verifier{
+		URL:      url,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+		Interval: interval,
+		Report:   report,
+		kick:     make(chan struct{}, 1),
+	}
+}
+
+// Expect records that the document with fingerprint sum was written at
+// now. Re-expecting the current document keeps its original write time.
+func (v *Verifier) Expect(sum string, now time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if sum == v.written {
+		return
+	}
+	v.written, v.writtenAt, v.syncedAt = sum, now, time.Time{}
+	select {
+	case v.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Start polls until ctx is done. Once the served document matches, polling
+// continues at Interval so later drift (e.g. duro restored from an old
+// volume) is noticed too.
+func (v *Verifier) Start(ctx context.Context) error {
+	ticker := time.NewTicker(v.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-v.kick:
+		}
+		if res, ok := v.Check(ctx, time.Now()); ok && v.Report != nil {
+			v.Report(ctx, res)
+		}
+	}
+}
+
+// Check fetches the served document and compares it with the last written
+// one. It returns false if nothing was written yet.
+func (v *Verifier) Check(ctx context.Context, now time.Time) (Result, bool) {
+	v.mu.Lock()
+	res := Result{Written: v.written, WrittenAt: v.writtenAt, CheckedAt: now}
+	v.mu.Unlock()
+	if res.Written == "" {
+		return res, false
+	}
+
+	v.mu.Lock()
+	backingOff, lastErr := now.Before(v.retryAt), v.lastErr
+	v.mu.Unlock()
+	if backingOff {
+		// duro asked to be left alone for a while
+		res.Err = lastErr
+	} else {
+		res.Served, res.Err = v.fetch(ctx)
+	}
+
+	v.mu.Lock()
+	if after, ok := operrors.RetryAfter(res.Err); ok && !backingOff {
+		v.retryAt, v.lastErr = now.Add(after), res.Err
+	}
+	if res.InSync() && res.Written == v.written {
+		if v.syncedAt.IsZero() {
+			v.syncedAt = now
+		}
+		res.SyncedAt = v.syncedAt
+	}
+	v.mu.Unlock()
+	return res, true
+}
+
+func (v *Verifier) fetch(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", operrors.WithRetryAfter(resp, fmt.Errorf("duro returned %s", resp.Status))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return "", err
+	}
+	return Fingerprint(data)
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (v *Verifier) NeedLeaderElection() bool {
+	return true
+}
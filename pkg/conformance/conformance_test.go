@@ -0,0 +1,113 @@
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+func TestFingerprint(t *testing.T) {
+	a, err := Fingerprint([]byte(`{"apps":[{"id":"plex","priority":10}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Fingerprint([]byte("{\n  \"apps\": [ { \"priority\": 10, \"id\": \"plex\" } ]\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("reformatted document fingerprints differ: %s != %s", a, b)
+	}
+	c, _ := Fingerprint([]byte(`{"apps":[{"id":"plex","priority":11}]}`))
+	if a == c {
+		t.Error("different documents share a fingerprint")
+	}
+	if _, err := Fingerprint([]byte("<html>")); err == nil {
+		t.Error("expected an error for a non-JSON document")
+	}
+}
+
+func TestVerifier_Check(t *testing.T) {
+	served := `{"apps":[]}`
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(served))
+	}))
+	defer srv.Close()
+
+	v := NewVerifier(srv.URL, time.Minute, nil)
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	if _, ok := v.Check(ctx, t0); ok {
+		t.Fatal("Check() before any write should report nothing")
+	}
+
+	written, _ := Fingerprint([]byte(`{"apps":[{"id":"plex"}]}`))
+	v.Expect(written, t0)
+
+	res, _ := v.Check(ctx, t0.Add(10*time.Second))
+	if res.InSync() || res.Lag() != 10*time.Second {
+		t.Errorf("stale document: InSync() = %v, Lag() = %v", res.InSync(), res.Lag())
+	}
+
+	status = http.StatusBadGateway
+	res, _ = v.Check(ctx, t0.Add(15*time.Second))
+	if res.Err == nil || res.InSync() {
+		t.Errorf("failed fetch: Err = %v, InSync() = %v", res.Err, res.InSync())
+	}
+
+	status, served = http.StatusOK, `{ "apps": [ { "id": "plex" } ] }`
+	res, _ = v.Check(ctx, t0.Add(20*time.Second))
+	if !res.InSync() || res.Lag() != 20*time.Second {
+		t.Errorf("served document: InSync() = %v, Lag() = %v", res.InSync(), res.Lag())
+	}
+
+	// Lag stays at the time it took to converge
+	res, _ = v.Check(ctx, t0.Add(time.Hour))
+	if !res.InSync() || res.Lag() != 20*time.Second {
+		t.Errorf("later check: InSync() = %v, Lag() = %v", res.InSync(), res.Lag())
+	}
+
+	// Re-expecting the same document keeps its write time
+	v.Expect(written, t0.Add(2*time.Hour))
+	res, _ = v.Check(ctx, t0.Add(2*time.Hour))
+	if res.WrittenAt != t0 {
+		t.Errorf("WrittenAt = %v, want %v", res.WrittenAt, t0)
+	}
+}
+
+func TestVerifier_RetryAfter(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	v := NewVerifier(srv.URL, time.Second, nil)
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	written, _ := Fingerprint([]byte(`{"apps":[]}`))
+	v.Expect(written, t0)
+
+	ctx := context.Background()
+	for _, at := range []time.Duration{0, 30 * time.Second} {
+		res, _ := v.Check(ctx, t0.Add(at))
+		if after, ok := operrors.RetryAfter(res.Err); !ok || after != time.Minute {
+			t.Fatalf("Check() at %s: Err = %v, want a one minute retry-after", at, res.Err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("duro polled %d times within its retry-after, want 1", calls)
+	}
+	v.Check(ctx, t0.Add(time.Minute))
+	if calls != 2 {
+		t.Errorf("duro polled %d times once the retry-after ran out, want 2", calls)
+	}
+}
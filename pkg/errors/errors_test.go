@@ -2,7 +2,10 @@ package errors
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestOperatorError_Error(t *testing.T) {
@@ -76,3 +79,78 @@ func TestShouldRetry(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryAfter(t *testing.T) {
+	cause := errors.New("429 Too Many Requests")
+	wrapped := fmt.Errorf("write failed: %w", NewRetryAfterError(42*time.Second, cause))
+
+	after, ok := RetryAfter(wrapped)
+	if !ok || after != 42*time.Second {
+		t.Errorf("RetryAfter() = %v, %v; want 42s, true", after, ok)
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Error("RetryAfterError does not unwrap to its cause")
+	}
+	if !ShouldRetry(NewRetryAfterError(time.Second, cause)) {
+		t.Error("RetryAfterError should be retried")
+	}
+	if _, ok := RetryAfter(cause); ok {
+		t.Error("plain error carries no hint")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Tue, 10 Mar 2026 12:00:30 GMT", 30 * time.Second, true},
+		{"Tue, 10 Mar 2026 11:00:00 GMT", 0, true},
+		{"-5", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			got, ok := ParseRetryAfter(tc.value, now)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("ParseRetryAfter(%q) = %v, %v; want %v, %v", tc.value, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	cause := errors.New("server returned 429 Too Many Requests")
+	tests := []struct {
+		name   string
+		status int
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"too many requests", http.StatusTooManyRequests, "30", 30 * time.Second, true},
+		{"unavailable", http.StatusServiceUnavailable, "120", 2 * time.Minute, true},
+		{"no header", http.StatusTooManyRequests, "", 0, false},
+		{"other status", http.StatusInternalServerError, "30", 0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+			if tc.header != "" {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+			err := WithRetryAfter(resp, cause)
+			if !errors.Is(err, cause) {
+				t.Errorf("WithRetryAfter() = %v, want it to wrap the cause", err)
+			}
+			if after, ok := RetryAfter(err); after != tc.want || ok != tc.wantOK {
+				t.Errorf("RetryAfter() = %v, %v; want %v, %v", after, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
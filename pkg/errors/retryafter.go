@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryAfterError is a transient error carrying how long the failing target
+// asked to be left alone (e.g. an HTTP 429 Retry-After)
+type RetryAfterError struct {
+	After time.Duration
+	Cause error
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Cause, e.After)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Cause
+}
+
+// NewRetryAfterError creates a retry-after error
+func NewRetryAfterError(after time.Duration, cause error) *RetryAfterError {
+	return &RetryAfterError{After: after, Cause: cause}
+}
+
+// RetryAfter returns the retry hint of the first RetryAfterError in err's chain.
+func RetryAfter(err error) (time.Duration, bool) {
+	var ra *RetryAfterError
+	if errors.As(err, &ra) {
+		return ra.After, true
+	}
+	return 0, false
+}
+
+// ParseRetryAfter parses an HTTP Retry-After header value, either delay
+// seconds or an HTTP date, relative to now.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// WithRetryAfter wraps err, the failure of an HTTP request, in a
+// RetryAfterError when resp is a 429 or 503 carrying a Retry-After header,
+// and returns it unchanged otherwise.
+func WithRetryAfter(resp *http.Response, err error) error {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return err
+	}
+	after, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return err
+	}
+	return NewRetryAfterError(after, err)
+}
@@ -0,0 +1,167 @@
+// Package facts gathers cluster facts (installed CRDs, namespaces, feature
+// flags) and evaluates DashboardApp conditions written in CEL against them,
+// so an app only shows up once the stack backing it is installed.
+package facts
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/lru"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Facts is what conditions can see of the cluster. In expressions they are
+// the variables crds (CRD names such as "ingressroutes.traefik.io"),
+// namespaces and flags (the feature-flag ConfigMap data), e.g.
+//
+//	"ingressroutes.traefik.io" in crds && flags["media"] == "true"
+type Facts struct {
+	CRDs       []string
+	Namespaces []string
+	Flags      map[string]string
+}
+
+var crdListGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinitionList"}
+
+// Gather reads the facts from the cluster. Only object metadata is listed.
+// flagsConfigMap is optional; an empty name or a missing ConfigMap yields no
+// flags.
+func Gather(ctx context.Context, c client.Reader, flagsConfigMap types.NamespacedName) (*Facts, error) {
+	f := &Facts{Flags: map[string]string{}}
+
+	crds := &metav1.PartialObjectMetadataList{}
+	crds.SetGroupVersionKind(crdListGVK)
+	if err := c.List(ctx, crds); err != nil {
+		return nil, fmt.Errorf("failed to list CRDs: %w", err)
+	}
+	for _, crd := range crds.Items {
+		f.CRDs = append(f.CRDs, crd.Name)
+	}
+
+	namespaces := &metav1.PartialObjectMetadataList{}
+	namespaces.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NamespaceList"))
+	if err := c.List(ctx, namespaces); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for _, ns := range namespaces.Items {
+		f.Namespaces = append(f.Namespaces, ns.Name)
+	}
+
+	if flagsConfigMap.Name != "" {
+		cm := &corev1.ConfigMap{}
+		err := c.Get(ctx, flagsConfigMap, cm)
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return nil, fmt.Errorf("failed to get feature flags ConfigMap: %w", err)
+		default:
+			for k, v := range cm.Data {
+				f.Flags[k] = v
+			}
+		}
+	}
+
+	sort.Strings(f.CRDs)
+	sort.Strings(f.Namespaces)
+	return f, nil
+}
+
+// costLimit bounds the evaluation cost of a condition, as Kubernetes does
+// for the CEL expressions of its own APIs, so a condition iterating over
+// the facts cannot hold up reconciles
+const costLimit = 1_000_000
+
+// programCacheSize is how many compiled conditions are kept, the least
+// recently used being evicted first
+const programCacheSize = 256
+
+var (
+	envOnce sync.Once
+	env     *cel.Env
+	envErr  error
+
+	// programs caches compiled conditions by expression
+	programs = lru.New(programCacheSize)
+)
+
+func celEnv() (*cel.Env, error) {
+	envOnce.Do(func() {
+		env, envErr = cel.NewEnv(
+			cel.Variable("crds", cel.ListType(cel.StringType)),
+			cel.Variable("namespaces", cel.ListType(cel.StringType)),
+			cel.Variable("flags", cel.MapType(cel.StringType, cel.StringType)),
+		)
+	})
+	return env, envErr
+}
+
+func compile(expr string) (cel.Program, error) {
+	if p, ok := programs.Get(expr); ok {
+		return p.(cel.Program), nil
+	}
+	e, err := celEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := e.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("condition must evaluate to a bool, got %s", ast.OutputType())
+	}
+	p, err := e.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, err
+	}
+	programs.Add(expr, p)
+	return p, nil
+}
+
+// Validate reports whether expr is a well-formed boolean condition.
+func Validate(expr string) error {
+	_, err := compile(expr)
+	return err
+}
+
+// Eval evaluates the condition expr against the facts. A nil Facts behaves
+// as an empty cluster. Errors are either compile errors (see Validate) or
+// evaluation errors such as indexing a missing flag or exceeding the cost
+// limit.
+func (f *Facts) Eval(expr string) (bool, error) {
+	p, err := compile(expr)
+	if err != nil {
+		return false, err
+	}
+	if f == nil {
+		f = &Facts{}
+	}
+	crds, namespaces, flags := f.CRDs, f.Namespaces, f.Flags
+	if crds == nil {
+		crds = []string{}
+	}
+	if namespaces == nil {
+		namespaces = []string{}
+	}
+	if flags == nil {
+		flags = map[string]string{}
+	}
+	out, _, err := p.Eval(map[string]any{"crds": crds, "namespaces": namespaces, "flags": flags})
+	if err != nil {
+		return false, err
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition evaluated to %T, not bool", out.Value())
+	}
+	return b, nil
+}
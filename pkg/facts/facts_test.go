@@ -0,0 +1,122 @@
+package facts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEval(t *testing.T) {
+	f := &Facts{
+		CRDs:       []string{"ingressroutes.traefik.io"},
+		Namespaces: []string{"default", "media"},
+		Flags:      map[string]string{"ai": "true"},
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{"crd present", `"ingressroutes.traefik.io" in crds`, true, false},
+		{"crd absent", `"clusters.postgresql.cnpg.io" in crds`, false, false},
+		{"namespace present", `"media" in namespaces`, true, false},
+		{"flag set", `flags["ai"] == "true"`, true, false},
+		{"flag guarded", `"games" in flags && flags["games"] == "true"`, false, false},
+		{"combined", `"media" in namespaces && !("gpu" in flags)`, true, false},
+		{"missing flag", `flags["games"] == "true"`, false, true},
+		{"not bool", `namespaces`, false, true},
+		{"syntax error", `"media" in`, false, true},
+		{"unknown variable", `"x" in pods`, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.Eval(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEval_NilFacts(t *testing.T) {
+	var f *Facts
+	got, err := f.Eval(`"media" in namespaces`)
+	if err != nil || got {
+		t.Errorf("Eval() = %v, %v; want false, nil", got, err)
+	}
+}
+
+func TestEval_CostLimit(t *testing.T) {
+	f := &Facts{}
+	for i := range 200 {
+		f.Namespaces = append(f.Namespaces, fmt.Sprintf("ns-%d", i))
+	}
+	_, err := f.Eval(`namespaces.all(a, namespaces.all(b, namespaces.all(c, a + b + c != "")))`)
+	if err == nil || !strings.Contains(err.Error(), "cost limit") {
+		t.Errorf("Eval() error = %v, want the cost limit exceeded", err)
+	}
+}
+
+func TestCompile_CacheBounded(t *testing.T) {
+	for i := range programCacheSize + 10 {
+		if err := Validate(fmt.Sprintf(`"ns-%d" in namespaces`, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := programs.Len(); n > programCacheSize {
+		t.Errorf("%d programs cached, want at most %d", n, programCacheSize)
+	}
+}
+
+func TestGather(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiextensionsv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(s).WithObjects(
+		&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "ingressroutes.traefik.io"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "media"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "duro-flags", Namespace: "duro"},
+			Data:       map[string]string{"ai": "true"},
+		},
+	).Build()
+
+	f, err := Gather(context.Background(), c, types.NamespacedName{Name: "duro-flags", Namespace: "duro"})
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	if len(f.CRDs) != 1 || f.CRDs[0] != "ingressroutes.traefik.io" {
+		t.Errorf("CRDs = %v", f.CRDs)
+	}
+	if len(f.Namespaces) != 1 || f.Namespaces[0] != "media" {
+		t.Errorf("Namespaces = %v", f.Namespaces)
+	}
+	if f.Flags["ai"] != "true" {
+		t.Errorf("Flags = %v", f.Flags)
+	}
+
+	f, err = Gather(context.Background(), c, types.NamespacedName{Name: "missing", Namespace: "duro"})
+	if err != nil {
+		t.Fatalf("Gather() with missing flags ConfigMap error = %v", err)
+	}
+	if len(f.Flags) != 0 {
+		t.Errorf("Flags = %v, want none", f.Flags)
+	}
+}
@@ -0,0 +1,102 @@
+package groups
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Separator delimits levels in hierarchical group names (e.g. "media/kids")
+	Separator = "/"
+
+	// Wildcard matches any suffix when used as the last character of a pattern
+	Wildcard = "*"
+)
+
+// Match reports whether a group pattern from spec.groups matches a concrete
+// group name. Supported forms:
+//
+//	"family"   exact match
+//	"*"        any group
+//	"media/*"  any descendant of "media" (e.g. "media/kids", "media/kids/tv"), not "media" itself
+//	"media*"   any group starting with "media" (e.g. "media", "mediaadmins", "media/kids")
+func Match(pattern, group string) bool {
+	if pattern == Wildcard {
+		return true
+	}
+	if !strings.HasSuffix(pattern, Wildcard) {
+		return pattern == group
+	}
+	prefix := strings.TrimSuffix(pattern, Wildcard)
+	if !strings.HasPrefix(group, prefix) {
+		return false
+	}
+	// "media/*" requires at least one more level below the prefix
+	if strings.HasSuffix(prefix, Separator) {
+		return len(group) > len(prefix)
+	}
+	return true
+}
+
+// MatchAny reports whether any of the patterns matches the group.
+func MatchAny(patterns []string, group string) bool {
+	for _, p := range patterns {
+		if Match(p, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Visible reports whether an app restricted to patterns is visible to a user
+// belonging to any of the given groups (OR logic, as in spec.groups).
+func Visible(patterns, userGroups []string) bool {
+	for _, g := range userGroups {
+		if MatchAny(patterns, g) {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleAll reports whether a user belonging to the given groups matches
+// every one of patterns (AND logic, as in spec.access.allOf).
+func VisibleAll(patterns, userGroups []string) bool {
+	for _, p := range patterns {
+		matched := false
+		for _, g := range userGroups {
+			if Match(p, g) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidatePattern checks that a group pattern is well-formed: non-empty, with
+// the wildcard only allowed as the final character and no empty hierarchy
+// levels.
+func ValidatePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("group must not be empty")
+	}
+	if i := strings.Index(pattern, Wildcard); i >= 0 && i != len(pattern)-1 {
+		return fmt.Errorf("group %q: wildcard is only allowed as the last character", pattern)
+	}
+	trimmed := strings.TrimSuffix(pattern, Wildcard)
+	if trimmed == "" {
+		return nil
+	}
+	levels := strings.Split(trimmed, Separator)
+	for i, level := range levels {
+		// "media/*" leaves a trailing empty level which is fine
+		if level == "" && !(i == len(levels)-1 && strings.HasSuffix(pattern, Wildcard)) {
+			return fmt.Errorf("group %q: empty hierarchy level", pattern)
+		}
+	}
+	return nil
+}
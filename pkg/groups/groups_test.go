@@ -0,0 +1,110 @@
+package groups
+
+import (
+	"slices"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		group   string
+		want    bool
+	}{
+		{"family", "family", true},
+		{"family", "friends", false},
+		{"family", "family/kids", false},
+		{"*", "anything", true},
+		{"*", "media/kids", true},
+		{"media/*", "media/kids", true},
+		{"media/*", "media/kids/tv", true},
+		{"media/*", "media", false},
+		{"media/*", "media/", false},
+		{"media/*", "mediaadmins", false},
+		{"media*", "media", true},
+		{"media*", "mediaadmins", true},
+		{"media*", "media/kids", true},
+		{"media*", "social", false},
+		{"media/kids/*", "media/kids/tv", true},
+		{"media/kids/*", "media/adults/tv", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern+"~"+tc.group, func(t *testing.T) {
+			if got := Match(tc.pattern, tc.group); got != tc.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tc.pattern, tc.group, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVisible(t *testing.T) {
+	patterns := []string{"lldap_admin", "media/*"}
+	if !Visible(patterns, []string{"friends", "media/kids"}) {
+		t.Errorf("expected media/kids to see the app")
+	}
+	if Visible(patterns, []string{"friends", "media"}) {
+		t.Errorf("expected media (parent) not to match media/*")
+	}
+	if Visible(patterns, nil) {
+		t.Errorf("expected no visibility without groups")
+	}
+}
+
+func TestVisibleAll(t *testing.T) {
+	patterns := []string{"media/*", "adults"}
+	if !VisibleAll(patterns, []string{"media/tv", "adults", "friends"}) {
+		t.Errorf("expected a member of both groups to see the app")
+	}
+	if VisibleAll(patterns, []string{"media/tv", "friends"}) {
+		t.Errorf("expected a member of media/tv only not to see the app")
+	}
+	if !VisibleAll(nil, nil) {
+		t.Errorf("expected no patterns to require nothing")
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"family", false},
+		{"*", false},
+		{"media/*", false},
+		{"media*", false},
+		{"media/kids", false},
+		{"", true},
+		{"*/kids", true},
+		{"me*dia", true},
+		{"media//kids", true},
+		{"/media", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			err := ValidatePattern(tc.pattern)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidatePattern(%q) error = %v, wantErr %v", tc.pattern, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFromRoleBindings(t *testing.T) {
+	binding := func(subjects ...rbacv1.Subject) rbacv1.RoleBinding {
+		return rbacv1.RoleBinding{Subjects: subjects}
+	}
+	group := func(name string) rbacv1.Subject { return rbacv1.Subject{Kind: rbacv1.GroupKind, Name: name} }
+	got := FromRoleBindings([]rbacv1.RoleBinding{
+		binding(group("media"), rbacv1.Subject{Kind: rbacv1.UserKind, Name: "alice"}),
+		binding(group("family"), group("media"), group("system:authenticated")),
+		binding(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "duro", Namespace: "duro"}, group("ad*mins")),
+	})
+	if want := []string{"family", "media"}; !slices.Equal(got, want) {
+		t.Errorf("FromRoleBindings() = %v, want %v", got, want)
+	}
+	if got := FromRoleBindings(nil); got != nil {
+		t.Errorf("FromRoleBindings(nil) = %v, want nil", got)
+	}
+}
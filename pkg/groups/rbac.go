@@ -0,0 +1,32 @@
+package groups
+
+import (
+	"slices"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// systemPrefix marks groups managed by Kubernetes itself (e.g.
+// "system:authenticated"), which say nothing about who uses an app
+const systemPrefix = "system:"
+
+// FromRoleBindings returns the groups bound by the given RoleBindings, sorted
+// and deduplicated, for apps that take their visibility from cluster
+// access. Kubernetes system groups and names that are not valid group
+// patterns are left out.
+func FromRoleBindings(bindings []rbacv1.RoleBinding) []string {
+	var out []string
+	for _, rb := range bindings {
+		for _, s := range rb.Subjects {
+			if s.Kind != rbacv1.GroupKind || strings.HasPrefix(s.Name, systemPrefix) || strings.Contains(s.Name, Wildcard) {
+				continue
+			}
+			if ValidatePattern(s.Name) == nil {
+				out = append(out, s.Name)
+			}
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
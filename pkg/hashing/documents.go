@@ -0,0 +1,50 @@
+package hashing
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// SumEach hashes every document of an output on its own, keyed by document
+// name, so consumers can tell which documents changed.
+func SumEach(alg string, docs map[string]string) map[string]string {
+	sums := make(map[string]string, len(docs))
+	for key, content := range docs {
+		sums[key] = Sum(alg, map[string]string{key: content})
+	}
+	return sums
+}
+
+// Changed returns the sorted names of documents added, modified or removed
+// between two sets of per-document hashes.
+func Changed(old, current map[string]string) []string {
+	var changed []string
+	for key, sum := range current {
+		if !Equal(old[key], sum) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range old {
+		if _, ok := current[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// EncodeSums serializes per-document hashes for an annotation.
+func EncodeSums(sums map[string]string) string {
+	b, _ := json.Marshal(sums)
+	return string(b)
+}
+
+// DecodeSums parses an annotation written by EncodeSums. Missing or malformed
+// values decode to an empty set, which marks every document as changed.
+func DecodeSums(value string) map[string]string {
+	sums := map[string]string{}
+	if value != "" {
+		_ = json.Unmarshal([]byte(value), &sums)
+	}
+	return sums
+}
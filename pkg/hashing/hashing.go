@@ -0,0 +1,122 @@
+// Package hashing computes the change-detection hashes recorded on output
+// targets. Hashes are written as "<algorithm>:<hex digest>" so the algorithm
+// can change without mistaking an old hash for a mismatch.
+package hashing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const (
+	// SHA256 is the default algorithm
+	SHA256 = "sha256"
+	// XXHash is a fast non-cryptographic alternative for large catalogs
+	XXHash = "xxhash"
+)
+
+const (
+	// ScopeDocument hashes every key of the output
+	ScopeDocument = "document"
+	// ScopeEntries hashes only the app entries, so changes confined to
+	// formatting or auxiliary keys do not cause a rewrite
+	ScopeEntries = "entries"
+)
+
+// ValidateAlgorithm checks alg is a supported algorithm.
+func ValidateAlgorithm(alg string) error {
+	switch alg {
+	case SHA256, XXHash:
+		return nil
+	}
+	return fmt.Errorf("unsupported hash algorithm %q (want %s or %s)", alg, SHA256, XXHash)
+}
+
+// ValidateScope checks scope is a supported hash scope.
+func ValidateScope(scope string) error {
+	switch scope {
+	case ScopeDocument, ScopeEntries:
+		return nil
+	}
+	return fmt.Errorf("unsupported hash scope %q (want %s or %s)", scope, ScopeDocument, ScopeEntries)
+}
+
+func newHash(alg string) hash.Hash {
+	if alg == XXHash {
+		return xxhash.New()
+	}
+	return sha256.New()
+}
+
+// Sum hashes all keys of data in deterministic order and returns
+// "<alg>:<hex digest>".
+func Sum(alg string, data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := newHash(alg)
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(data[k]))
+		h.Write([]byte{0})
+	}
+	return alg + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Parse splits a recorded hash into algorithm and digest. Values without an
+// algorithm prefix predate it and are sha256 digests.
+func Parse(value string) (alg, digest string) {
+	if alg, digest, ok := strings.Cut(value, ":"); ok {
+		return alg, digest
+	}
+	return SHA256, value
+}
+
+// Equal reports whether a recorded hash (possibly unprefixed) matches a hash
+// returned by Sum.
+func Equal(recorded, current string) bool {
+	if recorded == "" {
+		return false
+	}
+	ra, rd := Parse(recorded)
+	ca, cd := Parse(current)
+	return ra == ca && rd == cd
+}
+
+// nameSuffixLength is the length of the suffixes returned by NameSuffix,
+// kustomize's
+const nameSuffixLength = 10
+
+// NameSuffix derives a name suffix from a hash returned by Sum the way
+// kustomize's configMapGenerator does: the first ten digits of the digest,
+// with the digits and vowels that could spell words swapped for consonants.
+func NameSuffix(sum string) string {
+	_, digest := Parse(sum)
+	if len(digest) > nameSuffixLength {
+		digest = digest[:nameSuffixLength]
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '0':
+			return 'g'
+		case '1':
+			return 'h'
+		case '3':
+			return 'k'
+		case 'a':
+			return 'm'
+		case 'e':
+			return 't'
+		}
+		return r
+	}, digest)
+}
@@ -0,0 +1,107 @@
+package hashing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSum(t *testing.T) {
+	data := map[string]string{"apps.json": "[]", "categories.json": "[]"}
+
+	for _, alg := range []string{SHA256, XXHash} {
+		got := Sum(alg, data)
+		if !strings.HasPrefix(got, alg+":") {
+			t.Errorf("Sum(%s) = %q, want %s: prefix", alg, got, alg)
+		}
+		if again := Sum(alg, map[string]string{"categories.json": "[]", "apps.json": "[]"}); again != got {
+			t.Errorf("Sum(%s) not deterministic: %q vs %q", alg, got, again)
+		}
+		if other := Sum(alg, map[string]string{"apps.json": "[]"}); other == got {
+			t.Errorf("Sum(%s) ignores keys", alg)
+		}
+	}
+	if Sum(SHA256, data) == Sum(XXHash, data) {
+		t.Errorf("algorithms produce the same hash")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	data := map[string]string{"apps.json": "[]"}
+	sha := Sum(SHA256, data)
+	_, legacy := Parse(sha)
+
+	tests := []struct {
+		name     string
+		recorded string
+		current  string
+		want     bool
+	}{
+		{"same", sha, sha, true},
+		{"legacy unprefixed sha256", legacy, sha, true},
+		{"algorithm changed", sha, Sum(XXHash, data), false},
+		{"legacy vs xxhash", legacy, Sum(XXHash, data), false},
+		{"content changed", sha, Sum(SHA256, map[string]string{"apps.json": "[{}]"}), false},
+		{"nothing recorded", "", sha, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Equal(tt.recorded, tt.current); got != tt.want {
+				t.Errorf("Equal(%q, %q) = %v, want %v", tt.recorded, tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameSuffix(t *testing.T) {
+	tests := []struct {
+		sum  string
+		want string
+	}{
+		{"sha256:0123456789abcdef", "gh2k456789"},
+		{"sha256:ae3e1f", "mtkthf"},
+		{"a0b1c2d3e4f5a6b7", "mgbhc2dkt4"},
+	}
+	for _, tt := range tests {
+		if got := NameSuffix(tt.sum); got != tt.want {
+			t.Errorf("NameSuffix(%q) = %q, want %q", tt.sum, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := ValidateAlgorithm("md5"); err == nil {
+		t.Errorf("ValidateAlgorithm(md5) should fail")
+	}
+	if err := ValidateScope("everything"); err == nil {
+		t.Errorf("ValidateScope(everything) should fail")
+	}
+	if ValidateAlgorithm(XXHash) != nil || ValidateScope(ScopeEntries) != nil {
+		t.Errorf("valid values rejected")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	old := SumEach(SHA256, map[string]string{
+		"apps.json":       "[]",
+		"categories.json": "[]",
+		"apps-kids.json":  "[]",
+	})
+	current := SumEach(SHA256, map[string]string{
+		"apps.json":       "[]",
+		"categories.json": `[{"id":"media"}]`,
+		"theme.json":      "{}",
+	})
+
+	got := Changed(old, current)
+	want := []string{"apps-kids.json", "categories.json", "theme.json"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Changed() = %v, want %v", got, want)
+	}
+
+	if got := Changed(DecodeSums(EncodeSums(current)), current); len(got) != 0 {
+		t.Errorf("round-tripped sums report changes: %v", got)
+	}
+	if got := Changed(DecodeSums("not json"), current); len(got) != len(current) {
+		t.Errorf("malformed annotation should mark every document changed, got %v", got)
+	}
+}
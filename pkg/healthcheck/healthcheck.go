@@ -0,0 +1,118 @@
+// Package healthcheck probes apps over HTTP for the health checks they
+// configure in spec.healthCheck.
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+const (
+	// DefaultInterval and DefaultTimeout apply when a health check leaves
+	// them unset
+	DefaultInterval = time.Minute
+	DefaultTimeout  = 5 * time.Second
+
+	// MinInterval and MaxTimeout bound what a health check may ask for, so
+	// one app can't keep the probe workers busy
+	MinInterval = 10 * time.Second
+	MaxTimeout  = 30 * time.Second
+
+	// DefaultConcurrency is how many apps are probed at once
+	DefaultConcurrency = 8
+)
+
+// Check is a probe to run
+type Check struct {
+	// URL probed
+	URL string
+	// Timeout of the probe
+	Timeout time.Duration
+	// Expected status codes, any 2xx or 3xx when empty
+	Expected []int
+}
+
+// Result is the outcome of a probe
+type Result struct {
+	State dashboardv1alpha1.HealthState
+	// Reason explains a down state, e.g. "HTTP 503"
+	Reason string
+}
+
+// CheckFor returns the check of an app's health check against base, its
+// internal URL or else its URL.
+func CheckFor(hc *dashboardv1alpha1.HealthCheck, base string) (Check, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return Check{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Check{}, fmt.Errorf("cannot probe %s URLs", u.Scheme)
+	}
+	if hc.Path != "" {
+		u.Path, u.RawPath = hc.Path, ""
+	}
+	check := Check{URL: u.String(), Timeout: DefaultTimeout, Expected: hc.ExpectedStatuses}
+	if hc.Timeout != nil && hc.Timeout.Duration > 0 {
+		check.Timeout = min(hc.Timeout.Duration, MaxTimeout)
+	}
+	return check, nil
+}
+
+// Interval returns the probe interval of a health check.
+func Interval(hc *dashboardv1alpha1.HealthCheck) time.Duration {
+	if hc.Interval == nil || hc.Interval.Duration <= 0 {
+		return DefaultInterval
+	}
+	return max(hc.Interval.Duration, MinInterval)
+}
+
+// Prober runs checks with GET requests. Redirects are not followed, so a
+// login redirect counts as up like any other 3xx by default.
+type Prober struct {
+	Client *http.Client
+}
+
+// NewProber returns a Prober.
+func NewProber() *Prober {
+	return &Prober{Client: &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+// Probe runs check.
+func (p *Prober) Probe(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.URL, nil)
+	if err != nil {
+		return Result{State: dashboardv1alpha1.HealthDown, Reason: err.Error()}
+	}
+	req.Header.Set("User-Agent", "duro-operator-healthcheck")
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return Result{State: dashboardv1alpha1.HealthDown, Reason: err.Error()}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if !expected(resp.StatusCode, check.Expected) {
+		return Result{State: dashboardv1alpha1.HealthDown, Reason: fmt.Sprintf("HTTP %d", resp.StatusCode)}
+	}
+	return Result{State: dashboardv1alpha1.HealthUp}
+}
+
+// expected reports whether status is one of want, or any 2xx or 3xx if
+// want is empty.
+func expected(status int, want []int) bool {
+	if len(want) == 0 {
+		return status >= 200 && status < 400
+	}
+	return slices.Contains(want, status)
+}
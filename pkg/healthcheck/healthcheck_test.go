@@ -0,0 +1,82 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+func TestProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+		case "/login":
+			http.Redirect(w, r, "/sso", http.StatusFound)
+		case "/teapot":
+			w.WriteHeader(http.StatusTeapot)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		hc         dashboardv1alpha1.HealthCheck
+		want       dashboardv1alpha1.HealthState
+		wantReason string
+	}{
+		{"2xx is up", dashboardv1alpha1.HealthCheck{Path: "/healthz"}, dashboardv1alpha1.HealthUp, ""},
+		{"redirects are not followed", dashboardv1alpha1.HealthCheck{Path: "/login"}, dashboardv1alpha1.HealthUp, ""},
+		{"5xx is down", dashboardv1alpha1.HealthCheck{}, dashboardv1alpha1.HealthDown, "HTTP 503"},
+		{"expected statuses", dashboardv1alpha1.HealthCheck{Path: "/teapot", ExpectedStatuses: []int{418}}, dashboardv1alpha1.HealthUp, ""},
+		{"unexpected status", dashboardv1alpha1.HealthCheck{Path: "/healthz", ExpectedStatuses: []int{204}}, dashboardv1alpha1.HealthDown, "HTTP 200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, err := CheckFor(&tt.hc, srv.URL+"/web")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := NewProber().Probe(context.Background(), check)
+			if got.State != tt.want || got.Reason != tt.wantReason {
+				t.Errorf("Probe() = %+v, want %s %q", got, tt.want, tt.wantReason)
+			}
+		})
+	}
+
+	check := Check{URL: "http://127.0.0.1:1/", Timeout: time.Second}
+	if got := NewProber().Probe(context.Background(), check); got.State != dashboardv1alpha1.HealthDown || got.Reason == "" {
+		t.Errorf("Probe() of an unreachable app = %+v, want down with a reason", got)
+	}
+}
+
+func TestCheckFor(t *testing.T) {
+	hc := &dashboardv1alpha1.HealthCheck{Path: "/api/health", Timeout: &metav1.Duration{Duration: time.Hour}}
+	check, err := CheckFor(hc, "http://plex.media.svc:32400/web?x=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.URL != "http://plex.media.svc:32400/api/health?x=1" {
+		t.Errorf("URL = %s", check.URL)
+	}
+	if check.Timeout != MaxTimeout {
+		t.Errorf("Timeout = %s, want it capped at %s", check.Timeout, MaxTimeout)
+	}
+	if _, err := CheckFor(hc, "smb://nas/share"); err == nil {
+		t.Error("CheckFor() accepted a non-HTTP URL")
+	}
+
+	if got := Interval(&dashboardv1alpha1.HealthCheck{Interval: &metav1.Duration{Duration: time.Second}}); got != MinInterval {
+		t.Errorf("Interval() = %s, want %s", got, MinInterval)
+	}
+	if got := Interval(&dashboardv1alpha1.HealthCheck{}); got != DefaultInterval {
+		t.Errorf("Interval() = %s, want %s", got, DefaultInterval)
+	}
+}
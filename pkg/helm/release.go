@@ -0,0 +1,192 @@
+// Package helm reads Helm release Secrets and turns charts that opt in
+// through dashboard.homelab.io/* chart annotations into DashboardApp specs.
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+const (
+	// SecretType is the type of the Secrets Helm 3 stores releases in
+	SecretType = "helm.sh/release.v1"
+
+	// OwnerLabel, NameLabel and StatusLabel are set by Helm on release Secrets
+	OwnerLabel  = "owner"
+	NameLabel   = "name"
+	StatusLabel = "status"
+
+	// StatusDeployed is the status of the live revision of a release
+	StatusDeployed = "deployed"
+
+	// SourceHelm is the source label value of DashboardApps synthesized from
+	// Helm releases
+	SourceHelm = "helm"
+
+	// ReleaseLabel records the Helm release a DashboardApp was synthesized from
+	ReleaseLabel = "dashboard.homelab.io/helm-release"
+
+	// AnnotationPrefix prefixes the chart annotations describing the app:
+	// name, url, category, icon, groups (comma-separated) and priority
+	AnnotationPrefix = "dashboard.homelab.io/"
+
+	// ValuesKey is the top-level values key that overrides the chart
+	// annotations at install time (same fields, groups as a list)
+	ValuesKey = "dashboard"
+)
+
+// Release is the part of a Helm release this package reads
+type Release struct {
+	Name      string         `json:"name"`
+	Namespace string         `json:"namespace"`
+	Version   int            `json:"version"`
+	Info      ReleaseInfo    `json:"info"`
+	Chart     Chart          `json:"chart"`
+	Config    map[string]any `json:"config,omitempty"`
+}
+
+// ReleaseInfo holds the release status
+type ReleaseInfo struct {
+	Status string `json:"status"`
+}
+
+// Chart is the chart a release was installed from
+type Chart struct {
+	Metadata ChartMetadata  `json:"metadata"`
+	Values   map[string]any `json:"values,omitempty"`
+}
+
+// ChartMetadata is the chart's Chart.yaml
+type ChartMetadata struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// Decode decodes the "release" key of a release Secret: base64 encoded,
+// usually gzipped, JSON.
+func Decode(data []byte) (*Release, error) {
+	raw, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release encoding: %w", err)
+	}
+	if bytes.HasPrefix(raw, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid release compression: %w", err)
+		}
+		defer zr.Close()
+		if raw, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("invalid release compression: %w", err)
+		}
+	}
+	var rel Release
+	if err := json.Unmarshal(raw, &rel); err != nil {
+		return nil, fmt.Errorf("invalid release: %w", err)
+	}
+	return &rel, nil
+}
+
+// Encode is the inverse of Decode, mainly useful to tests.
+func Encode(rel *Release) ([]byte, error) {
+	raw, err := json.Marshal(rel)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+// AppSpec builds the DashboardApp spec the release declares. Chart
+// annotations are the defaults, overridden by the chart's and then the
+// user-supplied values under ValuesKey. It returns false if the release does
+// not opt in, i.e. declares no URL, and an error if it opts in but leaves a
+// required field out.
+func (r *Release) AppSpec() (*dashboardv1alpha1.DashboardAppSpec, bool, error) {
+	fields := map[string]string{"name": r.Name}
+	for k, v := range r.Chart.Metadata.Annotations {
+		if key, ok := strings.CutPrefix(k, AnnotationPrefix); ok {
+			fields[key] = v
+		}
+	}
+	mergeValues(fields, r.Chart.Values)
+	mergeValues(fields, r.Config)
+
+	if fields["url"] == "" {
+		return nil, false, nil
+	}
+
+	spec := &dashboardv1alpha1.DashboardAppSpec{
+		Name:     fields["name"],
+		URL:      fields["url"],
+		Category: fields["category"],
+		Icon:     fields["icon"],
+		Priority: 100,
+	}
+	for _, g := range strings.Split(fields["groups"], ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			spec.Groups = append(spec.Groups, g)
+		}
+	}
+	if p := fields["priority"]; p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid priority %q", p)
+		}
+		spec.Priority = n
+	}
+
+	for _, f := range []struct{ name, value string }{
+		{"category", spec.Category}, {"icon", spec.Icon},
+	} {
+		if f.value == "" {
+			return nil, true, fmt.Errorf("%s%s is required", AnnotationPrefix, f.name)
+		}
+	}
+	if len(spec.Groups) == 0 {
+		return nil, true, fmt.Errorf("%sgroups is required", AnnotationPrefix)
+	}
+	return spec, true, nil
+}
+
+// mergeValues copies the scalar fields under values[ValuesKey] into fields;
+// groups may be given as a list.
+func mergeValues(fields map[string]string, values map[string]any) {
+	dashboard, ok := values[ValuesKey].(map[string]any)
+	if !ok {
+		return
+	}
+	for k, v := range dashboard {
+		switch v := v.(type) {
+		case string:
+			fields[k] = v
+		case float64:
+			fields[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					items = append(items, s)
+				}
+			}
+			fields[k] = strings.Join(items, ",")
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package helm
+
+import (
+	"encoding/base64"
+	"slices"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	rel := &Release{
+		Name: "jellyfin", Namespace: "media", Version: 3,
+		Info:  ReleaseInfo{Status: StatusDeployed},
+		Chart: Chart{Metadata: ChartMetadata{Name: "jellyfin", Version: "1.2.0"}},
+	}
+	data, err := Encode(rel)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	got, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.Name != "jellyfin" || got.Version != 3 || got.Info.Status != StatusDeployed || got.Chart.Metadata.Version != "1.2.0" {
+		t.Errorf("Decode() = %+v", got)
+	}
+
+	// Releases written without compression decode too
+	plain := []byte(base64.StdEncoding.EncodeToString([]byte(`{"name":"gitea","version":1}`)))
+	if got, err := Decode(plain); err != nil || got.Name != "gitea" {
+		t.Errorf("Decode(uncompressed) = %+v, %v", got, err)
+	}
+
+	if _, err := Decode([]byte("not base64!")); err == nil {
+		t.Error("Decode(invalid) expected error")
+	}
+}
+
+func TestRelease_AppSpec(t *testing.T) {
+	annotations := map[string]string{
+		"dashboard.homelab.io/url":      "https://jellyfin.example.test",
+		"dashboard.homelab.io/category": "media",
+		"dashboard.homelab.io/icon":     "<svg/>",
+		"dashboard.homelab.io/groups":   "family, friends",
+		"artifacthub.io/license":        "GPL-2.0",
+	}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		chartValues  map[string]any
+		config       map[string]any
+		wantOptIn    bool
+		wantErr      bool
+		wantName     string
+		wantGroups   []string
+		wantPriority int
+	}{
+		{
+			name:         "annotations",
+			annotations:  annotations,
+			wantOptIn:    true,
+			wantName:     "jellyfin",
+			wantGroups:   []string{"family", "friends"},
+			wantPriority: 100,
+		},
+		{
+			name:        "values override annotations",
+			annotations: annotations,
+			chartValues: map[string]any{"dashboard": map[string]any{"priority": float64(20)}},
+			config: map[string]any{"dashboard": map[string]any{
+				"name":   "Jellyfin",
+				"groups": []any{"admins"},
+			}},
+			wantOptIn:    true,
+			wantName:     "Jellyfin",
+			wantGroups:   []string{"admins"},
+			wantPriority: 20,
+		},
+		{
+			name:        "values only",
+			annotations: nil,
+			config: map[string]any{"dashboard": map[string]any{
+				"url": "https://jellyfin", "category": "media", "icon": "<svg/>", "groups": "family",
+			}},
+			wantOptIn:    true,
+			wantName:     "jellyfin",
+			wantGroups:   []string{"family"},
+			wantPriority: 100,
+		},
+		{
+			name:        "no opt-in",
+			annotations: map[string]string{"artifacthub.io/license": "MIT"},
+		},
+		{
+			name:        "missing groups",
+			annotations: map[string]string{"dashboard.homelab.io/url": "https://x", "dashboard.homelab.io/category": "media", "dashboard.homelab.io/icon": "<svg/>"},
+			wantOptIn:   true,
+			wantErr:     true,
+		},
+		{
+			name:        "invalid priority",
+			annotations: annotations,
+			config:      map[string]any{"dashboard": map[string]any{"priority": "high"}},
+			wantOptIn:   true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel := &Release{
+				Name:   "jellyfin",
+				Chart:  Chart{Metadata: ChartMetadata{Name: "jellyfin", Annotations: tt.annotations}, Values: tt.chartValues},
+				Config: tt.config,
+			}
+			spec, optIn, err := rel.AppSpec()
+			if optIn != tt.wantOptIn {
+				t.Fatalf("AppSpec() opt-in = %v, want %v", optIn, tt.wantOptIn)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AppSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if spec == nil {
+				return
+			}
+			if spec.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", spec.Name, tt.wantName)
+			}
+			if !slices.Equal(spec.Groups, tt.wantGroups) {
+				t.Errorf("groups = %v, want %v", spec.Groups, tt.wantGroups)
+			}
+			if spec.Priority != tt.wantPriority {
+				t.Errorf("priority = %d, want %d", spec.Priority, tt.wantPriority)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package history keeps the outcomes of recent reconciles in memory so
+// transient failures can be investigated after the fact.
+package history
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+// DefaultSize is how many reconciles are kept by default
+const DefaultSize = 50
+
+// Reconcile is the outcome of one reconcile
+type Reconcile struct {
+	Time    time.Time `json:"time"`
+	TraceID string    `json:"traceId"`
+	// Trigger is the namespace/name of the object whose change was reconciled
+	Trigger  string          `json:"trigger"`
+	Duration metav1.Duration `json:"duration"`
+	Apps     int             `json:"apps"`
+	Entries  int             `json:"entries"`
+	// Delta is how the entries changed; nil if nothing was assembled
+	Delta *Delta `json:"delta,omitempty"`
+	// Error is the redacted failure, if any
+	Error string `json:"error,omitempty"`
+}
+
+// Delta lists the entry IDs added, removed and changed by a reconcile
+type Delta struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Empty reports whether nothing changed.
+func (d *Delta) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff compares two entry lists by ID. An entry is changed if its JSON
+// encoding differs.
+func Diff(previous, current []assembler.AppEntry) *Delta {
+	before := make(map[string][]byte, len(previous))
+	for _, e := range previous {
+		data, _ := json.Marshal(e)
+		before[e.ID] = data
+	}
+	d := &Delta{}
+	for _, e := range current {
+		old, ok := before[e.ID]
+		if !ok {
+			d.Added = append(d.Added, e.ID)
+			continue
+		}
+		delete(before, e.ID)
+		if data, _ := json.Marshal(e); string(data) != string(old) {
+			d.Changed = append(d.Changed, e.ID)
+		}
+	}
+	for id := range before {
+		d.Removed = append(d.Removed, id)
+	}
+	slices.Sort(d.Added)
+	slices.Sort(d.Removed)
+	slices.Sort(d.Changed)
+	return d
+}
+
+// Ring holds the last N reconciles. It is safe for concurrent use.
+type Ring struct {
+	mu      sync.Mutex
+	records []Reconcile
+	next    int
+	full    bool
+}
+
+// NewRing returns a Ring keeping the last size reconciles.
+func NewRing(size int) *Ring {
+	if size < 1 {
+		size = DefaultSize
+	}
+	return &Ring{records: make([]Reconcile, size)}
+}
+
+// Add records a reconcile, evicting the oldest once full.
+func (r *Ring) Add(rec Reconcile) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[r.next] = rec
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// List returns the recorded reconciles, newest first.
+func (r *Ring) List() []Reconcile {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.records)
+	}
+	out := make([]Reconcile, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, r.records[(r.next-i+len(r.records))%len(r.records)])
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package history
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+func TestRing(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		adds []string
+		want []string
+	}{
+		{name: "empty", size: 3, want: []string{}},
+		{name: "partial", size: 3, adds: []string{"a", "b"}, want: []string{"b", "a"}},
+		{name: "full", size: 3, adds: []string{"a", "b", "c"}, want: []string{"c", "b", "a"}},
+		{name: "wrapped", size: 3, adds: []string{"a", "b", "c", "d", "e"}, want: []string{"e", "d", "c"}},
+		{name: "default size", size: 0, adds: []string{"a"}, want: []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRing(tt.size)
+			for _, id := range tt.adds {
+				r.Add(Reconcile{TraceID: id})
+			}
+			got := []string{}
+			for _, rec := range r.List() {
+				got = append(got, rec.TraceID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	previous := []assembler.AppEntry{
+		{ID: "plex", Name: "Plex"},
+		{ID: "gitea", Name: "Gitea"},
+		{ID: "wiki", Name: "Wiki"},
+	}
+	current := []assembler.AppEntry{
+		{ID: "plex", Name: "Plex"},
+		{ID: "gitea", Name: "Forgejo"},
+		{ID: "grafana", Name: "Grafana"},
+	}
+	d := Diff(previous, current)
+	if !slices.Equal(d.Added, []string{"grafana"}) || !slices.Equal(d.Removed, []string{"wiki"}) || !slices.Equal(d.Changed, []string{"gitea"}) {
+		t.Errorf("Diff() = %+v", d)
+	}
+	if d.Empty() {
+		t.Error("Empty() = true for a non-empty delta")
+	}
+	if d := Diff(previous, previous); !d.Empty() {
+		t.Errorf("Diff() of identical lists = %+v", d)
+	}
+}
@@ -0,0 +1,125 @@
+// Package hooks runs external programs as assembly hooks: the entry list is
+// written as JSON to the program's stdin and the transformed list is read
+// back from its stdout.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+const (
+	// DefaultTimeout bounds a single hook run
+	DefaultTimeout = 5 * time.Second
+
+	// maxOutputBytes bounds what a hook may write to stdout
+	maxOutputBytes = 16 << 20
+
+	// maxStderrBytes bounds the stderr kept for error messages
+	maxStderrBytes = 4 << 10
+)
+
+// Exec runs an executable as an assembler.EntryHook. The program is run
+// without a shell, with an empty environment (except HOOK_TIMEOUT) and the
+// temporary directory as working directory, and is killed at the timeout.
+type Exec struct {
+	Path    string
+	Timeout time.Duration
+}
+
+// NewExec returns an Exec hook for an absolute path to an executable.
+func NewExec(path string, timeout time.Duration) (*Exec, error) {
+	if !filepath.IsAbs(path) {
+		return nil, fmt.Errorf("hook %q: path must be absolute", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("hook %q: %w", path, err)
+	}
+	if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+		return nil, fmt.Errorf("hook %q: not an executable file", path)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Exec{Path: path, Timeout: timeout}, nil
+}
+
+// Name implements assembler.EntryHook.
+func (h *Exec) Name() string {
+	return filepath.Base(h.Path)
+}
+
+// Transform implements assembler.EntryHook.
+func (h *Exec) Transform(ctx context.Context, entries []assembler.AppEntry) ([]assembler.AppEntry, error) {
+	input, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: maxOutputBytes}
+	stderr := &limitedBuffer{limit: maxStderrBytes}
+	cmd := exec.CommandContext(ctx, h.Path)
+	cmd.Env = []string{"HOOK_TIMEOUT=" + h.Timeout.String()}
+	cmd.Dir = os.TempDir()
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	// Don't wait on pipes held open by children once the hook is killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s", h.Timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	if stdout.truncated {
+		return nil, fmt.Errorf("output exceeds %d bytes", maxOutputBytes)
+	}
+
+	var out []assembler.AppEntry
+	dec := json.NewDecoder(&stdout.Buffer)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid output: trailing data after the entry list")
+	}
+	return out, nil
+}
+
+// limitedBuffer keeps the first limit bytes written and drops the rest
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
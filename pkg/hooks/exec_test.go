@@ -0,0 +1,116 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExec_Transform(t *testing.T) {
+	t.Setenv("DURO_API_TOKEN", "secret")
+	entries := []assembler.AppEntry{{ID: "plex", Name: "Plex", URL: "https://plex", Groups: []string{"family"}}}
+
+	tests := []struct {
+		name     string
+		script   string
+		timeout  time.Duration
+		wantErr  string
+		wantName string
+	}{
+		{
+			name:     "passthrough",
+			script:   "cat",
+			wantName: "Plex",
+		},
+		{
+			name:     "rewrite",
+			script:   "sed 's/\"name\":\"Plex\"/\"name\":\"Movies\"/'",
+			wantName: "Movies",
+		},
+		{
+			name:     "empty environment",
+			script:   `[ -z "$DURO_API_TOKEN" ] && [ -n "$HOOK_TIMEOUT" ] || exit 1; cat`,
+			wantName: "Plex",
+		},
+		{
+			name:    "exit status with stderr",
+			script:  "echo 'no thanks' >&2; exit 3",
+			wantErr: "no thanks",
+		},
+		{
+			name:    "invalid output",
+			script:  "echo '{\"not\": \"a list\"}'",
+			wantErr: "invalid output",
+		},
+		{
+			name:    "unknown field",
+			script:  "echo '[{\"id\": \"plex\", \"nmae\": \"Plex\"}]'",
+			wantErr: "invalid output",
+		},
+		{
+			name:    "timeout",
+			script:  "exec sleep 10",
+			timeout: 200 * time.Millisecond,
+			wantErr: "timed out",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := NewExec(writeScript(t, tt.script), tt.timeout)
+			if err != nil {
+				t.Fatalf("NewExec() error = %v", err)
+			}
+			out, err := hook.Transform(context.Background(), entries)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Transform() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+			if len(out) != 1 || out[0].Name != tt.wantName {
+				t.Errorf("Transform() = %+v, want name %q", out, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestNewExec(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain")
+	if err := os.WriteFile(plain, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"relative path", "hook.sh"},
+		{"missing", filepath.Join(dir, "missing")},
+		{"directory", dir},
+		{"not executable", plain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExec(tt.path, 0); err == nil {
+				t.Errorf("NewExec(%q) succeeded, want error", tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Package iconfetch downloads the SVG icons DashboardApps reference by URL
+// (spec.iconURL) and caches them in memory, so assembly only waits on the
+// network when an icon is new or due for a refresh.
+package iconfetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"syscall"
+	"time"
+
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+	"github.com/fredericrous/duro-operator/pkg/iconpolicy"
+)
+
+const (
+	// DefaultMaxBytes bounds the size of a fetched icon
+	DefaultMaxBytes = 256 << 10
+
+	// DefaultRefresh is how long a fetched icon is used before it is fetched
+	// again
+	DefaultRefresh = 24 * time.Hour
+
+	// DefaultFetchBudget is how long an assembly waits on icon fetches
+	DefaultFetchBudget = 5 * time.Second
+
+	// attempts is how often a fetch is tried before it fails
+	attempts = 3
+
+	// retryWait is the wait between two attempts of one fetch, doubled
+	// after each attempt
+	retryWait = 500 * time.Millisecond
+
+	// fetchTimeout bounds a fetch and its retries. Fetches run in the
+	// background, so a caller that stops waiting doesn't cancel them
+	fetchTimeout = 30 * time.Second
+
+	// minBackoff is how long a URL that failed is left alone before the
+	// next fetch, doubled after each further failure up to maxBackoff
+	minBackoff = time.Minute
+	maxBackoff = time.Hour
+)
+
+// errNonPublicAddress is returned for icon servers outside AllowedNetworks
+var errNonPublicAddress = errors.New("refusing to fetch an icon from non-public address")
+
+// Fetcher fetches and caches icons. It is safe for concurrent use. When a
+// refresh fails the previously fetched icon keeps being served; a URL that
+// keeps failing is only retried after an exponentially growing backoff.
+//
+// Icons are only fetched from public addresses unless AllowedNetworks says
+// otherwise, so spec.iconURL can't be used to make the operator probe the
+// cluster network or cloud metadata endpoints.
+type Fetcher struct {
+	Client   *http.Client
+	MaxBytes int64
+	Refresh  time.Duration
+
+	// AllowedNetworks lists the loopback, private and link-local networks
+	// icons may nevertheless be fetched from, e.g. an in-cluster icon server
+	AllowedNetworks []netip.Prefix
+
+	// Clock returns the current time; time.Now if nil
+	Clock func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*cached
+}
+
+type cached struct {
+	icon      string
+	fetchedAt time.Time
+	err       error
+	failures  int
+	retryAt   time.Time
+
+	// done is closed when the fetch in flight completes; nil when idle
+	done chan struct{}
+}
+
+// NewFetcher returns a Fetcher with a bounded request timeout.
+func NewFetcher(maxBytes int64, refresh time.Duration) *Fetcher {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if refresh <= 0 {
+		refresh = DefaultRefresh
+	}
+	f := &Fetcher{
+		MaxBytes: maxBytes,
+		Refresh:  refresh,
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: f.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would be dialed instead of the icon server, defeating the
+	// address check
+	transport.Proxy = nil
+	f.Client = &http.Client{Timeout: 10 * time.Second, Transport: transport}
+	return f
+}
+
+// Resolve returns the SVG served at url, from the cache when it is fresh.
+// A missing or stale icon is fetched in the background: if ctx ends first,
+// the stale icon is returned, or else an error wrapping ctx.Err(), and the
+// fetched icon is served by a later call.
+func (f *Fetcher) Resolve(ctx context.Context, url string) (string, error) {
+	now := f.now()
+	f.mu.Lock()
+	if f.cache == nil {
+		f.cache = make(map[string]*cached)
+	}
+	c := f.cache[url]
+	if c == nil {
+		c = &cached{}
+		f.cache[url] = c
+	}
+	switch {
+	case c.icon != "" && now.Sub(c.fetchedAt) < f.Refresh:
+		defer f.mu.Unlock()
+		return c.icon, nil
+	case c.done == nil && now.Before(c.retryAt):
+		// Backing off: serve the last good icon, if any
+		defer f.mu.Unlock()
+		if c.icon != "" {
+			return c.icon, nil
+		}
+		return "", c.err
+	}
+	done := c.done
+	if done == nil {
+		done = make(chan struct{})
+		c.done = done
+		go f.refresh(url, c, done)
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case c.icon != "":
+		return c.icon, nil
+	case c.done != nil:
+		return "", fmt.Errorf("icon %s: still fetching: %w", url, ctx.Err())
+	}
+	return "", c.err
+}
+
+// refresh fetches url into c, then closes done.
+func (f *Fetcher) refresh(url string, c *cached, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	icon, err := f.fetchWithRetry(ctx, url)
+	now := f.now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	defer close(done)
+	c.done = nil
+	if err == nil {
+		*c = cached{icon: icon, fetchedAt: now}
+		return
+	}
+	c.err = err
+	c.failures++
+	wait := backoff(c.failures)
+	if after, ok := operrors.RetryAfter(err); ok {
+		wait = max(wait, after)
+	}
+	c.retryAt = now.Add(wait)
+}
+
+// Forget drops the cached icons of urls, e.g. once no app references them.
+func (f *Fetcher) Forget(urls ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, url := range urls {
+		delete(f.cache, url)
+	}
+}
+
+// fetchWithRetry tries fetching url a few times, backing off in between.
+func (f *Fetcher) fetchWithRetry(ctx context.Context, url string) (string, error) {
+	var err error
+	wait := retryWait
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+		var icon string
+		var retry bool
+		icon, retry, err = f.fetch(ctx, url)
+		if err == nil {
+			return icon, nil
+		}
+		if !retry {
+			break
+		}
+	}
+	return "", fmt.Errorf("icon %s: %w", url, err)
+}
+
+// fetch fetches url once, reporting whether a failure is worth retrying.
+func (f *Fetcher) fetch(ctx context.Context, url string) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Accept", "image/svg+xml")
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return "", !errors.Is(err, errNonPublicAddress), err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := operrors.WithRetryAfter(resp, fmt.Errorf("server returned %s", resp.Status))
+		// A Retry-After outlasts the attempts: it sets the backoff instead
+		_, hinted := operrors.RetryAfter(err)
+		retry := !hinted && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500)
+		return "", retry, err
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.MaxBytes+1))
+	if err != nil {
+		return "", true, err
+	}
+	if int64(len(data)) > f.MaxBytes {
+		return "", false, fmt.Errorf("icon is larger than %d bytes", f.MaxBytes)
+	}
+	if !bytes.Contains(data, []byte("<svg")) {
+		return "", false, fmt.Errorf("not an SVG document")
+	}
+	icon, err := iconpolicy.Sanitize(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return "", false, err
+	}
+	return icon, false, nil
+}
+
+// checkAddress refuses connections to loopback, private, link-local and
+// unspecified addresses outside AllowedNetworks. It runs once the host name
+// is resolved, so it also covers redirects and DNS names pointing inward.
+func (f *Fetcher) checkAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsUnspecified() {
+		return nil
+	}
+	for _, n := range f.AllowedNetworks {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %s", errNonPublicAddress, ip)
+}
+
+func (f *Fetcher) now() time.Time {
+	if f.Clock != nil {
+		return f.Clock()
+	}
+	return time.Now()
+}
+
+// backoff is how long to wait before retrying a URL that failed n times in
+// a row.
+func backoff(n int) time.Duration {
+	d := minBackoff
+	for i := 1; i < n && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
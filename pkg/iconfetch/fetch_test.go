@@ -0,0 +1,236 @@
+package iconfetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const svg = `<svg xmlns="http://www.w3.org/2000/svg"/>`
+
+// newTestFetcher returns a Fetcher allowed to fetch from httptest servers.
+func newTestFetcher(maxBytes int64, refresh time.Duration) *Fetcher {
+	f := NewFetcher(maxBytes, refresh)
+	f.AllowedNetworks = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+	return f
+}
+
+func TestFetcher_Resolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(n int32, w http.ResponseWriter)
+		want    string
+		wantErr string
+		calls   int32
+	}{
+		{
+			name:    "svg",
+			handler: func(_ int32, w http.ResponseWriter) { w.Write([]byte(svg + "\n")) },
+			want:    svg,
+			calls:   1,
+		},
+		{
+			name: "retries server errors",
+			handler: func(n int32, w http.ResponseWriter) {
+				if n == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(svg))
+			},
+			want:  svg,
+			calls: 2,
+		},
+		{
+			name: "backs off as long as the server asks",
+			handler: func(_ int32, w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "3600")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			wantErr: "retry after 1h0m0s",
+			calls:   1,
+		},
+		{
+			name:    "does not retry not found",
+			handler: func(_ int32, w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			wantErr: "404",
+			calls:   1,
+		},
+		{
+			name:    "too large",
+			handler: func(_ int32, w http.ResponseWriter) { w.Write([]byte("<svg>" + strings.Repeat(" ", 64) + "</svg>")) },
+			wantErr: "larger than",
+			calls:   1,
+		},
+		{
+			name:    "sanitized",
+			handler: func(_ int32, w http.ResponseWriter) { w.Write([]byte(`<svg onload="x()"><script>y()</script></svg>`)) },
+			want:    `<svg/>`,
+			calls:   1,
+		},
+		{
+			name:    "malformed svg",
+			handler: func(_ int32, w http.ResponseWriter) { w.Write([]byte(`<svg><script>y()</svg>`)) },
+			wantErr: "invalid SVG document",
+			calls:   1,
+		},
+		{
+			name:    "not an svg",
+			handler: func(_ int32, w http.ResponseWriter) { w.Write([]byte("<html></html>")) },
+			wantErr: "not an SVG",
+			calls:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				tt.handler(calls.Add(1), w)
+			}))
+			defer srv.Close()
+
+			f := newTestFetcher(64, time.Hour)
+			got, err := f.Resolve(context.Background(), srv.URL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil || got != tt.want {
+				t.Fatalf("Resolve() = %q, %v, want %q", got, err, tt.want)
+			}
+			if calls.Load() != tt.calls {
+				t.Errorf("server called %d times, want %d", calls.Load(), tt.calls)
+			}
+		})
+	}
+}
+
+func TestFetcher_Cache(t *testing.T) {
+	var calls atomic.Int32
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(svg))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := newTestFetcher(0, time.Hour)
+	f.Clock = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := f.Resolve(ctx, srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	f.Resolve(ctx, srv.URL)
+	if calls.Load() != 1 {
+		t.Fatalf("fresh icon fetched %d times, want 1", calls.Load())
+	}
+
+	// A failed refresh keeps serving the last icon and backs off
+	failing.Store(true)
+	now = now.Add(2 * time.Hour)
+	if got, err := f.Resolve(ctx, srv.URL); err != nil || got != svg {
+		t.Fatalf("failed refresh = %q, %v, want the last icon", got, err)
+	}
+	f.Resolve(ctx, srv.URL)
+	if calls.Load() != 2 {
+		t.Fatalf("server called %d times while backing off, want 2", calls.Load())
+	}
+
+	now = now.Add(minBackoff)
+	failing.Store(false)
+	f.Resolve(ctx, srv.URL)
+	if calls.Load() != 3 {
+		t.Errorf("server called %d times after the backoff, want 3", calls.Load())
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{4, 8 * time.Minute},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.failures); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestFetcher_Forget(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(svg))
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(0, time.Hour)
+	for range 2 {
+		if _, err := f.Resolve(context.Background(), srv.URL); err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+	}
+	f.Forget(srv.URL)
+	if _, err := f.Resolve(context.Background(), srv.URL); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("fetched %d times, want 2: once, then again after Forget", got)
+	}
+}
+
+func TestFetcher_NonPublicAddress(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(svg))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher(0, time.Hour)
+	if _, err := f.Resolve(context.Background(), srv.URL); err == nil || !strings.Contains(err.Error(), "non-public address") {
+		t.Errorf("Resolve() error = %v, want the loopback address refused", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("server called %d times, want 0", calls.Load())
+	}
+}
+
+func TestFetcher_Background(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.Write([]byte(svg))
+	}))
+	defer srv.Close()
+
+	f := newTestFetcher(0, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := f.Resolve(ctx, srv.URL); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Resolve() error = %v, want the fetch still running", err)
+	}
+
+	// The fetch carries on once the caller stopped waiting
+	close(release)
+	if got, err := f.Resolve(context.Background(), srv.URL); err != nil || got != svg {
+		t.Errorf("Resolve() = %q, %v, want the icon fetched in the background", got, err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package iconlib resolves icon shorthands such as "sh:plex" or
+// "mdi:server" to the URL of the SVG in a well-known icon set.
+package iconlib
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// NamePlaceholder is replaced by the icon name in a library URL template
+const NamePlaceholder = "{name}"
+
+// Defaults maps the built-in library prefixes to their URL templates
+var Defaults = Libraries{
+	// selfh.st dashboard icons
+	"sh": "https://cdn.jsdelivr.net/gh/selfhst/icons/svg/{name}.svg",
+	// Simple Icons
+	"si": "https://cdn.jsdelivr.net/npm/simple-icons/icons/{name}.svg",
+	// Material Design Icons
+	"mdi": "https://cdn.jsdelivr.net/npm/@mdi/svg/svg/{name}.svg",
+}
+
+var (
+	prefixPattern = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	namePattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+)
+
+// Libraries maps shorthand prefixes to URL templates containing
+// NamePlaceholder
+type Libraries map[string]string
+
+// With returns the libraries overridden or extended by overrides, e.g. to
+// point a prefix at a local mirror.
+func (l Libraries) With(overrides map[string]string) Libraries {
+	out := make(Libraries, len(l)+len(overrides))
+	maps.Copy(out, l)
+	maps.Copy(out, overrides)
+	return out
+}
+
+// Validate checks the prefixes and URL templates.
+func (l Libraries) Validate() error {
+	for prefix, tmpl := range l {
+		if !prefixPattern.MatchString(prefix) {
+			return fmt.Errorf("invalid icon library prefix %q", prefix)
+		}
+		if !strings.Contains(tmpl, NamePlaceholder) {
+			return fmt.Errorf("icon library %s: URL %q has no %s placeholder", prefix, tmpl, NamePlaceholder)
+		}
+		if u, err := url.Parse(strings.ReplaceAll(tmpl, NamePlaceholder, "x")); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("icon library %s: URL %q must be an absolute http(s) URL", prefix, tmpl)
+		}
+	}
+	return nil
+}
+
+// URL returns the URL a shorthand icon reference resolves to. ok is false
+// when icon is not a reference to one of the libraries (e.g. raw SVG).
+func (l Libraries) URL(icon string) (string, bool) {
+	prefix, name, found := strings.Cut(icon, ":")
+	if !found {
+		return "", false
+	}
+	tmpl, known := l[prefix]
+	if !known || !namePattern.MatchString(name) {
+		return "", false
+	}
+	return strings.ReplaceAll(tmpl, NamePlaceholder, name), true
+}
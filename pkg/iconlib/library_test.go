@@ -0,0 +1,58 @@
+package iconlib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLibraries_URL(t *testing.T) {
+	libs := Defaults.With(map[string]string{"sh": "https://mirror.lan/sh/{name}.svg"})
+	tests := []struct {
+		icon   string
+		want   string
+		wantOK bool
+	}{
+		{"sh:plex", "https://mirror.lan/sh/plex.svg", true},
+		{"mdi:server-network", "https://cdn.jsdelivr.net/npm/@mdi/svg/svg/server-network.svg", true},
+		{"si:home-assistant", "https://cdn.jsdelivr.net/npm/simple-icons/icons/home-assistant.svg", true},
+		{"<svg/>", "", false},
+		{"fa:house", "", false},
+		{"sh:../secrets", "", false},
+		{"sh:", "", false},
+		{`<svg xmlns:xlink="http://www.w3.org/1999/xlink"/>`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := libs.URL(tt.icon)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("URL(%q) = %q, %v, want %q, %v", tt.icon, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestLibraries_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		libs    Libraries
+		wantErr string
+	}{
+		{"defaults", Defaults, ""},
+		{"mirror", Defaults.With(map[string]string{"lan": "http://icons.lan/{name}.svg"}), ""},
+		{"no placeholder", Libraries{"sh": "https://mirror.lan/plex.svg"}, "placeholder"},
+		{"relative", Libraries{"sh": "/icons/{name}.svg"}, "absolute"},
+		{"bad prefix", Libraries{"My-Set": "https://mirror.lan/{name}.svg"}, "prefix"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.libs.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package iconpolicy keeps SVG icons self-contained: it finds references to
+// external resources (href/xlink:href/src attributes and CSS url()) and
+// oversized raster data URIs, and rewrites or rejects the offending icons.
+package iconpolicy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mode selects what happens to an icon violating the policy
+type Mode string
+
+const (
+	// ModeOff leaves icons untouched
+	ModeOff Mode = "off"
+	// ModeRewrite strips the offending references and keeps the icon
+	ModeRewrite Mode = "rewrite"
+	// ModeReject drops the whole icon
+	ModeReject Mode = "reject"
+)
+
+// DefaultMaxDataURIBytes bounds embedded raster images
+const DefaultMaxDataURIBytes = 32 << 10
+
+// Policy is the operator's icon policy
+type Policy struct {
+	Mode Mode
+
+	// MaxDataURIBytes is the largest raster data URI (its encoded length)
+	// an icon may embed; 0 disallows raster data URIs entirely
+	MaxDataURIBytes int
+}
+
+// Validate checks the policy mode is known.
+func (p Policy) Validate() error {
+	switch p.Mode {
+	case "", ModeOff, ModeRewrite, ModeReject:
+	default:
+		return fmt.Errorf("unknown icon policy mode %q (want off, rewrite or reject)", p.Mode)
+	}
+	if p.MaxDataURIBytes < 0 {
+		return fmt.Errorf("maxDataURIBytes must not be negative")
+	}
+	return nil
+}
+
+// Violation describes one offending reference in an icon
+type Violation struct {
+	Reference string
+	Reason    string
+}
+
+func (v Violation) String() string {
+	return v.Reason + ": " + v.Reference
+}
+
+var (
+	attrRef = regexp.MustCompile(`(?i)\s(?:xlink:href|href|src)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	cssURL  = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"]*))\s*\)`)
+)
+
+// check classifies a reference, returning a reason if it violates the policy.
+func (p Policy) check(ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	lower := strings.ToLower(ref)
+	switch {
+	case ref == "" || strings.HasPrefix(ref, "#"):
+		return "", false
+	case strings.HasPrefix(lower, "data:image/svg+xml"):
+		return "", false
+	case strings.HasPrefix(lower, "data:image/"):
+		if len(ref) > p.MaxDataURIBytes {
+			return fmt.Sprintf("raster data URI larger than %d bytes", p.MaxDataURIBytes), true
+		}
+		return "", false
+	case strings.HasPrefix(lower, "data:"):
+		return "non-image data URI", true
+	}
+	return "external reference", true
+}
+
+// Check lists the icon's violations without modifying it.
+func (p Policy) Check(icon string) []Violation {
+	var out []Violation
+	collect := func(re *regexp.Regexp) {
+		for _, m := range re.FindAllStringSubmatch(icon, -1) {
+			ref := firstNonEmpty(m[1:]...)
+			if reason, bad := p.check(ref); bad {
+				out = append(out, Violation{Reference: truncate(ref), Reason: reason})
+			}
+		}
+	}
+	collect(attrRef)
+	collect(cssURL)
+	return out
+}
+
+// Apply enforces the policy on an icon. It returns the icon to publish (empty
+// if rejected) and the violations found.
+func (p Policy) Apply(icon string) (string, []Violation) {
+	if p.Mode == "" || p.Mode == ModeOff {
+		return icon, nil
+	}
+	violations := p.Check(icon)
+	if len(violations) == 0 {
+		return icon, nil
+	}
+	if p.Mode == ModeReject {
+		return "", violations
+	}
+
+	icon = attrRef.ReplaceAllStringFunc(icon, func(attr string) string {
+		m := attrRef.FindStringSubmatch(attr)
+		if _, bad := p.check(firstNonEmpty(m[1:]...)); bad {
+			return ""
+		}
+		return attr
+	})
+	icon = cssURL.ReplaceAllStringFunc(icon, func(u string) string {
+		m := cssURL.FindStringSubmatch(u)
+		if _, bad := p.check(firstNonEmpty(m[1:]...)); bad {
+			return "none"
+		}
+		return u
+	})
+	return icon, violations
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// truncate keeps data URIs readable in logs.
+func truncate(ref string) string {
+	if len(ref) > 64 {
+		return ref[:64] + "..."
+	}
+	return ref
+}
@@ -0,0 +1,187 @@
+package iconpolicy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicy_Apply(t *testing.T) {
+	bigPNG := "data:image/png;base64," + strings.Repeat("A", 100)
+
+	tests := []struct {
+		name           string
+		policy         Policy
+		icon           string
+		want           string
+		wantViolations int
+	}{
+		{
+			name:   "off",
+			policy: Policy{Mode: ModeOff},
+			icon:   `<svg><image href="https://evil.test/x.png"/></svg>`,
+			want:   `<svg><image href="https://evil.test/x.png"/></svg>`,
+		},
+		{
+			name:   "clean icon",
+			policy: Policy{Mode: ModeReject},
+			icon:   `<svg><use xlink:href="#a"/><path fill="url(#grad)"/></svg>`,
+			want:   `<svg><use xlink:href="#a"/><path fill="url(#grad)"/></svg>`,
+		},
+		{
+			name:           "rewrite external href",
+			policy:         Policy{Mode: ModeRewrite},
+			icon:           `<svg><image xlink:href="https://evil.test/x.png" width="1"/></svg>`,
+			want:           `<svg><image width="1"/></svg>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "rewrite css url",
+			policy:         Policy{Mode: ModeRewrite},
+			icon:           `<svg><rect style="fill: url('//cdn.test/p.svg')"/></svg>`,
+			want:           `<svg><rect style="fill: none"/></svg>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "reject external href",
+			policy:         Policy{Mode: ModeReject},
+			icon:           `<svg><image href='http://tracker.test/p.gif'/></svg>`,
+			want:           "",
+			wantViolations: 1,
+		},
+		{
+			name:   "small raster data uri",
+			policy: Policy{Mode: ModeReject, MaxDataURIBytes: 1024},
+			icon:   `<svg><image href="` + bigPNG + `"/></svg>`,
+			want:   `<svg><image href="` + bigPNG + `"/></svg>`,
+		},
+		{
+			name:           "oversized raster data uri",
+			policy:         Policy{Mode: ModeRewrite, MaxDataURIBytes: 64},
+			icon:           `<svg><image href="` + bigPNG + `"/></svg>`,
+			want:           `<svg><image/></svg>`,
+			wantViolations: 1,
+		},
+		{
+			name:           "non-image data uri",
+			policy:         Policy{Mode: ModeReject, MaxDataURIBytes: 1024},
+			icon:           `<svg><a href="data:text/html,hi"/></svg>`,
+			want:           "",
+			wantViolations: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, violations := tt.policy.Apply(tt.icon)
+			if got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+			if len(violations) != tt.wantViolations {
+				t.Errorf("violations = %v, want %d", violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	if err := (Policy{Mode: "strict"}).Validate(); err == nil {
+		t.Error("unknown mode accepted")
+	}
+	if err := (Policy{Mode: ModeRewrite, MaxDataURIBytes: -1}).Validate(); err == nil {
+		t.Error("negative size accepted")
+	}
+	if err := (Policy{}).Validate(); err != nil {
+		t.Errorf("zero policy: %v", err)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name    string
+		icon    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "clean icon",
+			icon: `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><use xlink:href="#a"/><path d="M0 0"/></svg>`,
+			want: `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><use xlink:href="#a"/><path d="M0 0"/></svg>`,
+		},
+		{
+			name: "script",
+			icon: `<?xml version="1.0"?><svg><script type="text/javascript">alert("/>")</script><path/></svg>`,
+			want: `<svg><path/></svg>`,
+		},
+		{
+			name: "foreignObject with a nested script",
+			icon: `<svg><foreignObject><script>x()</script><iframe src="https://evil.test"></iframe></foreignObject><path/></svg>`,
+			want: `<svg><path/></svg>`,
+		},
+		{
+			name: "elements out of the allowlist",
+			icon: `<svg><SCRIPT>x()</SCRIPT><set attributeName="href" to="javascript:x()"/><text>a &lt; b</text></svg>`,
+			want: `<svg><text>a &lt; b</text></svg>`,
+		},
+		{
+			name: "event handlers",
+			icon: `<svg onload="x()"><path OnClick='y()' onmouseover="z" d="M0 0"/></svg>`,
+			want: `<svg><path d="M0 0"/></svg>`,
+		},
+		{
+			name: "javascript link",
+			icon: `<svg><a href=" javascript:x()"><path/></a></svg>`,
+			want: `<svg><a><path/></a></svg>`,
+		},
+		{
+			name: "entity-encoded javascript link",
+			icon: `<svg><a href="&#106;avascript:x()"><path/></a><use xlink:href="java&#x09;script:y()"/></svg>`,
+			want: `<svg><a><path/></a><use/></svg>`,
+		},
+		{
+			name: "links kept",
+			icon: `<svg><a href="https://example.com/?a=1&amp;b=2"><image href="data:image/png;base64,AAAA"/></a></svg>`,
+			want: `<svg><a href="https://example.com/?a=1&amp;b=2"><image href="data:image/png;base64,AAAA"/></a></svg>`,
+		},
+		{
+			name:    "nested script tags",
+			icon:    `<svg><scr<script></script>ipt>alert(1)</script></svg>`,
+			wantErr: "invalid SVG document",
+		},
+		{
+			name:    "unclosed script",
+			icon:    `<svg><script>alert(1)</svg>`,
+			wantErr: "invalid SVG document",
+		},
+		{
+			name:    "unclosed document",
+			icon:    `<svg><script>alert(1)`,
+			wantErr: "invalid SVG document",
+		},
+		{
+			name:    "unquoted javascript link",
+			icon:    `<svg><a href=javascript:alert(1)><path/></a></svg>`,
+			wantErr: "invalid SVG document",
+		},
+		{
+			name:    "not an svg",
+			icon:    `<html><script>alert(1)</script></html>`,
+			wantErr: "not an SVG document",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Sanitize(tt.icon)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Sanitize() = %q, %v, want error %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sanitize() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Sanitize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package iconpolicy
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// svgElements are the elements kept by Sanitize: shapes, text, paint
+// servers, clipping, masking and filters. Scripts, foreignObject (which may
+// embed HTML) and animations (which may rewrite links) are left out.
+var svgElements = setOf(
+	"svg", "g", "defs", "symbol", "use", "title", "desc", "a", "switch",
+	"path", "rect", "circle", "ellipse", "line", "polyline", "polygon", "image",
+	"text", "tspan", "textPath", "style", "marker", "pattern",
+	"linearGradient", "radialGradient", "stop", "clipPath", "mask",
+	"filter", "feBlend", "feColorMatrix", "feComponentTransfer", "feComposite",
+	"feConvolveMatrix", "feDiffuseLighting", "feDisplacementMap", "feDistantLight",
+	"feDropShadow", "feFlood", "feFuncA", "feFuncB", "feFuncG", "feFuncR",
+	"feGaussianBlur", "feImage", "feMerge", "feMergeNode", "feMorphology",
+	"feOffset", "fePointLight", "feSpecularLighting", "feSpotLight", "feTile",
+	"feTurbulence",
+)
+
+// svgAttributes are the attributes kept by Sanitize: geometry,
+// presentation, text and filter attributes and links, the latter checked by
+// safeLink. Event handlers (onload, onclick...) are left out.
+var svgAttributes = setOf(
+	"xmlns", "xlink:href", "href", "xml:space", "xml:lang", "id", "class", "style", "lang",
+	"version", "baseProfile", "viewBox", "preserveAspectRatio", "width", "height", "x", "y",
+	"x1", "y1", "x2", "y2", "cx", "cy", "r", "rx", "ry", "fx", "fy", "fr", "d", "points",
+	"pathLength", "transform", "role", "aria-label", "aria-hidden", "focusable", "target",
+	"fill", "fill-opacity", "fill-rule", "stroke", "stroke-width", "stroke-linecap",
+	"stroke-linejoin", "stroke-miterlimit", "stroke-dasharray", "stroke-dashoffset",
+	"stroke-opacity", "opacity", "color", "display", "visibility", "overflow",
+	"clip-path", "clip-rule", "clipPathUnits", "mask", "maskUnits", "maskContentUnits",
+	"filter", "filterUnits", "primitiveUnits", "vector-effect", "shape-rendering",
+	"color-interpolation", "color-interpolation-filters", "paint-order", "mix-blend-mode",
+	"offset", "stop-color", "stop-opacity", "gradientUnits", "gradientTransform",
+	"spreadMethod", "patternUnits", "patternContentUnits", "patternTransform",
+	"marker-start", "marker-mid", "marker-end", "markerWidth", "markerHeight",
+	"markerUnits", "refX", "refY", "orient",
+	"font-family", "font-size", "font-weight", "font-style", "font-variant",
+	"text-anchor", "dominant-baseline", "alignment-baseline", "baseline-shift",
+	"letter-spacing", "word-spacing", "text-decoration", "writing-mode", "dx", "dy",
+	"rotate", "textLength", "lengthAdjust", "startOffset", "method", "spacing", "side",
+	"in", "in2", "result", "mode", "type", "values", "tableValues", "slope", "intercept",
+	"amplitude", "exponent", "operator", "k1", "k2", "k3", "k4", "stdDeviation",
+	"edgeMode", "flood-color", "flood-opacity", "lighting-color", "scale",
+	"xChannelSelector", "yChannelSelector", "radius", "baseFrequency", "numOctaves",
+	"seed", "stitchTiles", "order", "kernelMatrix", "divisor", "bias", "targetX",
+	"targetY", "preserveAlpha", "surfaceScale", "diffuseConstant", "specularConstant",
+	"specularExponent", "kernelUnitLength", "azimuth", "elevation", "z", "pointsAtX",
+	"pointsAtY", "pointsAtZ", "limitingConeAngle", "requiredFeatures", "systemLanguage",
+)
+
+// linkScheme matches the scheme of a link, once stripped of the whitespace
+// and control characters browsers ignore in it
+var linkScheme = regexp.MustCompile(`^([a-z][a-z0-9+.-]*):`)
+
+// Sanitize keeps the allowlisted SVG elements and attributes of an icon
+// (see svgElements and svgAttributes) and drops links with a scheme other
+// than http, https or an image data URI, so the icon carries no script,
+// foreignObject, event handler or javascript: link. The icon is parsed as
+// XML and written back, so nothing the browser would read differently than
+// the parser survives; icons that are not well-formed SVG documents are
+// refused. It is applied to every icon fetched by URL, whatever the policy
+// mode, since those are not reviewed the way spec.icon is.
+func Sanitize(icon string) (string, error) {
+	d := xml.NewDecoder(strings.NewReader(icon))
+	var (
+		out   bytes.Buffer
+		open  []string
+		skip  int  // depth inside a dropped element
+		unend bool // a start tag waits for its closing bracket
+		root  bool
+	)
+	closeStart := func(empty bool) {
+		if !unend {
+			return
+		}
+		if empty {
+			out.WriteString("/>")
+		} else {
+			out.WriteString(">")
+		}
+		unend = false
+	}
+	for {
+		// Raw tokens keep the namespace prefixes as written; entities are
+		// decoded, and start and end tags are matched below
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid SVG document: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := qualifiedName(t.Name)
+			if len(open) == 0 {
+				if root || name != "svg" {
+					return "", errors.New("not an SVG document")
+				}
+				root = true
+			}
+			open = append(open, name)
+			if skip > 0 || !svgElements[name] {
+				skip++
+				continue
+			}
+			closeStart(false)
+			out.WriteString("<" + name)
+			for _, attr := range t.Attr {
+				attrName := qualifiedName(attr.Name)
+				if !svgAttributes[attrName] && attr.Name.Space != "xmlns" {
+					continue
+				}
+				if (attrName == "href" || attrName == "xlink:href") && !safeLink(attr.Value) {
+					continue
+				}
+				out.WriteString(" " + attrName + `="`)
+				_ = xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteString(`"`)
+			}
+			unend = true
+		case xml.EndElement:
+			name := qualifiedName(t.Name)
+			if len(open) == 0 || open[len(open)-1] != name {
+				return "", fmt.Errorf("invalid SVG document: unexpected end element </%s>", name)
+			}
+			open = open[:len(open)-1]
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if unend {
+				closeStart(true)
+				continue
+			}
+			out.WriteString("</" + name + ">")
+		case xml.CharData:
+			if skip > 0 || len(open) == 0 {
+				continue
+			}
+			closeStart(false)
+			_ = xml.EscapeText(&out, t)
+		}
+		// Comments, processing instructions and directives are dropped
+	}
+	if len(open) > 0 {
+		return "", fmt.Errorf("invalid SVG document: element <%s> is not closed", open[len(open)-1])
+	}
+	if !root {
+		return "", errors.New("not an SVG document")
+	}
+	return out.String(), nil
+}
+
+// safeLink reports whether a link may be kept: a fragment, a relative
+// reference, an http(s) URL or an image data URI.
+func safeLink(link string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.ToLower(link))
+	m := linkScheme.FindStringSubmatch(cleaned)
+	if m == nil {
+		return true
+	}
+	switch m[1] {
+	case "http", "https":
+		return true
+	case "data":
+		return strings.HasPrefix(cleaned, "data:image/")
+	}
+	return false
+}
+
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+func setOf(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,120 @@
+// Package ingress turns dashboard.homelab.io/* annotations on Ingresses into
+// DashboardApp specs, taking the app URL from the Ingress rules.
+package ingress
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/workload"
+)
+
+const (
+	// AnnotationPrefix prefixes the Ingress annotations describing the app:
+	// enabled, name, url, category, icon, groups (comma-separated) and
+	// priority
+	AnnotationPrefix = "dashboard.homelab.io/"
+
+	// EnabledAnnotation set to "true" opts an Ingress in
+	EnabledAnnotation = AnnotationPrefix + "enabled"
+
+	// SourceIngress is the source label value of DashboardApps synthesized
+	// from Ingresses
+	SourceIngress = "ingress"
+)
+
+// AppSpec builds the DashboardApp spec declared by the annotations of ing.
+// The name defaults to the Ingress name, the URL to the host and path of its
+// first rule (https if the host is covered by its TLS section), the category
+// and icon to those of workload discovery. It returns false if the Ingress
+// is not enabled, and an error if it is but has no URL or groups.
+func AppSpec(ing *networkingv1.Ingress) (*dashboardv1alpha1.DashboardAppSpec, bool, error) {
+	fields := map[string]string{}
+	for k, v := range ing.Annotations {
+		if key, ok := strings.CutPrefix(k, AnnotationPrefix); ok {
+			fields[key] = v
+		}
+	}
+	if enabled, _ := strconv.ParseBool(fields["enabled"]); !enabled {
+		return nil, false, nil
+	}
+
+	spec := &dashboardv1alpha1.DashboardAppSpec{
+		Name:     ing.Name,
+		URL:      fields["url"],
+		Category: workload.DefaultCategory,
+		Icon:     workload.DefaultIcon,
+		Priority: dashboardv1alpha1.DefaultPriority,
+	}
+	if spec.URL == "" {
+		spec.URL = ruleURL(ing)
+	}
+	if v := fields["name"]; v != "" {
+		spec.Name = v
+	}
+	if v := fields["category"]; v != "" {
+		spec.Category = v
+	}
+	if v := fields["icon"]; v != "" {
+		spec.Icon = v
+	}
+	for _, g := range strings.Split(fields["groups"], ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			spec.Groups = append(spec.Groups, g)
+		}
+	}
+	if p := fields["priority"]; p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid %spriority %q", AnnotationPrefix, p)
+		}
+		spec.Priority = n
+	}
+	if spec.URL == "" {
+		return nil, true, fmt.Errorf("%surl is required when no rule has a host", AnnotationPrefix)
+	}
+	if len(spec.Groups) == 0 {
+		return nil, true, fmt.Errorf("%sgroups is required", AnnotationPrefix)
+	}
+	return spec, true, nil
+}
+
+// ruleURL returns the URL of the first Ingress rule with a host, or "".
+func ruleURL(ing *networkingv1.Ingress) string {
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host == "" || strings.HasPrefix(rule.Host, "*") {
+			continue
+		}
+		scheme := "http"
+		if coveredByTLS(ing, rule.Host) {
+			scheme = "https"
+		}
+		path := ""
+		if rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+			path = strings.TrimSuffix(rule.HTTP.Paths[0].Path, "/")
+		}
+		return scheme + "://" + rule.Host + path
+	}
+	return ""
+}
+
+// coveredByTLS reports whether a TLS section of ing serves host, directly or
+// through a wildcard.
+func coveredByTLS(ing *networkingv1.Ingress, host string) bool {
+	for _, tls := range ing.Spec.TLS {
+		for _, h := range tls.Hosts {
+			if h == host {
+				return true
+			}
+			if suffix, ok := strings.CutPrefix(h, "*"); ok && strings.HasSuffix(host, suffix) &&
+				!strings.Contains(strings.TrimSuffix(host, suffix), ".") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package ingress
+
+import (
+	"slices"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAppSpec(t *testing.T) {
+	rules := []networkingv1.IngressRule{{
+		Host: "grafana.example.test",
+		IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+			Paths: []networkingv1.HTTPIngressPath{{Path: "/"}},
+		}},
+	}}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		spec        networkingv1.IngressSpec
+		wantEnabled bool
+		wantErr     bool
+		wantName    string
+		wantURL     string
+		wantGroups  []string
+	}{
+		{
+			name:        "not enabled",
+			annotations: map[string]string{"dashboard.homelab.io/groups": "admins"},
+			spec:        networkingv1.IngressSpec{Rules: rules},
+		},
+		{
+			name:        "url from the rule",
+			annotations: map[string]string{EnabledAnnotation: "true", "dashboard.homelab.io/groups": "admins, family"},
+			spec:        networkingv1.IngressSpec{Rules: rules},
+			wantEnabled: true,
+			wantName:    "grafana",
+			wantURL:     "http://grafana.example.test",
+			wantGroups:  []string{"admins", "family"},
+		},
+		{
+			name:        "https under a wildcard certificate",
+			annotations: map[string]string{EnabledAnnotation: "true", "dashboard.homelab.io/groups": "admins", "dashboard.homelab.io/name": "Grafana"},
+			spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"*.example.test"}}},
+				Rules: []networkingv1.IngressRule{{Host: "grafana.example.test", IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{Path: "/grafana/"}}},
+				}}},
+			},
+			wantEnabled: true,
+			wantName:    "Grafana",
+			wantURL:     "https://grafana.example.test/grafana",
+			wantGroups:  []string{"admins"},
+		},
+		{
+			name: "url annotation wins",
+			annotations: map[string]string{EnabledAnnotation: "true", "dashboard.homelab.io/groups": "admins",
+				"dashboard.homelab.io/url": "https://grafana.lan"},
+			spec:        networkingv1.IngressSpec{Rules: rules},
+			wantEnabled: true,
+			wantName:    "grafana",
+			wantURL:     "https://grafana.lan",
+			wantGroups:  []string{"admins"},
+		},
+		{
+			name:        "no host",
+			annotations: map[string]string{EnabledAnnotation: "true", "dashboard.homelab.io/groups": "admins"},
+			wantEnabled: true,
+			wantErr:     true,
+		},
+		{
+			name:        "missing groups",
+			annotations: map[string]string{EnabledAnnotation: "true"},
+			spec:        networkingv1.IngressSpec{Rules: rules},
+			wantEnabled: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring", Annotations: tt.annotations},
+				Spec:       tt.spec,
+			}
+			spec, enabled, err := AppSpec(ing)
+			if enabled != tt.wantEnabled {
+				t.Fatalf("AppSpec() enabled = %v, want %v", enabled, tt.wantEnabled)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AppSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if spec == nil {
+				return
+			}
+			if spec.Name != tt.wantName || spec.URL != tt.wantURL {
+				t.Errorf("spec = %+v", spec)
+			}
+			if !slices.Equal(spec.Groups, tt.wantGroups) {
+				t.Errorf("groups = %v, want %v", spec.Groups, tt.wantGroups)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package linkcheck probes dashboard links to find the ones that are dead,
+// independently of the health checks apps may configure.
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is how many links are probed at once
+const DefaultConcurrency = 8
+
+// Link is a dashboard link to probe
+type Link struct {
+	// App is the namespace/name of the DashboardApp listing the link
+	App string
+	// URL is the link
+	URL string
+}
+
+// Dead is a link that could not be reached
+type Dead struct {
+	Link
+	// Reason is the error or HTTP status the probe got
+	Reason string
+}
+
+// Prober probes links with HEAD requests, retried as GET for servers not
+// supporting HEAD. A link is dead when the request fails or the server
+// answers 404, 410 or 5xx; any other answer, including a login prompt,
+// shows something is there.
+type Prober struct {
+	Client *http.Client
+	// Concurrency bounds the probes in flight (DefaultConcurrency if 0)
+	Concurrency int
+}
+
+// NewProber returns a Prober whose requests time out after timeout.
+func NewProber(timeout time.Duration) *Prober {
+	return &Prober{Client: &http.Client{Timeout: timeout}, Concurrency: DefaultConcurrency}
+}
+
+// Probe probes links and returns the dead ones, in the order of links.
+func (p *Prober) Probe(ctx context.Context, links []Link) []Dead {
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	reasons := make([]string, len(links))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, link := range links {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			reasons[i] = p.probe(ctx, link.URL)
+		}()
+	}
+	wg.Wait()
+
+	var dead []Dead
+	for i, reason := range reasons {
+		if reason != "" {
+			dead = append(dead, Dead{Link: links[i], Reason: reason})
+		}
+	}
+	return dead
+}
+
+// probe returns why url is dead, or "" if it is alive.
+func (p *Prober) probe(ctx context.Context, url string) string {
+	status, err := p.request(ctx, http.MethodHead, url)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = p.request(ctx, http.MethodGet, url)
+	}
+	switch {
+	case err != nil:
+		return err.Error()
+	case status == http.StatusNotFound, status == http.StatusGone, status >= 500:
+		return fmt.Sprintf("HTTP %d", status)
+	}
+	return ""
+}
+
+func (p *Prober) request(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "duro-operator-linkcheck")
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,55 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/login":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/down":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	links := []Link{
+		{App: "media/plex", URL: srv.URL + "/ok"},
+		{App: "media/sonarr", URL: srv.URL + "/login"},
+		{App: "media/radarr", URL: srv.URL + "/no-head"},
+		{App: "monitoring/grafana", URL: srv.URL + "/down"},
+		{App: "monitoring/loki", URL: srv.URL + "/gone"},
+		{App: "ai/openwebui", URL: "http://127.0.0.1:1/unreachable"},
+	}
+	dead := (&Prober{Client: &http.Client{Timeout: 5 * time.Second}, Concurrency: 2}).Probe(context.Background(), links)
+
+	want := map[string]string{"monitoring/grafana": "HTTP 502", "monitoring/loki": "HTTP 404", "ai/openwebui": ""}
+	if len(dead) != len(want) {
+		t.Fatalf("dead = %+v, want %d links", dead, len(want))
+	}
+	for _, d := range dead {
+		reason, ok := want[d.App]
+		if !ok {
+			t.Errorf("%s reported dead: %s", d.App, d.Reason)
+		}
+		if reason != "" && d.Reason != reason {
+			t.Errorf("%s reason = %q, want %q", d.App, d.Reason, reason)
+		}
+	}
+	if dead[0].App != "monitoring/grafana" {
+		t.Errorf("dead links are not in input order: %+v", dead)
+	}
+}
@@ -0,0 +1,116 @@
+// Package logging adds per-component log levels and sampling on top of the
+// zap logger controller-runtime sets up.
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ParseLevel parses a level name (debug, info, warn, error) or a logr
+// verbosity (1 is debug, 2 is more verbose and so on).
+func ParseLevel(s string) (zapcore.Level, error) {
+	if v, err := strconv.Atoi(s); err == nil {
+		if v < 0 {
+			return 0, fmt.Errorf("verbosity %d must not be negative", v)
+		}
+		return zapcore.Level(-v), nil
+	}
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(strings.ToLower(s))); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return l, nil
+}
+
+// ParseComponentLevels parses overrides of the form
+// "assembler=debug,apiserver=warn". Components are matched against the
+// segments of logger names (e.g. controllers.DashboardApp.assembler),
+// case-insensitively.
+func ParseComponentLevels(s string) (map[string]zapcore.Level, error) {
+	levels := make(map[string]zapcore.Level)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		component, level, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(component) == "" {
+			return nil, fmt.Errorf("invalid component log level %q, want component=level", item)
+		}
+		l, err := ParseLevel(strings.TrimSpace(level))
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", component, err)
+		}
+		levels[strings.ToLower(strings.TrimSpace(component))] = l
+	}
+	return levels, nil
+}
+
+// MinLevel returns the most verbose of def and the overrides, i.e. the level
+// the underlying core must be built with for every override to take effect.
+func MinLevel(def zapcore.Level, overrides map[string]zapcore.Level) zapcore.Level {
+	lowest := def
+	for _, l := range overrides {
+		lowest = min(lowest, l)
+	}
+	return lowest
+}
+
+// componentCore drops entries below the level of their component
+type componentCore struct {
+	zapcore.Core
+	def       zapcore.Level
+	overrides map[string]zapcore.Level
+}
+
+// NewComponentCore wraps core so entries are filtered by the level of their
+// logger's component: the deepest segment of the logger name with an
+// override, else def. core must be enabled down to MinLevel.
+func NewComponentCore(core zapcore.Core, def zapcore.Level, overrides map[string]zapcore.Level) zapcore.Core {
+	if len(overrides) == 0 {
+		return core
+	}
+	return &componentCore{Core: core, def: def, overrides: overrides}
+}
+
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &componentCore{Core: c.Core.With(fields), def: c.def, overrides: c.overrides}
+}
+
+func (c *componentCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levelFor(e.LoggerName).Enabled(e.Level) {
+		return ce
+	}
+	return c.Core.Check(e, ce)
+}
+
+func (c *componentCore) levelFor(name string) zapcore.Level {
+	level := c.def
+	for _, segment := range strings.Split(strings.ToLower(name), ".") {
+		if l, ok := c.overrides[segment]; ok {
+			level = l
+		}
+	}
+	return level
+}
+
+// Sampling configures zap's sampler: per second and message, the first
+// Initial entries are logged, then every Thereafter-th. Zero Initial
+// disables sampling.
+type Sampling struct {
+	Initial    int
+	Thereafter int
+}
+
+// Wrap applies the sampling to core.
+func (s Sampling) Wrap(core zapcore.Core) zapcore.Core {
+	if s.Initial <= 0 {
+		return core
+	}
+	return zapcore.NewSamplerWithOptions(core, time.Second, s.Initial, max(s.Thereafter, 1))
+}
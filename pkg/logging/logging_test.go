@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestParseComponentLevels(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]zapcore.Level
+		wantErr bool
+	}{
+		{"", map[string]zapcore.Level{}, false},
+		{"assembler=debug, APIServer=warn", map[string]zapcore.Level{"assembler": zapcore.DebugLevel, "apiserver": zapcore.WarnLevel}, false},
+		{"assembler=2", map[string]zapcore.Level{"assembler": zapcore.Level(-2)}, false},
+		{"assembler", nil, true},
+		{"assembler=loud", nil, true},
+		{"=debug", nil, true},
+		{"assembler=-1", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseComponentLevels(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseComponentLevels(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseComponentLevels(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("level[%s] = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestComponentCore(t *testing.T) {
+	overrides := map[string]zapcore.Level{"assembler": zapcore.DebugLevel, "apiserver": zapcore.ErrorLevel}
+	obs, logs := observer.New(MinLevel(zapcore.InfoLevel, overrides))
+	log := zap.New(NewComponentCore(obs, zapcore.InfoLevel, overrides))
+
+	log.Named("controllers").Named("DashboardApp").Debug("controller debug")
+	log.Named("controllers").Named("DashboardApp").Named("assembler").Debug("assembler debug")
+	log.Named("apiserver").Info("apiserver info")
+	log.Named("apiserver").Error("apiserver error")
+	log.Named("setup").With(zap.String("k", "v")).Info("setup info")
+
+	var got []string
+	for _, e := range logs.All() {
+		got = append(got, e.Message)
+	}
+	want := []string{"assembler debug", "apiserver error", "setup info"}
+	if len(got) != len(want) {
+		t.Fatalf("logged %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSampling(t *testing.T) {
+	obs, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(Sampling{Initial: 2, Thereafter: 5}.Wrap(obs))
+	for range 12 {
+		log.Info("noisy")
+	}
+	// first 2, then every 5th of the remaining 10
+	if n := logs.Len(); n != 4 {
+		t.Errorf("logged %d entries, want 4", n)
+	}
+}
@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// AppsTotal is the number of entries in the last written output, by
+	// category
+	AppsTotal = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "duro_operator_apps_total",
+			Help: "Number of dashboard apps in the last written output, by category",
+		},
+		[]string{"category"},
+	)
+
+	// AssemblyDuration is how long assembling the catalog takes
+	AssemblyDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "duro_operator_assembly_duration_seconds",
+			Help:    "Time taken to assemble the apps catalog",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
+	// ConfigMapUpdates counts writes of the duro apps output; writes
+	// skipped on a hash match are not counted
+	ConfigMapUpdates = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "duro_operator_configmap_updates_total",
+			Help: "Number of writes of the duro apps output",
+		},
+	)
+
+	// SyncErrors counts failed syncs, by the step that failed (the reason of
+	// the warning Event)
+	SyncErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "duro_operator_sync_errors_total",
+			Help: "Number of failed syncs, by type of failure",
+		},
+		[]string{"type"},
+	)
+
+	// PriorityCollisions counts entries sharing a priority with another entry
+	// of the same category (only populated when priority analysis is enabled)
+	PriorityCollisions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "duro_operator_priority_collisions",
+			Help: "Number of dashboard apps sharing their priority with another app in the same category",
+		},
+		[]string{"category"},
+	)
+
+	// InstanceInfo identifies the operator instance and version exposing
+	// the metrics; always 1
+	InstanceInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "duro_operator_instance_info",
+			Help: "Operator instance and version, always 1",
+		},
+		[]string{"instance", "version"},
+	)
+
+	// ReplicaSkew is 1 for each kind of difference (version, config)
+	// between this replica and the leader
+	ReplicaSkew = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "duro_operator_replica_skew",
+			Help: "1 if this replica runs a different version or config than the leader, by kind",
+		},
+		[]string{"kind"},
+	)
+
+	// NonConformingApps is the number of DashboardApps breaking a rule at
+	// the last validation sweep
+	NonConformingApps = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "duro_operator_nonconforming_apps",
+			Help: "Number of DashboardApps breaking a current rule at the last validation sweep",
+		},
+	)
+
+	// ConformanceDrift is 1 while duro does not serve the last written
+	// document (only populated when conformance checks are enabled)
+	ConformanceDrift = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "duro_operator_conformance_drift",
+			Help: "1 if duro does not serve the last written apps document, 0 if it does",
+		},
+	)
+
+	// ConformanceLag is how long the last written document took to be
+	// served, or has not been served for so far
+	ConformanceLag = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "duro_operator_conformance_lag_seconds",
+			Help: "Seconds between writing the apps document and duro serving it (growing while drift persists)",
+		},
+	)
+
+	// AppHealthStatus is 1 for the published health state of each listed
+	// app and 0 for the other states; apps without health are not exported
+	AppHealthStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "duro_app_health_status",
+			Help: "Published health of each dashboard app: 1 for its current state, 0 for the others",
+		},
+		[]string{"namespace", "app", "state"},
+	)
+
+	// ReconcileDeadlineRatio is the share of the reconcile timeout each
+	// reconcile used, to tune the timeout against actual durations
+	ReconcileDeadlineRatio = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "duro_operator_reconcile_deadline_ratio",
+			Help:    "Reconcile duration as a fraction of the reconcile timeout",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 0.75, 0.9, 1},
+		},
+	)
+
+	// SlowReconciles counts reconciles exceeding the slow reconcile
+	// threshold (only populated when a threshold is configured)
+	SlowReconciles = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "duro_operator_slow_reconciles_total",
+			Help: "Number of reconciles taking longer than the slow reconcile threshold",
+		},
+	)
+
+	// OutputDriftRepairs counts writes repairing output documents edited or
+	// removed by someone else
+	OutputDriftRepairs = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "duro_operator_output_drift_repairs_total",
+			Help: "Number of writes repairing output documents edited or removed outside the operator",
+		},
+	)
+
+	// StatusUpdatesSkipped counts DashboardApp status updates skipped
+	// because neither the app nor its entry or findings changed since its
+	// status was last brought up to date
+	StatusUpdatesSkipped = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "duro_operator_status_updates_skipped_total",
+			Help: "Number of DashboardApp status updates skipped because neither the app nor its assembly outcome changed",
+		},
+	)
+
+	// DeadLinks is the number of dashboard links found dead by the last
+	// dead-link sweep (only populated when the sweep is enabled)
+	DeadLinks = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "duro_operator_dead_links",
+			Help: "Number of dashboard links found dead by the last dead-link sweep",
+		},
+	)
+
+	// ConformanceCheckErrors counts conformance checks that could not fetch
+	// or decode the served document
+	ConformanceCheckErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "duro_operator_conformance_check_errors_total",
+			Help: "Number of conformance checks that failed to fetch or decode the document served by duro",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		AppsTotal,
+		AssemblyDuration,
+		ConfigMapUpdates,
+		SyncErrors,
+		PriorityCollisions,
+		InstanceInfo,
+		ReplicaSkew,
+		NonConformingApps,
+		ConformanceDrift,
+		ConformanceLag,
+		ConformanceCheckErrors,
+		DeadLinks,
+		AppHealthStatus,
+		ReconcileDeadlineRatio,
+		SlowReconciles,
+		OutputDriftRepairs,
+		StatusUpdatesSkipped,
+	)
+}
@@ -0,0 +1,176 @@
+// Package rbac generates the RBAC manifests an operator instance needs for
+// the features it has enabled, as a narrower alternative to the
+// manager-role ClusterRole, which grants every feature's rights.
+package rbac
+
+import (
+	"bytes"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	"github.com/fredericrous/duro-operator/pkg/alerting"
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+// Subject is the service account the operator runs as
+type Subject struct {
+	// Name of the service account, also prefixing the generated objects
+	Name string
+
+	// Namespace the operator is deployed in, where leader election leases
+	// are kept unless Config.LeaderElectionNamespace says otherwise
+	Namespace string
+}
+
+var (
+	read  = []string{"get", "list", "watch"}
+	write = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+)
+
+// Manifests returns the ClusterRole, Roles and their bindings granting sa
+// what an operator running with cfg needs. Rights over DashboardApps and the
+// other dashboard.homelab.io resources are cluster-wide, since apps live in
+// any namespace; ConfigMaps, leases and the creation of registered or
+// dispatched apps are confined to the namespaces the operator uses them in.
+func Manifests(cfg *config.OperatorConfig, sa Subject) []runtime.Object {
+	group := dashboardv1alpha1.GroupVersion.Group
+	appVerbs := []string{"get", "list", "watch", "update", "patch", "delete"}
+	if discovers(cfg) {
+		appVerbs = append(appVerbs, "create")
+	}
+
+	cluster := []rbacv1.PolicyRule{
+		{APIGroups: []string{group}, Resources: []string{"dashboardapps"}, Verbs: appVerbs},
+		{APIGroups: []string{group}, Resources: []string{"dashboardapps/status"}, Verbs: []string{"get", "update", "patch"}},
+		{APIGroups: []string{group}, Resources: []string{"dashboardapps/finalizers"}, Verbs: []string{"update"}},
+		{APIGroups: []string{group}, Resources: []string{"dashboardbookmarks"}, Verbs: read},
+		{APIGroups: []string{group}, Resources: []string{"dashboardcategories"}, Verbs: read},
+		{APIGroups: []string{group}, Resources: []string{"operatoroverviews"}, Verbs: []string{"get", "list", "watch", "create", "update"}},
+		{APIGroups: []string{group}, Resources: []string{"operatoroverviews/status"}, Verbs: []string{"get", "update"}},
+		{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "patch"}},
+		// Cluster facts exposed to spec.condition
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: read},
+		{APIGroups: []string{"apiextensions.k8s.io"}, Resources: []string{"customresourcedefinitions"}, Verbs: read},
+	}
+	if cfg.IconConfigMap != "" || cfg.Dashboards {
+		// Icon ConfigMaps are written next to the apps, Dashboards to any
+		// namespace
+		cluster = append(cluster, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: write})
+	}
+	if cfg.Dashboards {
+		cluster = append(cluster,
+			rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{"dashboards"}, Verbs: []string{"get", "list", "watch", "patch"}},
+			rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{"dashboards/status"}, Verbs: []string{"get", "update"}},
+			rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{"dashboards/finalizers"}, Verbs: []string{"update"}})
+	}
+	if cfg.RBACGroups {
+		cluster = append(cluster, rbacv1.PolicyRule{APIGroups: []string{rbacv1.GroupName}, Resources: []string{"rolebindings"}, Verbs: read})
+	}
+	if cfg.Dashboards && cfg.WritesSecrets() {
+		// Dashboards may choose a Secret as their target
+		cluster = append(cluster, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: write})
+	} else if cfg.HelmDiscovery {
+		cluster = append(cluster, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: read})
+	}
+	if cfg.WorkloadDiscovery {
+		cluster = append(cluster, rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments", "statefulsets", "daemonsets"}, Verbs: read})
+	}
+	if cfg.IngressDiscovery {
+		cluster = append(cluster, rbacv1.PolicyRule{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingresses"}, Verbs: read})
+	}
+	if cfg.AlertRules {
+		cluster = append(cluster, rbacv1.PolicyRule{APIGroups: []string{alerting.GroupVersionKind.Group}, Resources: []string{"prometheusrules"},
+			Verbs: []string{"get", "list", "watch", "create", "update", "delete"}})
+	}
+
+	name := sa.Name
+	if cfg.InstanceName != "" {
+		name += "-" + cfg.InstanceName
+	}
+	objs := []runtime.Object{
+		&rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Rules:      cluster,
+		},
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: sa.Name, Namespace: sa.Namespace}},
+		},
+	}
+
+	// Rules per namespace, in the order namespaces are first granted
+	namespaced := make(map[string][]rbacv1.PolicyRule)
+	var namespaces []string
+	grant := func(namespace string, rule rbacv1.PolicyRule) {
+		if _, ok := namespaced[namespace]; !ok {
+			namespaces = append(namespaces, namespace)
+		}
+		namespaced[namespace] = append(namespaced[namespace], rule)
+	}
+	if cfg.IconConfigMap == "" && !cfg.Dashboards {
+		// The output and the substitutions, usage and facts ConfigMaps
+		grant(cfg.DuroNamespace, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: write})
+	}
+	if cfg.OutputKind == config.OutputKindSecret && !cfg.Dashboards {
+		// The output Secret
+		grant(cfg.DuroNamespace, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: write})
+	}
+	if (cfg.APIToken != "" || cfg.DispatchSecret != "") && !discovers(cfg) {
+		grant(cfg.RegistrationNamespaceOrDefault(), rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{"dashboardapps"}, Verbs: []string{"create"}})
+	}
+	if cfg.RestartDeployment != "" {
+		// Rolling out duro again when the output changes
+		key := cfg.RestartDeploymentKey()
+		grant(key.Namespace, rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{key.Name}, Verbs: []string{"patch"}})
+	}
+	if cfg.EnableLeaderElection {
+		namespace := cfg.LeaderElectionNamespace
+		if namespace == "" {
+			namespace = sa.Namespace
+		}
+		grant(namespace, rbacv1.PolicyRule{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"get", "list", "create", "update"}})
+	}
+
+	for _, namespace := range namespaces {
+		objs = append(objs,
+			&rbacv1.Role{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Rules:      namespaced[namespace],
+			},
+			&rbacv1.RoleBinding{
+				TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: sa.Name, Namespace: sa.Namespace}},
+			})
+	}
+	return objs
+}
+
+// discovers reports whether cfg synthesizes DashboardApps from other
+// resources anywhere in the cluster.
+func discovers(cfg *config.OperatorConfig) bool {
+	return cfg.HelmDiscovery || cfg.WorkloadDiscovery || cfg.IngressDiscovery
+}
+
+// YAML renders objs as a multi-document YAML stream.
+func YAML(objs []runtime.Object) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, obj := range objs {
+		doc, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("---\n")
+		buf.Write(doc)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,172 @@
+package rbac
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/fredericrous/duro-operator/pkg/config"
+)
+
+// grants reports whether rules allow verb on resource.
+func grants(rules []rbacv1.PolicyRule, resource, verb string) bool {
+	for _, rule := range rules {
+		if slices.Contains(rule.Resources, resource) && slices.Contains(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestManifests(t *testing.T) {
+	sa := Subject{Name: "duro-operator", Namespace: "duro-system"}
+
+	tests := []struct {
+		name      string
+		configure func(*config.OperatorConfig)
+		// cluster and namespaced list "resource/verb" grants expected in the
+		// ClusterRole and in the Role of a namespace
+		cluster    []string
+		notCluster []string
+		namespaced map[string][]string
+	}{
+		{
+			name:       "defaults confine ConfigMaps to the duro namespace",
+			configure:  func(*config.OperatorConfig) {},
+			cluster:    []string{"dashboardapps/delete", "operatoroverviews/create", "events/create"},
+			notCluster: []string{"configmaps/get", "dashboardapps/create", "secrets/list", "rolebindings/list", "prometheusrules/create", "dashboards/list"},
+			namespaced: map[string][]string{"duro": {"configmaps/update"}},
+		},
+		{
+			name: "icon ConfigMaps need ConfigMaps everywhere",
+			configure: func(c *config.OperatorConfig) {
+				c.IconConfigMap = "duro-icons"
+			},
+			cluster:    []string{"configmaps/create", "configmaps/delete"},
+			namespaced: map[string][]string{},
+		},
+		{
+			name: "dashboards write ConfigMaps in any namespace",
+			configure: func(c *config.OperatorConfig) {
+				c.Dashboards = true
+			},
+			cluster:    []string{"configmaps/update", "dashboards/watch"},
+			namespaced: map[string][]string{},
+		},
+		{
+			name: "a Secret output is confined to the duro namespace",
+			configure: func(c *config.OperatorConfig) {
+				c.OutputKind = config.OutputKindSecret
+			},
+			notCluster: []string{"secrets/get"},
+			namespaced: map[string][]string{"duro": {"configmaps/delete", "secrets/create", "secrets/update"}},
+		},
+		{
+			name: "dashboards may write Secrets in any namespace",
+			configure: func(c *config.OperatorConfig) {
+				c.Dashboards = true
+				c.HelmDiscovery = true
+			},
+			cluster:    []string{"secrets/list", "secrets/create", "secrets/update"},
+			namespaced: map[string][]string{},
+		},
+		{
+			name: "discovery creates apps cluster-wide",
+			configure: func(c *config.OperatorConfig) {
+				c.HelmDiscovery = true
+				c.WorkloadDiscovery = true
+				c.APIToken = "set"
+			},
+			cluster:    []string{"dashboardapps/create", "secrets/list", "deployments/watch"},
+			namespaced: map[string][]string{"duro": {"configmaps/get"}},
+		},
+		{
+			name: "registrations create apps in the registration namespace",
+			configure: func(c *config.OperatorConfig) {
+				c.APIToken = "set"
+				c.RegistrationNamespace = "registered"
+			},
+			notCluster: []string{"dashboardapps/create"},
+			namespaced: map[string][]string{"duro": {"configmaps/get"}, "registered": {"dashboardapps/create"}},
+		},
+		{
+			name: "restarts patch the duro Deployment only",
+			configure: func(c *config.OperatorConfig) {
+				c.RestartDeployment = "apps/duro"
+			},
+			notCluster: []string{"deployments/patch"},
+			namespaced: map[string][]string{"duro": {"configmaps/get"}, "apps": {"deployments/patch"}},
+		},
+		{
+			name: "leader election leases default to the operator namespace",
+			configure: func(c *config.OperatorConfig) {
+				c.EnableLeaderElection = true
+				c.RBACGroups = true
+				c.AlertRules = true
+			},
+			cluster:    []string{"rolebindings/watch", "prometheusrules/delete"},
+			namespaced: map[string][]string{"duro": {"configmaps/get"}, "duro-system": {"leases/update"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			tt.configure(cfg)
+
+			var clusterRules []rbacv1.PolicyRule
+			roles := make(map[string][]rbacv1.PolicyRule)
+			for _, obj := range Manifests(cfg, sa) {
+				switch o := obj.(type) {
+				case *rbacv1.ClusterRole:
+					clusterRules = o.Rules
+				case *rbacv1.Role:
+					roles[o.Namespace] = o.Rules
+				case *rbacv1.ClusterRoleBinding:
+					if o.Subjects[0].Name != sa.Name || o.Subjects[0].Namespace != sa.Namespace {
+						t.Errorf("ClusterRoleBinding subject = %+v, want %+v", o.Subjects[0], sa)
+					}
+				}
+			}
+
+			for _, grant := range tt.cluster {
+				resource, verb, _ := strings.Cut(grant, "/")
+				if !grants(clusterRules, resource, verb) {
+					t.Errorf("ClusterRole does not grant %s", grant)
+				}
+			}
+			for _, grant := range tt.notCluster {
+				resource, verb, _ := strings.Cut(grant, "/")
+				if grants(clusterRules, resource, verb) {
+					t.Errorf("ClusterRole grants %s", grant)
+				}
+			}
+			if len(roles) != len(tt.namespaced) {
+				t.Errorf("Roles in %d namespaces, want %d", len(roles), len(tt.namespaced))
+			}
+			for namespace, want := range tt.namespaced {
+				for _, grant := range want {
+					resource, verb, _ := strings.Cut(grant, "/")
+					if !grants(roles[namespace], resource, verb) {
+						t.Errorf("Role in %s does not grant %s", namespace, grant)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestYAML(t *testing.T) {
+	out, err := YAML(Manifests(config.NewDefaultConfig(), Subject{Name: "duro-operator", Namespace: "duro-system"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(out), "---\n"); n != 4 {
+		t.Errorf("%d documents, want 4", n)
+	}
+	if !strings.Contains(string(out), "kind: ClusterRole\n") {
+		t.Errorf("output has no ClusterRole:\n%s", out)
+	}
+}
@@ -0,0 +1,174 @@
+// Package render loads DashboardApp, DashboardCategory and DashboardBookmark
+// manifests for an offline assembly of the duro output, e.g. to check
+// GitOps manifests before they are applied.
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+	dashboardv1beta1 "github.com/fredericrous/duro-operator/api/v1beta1"
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+var decoder runtime.Decoder
+
+func init() {
+	s := runtime.NewScheme()
+	if err := dashboardv1alpha1.AddToScheme(s); err != nil {
+		panic(err)
+	}
+	if err := dashboardv1beta1.AddToScheme(s); err != nil {
+		panic(err)
+	}
+	decoder = serializer.NewCodecFactory(s).UniversalDeserializer()
+}
+
+// Catalog is the set of objects the output is assembled from
+type Catalog struct {
+	Apps       []dashboardv1alpha1.DashboardApp
+	Categories []dashboardv1alpha1.DashboardCategory
+	Bookmarks  []dashboardv1alpha1.DashboardBookmark
+}
+
+// Decode adds the DashboardApps, DashboardCategories and DashboardBookmarks
+// of a stream of YAML or JSON documents to the catalog; other objects are
+// skipped, so whole GitOps directories can be read. Apps are defaulted the
+// way the admission webhook would, v1beta1 ones are converted to v1alpha1,
+// and objects without a namespace are put in namespace. source names the
+// stream in errors.
+func (c *Catalog) Decode(r io.Reader, source, namespace string) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for i := 1; ; i++ {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if err := c.decodeDocument(doc, namespace); err != nil {
+			return fmt.Errorf("%s: document %d: %w", source, i, err)
+		}
+	}
+}
+
+func (c *Catalog) decodeDocument(doc []byte, namespace string) error {
+	doc = bytes.TrimSpace(doc)
+	if len(doc) == 0 {
+		return nil
+	}
+	var meta struct {
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := yaml.Unmarshal(doc, &meta); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(meta.APIVersion, dashboardv1alpha1.GroupVersion.Group+"/") {
+		return nil
+	}
+
+	obj, _, err := decoder.Decode(doc, nil, nil)
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			return nil
+		}
+		return err
+	}
+	switch o := obj.(type) {
+	case *dashboardv1alpha1.DashboardApp:
+		c.AddApp(*o, namespace)
+	case *dashboardv1beta1.DashboardApp:
+		app := dashboardv1alpha1.DashboardApp{}
+		if err := o.ConvertTo(&app); err != nil {
+			return err
+		}
+		c.AddApp(app, namespace)
+	case *dashboardv1alpha1.DashboardCategory:
+		c.AddCategory(*o)
+	case *dashboardv1alpha1.DashboardBookmark:
+		c.AddBookmark(*o, namespace)
+	}
+	return nil
+}
+
+// AddApp adds app to the catalog, replacing the app of the same namespace
+// and name if any. Apps without a namespace are put in namespace.
+func (c *Catalog) AddApp(app dashboardv1alpha1.DashboardApp, namespace string) {
+	if app.Namespace == "" {
+		app.Namespace = namespace
+	}
+	app.Default()
+	for i := range c.Apps {
+		if c.Apps[i].Namespace == app.Namespace && c.Apps[i].Name == app.Name {
+			c.Apps[i] = app
+			return
+		}
+	}
+	c.Apps = append(c.Apps, app)
+}
+
+// AddCategory adds category to the catalog, replacing the category of the
+// same name if any.
+func (c *Catalog) AddCategory(category dashboardv1alpha1.DashboardCategory) {
+	for i := range c.Categories {
+		if c.Categories[i].Name == category.Name {
+			c.Categories[i] = category
+			return
+		}
+	}
+	c.Categories = append(c.Categories, category)
+}
+
+// AddBookmark adds bookmark to the catalog, replacing the bookmark of the
+// same namespace and name if any. Bookmarks without a namespace are put in
+// namespace.
+func (c *Catalog) AddBookmark(bookmark dashboardv1alpha1.DashboardBookmark, namespace string) {
+	if bookmark.Namespace == "" {
+		bookmark.Namespace = namespace
+	}
+	for i := range c.Bookmarks {
+		if c.Bookmarks[i].Namespace == bookmark.Namespace && c.Bookmarks[i].Name == bookmark.Name {
+			c.Bookmarks[i] = bookmark
+			return
+		}
+	}
+	c.Bookmarks = append(c.Bookmarks, bookmark)
+}
+
+// Findings lists what the operator would report about the assembled apps,
+// as conditions and events, one line each: rule violations, dangling
+// categories, ID collisions, shared display names and strict mode failures.
+func Findings(result *assembler.AssemblyResult) []string {
+	var out []string
+	for _, source := range slices.Sorted(maps.Keys(result.Violations)) {
+		for _, v := range result.Violations[source] {
+			out = append(out, fmt.Sprintf("%s: %s", source, v))
+		}
+	}
+	for _, source := range slices.Sorted(maps.Keys(result.DanglingCategories)) {
+		out = append(out, fmt.Sprintf("%s: category %q is not defined", source, result.DanglingCategories[source]))
+	}
+	for _, c := range result.IDCollisions {
+		out = append(out, fmt.Sprintf("%s share the entry ID %q, only the first is listed", strings.Join(c.Sources, ", "), c.ID))
+	}
+	for _, d := range result.DuplicateNames {
+		out = append(out, fmt.Sprintf("%s share the display name %q", strings.Join(d.Sources, ", "), d.Name))
+	}
+	for _, f := range result.StrictFailures {
+		out = append(out, f.Message())
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fredericrous/duro-operator/pkg/assembler"
+)
+
+const manifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: plex
+---
+apiVersion: dashboard.homelab.io/v1alpha1
+kind: DashboardApp
+metadata:
+  name: plex
+spec:
+  url: plex.example.com/
+  category: Media
+  groups: ["media"]
+---
+apiVersion: dashboard.homelab.io/v1beta1
+kind: DashboardApp
+metadata:
+  name: sonarr
+  namespace: arr
+spec:
+  url: https://sonarr.example.com
+  category: media
+  access:
+    anyOf: ["media", "admins"]
+---
+# comment only
+---
+apiVersion: dashboard.homelab.io/v1alpha1
+kind: DashboardBookmark
+metadata:
+  name: wiki
+spec:
+  url: https://wiki.example.com
+---
+apiVersion: dashboard.homelab.io/v1alpha1
+kind: DashboardCategory
+metadata:
+  name: media
+spec:
+  displayName: Media
+`
+
+func TestCatalog_Decode(t *testing.T) {
+	c := &Catalog{}
+	if err := c.Decode(strings.NewReader(manifests), "apps.yaml", "default"); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(c.Apps) != 2 || len(c.Categories) != 1 || len(c.Bookmarks) != 1 {
+		t.Fatalf("got %d apps, %d categories and %d bookmarks, want 2, 1 and 1", len(c.Apps), len(c.Categories), len(c.Bookmarks))
+	}
+	if c.Bookmarks[0].Namespace != "default" {
+		t.Errorf("bookmark namespace = %q, want default", c.Bookmarks[0].Namespace)
+	}
+
+	plex := c.Apps[0]
+	if plex.Namespace != "default" || plex.Spec.Name != "plex" || plex.Spec.URL != "https://plex.example.com" || plex.Spec.Category != "media" {
+		t.Errorf("plex = %s/%s %+v, want it defaulted into the default namespace", plex.Namespace, plex.Name, plex.Spec)
+	}
+	sonarr := c.Apps[1]
+	if sonarr.Namespace != "arr" || strings.Join(sonarr.Spec.Groups, ",") != "media,admins" || sonarr.Spec.Access != nil {
+		t.Errorf("sonarr = %s/%s groups %v access %v, want the v1beta1 access converted to groups", sonarr.Namespace, sonarr.Name, sonarr.Spec.Groups, sonarr.Spec.Access)
+	}
+
+	t.Run("later manifests replace earlier ones", func(t *testing.T) {
+		err := c.Decode(strings.NewReader(`{"apiVersion":"dashboard.homelab.io/v1alpha1","kind":"DashboardApp","metadata":{"name":"plex"},"spec":{"url":"https://plex.lan","category":"media","groups":["media"]}}`), "plex.json", "default")
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if len(c.Apps) != 2 || c.Apps[0].Spec.URL != "https://plex.lan" {
+			t.Errorf("apps = %+v, want plex replaced", c.Apps)
+		}
+	})
+
+	t.Run("invalid documents name their source", func(t *testing.T) {
+		err := (&Catalog{}).Decode(strings.NewReader("---\napiVersion: dashboard.homelab.io/v1alpha1\nkind: DashboardApp\nspec: [\n"), "broken.yaml", "default")
+		if err == nil || !strings.Contains(err.Error(), "broken.yaml: document") {
+			t.Errorf("Decode() error = %v, want it to name the file and document", err)
+		}
+	})
+}
+
+func TestFindings(t *testing.T) {
+	result := &assembler.AssemblyResult{
+		Violations:         map[string][]string{"media/plex": {"url must use https"}},
+		DanglingCategories: map[string]string{"media/sonarr": "arr"},
+		IDCollisions:       []assembler.IDCollision{{ID: "plex", Sources: []string{"media/plex", "test/plex"}}},
+	}
+	got := Findings(result)
+	want := []string{
+		"media/plex: url must use https",
+		`media/sonarr: category "arr" is not defined`,
+		`media/plex, test/plex share the entry ID "plex", only the first is listed`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Findings() = %q, want %q", got, want)
+	}
+}
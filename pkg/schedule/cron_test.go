@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"* * * * *", false},
+		{"0 20 * * 0-4", false},
+		{"*/15 8-18 * * 1,3,5", false},
+		{"0 0 1 1 7", false},
+		{"5/10 * * * *", false},
+		{"* * * *", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"*/0 * * * *", true},
+		{"5-1 * * * *", true},
+		{"a * * * *", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := ParseCron(tc.expr)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ParseCron(%q) error = %v, wantErr %v", tc.expr, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCron_Matches(t *testing.T) {
+	// Wednesday 2025-01-15 20:00 UTC
+	wed := time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{"0 20 * * 0-4", wed, true},
+		{"0 20 * * 5,6", wed, false},
+		{"*/15 * * * *", wed.Add(45 * time.Minute), true},
+		{"*/15 * * * *", wed.Add(50 * time.Minute), false},
+		{"0 20 * * 7", wed.AddDate(0, 0, 4), true}, // Sunday via 7
+		// both day fields restricted: either may match
+		{"0 20 1 * 3", wed, true},
+		{"0 20 15 * 1", wed, true},
+		{"0 20 1 * 1", wed, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr+"@"+tc.at.Format(time.RFC3339), func(t *testing.T) {
+			c, err := ParseCron(tc.expr)
+			if err != nil {
+				t.Fatalf("ParseCron: %v", err)
+			}
+			if got := c.Matches(tc.at); got != tc.want {
+				t.Errorf("Matches = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
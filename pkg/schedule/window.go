@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	// Embed the time zone database: the runtime image ships without tzdata
+	_ "time/tzdata"
+)
+
+const (
+	// MaxWindowDuration bounds how long a single window occurrence may last
+	MaxWindowDuration = 7 * 24 * time.Hour
+
+	// lookahead bounds the search for the next window start; schedules
+	// firing less often are simply re-evaluated after this long
+	lookahead = 8 * 24 * time.Hour
+)
+
+// Window is a recurring time window that opens whenever Start fires and
+// stays open for Duration.
+type Window struct {
+	Start    *Cron
+	Duration time.Duration
+	Location *time.Location
+}
+
+// NewWindow builds a Window from a cron expression, a duration and an IANA
+// time zone name (empty means UTC).
+func NewWindow(expr string, duration time.Duration, timeZone string) (*Window, error) {
+	if duration <= 0 || duration > MaxWindowDuration {
+		return nil, fmt.Errorf("window duration must be between 1m and %s, got %s", MaxWindowDuration, duration)
+	}
+	start, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	loc := time.UTC
+	if timeZone != "" {
+		if loc, err = time.LoadLocation(timeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+		}
+	}
+	return &Window{Start: start, Duration: duration, Location: loc}, nil
+}
+
+// Active reports whether t falls inside an occurrence of the window and, if
+// so, when that occurrence ends (the latest end when occurrences overlap).
+func (w *Window) Active(t time.Time) (bool, time.Time) {
+	t = t.In(w.Location)
+	var end time.Time
+	for m := t.Truncate(time.Minute); t.Sub(m) < w.Duration; m = m.Add(-time.Minute) {
+		if !w.Start.Matches(m) {
+			continue
+		}
+		if e := m.Add(w.Duration); e.After(t) && e.After(end) {
+			end = e
+		}
+	}
+	return !end.IsZero(), end
+}
+
+// NextChange returns the next instant after t at which Active flips: the end
+// of the current occurrence, or the next start. If no start is found within
+// the lookahead horizon the horizon itself is returned.
+func (w *Window) NextChange(t time.Time) time.Time {
+	if active, end := w.Active(t); active {
+		return end
+	}
+	t = t.In(w.Location)
+	limit := t.Add(lookahead)
+	for m := t.Truncate(time.Minute).Add(time.Minute); m.Before(limit); m = m.Add(time.Minute) {
+		if w.Start.Matches(m) {
+			return m
+		}
+	}
+	return limit
+}
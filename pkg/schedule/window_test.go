@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindow_SchoolNights(t *testing.T) {
+	// Hidden Sunday–Thursday from 20:00 for 11 hours (until 07:00 next day)
+	w, err := NewWindow("0 20 * * 0-4", 11*time.Hour, "Europe/Paris")
+	if err != nil {
+		t.Fatalf("NewWindow: %v", err)
+	}
+	paris, _ := time.LoadLocation("Europe/Paris")
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		active  bool
+		endOrNx time.Time
+	}{
+		{"wednesday evening", time.Date(2025, 1, 15, 21, 30, 0, 0, paris), true, time.Date(2025, 1, 16, 7, 0, 0, 0, paris)},
+		{"thursday early morning", time.Date(2025, 1, 16, 6, 59, 0, 0, paris), true, time.Date(2025, 1, 16, 7, 0, 0, 0, paris)},
+		{"thursday afternoon", time.Date(2025, 1, 16, 15, 0, 0, 0, paris), false, time.Date(2025, 1, 16, 20, 0, 0, 0, paris)},
+		{"friday evening", time.Date(2025, 1, 17, 21, 0, 0, 0, paris), false, time.Date(2025, 1, 19, 20, 0, 0, 0, paris)},
+		{"at start", time.Date(2025, 1, 19, 20, 0, 0, 0, paris), true, time.Date(2025, 1, 20, 7, 0, 0, 0, paris)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			active, _ := w.Active(tc.at)
+			if active != tc.active {
+				t.Errorf("Active = %v, want %v", active, tc.active)
+			}
+			if next := w.NextChange(tc.at); !next.Equal(tc.endOrNx) {
+				t.Errorf("NextChange = %s, want %s", next, tc.endOrNx)
+			}
+		})
+	}
+}
+
+func TestNewWindow_Invalid(t *testing.T) {
+	if _, err := NewWindow("0 20 * * *", 0, ""); err == nil {
+		t.Errorf("expected error for zero duration")
+	}
+	if _, err := NewWindow("0 20 * * *", 8*24*time.Hour, ""); err == nil {
+		t.Errorf("expected error for duration above maximum")
+	}
+	if _, err := NewWindow("0 20 * * *", time.Hour, "Mars/Olympus"); err == nil {
+		t.Errorf("expected error for unknown time zone")
+	}
+	if _, err := NewWindow("nope", time.Hour, ""); err == nil {
+		t.Errorf("expected error for invalid cron")
+	}
+}
@@ -0,0 +1,144 @@
+// Package usage imports per-app usage counts (dashboard clicks) exported by
+// duro, so apps can be ranked by popularity.
+package usage
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+// DefaultKey is the ConfigMap key duro exports usage counts under
+const DefaultKey = "usage.json"
+
+// Counts maps app IDs to how often they were opened
+type Counts map[string]int64
+
+// Source loads usage counts
+type Source interface {
+	Load(ctx context.Context) (Counts, error)
+}
+
+// Parse decodes a usage export: a JSON object mapping app IDs to counts.
+func Parse(data []byte) (Counts, error) {
+	var counts Counts
+	if err := json.Unmarshal(data, &counts); err != nil {
+		return nil, fmt.Errorf("invalid usage export: %w", err)
+	}
+	for id, n := range counts {
+		if n < 0 {
+			return nil, fmt.Errorf("invalid usage export: negative count %d for %q", n, id)
+		}
+	}
+	return counts, nil
+}
+
+// Ranks orders app IDs by count, most used first (ties by ID), and returns
+// each ID's 1-based rank. Apps with a zero count are not ranked.
+func (c Counts) Ranks() map[string]int {
+	ids := make([]string, 0, len(c))
+	for id, n := range c {
+		if n > 0 {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		if n := cmp.Compare(c[b], c[a]); n != 0 {
+			return n
+		}
+		return cmp.Compare(a, b)
+	})
+	ranks := make(map[string]int, len(ids))
+	for i, id := range ids {
+		ranks[id] = i + 1
+	}
+	return ranks
+}
+
+// ConfigMapSource reads usage counts from a ConfigMap key.
+type ConfigMapSource struct {
+	Reader client.Reader
+	Name   types.NamespacedName
+	Key    string
+}
+
+// Load implements Source.
+func (s *ConfigMapSource) Load(ctx context.Context) (Counts, error) {
+	cm := &corev1.ConfigMap{}
+	if err := s.Reader.Get(ctx, s.Name, cm); err != nil {
+		return nil, err
+	}
+	key := s.Key
+	if key == "" {
+		key = DefaultKey
+	}
+	data, ok := cm.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("usage ConfigMap %s has no key %q", s.Name, key)
+	}
+	return Parse([]byte(data))
+}
+
+// HTTPSource fetches usage counts from an HTTP endpoint. While a
+// Retry-After the endpoint sent runs, Load returns the error again without
+// a request.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client
+
+	mu      sync.Mutex
+	retryAt time.Time
+	lastErr error
+}
+
+// NewHTTPSource returns an HTTPSource with a bounded request timeout.
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Load implements Source.
+func (s *HTTPSource) Load(ctx context.Context) (Counts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Now().Before(s.retryAt) {
+		return nil, s.lastErr
+	}
+	counts, err := s.load(ctx)
+	if after, ok := operrors.RetryAfter(err); ok {
+		s.retryAt, s.lastErr = time.Now().Add(after), err
+	}
+	return counts, err
+}
+
+func (s *HTTPSource) load(ctx context.Context) (Counts, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, operrors.WithRetryAfter(resp, fmt.Errorf("usage endpoint returned %s", resp.Status))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
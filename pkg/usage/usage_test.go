@@ -0,0 +1,130 @@
+package usage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	operrors "github.com/fredericrous/duro-operator/pkg/errors"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Counts
+		wantErr bool
+	}{
+		{"valid", `{"plex":12,"gitea":3}`, Counts{"plex": 12, "gitea": 3}, false},
+		{"empty", `{}`, Counts{}, false},
+		{"negative", `{"plex":-1}`, nil, true},
+		{"not an object", `[1,2]`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() = %v, want %v", got, tt.want)
+			}
+			for id, n := range tt.want {
+				if got[id] != n {
+					t.Errorf("count[%s] = %d, want %d", id, got[id], n)
+				}
+			}
+		})
+	}
+}
+
+func TestRanks(t *testing.T) {
+	ranks := Counts{"plex": 12, "gitea": 3, "wiki": 12, "unused": 0}.Ranks()
+	want := map[string]int{"plex": 1, "wiki": 2, "gitea": 3}
+	if len(ranks) != len(want) {
+		t.Fatalf("Ranks() = %v, want %v", ranks, want)
+	}
+	for id, r := range want {
+		if ranks[id] != r {
+			t.Errorf("rank[%s] = %d, want %d", id, ranks[id], r)
+		}
+	}
+}
+
+func TestConfigMapSource(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "duro-usage", Namespace: "duro"},
+		Data:       map[string]string{DefaultKey: `{"plex":5}`},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(s).WithObjects(cm).Build()
+
+	src := &ConfigMapSource{Reader: c, Name: types.NamespacedName{Name: "duro-usage", Namespace: "duro"}}
+	counts, err := src.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if counts["plex"] != 5 {
+		t.Errorf("counts = %v, want plex=5", counts)
+	}
+
+	src.Key = "missing.json"
+	if _, err := src.Load(context.Background()); err == nil {
+		t.Errorf("Load() with missing key should fail")
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/usage" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"gitea":7}`))
+	}))
+	defer srv.Close()
+
+	counts, err := NewHTTPSource(srv.URL + "/api/usage").Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if counts["gitea"] != 7 {
+		t.Errorf("counts = %v, want gitea=7", counts)
+	}
+
+	if _, err := NewHTTPSource(srv.URL + "/nope").Load(context.Background()); err == nil {
+		t.Errorf("Load() on 404 should fail")
+	}
+
+	t.Run("retry after", func(t *testing.T) {
+		var calls int
+		throttled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer throttled.Close()
+
+		s := NewHTTPSource(throttled.URL)
+		for range 2 {
+			_, err := s.Load(context.Background())
+			if after, ok := operrors.RetryAfter(err); !ok || after != time.Hour {
+				t.Fatalf("Load() error = %v, want a one hour retry-after", err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("endpoint called %d times, want 1: the second load waits for the retry-after", calls)
+		}
+	})
+}
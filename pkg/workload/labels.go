@@ -0,0 +1,87 @@
+// Package workload turns Docker-Compose-style duro.* labels and annotations
+// on workloads into DashboardApp specs, the way Traefik discovers services
+// from container labels.
+package workload
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	dashboardv1alpha1 "github.com/fredericrous/duro-operator/api/v1alpha1"
+)
+
+const (
+	// Prefix prefixes the keys read from workload labels and annotations
+	Prefix = "duro."
+
+	// KeyEnable opts a workload in when set to "true"
+	KeyEnable = Prefix + "enable"
+
+	// SourceWorkload is the source label value of DashboardApps synthesized
+	// from workloads
+	SourceWorkload = "workload"
+
+	// DefaultCategory is used when duro.category is not set
+	DefaultCategory = "other"
+
+	// DefaultIcon is used when duro.icon is not set
+	DefaultIcon = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><rect x="3" y="3" width="18" height="18" rx="3" fill="none" stroke="currentColor" stroke-width="2"/></svg>`
+)
+
+// AppSpec builds the DashboardApp spec declared by a workload named name.
+// Keys are read from labels, then annotations (which win, since label values
+// cannot hold URLs or SVG): duro.enable, duro.name, duro.url, duro.category,
+// duro.icon, duro.groups (comma-separated) and duro.priority. It returns
+// false if the workload is not enabled, and an error if it is but lacks a
+// URL or groups.
+func AppSpec(name string, sources ...map[string]string) (*dashboardv1alpha1.DashboardAppSpec, bool, error) {
+	fields := map[string]string{}
+	for _, src := range sources {
+		for k, v := range src {
+			if key, ok := strings.CutPrefix(k, Prefix); ok {
+				fields[key] = v
+			}
+		}
+	}
+	if enabled, _ := strconv.ParseBool(fields["enable"]); !enabled {
+		return nil, false, nil
+	}
+
+	spec := &dashboardv1alpha1.DashboardAppSpec{
+		Name:     name,
+		URL:      fields["url"],
+		Category: DefaultCategory,
+		Icon:     DefaultIcon,
+		Priority: 100,
+	}
+	if v := fields["name"]; v != "" {
+		spec.Name = v
+	}
+	if v := fields["category"]; v != "" {
+		spec.Category = v
+	}
+	if v := fields["icon"]; v != "" {
+		spec.Icon = v
+	}
+	for _, g := range strings.Split(fields["groups"], ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			spec.Groups = append(spec.Groups, g)
+		}
+	}
+	if p := fields["priority"]; p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid %spriority %q", Prefix, p)
+		}
+		spec.Priority = n
+	}
+
+	if spec.URL == "" {
+		return nil, true, fmt.Errorf("%surl is required", Prefix)
+	}
+	if len(spec.Groups) == 0 {
+		return nil, true, fmt.Errorf("%sgroups is required", Prefix)
+	}
+	return spec, true, nil
+}
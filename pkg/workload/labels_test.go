@@ -0,0 +1,88 @@
+package workload
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAppSpec(t *testing.T) {
+	tests := []struct {
+		name         string
+		labels       map[string]string
+		annotations  map[string]string
+		wantEnabled  bool
+		wantErr      bool
+		wantName     string
+		wantCategory string
+		wantGroups   []string
+		wantPriority int
+	}{
+		{
+			name:        "not enabled",
+			labels:      map[string]string{"duro.name": "Plex"},
+			annotations: map[string]string{"duro.url": "https://plex"},
+		},
+		{
+			name:         "labels and annotations",
+			labels:       map[string]string{"duro.enable": "true", "duro.groups": "family", "app": "plex"},
+			annotations:  map[string]string{"duro.url": "https://plex.example.test", "duro.name": "Plex"},
+			wantEnabled:  true,
+			wantName:     "Plex",
+			wantCategory: DefaultCategory,
+			wantGroups:   []string{"family"},
+			wantPriority: 100,
+		},
+		{
+			name:   "annotations win",
+			labels: map[string]string{"duro.enable": "true", "duro.category": "media", "duro.groups": "family"},
+			annotations: map[string]string{
+				"duro.url": "https://plex", "duro.category": "video", "duro.groups": "family,friends", "duro.priority": "5",
+			},
+			wantEnabled:  true,
+			wantName:     "plex",
+			wantCategory: "video",
+			wantGroups:   []string{"family", "friends"},
+			wantPriority: 5,
+		},
+		{
+			name:        "missing url",
+			labels:      map[string]string{"duro.enable": "true", "duro.groups": "family"},
+			wantEnabled: true,
+			wantErr:     true,
+		},
+		{
+			name:        "missing groups",
+			labels:      map[string]string{"duro.enable": "true"},
+			annotations: map[string]string{"duro.url": "https://plex"},
+			wantEnabled: true,
+			wantErr:     true,
+		},
+		{
+			name:        "invalid priority",
+			labels:      map[string]string{"duro.enable": "true", "duro.groups": "family", "duro.priority": "first"},
+			annotations: map[string]string{"duro.url": "https://plex"},
+			wantEnabled: true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, enabled, err := AppSpec("plex", tt.labels, tt.annotations)
+			if enabled != tt.wantEnabled {
+				t.Fatalf("AppSpec() enabled = %v, want %v", enabled, tt.wantEnabled)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AppSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if spec == nil {
+				return
+			}
+			if spec.Name != tt.wantName || spec.Category != tt.wantCategory || spec.Priority != tt.wantPriority {
+				t.Errorf("spec = %+v", spec)
+			}
+			if !slices.Equal(spec.Groups, tt.wantGroups) {
+				t.Errorf("groups = %v, want %v", spec.Groups, tt.wantGroups)
+			}
+		})
+	}
+}